@@ -0,0 +1,54 @@
+// Package admin contains the message of the dev-mode admin API, which edits live entity state without a dedicated
+// message and system for each edit.
+package admin
+
+import (
+	"encoding/json"
+
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+const (
+	// MessageGroup is the group of the admin message. Messages of this group can't be submitted through /tx.
+	MessageGroup = "admin"
+	// OperationsMessageName is the name of the message that carries admin operations.
+	OperationsMessageName = "operations"
+
+	// PersonaTag is the persona tag of admin transactions. It is not a valid persona tag, so no persona can claim it.
+	PersonaTag = "$admin"
+)
+
+type OperationType string
+
+const (
+	// OpCreate creates an entity with the given Components.
+	OpCreate OperationType = "create"
+	// OpRemove removes the given Entity.
+	OpRemove OperationType = "remove"
+	// OpSetComponent sets the Value of the given Component on the given Entity.
+	OpSetComponent OperationType = "set-component"
+	// OpAddComponent adds the given Component to the given Entity. If a Value is given, it is set as well.
+	OpAddComponent OperationType = "add-component"
+	// OpRemoveComponent removes the given Component from the given Entity.
+	OpRemoveComponent OperationType = "remove-component"
+)
+
+// Operation is a single edit of the entity state.
+type Operation struct {
+	Type       OperationType              `json:"type"`
+	Entity     types.EntityID             `json:"entity,omitempty"`
+	Component  string                     `json:"component,omitempty"`
+	Value      json.RawMessage            `json:"value,omitempty" swaggertype:"object"`
+	Components map[string]json.RawMessage `json:"components,omitempty" swaggertype:"object"`
+}
+
+// Operations are applied in order during the next tick. Applying stops at the first operation that fails, but the
+// operations before it are kept, like the state changes of any other message that fails halfway.
+type Operations struct {
+	Operations []Operation `json:"operations"`
+}
+
+type OperationsResult struct {
+	// Created contains the IDs of the entities created by OpCreate operations, in order.
+	Created []types.EntityID `json:"created"`
+}
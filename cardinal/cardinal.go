@@ -17,6 +17,8 @@ var (
 	ErrEntityMutationOnReadOnly          = errors.New("cannot modify state with read only context")
 	ErrEntitiesCreatedBeforeReady        = errors.New("entities should not be created before world is ready")
 	ErrEntityDoesNotExist                = gamestate.ErrEntityDoesNotExist
+	ErrStaleEntityID                     = gamestate.ErrStaleEntityID
+	ErrEntityNeverExisted                = gamestate.ErrEntityNeverExisted
 	ErrEntityMustHaveAtLeastOneComponent = gamestate.ErrEntityMustHaveAtLeastOneComponent
 	ErrComponentNotOnEntity              = gamestate.ErrComponentNotOnEntity
 	ErrComponentAlreadyOnEntity          = gamestate.ErrComponentAlreadyOnEntity
@@ -150,6 +152,61 @@ func RegisterQuery[Request any, Reply any](
 	return res
 }
 
+// RegisterEvent registers a typed event to the world. Registered events are described by the /world endpoint, which
+// lets clients decode them into typed values. Registered events are emitted with EmitTypedEvent and EmitTypedEventTo.
+func RegisterEvent[T types.Event](w *World) error {
+	if w.worldStage.Current() != worldstage.Init {
+		return eris.Errorf(
+			"world state is %s, expected %s to register event",
+			w.worldStage.Current(),
+			worldstage.Init,
+		)
+	}
+
+	var event T
+	eventType := reflect.TypeOf(event)
+	if _, err := types.GetJSONSchema(eventType); err != nil {
+		return eris.Wrap(err, "event must be json serializable")
+	}
+	return w.RegisterEvent(event.Name(), eventType)
+}
+
+// EmitTypedEvent emits a registered event that will be broadcast to all websocket subscribers. The event is wrapped
+// in a types.TypedEvent, which carries the name of the event.
+func EmitTypedEvent(wCtx WorldContext, event types.Event) error {
+	typedEvent, err := newTypedEvent(wCtx, event)
+	if err != nil {
+		return err
+	}
+	return wCtx.emitEvent(typedEvent)
+}
+
+// EmitTypedEventTo emits a registered event that will only be delivered to websocket subscribers that have
+// authenticated as the given persona. The event is wrapped in a types.TypedEvent, which carries the name of the event.
+func EmitTypedEventTo(wCtx WorldContext, personaTag string, event types.Event) error {
+	if personaTag == "" {
+		return eris.New("persona tag is required to emit a targeted event")
+	}
+	typedEvent, err := newTypedEvent(wCtx, event)
+	if err != nil {
+		return err
+	}
+	return wCtx.emitEventTo(personaTag, typedEvent)
+}
+
+func newTypedEvent(wCtx WorldContext, event types.Event) (types.TypedEvent, error) {
+	eventType, ok := wCtx.getEventType(event.Name())
+	if !ok {
+		return types.TypedEvent{}, eris.Errorf("event %q is not registered", event.Name())
+	}
+	if eventType != reflect.TypeOf(event) {
+		return types.TypedEvent{}, eris.Errorf(
+			"event %q is registered as %s, but emitted as %T", event.Name(), eventType, event,
+		)
+	}
+	return types.TypedEvent{Event: event.Name(), Data: event}, nil
+}
+
 // Create creates a single entity in the world, and returns the id of the newly created entity.
 // At least 1 component must be provided.
 func Create(wCtx WorldContext, components ...types.Component) (_ types.EntityID, err error) {
@@ -348,7 +405,8 @@ func RemoveComponentFrom[T types.Component](wCtx WorldContext, id types.EntityID
 	return nil
 }
 
-// Remove removes the given Entity from the world.
+// Remove removes the given Entity from the world, along with its relations. The entities that are related to it by a
+// relation with the CascadeOnRemove policy, e.g. its children, are removed as well.
 func Remove(wCtx WorldContext, id types.EntityID) (err error) {
 	defer func() { panicOnFatalError(wCtx, err) }()
 
@@ -357,7 +415,7 @@ func Remove(wCtx WorldContext, id types.EntityID) (err error) {
 		return ErrEntityMutationOnReadOnly
 	}
 
-	err = wCtx.storeManager().RemoveEntity(id)
+	err = removeEntity(wCtx, id, map[types.EntityID]bool{})
 	if err != nil {
 		return err
 	}
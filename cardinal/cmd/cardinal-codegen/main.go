@@ -0,0 +1,92 @@
+// Command cardinal-codegen generates typed TypeScript and Go clients for a Cardinal world.
+//
+// The world is read either live from the /world endpoint of a running Cardinal server:
+//
+//	cardinal-codegen -url http://localhost:4040 -ts client.ts -go client/client.go
+//
+// or offline from the world binary, which describes itself without starting the game:
+//
+//	cardinal-codegen -binary ./game -ts client.ts
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"pkg.world.dev/world-engine/cardinal/codegen"
+	"pkg.world.dev/world-engine/cardinal/server/handler"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "cardinal-codegen:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	url := flag.String("url", "", "base URL of a running Cardinal server to read the world from")
+	binary := flag.String("binary", "", "path to a world binary to read the world from")
+	tsOut := flag.String("ts", "", "path to write the TypeScript client to")
+	goOut := flag.String("go", "", "path to write the Go client to")
+	goPackage := flag.String("go-package", "", "package name of the Go client (default: name of its directory)")
+	flag.Parse()
+
+	if (*url == "") == (*binary == "") {
+		return fmt.Errorf("exactly one of -url and -binary must be set")
+	}
+	if *tsOut == "" && *goOut == "" {
+		return fmt.Errorf("at least one of -ts and -go must be set")
+	}
+
+	ctx := context.Background()
+	var world *handler.GetWorldResponse
+	var err error
+	if *url != "" {
+		world, err = codegen.FetchWorld(ctx, *url)
+	} else {
+		world, err = codegen.DescribeBinary(ctx, *binary)
+	}
+	if err != nil {
+		return err
+	}
+
+	if *tsOut != "" {
+		src, err := codegen.GenerateTypeScript(world)
+		if err != nil {
+			return err
+		}
+		if err := writeFile(*tsOut, src); err != nil {
+			return err
+		}
+	}
+
+	if *goOut != "" {
+		pkg := *goPackage
+		if pkg == "" {
+			abs, err := filepath.Abs(*goOut)
+			if err != nil {
+				return err
+			}
+			pkg = filepath.Base(filepath.Dir(abs))
+		}
+		src, err := codegen.GenerateGo(world, pkg)
+		if err != nil {
+			return err
+		}
+		if err := writeFile(*goOut, src); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeFile(path string, src []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, src, 0o644) //nolint:gosec // generated source is not secret
+}
@@ -0,0 +1,359 @@
+// Package codegen generates typed clients for a Cardinal world from the description of the world that is served at
+// /world. The description lists the registered messages, queries, components, and events along with the JSON schemas
+// of their types, which the generated clients mirror, so that clients don't have to hand-copy the structs from the
+// Cardinal source.
+package codegen
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/admin"
+	"pkg.world.dev/world-engine/cardinal/server/handler"
+	"pkg.world.dev/world-engine/cardinal/server/openapi"
+)
+
+const (
+	// Header is the first line of every generated file.
+	Header = "Code generated by cardinal-codegen. DO NOT EDIT."
+
+	// describeWorldEnvVariable makes a world binary write its description to the given path instead of starting.
+	describeWorldEnvVariable = "CARDINAL_DESCRIBE_WORLD"
+
+	defaultGroup = "game"
+)
+
+// FetchWorld reads the description of a running world from its /world endpoint.
+func FetchWorld(ctx context.Context, baseURL string) (*handler.GetWorldResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(baseURL, "/")+"/world", nil)
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to create world request")
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to fetch world")
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, eris.Errorf("failed to fetch world: unexpected status %s", res.Status)
+	}
+
+	world := new(handler.GetWorldResponse)
+	if err := json.NewDecoder(res.Body).Decode(world); err != nil {
+		return nil, eris.Wrap(err, "failed to decode world")
+	}
+	return world, nil
+}
+
+// DescribeBinary reads the description of a world from its binary, without starting the game. The binary is run with
+// CARDINAL_DESCRIBE_WORLD set, which makes it write the description and exit once StartGame is called. Components are
+// checked against the schemas stored in Redis when they are registered, so the binary must be able to reach Redis.
+func DescribeBinary(ctx context.Context, binaryPath string) (*handler.GetWorldResponse, error) {
+	dir, err := os.MkdirTemp("", "cardinal-codegen")
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to create temporary directory")
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "world.json")
+
+	cmd := exec.CommandContext(ctx, binaryPath)
+	cmd.Env = append(os.Environ(), describeWorldEnvVariable+"="+path)
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, eris.Wrapf(err, "failed to run %s", binaryPath)
+	}
+
+	bz, err := os.ReadFile(path)
+	if err != nil {
+		return nil, eris.Wrap(err, "world binary did not write a description, is it built with a recent cardinal?")
+	}
+	world := new(handler.GetWorldResponse)
+	if err := json.Unmarshal(bz, world); err != nil {
+		return nil, eris.Wrap(err, "failed to decode world")
+	}
+	return world, nil
+}
+
+// model is the language agnostic description of a client, which the generators for each language render.
+type model struct {
+	namespace  string
+	types      []namedType
+	components []component
+	messages   []endpoint
+	queries    []endpoint
+	events     []event
+}
+
+type namedType struct {
+	ident  string
+	schema any
+}
+
+type component struct {
+	name   string
+	schema any
+}
+
+type endpoint struct {
+	group  string
+	name   string
+	url    string
+	ident  string
+	input  any
+	result any
+}
+
+type event struct {
+	name   string
+	ident  string
+	schema any
+}
+
+// newModel collects the types of the world, which are named after their Go types, or after the component they are
+// registered as. Names that would shadow the helpers of a generated client are given a numeric suffix.
+func newModel(world *handler.GetWorldResponse, reserved []string) (*model, error) {
+	schemas := openapi.NewSchemaSet()
+	m := &model{namespace: world.Namespace}
+
+	for _, c := range world.Components {
+		schema, err := schemas.AddNamed(c.Name, c.Schema)
+		if err != nil {
+			return nil, eris.Wrapf(err, "failed to read schema of component %s", c.Name)
+		}
+		m.components = append(m.components, component{name: c.Name, schema: schema})
+	}
+
+	var err error
+	for _, msg := range world.Messages {
+		group, name := splitURL(msg.URL, "/tx/")
+		// Admin operations can only be submitted through the admin API.
+		if group == admin.MessageGroup {
+			continue
+		}
+		e := endpoint{group: group, name: name, url: msg.URL, ident: endpointIdent(group, name)}
+		if e.input, err = schemas.Add(msg.Schema); err != nil {
+			return nil, eris.Wrapf(err, "failed to read input schema of message %s", msg.Name)
+		}
+		if e.result, err = schemas.Add(msg.ResultSchema); err != nil {
+			return nil, eris.Wrapf(err, "failed to read result schema of message %s", msg.Name)
+		}
+		m.messages = append(m.messages, e)
+	}
+
+	for _, q := range world.Queries {
+		group, name := splitURL(q.URL, "/query/")
+		e := endpoint{group: group, name: name, url: q.URL, ident: endpointIdent(group, name)}
+		if e.input, err = schemas.Add(q.Schema); err != nil {
+			return nil, eris.Wrapf(err, "failed to read request schema of query %s", q.Name)
+		}
+		if e.result, err = schemas.Add(q.ResultSchema); err != nil {
+			return nil, eris.Wrapf(err, "failed to read reply schema of query %s", q.Name)
+		}
+		m.queries = append(m.queries, e)
+	}
+
+	for _, ev := range world.Events {
+		e := event{name: ev.Name, ident: pascalCase(ev.Name)}
+		if e.schema, err = schemas.Add(ev.Schema); err != nil {
+			return nil, eris.Wrapf(err, "failed to read schema of event %s", ev.Name)
+		}
+		m.events = append(m.events, e)
+	}
+
+	sortEndpoints(m.messages)
+	sortEndpoints(m.queries)
+	sort.Slice(m.components, func(i, j int) bool { return m.components[i].name < m.components[j].name })
+	sort.Slice(m.events, func(i, j int) bool { return m.events[i].name < m.events[j].name })
+
+	// Types are named in order, so that the suffixes given to clashing names are stable.
+	names := make([]string, 0, len(schemas.Schemas()))
+	for name := range schemas.Schemas() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	idents := newIdentAllocator(reserved)
+	renames := make(map[string]string, len(names))
+	for _, name := range names {
+		renames[name] = idents.allocate(pascalCase(name))
+	}
+	for _, name := range names {
+		m.types = append(m.types, namedType{
+			ident:  renames[name],
+			schema: renameRefs(schemas.Schemas()[name], renames),
+		})
+	}
+	for i := range m.components {
+		m.components[i].schema = renameRefs(m.components[i].schema, renames)
+	}
+	for _, endpoints := range [][]endpoint{m.messages, m.queries} {
+		for i := range endpoints {
+			endpoints[i].input = renameRefs(endpoints[i].input, renames)
+			endpoints[i].result = renameRefs(endpoints[i].result, renames)
+		}
+	}
+	for i := range m.events {
+		m.events[i].schema = renameRefs(m.events[i].schema, renames)
+	}
+	return m, nil
+}
+
+func sortEndpoints(endpoints []endpoint) {
+	sort.Slice(endpoints, func(i, j int) bool { return endpoints[i].url < endpoints[j].url })
+}
+
+// splitURL returns the group and name of a message or query from its URL.
+func splitURL(url string, prefix string) (string, string) {
+	group, name, _ := strings.Cut(strings.TrimPrefix(url, prefix), "/")
+	return group, name
+}
+
+// endpointIdent names the client method of a message or query. Messages and queries of the default group are named
+// after their name only.
+func endpointIdent(group, name string) string {
+	if group == defaultGroup {
+		return pascalCase(name)
+	}
+	return pascalCase(group + "-" + name)
+}
+
+// renameRefs replaces the references to the schema set with the identifiers of the named types.
+func renameRefs(schema any, renames map[string]string) any {
+	switch v := schema.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, value := range v {
+			if ref, ok := value.(string); ok && key == "$ref" {
+				out[key] = renames[strings.TrimPrefix(ref, openapi.SchemaRefPrefix)]
+				continue
+			}
+			out[key] = renameRefs(value, renames)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, value := range v {
+			out[i] = renameRefs(value, renames)
+		}
+		return out
+	default:
+		return schema
+	}
+}
+
+// identAllocator hands out unique identifiers.
+type identAllocator struct {
+	used map[string]bool
+}
+
+func newIdentAllocator(reserved []string) *identAllocator {
+	a := &identAllocator{used: make(map[string]bool)}
+	for _, ident := range reserved {
+		a.used[ident] = true
+	}
+	return a
+}
+
+func (a *identAllocator) allocate(ident string) string {
+	name := ident
+	for i := 2; a.used[name]; i++ {
+		name = ident + strconv.Itoa(i)
+	}
+	a.used[name] = true
+	return name
+}
+
+// pascalCase turns a name such as "create-persona" into an exported identifier such as "CreatePersona". Letters that
+// are already upper case are kept, so Go type names are left as they are.
+func pascalCase(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	ident := b.String()
+	if ident == "" || unicode.IsDigit([]rune(ident)[0]) {
+		ident = "T" + ident
+	}
+	return ident
+}
+
+// camelCase turns a name such as "create-persona" into an identifier such as "createPersona".
+func camelCase(name string) string {
+	ident := []rune(pascalCase(name))
+	ident[0] = unicode.ToLower(ident[0])
+	return string(ident)
+}
+
+// schemaObject returns the JSON schema as an object. Schemas that are not objects, such as the true schema, accept
+// any value and are returned as an empty object.
+func schemaObject(schema any) map[string]any {
+	obj, ok := schema.(map[string]any)
+	if !ok {
+		return map[string]any{}
+	}
+	return obj
+}
+
+// properties returns the names of the properties of an object schema, in sorted order, and whether each one is required.
+func properties(schema map[string]any) ([]string, map[string]any, map[string]bool) {
+	props, _ := schema["properties"].(map[string]any)
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	required := make(map[string]bool)
+	if list, ok := schema["required"].([]any); ok {
+		for _, name := range list {
+			if s, ok := name.(string); ok {
+				required[s] = true
+			}
+		}
+	}
+	return names, props, required
+}
+
+// mapValues returns the schema of the values of a map schema, if the schema describes a map.
+func mapValues(schema map[string]any) (any, bool) {
+	if _, ok := schema["properties"]; ok {
+		return nil, false
+	}
+	if values, ok := schema["additionalProperties"].(map[string]any); ok {
+		return values, true
+	}
+	// Maps with non string keys are described with a single pattern for their keys.
+	if patterns, ok := schema["patternProperties"].(map[string]any); ok && len(patterns) == 1 {
+		for _, values := range patterns {
+			return values, true
+		}
+	}
+	return nil, false
+}
+
+func quote(s string) string {
+	return strconv.Quote(s)
+}
+
+func writef(b *strings.Builder, format string, args ...any) {
+	fmt.Fprintf(b, format, args...)
+}
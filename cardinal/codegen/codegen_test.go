@@ -0,0 +1,135 @@
+package codegen_test
+
+import (
+	"context"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+	"time"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal"
+	"pkg.world.dev/world-engine/cardinal/codegen"
+	"pkg.world.dev/world-engine/cardinal/server/handler"
+)
+
+type Position struct {
+	X, Y int
+}
+
+func (Position) Name() string { return "position" }
+
+type Move struct {
+	Direction string `json:"direction"`
+	Steps     int    `json:"steps,omitempty"`
+}
+
+type MoveResult struct {
+	Position Position `json:"position"`
+}
+
+type PlayerRequest struct {
+	Player string `json:"player"`
+}
+
+type PlayerReply struct {
+	Positions []Position          `json:"positions"`
+	Scores    map[string]float64  `json:"scores"`
+	Tags      map[string][]string `json:"tags"`
+}
+
+type PlayerMoved struct {
+	Player string   `json:"player"`
+	To     Position `json:"to"`
+}
+
+func (PlayerMoved) Name() string { return "player-moved" }
+
+// Receipt clashes with the name of a helper of the generated Go client.
+type Receipt struct {
+	ID string
+}
+
+func (Receipt) Name() string { return "receipt" }
+
+func newWorld(t *testing.T) *handler.GetWorldResponse {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[Position](world))
+	assert.NilError(t, cardinal.RegisterMessage[Move, MoveResult](world, "move"))
+	assert.NilError(t, cardinal.RegisterQuery[PlayerRequest, PlayerReply](world, "player",
+		func(_ cardinal.WorldContext, _ *PlayerRequest) (*PlayerReply, error) {
+			return &PlayerReply{}, nil
+		}))
+	assert.NilError(t, cardinal.RegisterEvent[PlayerMoved](world))
+	assert.NilError(t, cardinal.RegisterEvent[Receipt](world))
+	tf.StartWorld()
+
+	// Wait for the server to accept connections
+	var res *handler.GetWorldResponse
+	assert.Eventually(t, func() bool {
+		var err error
+		res, err = codegen.FetchWorld(context.Background(), "http://"+tf.BaseURL)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	return res
+}
+
+func TestGenerateGo(t *testing.T) {
+	world := newWorld(t)
+	src, err := codegen.GenerateGo(world, "client")
+	assert.NilError(t, err)
+
+	file, err := parser.ParseFile(token.NewFileSet(), "client.go", src, parser.ParseComments)
+	assert.NilError(t, err, string(src))
+	assert.Equal(t, file.Name.Name, "client")
+	assert.Check(t, strings.HasPrefix(string(src), "// "+codegen.Header))
+
+	decls := make(map[string]bool)
+	for _, obj := range file.Scope.Objects {
+		decls[obj.Name] = true
+	}
+	for _, name := range []string{"Position", "Move", "MoveResult", "PlayerRequest", "PlayerReply", "PlayerMoved",
+		"Receipt", "Receipt2", "Client", "NewClient", "DecodeMoveResult", "DecodeEvent", "Namespace"} {
+		assert.Check(t, decls[name], "missing declaration of %s", name)
+	}
+
+	for _, want := range []string{
+		"func (c *Client) SubmitMove(",
+		"msg Move,",
+		"func (c *Client) QueryPlayer(ctx context.Context, req PlayerRequest) (*PlayerReply, error)",
+		"func (c *Client) SubmitPersonaCreatePersona(",
+		"Steps     int64  `json:\"steps,omitempty\"`",
+		"Scores    map[string]float64  `json:\"scores\"`",
+		"Tags      map[string][]string `json:\"tags\"`",
+		"case \"player-moved\":",
+		"var event PlayerMoved",
+		"var event Receipt2",
+	} {
+		assert.Check(t, strings.Contains(string(src), want), "generated go client is missing %q", want)
+	}
+	// Admin operations are not served under /tx.
+	assert.Check(t, !strings.Contains(string(src), "/tx/admin/"))
+}
+
+func TestGenerateTypeScript(t *testing.T) {
+	world := newWorld(t)
+	src, err := codegen.GenerateTypeScript(world)
+	assert.NilError(t, err)
+
+	for _, want := range []string{
+		"export interface Move {\n  direction: string;\n  steps?: number;\n}",
+		"export interface PlayerReply {\n  positions: Array<Position>;\n  scores: Record<string, number>;\n",
+		"\"game.move\": { input: Move; result: MoveResult };",
+		"\"game.player\": { request: PlayerRequest; reply: PlayerReply };",
+		"\"player-moved\": PlayerMoved;",
+		"\"position\": Position;",
+		"submitMove(",
+		"queryPlayer(",
+		"submitPersonaCreatePersona(",
+		"return this.post(\"/tx/game/move\", tx);",
+	} {
+		assert.Check(t, strings.Contains(string(src), want), "generated typescript client is missing %q", want)
+	}
+}
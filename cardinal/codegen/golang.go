@@ -0,0 +1,263 @@
+package codegen
+
+import (
+	"go/format"
+	"strings"
+
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/server/handler"
+)
+
+// goReserved are the identifiers of the helpers of a generated Go client.
+var goReserved = []string{
+	"Namespace", "Client", "NewClient", "TxResponse", "Receipt", "ListReceiptsResponse", "TickResults",
+	"DecodeEvent",
+}
+
+// GenerateGo returns the source of a Go client for the world, in a package with the given name. The client signs
+// transactions with the sign package, and decodes receipts and events into the types of the world.
+func GenerateGo(world *handler.GetWorldResponse, packageName string) ([]byte, error) {
+	m, err := newModel(world, goReserved)
+	if err != nil {
+		return nil, err
+	}
+	g := &goGenerator{}
+	src := g.generate(m, packageName)
+	formatted, err := format.Source([]byte(src))
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to format generated go client")
+	}
+	return formatted, nil
+}
+
+type goGenerator struct {
+	usesTime bool
+}
+
+func (g *goGenerator) generate(m *model, packageName string) string {
+	var body strings.Builder
+
+	writef(&body, "// Namespace is the namespace of the world the client was generated for.\n")
+	writef(&body, "const Namespace = %s\n\n", quote(m.namespace))
+
+	for _, t := range m.types {
+		writef(&body, "type %s %s\n\n", t.ident, g.typeOf(t.schema))
+	}
+
+	writef(&body, "%s\n", goClientSource)
+
+	for _, msg := range m.messages {
+		input, result := g.typeOf(msg.input), g.typeOf(msg.result)
+		writef(&body, "// Submit%s submits the %s message, signed by the given persona.\n", msg.ident, msg.name)
+		writef(&body, "func (c *Client) Submit%s(\n", msg.ident)
+		writef(&body, "\tctx context.Context, pk *ecdsa.PrivateKey, personaTag string, msg %s,\n", input)
+		writef(&body, ") (*TxResponse, error) {\n")
+		writef(&body, "\treturn c.submit(ctx, %s, pk, personaTag, msg)\n}\n\n", quote(msg.url))
+		writef(&body, "// Decode%sResult decodes the result of the %s message from its receipt.\n", msg.ident, msg.name)
+		writef(&body, "func Decode%sResult(r Receipt) (%s, error) {\n", msg.ident, result)
+		writef(&body, "\tvar result %s\n", result)
+		writef(&body, "\terr := json.Unmarshal(r.Result, &result)\n")
+		writef(&body, "\treturn result, err\n}\n\n")
+	}
+
+	for _, q := range m.queries {
+		request, reply := g.typeOf(q.input), g.typeOf(q.result)
+		writef(&body, "// Query%s runs the %s query.\n", q.ident, q.name)
+		writef(&body, "func (c *Client) Query%s(ctx context.Context, req %s) (*%s, error) {\n", q.ident, request, reply)
+		writef(&body, "\treply := new(%s)\n", reply)
+		writef(&body, "\tif err := c.post(ctx, %s, req, reply); err != nil {\n", quote(q.url))
+		writef(&body, "\t\treturn nil, err\n\t}\n")
+		writef(&body, "\treturn reply, nil\n}\n\n")
+	}
+
+	writef(&body, "// DecodeEvent decodes an event emitted with cardinal.EmitTypedEvent into the type it was registered with.\n")
+	writef(&body, "// The name of the event is returned along with it. Events that are not typed are returned as is.\n")
+	writef(&body, "func DecodeEvent(raw []byte) (string, any, error) {\n")
+	writef(&body, "\tvar envelope struct {\n\t\tEvent string `json:\"event\"`\n\t\tData json.RawMessage `json:\"data\"`\n\t}\n")
+	writef(&body, "\tif err := json.Unmarshal(raw, &envelope); err != nil || envelope.Event == \"\" {\n")
+	writef(&body, "\t\treturn \"\", json.RawMessage(raw), nil\n\t}\n")
+	writef(&body, "\tswitch envelope.Event {\n")
+	for _, e := range m.events {
+		writef(&body, "\tcase %s:\n", quote(e.name))
+		writef(&body, "\t\tvar event %s\n", g.typeOf(e.schema))
+		writef(&body, "\t\terr := json.Unmarshal(envelope.Data, &event)\n")
+		writef(&body, "\t\treturn envelope.Event, event, err\n")
+	}
+	writef(&body, "\tdefault:\n\t\treturn envelope.Event, envelope.Data, nil\n\t}\n}\n")
+
+	var b strings.Builder
+	writef(&b, "// %s\n\n", Header)
+	writef(&b, "// Package %s is a client for the %s world.\n", packageName, m.namespace)
+	writef(&b, "package %s\n\n", packageName)
+	writef(&b, "import (\n")
+	imports := []string{"bytes", "context", "crypto/ecdsa", "encoding/json", "fmt", "io", "net/http"}
+	if g.usesTime {
+		imports = append(imports, "time")
+	}
+	for _, imp := range imports {
+		writef(&b, "\t%s\n", quote(imp))
+	}
+	writef(&b, "\n\t\"pkg.world.dev/world-engine/sign\"\n)\n\n")
+	b.WriteString(body.String())
+	return b.String()
+}
+
+// typeOf returns the Go type of a JSON schema generated from a Go type.
+func (g *goGenerator) typeOf(schema any) string {
+	obj := schemaObject(schema)
+	if ref, ok := obj["$ref"].(string); ok {
+		return ref
+	}
+
+	switch obj["type"] {
+	case "string":
+		if obj["contentEncoding"] == "base64" {
+			return "[]byte"
+		}
+		if obj["format"] == "date-time" {
+			g.usesTime = true
+			return "time.Time"
+		}
+		return "string"
+	case "integer":
+		return "int64"
+	case "number":
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "[]" + g.typeOf(obj["items"])
+	case "object":
+		if values, ok := mapValues(obj); ok {
+			return "map[string]" + g.typeOf(values)
+		}
+		return g.structOf(obj)
+	}
+	return "json.RawMessage"
+}
+
+func (g *goGenerator) structOf(schema map[string]any) string {
+	names, props, required := properties(schema)
+	if len(names) == 0 {
+		return "struct{}"
+	}
+
+	var b strings.Builder
+	b.WriteString("struct {\n")
+	fields := newIdentAllocator(nil)
+	for _, name := range names {
+		tag := name
+		if !required[name] {
+			tag += ",omitempty"
+		}
+		writef(&b, "\t%s %s `json:%s`\n", fields.allocate(pascalCase(name)), g.typeOf(props[name]), quote(tag))
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// goClientSource holds the helpers of a generated Go client, which don't depend on the world.
+const goClientSource = `
+// Client submits the messages and runs the queries of the world on a Cardinal server.
+type Client struct {
+	baseURL    string
+	namespace  string
+	httpClient *http.Client
+}
+
+// NewClient returns a client for the Cardinal server at the given base URL, e.g. http://localhost:4040.
+func NewClient(baseURL string) *Client {
+	return &Client{
+		baseURL:    baseURL,
+		namespace:  Namespace,
+		httpClient: http.DefaultClient,
+	}
+}
+
+// TxResponse is the response of the server to a submitted message.
+type TxResponse struct {
+	TxHash string
+	Tick   uint64
+}
+
+// Receipt is the receipt of a transaction, as listed by ListReceipts or sent in TickResults. Its result is decoded
+// with the Decode<Message>Result function of its message.
+type Receipt struct {
+	TxHash string          ` + "`json:\"txHash\"`" + `
+	Tick   uint64          ` + "`json:\"tick,omitempty\"`" + `
+	Result json.RawMessage ` + "`json:\"result\"`" + `
+	Errors []string        ` + "`json:\"errors\"`" + `
+}
+
+// ListReceiptsResponse holds the receipts of the ticks in [StartTick, EndTick).
+type ListReceiptsResponse struct {
+	StartTick uint64    ` + "`json:\"startTick\"`" + `
+	EndTick   uint64    ` + "`json:\"endTick\"`" + `
+	Receipts  []Receipt ` + "`json:\"receipts\"`" + `
+}
+
+// TickResults are sent over the /events websocket after every tick. Events are decoded with DecodeEvent.
+type TickResults struct {
+	Tick       uint64
+	Receipts   []Receipt
+	Events     [][]byte
+	PersonaTag string ` + "`json:\",omitempty\"`" + `
+}
+
+// ListReceipts lists the receipts of the transactions processed since the given tick.
+func (c *Client) ListReceipts(ctx context.Context, startTick uint64) (*ListReceiptsResponse, error) {
+	res := new(ListReceiptsResponse)
+	if err := c.post(ctx, "/query/receipts/list", map[string]uint64{"startTick": startTick}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// submit signs the message as the given persona and submits it. Messages that are signed as sign.SystemPersonaTag,
+// such as persona creation, are signed as system transactions.
+func (c *Client) submit(ctx context.Context, url string, pk *ecdsa.PrivateKey, personaTag string, msg any) (
+	*TxResponse, error,
+) {
+	var tx *sign.Transaction
+	var err error
+	if personaTag == sign.SystemPersonaTag {
+		tx, err = sign.NewSystemTransaction(pk, c.namespace, msg)
+	} else {
+		tx, err = sign.NewTransaction(pk, personaTag, c.namespace, msg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	res := new(TxResponse)
+	if err := c.post(ctx, url, tx, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *Client) post(ctx context.Context, url string, body any, reply any) error {
+	bz, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+url, bytes.NewReader(bz))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("request to %s failed with status %d: %s", url, res.StatusCode, msg)
+	}
+	if err := json.NewDecoder(res.Body).Decode(reply); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+`
@@ -0,0 +1,276 @@
+package codegen
+
+import (
+	"regexp"
+	"strings"
+
+	"pkg.world.dev/world-engine/cardinal/server/handler"
+)
+
+// tsReserved are the identifiers of the helpers of a generated TypeScript client.
+var tsReserved = []string{
+	"NAMESPACE", "SYSTEM_PERSONA_TAG", "CardinalClient", "TxResponse", "Receipt", "ListReceiptsResponse",
+	"TickResults", "Messages", "Queries", "Events", "Components", "TypedEvent", "stableStringify", "signTransaction",
+	"decodeReceiptResult", "decodeEvent",
+}
+
+var tsIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// GenerateTypeScript returns the source of a TypeScript client for the world. The client signs transactions the same
+// way the sign package does, using ethers for hashing and signing, and decodes receipts and events into the types of
+// the world.
+func GenerateTypeScript(world *handler.GetWorldResponse) ([]byte, error) {
+	m, err := newModel(world, tsReserved)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	writef(&b, "// %s\n\n", Header)
+	writef(&b, "import { SigningKey, concat, keccak256, toUtf8Bytes } from \"ethers\";\n\n")
+	writef(&b, "/** The namespace of the world the client was generated for. */\n")
+	writef(&b, "export const NAMESPACE = %s;\n\n", quote(m.namespace))
+
+	for _, t := range m.types {
+		obj := schemaObject(t.schema)
+		if _, isMap := mapValues(obj); obj["type"] == "object" && !isMap {
+			writef(&b, "export interface %s %s\n\n", t.ident, tsObjectOf(obj, ""))
+		} else {
+			writef(&b, "export type %s = %s;\n\n", t.ident, tsTypeOf(t.schema, ""))
+		}
+	}
+
+	writef(&b, "/** The input and result of every message, keyed by the full name of the message. */\n")
+	writef(&b, "export type Messages = {\n")
+	for _, msg := range m.messages {
+		writef(&b, "  %s: { input: %s; result: %s };\n",
+			quote(msg.group+"."+msg.name), tsTypeOf(msg.input, "  "), tsTypeOf(msg.result, "  "))
+	}
+	writef(&b, "};\n\n")
+
+	writef(&b, "/** The request and reply of every query, keyed by the full name of the query. */\n")
+	writef(&b, "export type Queries = {\n")
+	for _, q := range m.queries {
+		writef(&b, "  %s: { request: %s; reply: %s };\n",
+			quote(q.group+"."+q.name), tsTypeOf(q.input, "  "), tsTypeOf(q.result, "  "))
+	}
+	writef(&b, "};\n\n")
+
+	writef(&b, "/** The registered events, keyed by name. */\n")
+	writef(&b, "export type Events = {\n")
+	for _, e := range m.events {
+		writef(&b, "  %s: %s;\n", quote(e.name), tsTypeOf(e.schema, "  "))
+	}
+	writef(&b, "};\n\n")
+
+	writef(&b, "/** The registered components, keyed by name. */\n")
+	writef(&b, "export type Components = {\n")
+	for _, c := range m.components {
+		writef(&b, "  %s: %s;\n", quote(c.name), tsTypeOf(c.schema, "  "))
+	}
+	writef(&b, "};\n\n")
+
+	b.WriteString(tsClientHelpers)
+
+	writef(&b, "\n/** Submits the messages and runs the queries of the world on a Cardinal server. */\n")
+	writef(&b, "export class CardinalClient {\n")
+	writef(&b, "  constructor(\n    readonly baseUrl: string,\n    readonly namespace: string = NAMESPACE,\n  ) {}\n")
+	for _, msg := range m.messages {
+		fullName := quote(msg.group + "." + msg.name)
+		writef(&b, "\n  /** Submits the %s message, signed by the given persona. */\n", msg.name)
+		writef(&b, "  submit%s(\n", msg.ident)
+		writef(&b, "    privateKey: string,\n    personaTag: string,\n")
+		writef(&b, "    input: Messages[%s][\"input\"],\n  ): Promise<TxResponse> {\n", fullName)
+		writef(&b, "    const tx = signTransaction(privateKey, personaTag, this.namespace, input);\n")
+		writef(&b, "    return this.post(%s, tx);\n  }\n", quote(msg.url))
+	}
+	for _, q := range m.queries {
+		fullName := quote(q.group + "." + q.name)
+		writef(&b, "\n  /** Runs the %s query. */\n", q.name)
+		writef(&b, "  query%s(\n    request: Queries[%s][\"request\"],\n", q.ident, fullName)
+		writef(&b, "  ): Promise<Queries[%s][\"reply\"]> {\n", fullName)
+		writef(&b, "    return this.post(%s, JSON.stringify(request));\n  }\n", quote(q.url))
+	}
+	b.WriteString(tsClientMethods)
+	writef(&b, "}\n")
+	return []byte(b.String()), nil
+}
+
+// tsTypeOf returns the TypeScript type of a JSON schema generated from a Go type.
+func tsTypeOf(schema any, indent string) string {
+	obj := schemaObject(schema)
+	if ref, ok := obj["$ref"].(string); ok {
+		return ref
+	}
+
+	switch obj["type"] {
+	case "string":
+		return "string"
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "array":
+		return "Array<" + tsTypeOf(obj["items"], indent) + ">"
+	case "object":
+		if values, ok := mapValues(obj); ok {
+			return "Record<string, " + tsTypeOf(values, indent) + ">"
+		}
+		return tsObjectOf(obj, indent)
+	}
+	return "unknown"
+}
+
+func tsObjectOf(schema map[string]any, indent string) string {
+	names, props, required := properties(schema)
+	if len(names) == 0 {
+		return "{}"
+	}
+
+	var b strings.Builder
+	b.WriteString("{\n")
+	for _, name := range names {
+		key := name
+		if !tsIdentifier.MatchString(name) {
+			key = quote(name)
+		}
+		if !required[name] {
+			key += "?"
+		}
+		writef(&b, "%s  %s: %s;\n", indent, key, tsTypeOf(props[name], indent+"  "))
+	}
+	b.WriteString(indent + "}")
+	return b.String()
+}
+
+// tsClientHelpers holds the helpers of a generated TypeScript client, which don't depend on the world.
+const tsClientHelpers = `/** The persona tag that persona creation messages are signed with. */
+export const SYSTEM_PERSONA_TAG = "SystemPersonaTag";
+
+/** The response of the server to a submitted message. */
+export interface TxResponse {
+  TxHash: string;
+  Tick: number;
+}
+
+/** The receipt of a transaction. Its result is decoded with decodeReceiptResult. */
+export interface Receipt {
+  txHash: string;
+  tick?: number;
+  result: unknown;
+  errors: string[] | null;
+}
+
+/** The receipts of the ticks in [startTick, endTick). */
+export interface ListReceiptsResponse {
+  startTick: number;
+  endTick: number;
+  receipts: Receipt[] | null;
+}
+
+/** Sent over the /events websocket after every tick. Events are base64 encoded, and decoded with decodeEvent. */
+export interface TickResults {
+  Tick: number;
+  Receipts: Receipt[];
+  Events: string[];
+  PersonaTag?: string;
+}
+
+/** An event emitted with cardinal.EmitTypedEvent. */
+export type TypedEvent = { [K in keyof Events]: { event: K; data: Events[K] } }[keyof Events];
+
+/**
+ * Encodes the value as JSON the way Go does, with the keys of objects in sorted order, so that the signed body
+ * matches the body the sign package would sign.
+ */
+export function stableStringify(value: unknown): string {
+  if (value === null || typeof value !== "object") {
+    return JSON.stringify(value)
+      .replace(/</g, "\\u003c")
+      .replace(/>/g, "\\u003e")
+      .replace(/&/g, "\\u0026")
+      .replace(/\u2028/g, "\\u2028")
+      .replace(/\u2029/g, "\\u2029");
+  }
+  if (Array.isArray(value)) {
+    return "[" + value.map((v) => stableStringify(v ?? null)).join(",") + "]";
+  }
+  const entries = Object.entries(value as Record<string, unknown>)
+    .filter(([, v]) => v !== undefined)
+    .sort(([a], [b]) => (a < b ? -1 : a > b ? 1 : 0));
+  return "{" + entries.map(([k, v]) => stableStringify(k) + ":" + stableStringify(v)).join(",") + "}";
+}
+
+/**
+ * Signs the body as the given persona, the same way the sign package does: the signature is over the keccak256 hash
+ * of the persona tag, namespace, timestamp, salt, and body. Returns the signed transaction encoded as JSON, with the
+ * body exactly as it was signed.
+ */
+export function signTransaction(
+  privateKey: string,
+  personaTag: string,
+  namespace: string,
+  body: unknown,
+): string {
+  const encodedBody = stableStringify(body);
+  const timestamp = Date.now();
+  const salt = 1 + Math.floor(Math.random() * 65534);
+  const hash = keccak256(
+    concat([
+      toUtf8Bytes(personaTag),
+      toUtf8Bytes(namespace),
+      toUtf8Bytes(timestamp.toString()),
+      toUtf8Bytes(salt.toString()),
+      toUtf8Bytes(encodedBody),
+    ]),
+  );
+  const signature = new SigningKey(privateKey).sign(hash).serialized.slice(2);
+  const fields = { personaTag, namespace, timestamp, salt, signature };
+  return JSON.stringify(fields).slice(0, -1) + ',"body":' + encodedBody + "}";
+}
+
+/** Decodes the result of a message from its receipt. */
+export function decodeReceiptResult<K extends keyof Messages>(
+  _message: K,
+  receipt: Receipt,
+): Messages[K]["result"] {
+  return receipt.result as Messages[K]["result"];
+}
+
+/**
+ * Decodes a base64 encoded event from TickResults. Events emitted with cardinal.EmitTypedEvent are returned with their
+ * name, other events are returned as undefined.
+ */
+export function decodeEvent(encoded: string): TypedEvent | undefined {
+  let parsed: unknown;
+  try {
+    parsed = JSON.parse(new TextDecoder().decode(Uint8Array.from(atob(encoded), (c) => c.charCodeAt(0))));
+  } catch {
+    return undefined;
+  }
+  if (parsed === null || typeof parsed !== "object" || typeof (parsed as { event?: unknown }).event !== "string") {
+    return undefined;
+  }
+  return parsed as TypedEvent;
+}
+`
+
+// tsClientMethods holds the methods of a generated TypeScript client, which don't depend on the world.
+const tsClientMethods = `
+  /** Lists the receipts of the transactions processed since the given tick. */
+  listReceipts(startTick: number): Promise<ListReceiptsResponse> {
+    return this.post("/query/receipts/list", JSON.stringify({ startTick }));
+  }
+
+  private async post<T>(url: string, body: string): Promise<T> {
+    const res = await fetch(this.baseUrl + url, {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body,
+    });
+    if (!res.ok) {
+      throw new Error("request to " + url + " failed with status " + res.status + ": " + (await res.text()));
+    }
+    return (await res.json()) as T;
+  }
+`
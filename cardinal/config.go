@@ -47,6 +47,7 @@ var (
 		BaseShardSequencerAddress: DefaultBaseShardSequencerAddress,
 		BaseShardRouterKey:        "",
 		TelemetryTraceEnabled:     false,
+		CardinalDescribeWorld:     "",
 	}
 )
 
@@ -77,28 +78,34 @@ type WorldConfig struct {
 
 	// TelemetryTraceEnabled When true, Cardinal will collect OpenTelemetry traces
 	TelemetryTraceEnabled bool `mapstructure:"TELEMETRY_TRACE_ENABLED"`
+
+	// CardinalDescribeWorld When set, StartGame writes the description of the world that is served at /world to this
+	// path and exits without starting the game. This lets tools such as the client code generator read the
+	// registered messages, queries, components, and events from a world binary.
+	CardinalDescribeWorld string `mapstructure:"CARDINAL_DESCRIBE_WORLD"`
 }
 
 func loadWorldConfig() (*WorldConfig, error) {
 	// Set default config
 	cfg := defaultConfig
 
-	// Setup Viper for world toml config file
-	setupViper()
+	// Setup Viper for world toml config file. A new Viper instance is used for every world, so that worlds in the same
+	// process don't share config state.
+	v := setupViper()
 
 	// Read the config file
 	// Unmarshal the [cardinal] section from config file into the WorldConfig struct
-	if err := viper.ReadInConfig(); err != nil {
+	if err := v.ReadInConfig(); err != nil {
 		log.Warn().Err(err).Msg("No config file found")
 	} else {
-		if err := viper.Sub("cardinal").Unmarshal(&cfg); err != nil {
+		if err := v.Sub("cardinal").Unmarshal(&cfg); err != nil {
 			log.Warn().Err(err).Msg("Failed to unmarshal config file")
 		}
 	}
 
 	// Override config values with environment variables
 	// This is done after reading the config file to allow for environment variable overrides
-	if err := viper.Unmarshal(&cfg); err != nil {
+	if err := v.Unmarshal(&cfg); err != nil {
 		log.Warn().Err(err).Msg("Failed to load config from environment variables")
 	} else {
 		log.Debug().Msg("Loaded config from environment variables")
@@ -108,10 +115,6 @@ func loadWorldConfig() (*WorldConfig, error) {
 		return nil, eris.Wrap(err, "Invalid config")
 	}
 
-	if err := cfg.setLogger(); err != nil {
-		return nil, eris.Wrap(err, "Failed to set log level")
-	}
-
 	return &cfg, nil
 }
 
@@ -142,23 +145,26 @@ func (w *WorldConfig) Validate() error {
 	return nil
 }
 
-func (w *WorldConfig) setLogger() error {
-	// Set global logger level
+// newLogger returns a logger for a world with this config. It is derived from the global logger, but the global logger
+// and the global log level are left untouched, so that worlds with different log configs can run in the same process.
+func (w *WorldConfig) newLogger() (zerolog.Logger, error) {
 	level, err := zerolog.ParseLevel(w.CardinalLogLevel)
 	if err != nil {
-		return eris.Wrap(err, "CARDINAL_LOG_LEVEL is not a valid log level")
+		return zerolog.Logger{}, eris.Wrap(err, "CARDINAL_LOG_LEVEL is not a valid log level")
 	}
-	zerolog.SetGlobalLevel(level)
+	logger := log.Logger.Level(level)
 
-	// Override global logger to console writer if pretty logging is enabled
+	// Log to a console writer if pretty logging is enabled
 	if w.CardinalLogPretty {
-		log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+		logger = logger.Output(zerolog.ConsoleWriter{Out: os.Stderr})
 	}
 
-	return nil
+	return logger, nil
 }
 
-func setupViper() {
+func setupViper() *viper.Viper {
+	v := viper.New()
+
 	if pflag.Lookup(configFilePathEnvVariable) == nil {
 		pflag.String(configFilePathEnvVariable, "", "Path to the TOML config file")
 	}
@@ -166,34 +172,34 @@ func setupViper() {
 	pflag.Parse()
 
 	// Bind the command-line flags to Viper
-	if err := viper.BindPFlags(pflag.CommandLine); err != nil {
+	if err := v.BindPFlags(pflag.CommandLine); err != nil {
 		log.Debug().Err(err).Msg("Failed to bind command-line flags to Viper")
 		// Continue even if the binding fails
 	}
 
 	// Bind env for CARDINAL_CONFIG
-	if err := viper.BindEnv(configFilePathEnvVariable); err != nil {
+	if err := v.BindEnv(configFilePathEnvVariable); err != nil {
 		log.Warn().Err(err).Str("env", configFilePathEnvVariable).Msg("Failed to bind env variable")
 	}
 
 	// Set default toml config file name and type
-	viper.SetConfigName("world") // name of config file (without extension)
-	viper.SetConfigType("toml")  // REQUIRED if the config file does not have the extension in the name
+	v.SetConfigName("world") // name of config file (without extension)
+	v.SetConfigType("toml")  // REQUIRED if the config file does not have the extension in the name
 
 	// Find the toml config file from the flag and env variable
 	// viper precedence: flag > env > default
-	configFilePath := viper.GetString(configFilePathEnvVariable)
+	configFilePath := v.GetString(configFilePathEnvVariable)
 	if configFilePath != "" { //nolint:nestif // better consistency and readability
 		// Use Specified config file
 		fileName := filepath.Base(configFilePath)
 
-		viper.SetConfigName(strings.TrimSuffix(fileName, filepath.Ext(fileName)))
-		viper.SetConfigType(strings.TrimPrefix(filepath.Ext(fileName), "."))
+		v.SetConfigName(strings.TrimSuffix(fileName, filepath.Ext(fileName)))
+		v.SetConfigType(strings.TrimPrefix(filepath.Ext(fileName), "."))
 
-		viper.AddConfigPath(filepath.Dir(configFilePath))
+		v.AddConfigPath(filepath.Dir(configFilePath))
 	} else {
 		// Search for toml file in the current directory and parent directory
-		viper.AddConfigPath(".") // look for config in the working directory
+		v.AddConfigPath(".") // look for config in the working directory
 
 		// If the config file is not found in the current directory, search in the parent directory
 		if _, err := os.Stat(defaultConfigFileName); err != nil {
@@ -202,7 +208,7 @@ func setupViper() {
 				log.Warn().Err(err).Msg("Failed to get current directory for TOML file search")
 			} else {
 				parentDir = filepath.Dir(parentDir) // get parent directory
-				viper.AddConfigPath(parentDir)
+				v.AddConfigPath(parentDir)
 			}
 		}
 	}
@@ -215,9 +221,11 @@ func setupViper() {
 		field := typ.Field(i)
 		tag := field.Tag.Get("mapstructure")
 		if tag != "" {
-			if err := viper.BindEnv(tag); err != nil {
+			if err := v.BindEnv(tag); err != nil {
 				log.Warn().Err(err).Str("field", field.Name).Msg("Failed to bind env variable")
 			}
 		}
 	}
+
+	return v
 }
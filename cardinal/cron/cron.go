@@ -0,0 +1,222 @@
+// Package cron parses cron expressions, and computes the times they are scheduled at.
+//
+// An expression has five fields, separated by spaces: minute (0-59), hour (0-23), day of month (1-31), month (1-12 or
+// JAN-DEC), and day of week (0-6 or SUN-SAT, 7 is also Sunday). Each field is a comma separated list of values, ranges
+// ("1-5"), steps ("*/15" or "10-30/5"), or "*". A day of week can be followed by "#n" to match only the nth such day
+// of the month, e.g. "MON#1" is the first Monday. If both the day of month and the day of week are restricted, a day
+// matches if either of them matches, as in standard cron.
+//
+// The descriptors @yearly (or @annually), @monthly, @weekly, @daily (or @midnight), and @hourly can be used instead
+// of the five fields. All times are in UTC.
+package cron
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rotisserie/eris"
+)
+
+// maxSearchYears bounds the search for the next scheduled time, so that expressions that never match, like
+// "0 0 30 2 *", don't search forever.
+const maxSearchYears = 5
+
+var ErrInvalidExpression = errors.New("invalid cron expression")
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var (
+	monthNames = []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}
+	dayNames   = []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}
+)
+
+// Schedule is a parsed cron expression.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	// nthDow holds, for each day of the week, the bits of the occurrences in the month that match ("MON#1").
+	nthDow [7]uint8
+	// domRestricted and dowRestricted are false if the field is "*", in which case only the other field decides which
+	// days match.
+	domRestricted, dowRestricted bool
+}
+
+type bounds struct {
+	min, max uint
+	names    []string
+}
+
+var (
+	minuteBounds = bounds{0, 59, nil}
+	hourBounds   = bounds{0, 23, nil}
+	domBounds    = bounds{1, 31, nil}
+	monthBounds  = bounds{1, 12, monthNames}
+	dowBounds    = bounds{0, 7, dayNames}
+)
+
+// Parse parses a cron expression in the format described in the package documentation.
+func Parse(expr string) (*Schedule, error) {
+	spec := strings.TrimSpace(expr)
+	if descriptor, ok := descriptors[strings.ToLower(spec)]; ok {
+		spec = descriptor
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 { //nolint:mnd // the number of fields of a cron expression
+		return nil, eris.Wrapf(ErrInvalidExpression, "%q must have 5 fields", expr)
+	}
+
+	s := &Schedule{
+		domRestricted: !strings.HasPrefix(fields[2], "*"),
+		dowRestricted: !strings.HasPrefix(fields[4], "*"),
+	}
+	var err error
+	if s.minute, err = parseField(fields[0], minuteBounds, nil); err != nil {
+		return nil, eris.Wrapf(err, "minute of %q", expr)
+	}
+	if s.hour, err = parseField(fields[1], hourBounds, nil); err != nil {
+		return nil, eris.Wrapf(err, "hour of %q", expr)
+	}
+	if s.dom, err = parseField(fields[2], domBounds, nil); err != nil {
+		return nil, eris.Wrapf(err, "day of month of %q", expr)
+	}
+	if s.month, err = parseField(fields[3], monthBounds, nil); err != nil {
+		return nil, eris.Wrapf(err, "month of %q", expr)
+	}
+	if s.dow, err = parseField(fields[4], dowBounds, &s.nthDow); err != nil {
+		return nil, eris.Wrapf(err, "day of week of %q", expr)
+	}
+	// 7 is another name for Sunday
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	return s, nil
+}
+
+// parseField returns the bits of the values of a field. If nthDow is not nil, the values may be followed by "#n".
+func parseField(field string, b bounds, nthDow *[7]uint8) (uint64, error) {
+	var set uint64
+	for _, item := range strings.Split(field, ",") {
+		if nthDow != nil {
+			if day, nth, ok := strings.Cut(item, "#"); ok {
+				d, err := parseValue(day, b)
+				if err != nil {
+					return 0, err
+				}
+				n, err := strconv.ParseUint(nth, 10, 8)
+				if err != nil || n < 1 || n > 5 {
+					return 0, eris.Wrapf(ErrInvalidExpression, "%q is not an occurrence between 1 and 5", nth)
+				}
+				nthDow[d%7] |= 1 << (n - 1)
+				continue
+			}
+		}
+		bitsOfItem, err := parseItem(item, b)
+		if err != nil {
+			return 0, err
+		}
+		set |= bitsOfItem
+	}
+	return set, nil
+}
+
+// parseItem returns the bits of a value, a range, or "*", optionally followed by a step.
+func parseItem(item string, b bounds) (uint64, error) {
+	rangePart, stepPart, hasStep := strings.Cut(item, "/")
+	step := uint(1)
+	if hasStep {
+		n, err := strconv.ParseUint(stepPart, 10, 8)
+		if err != nil || n == 0 {
+			return 0, eris.Wrapf(ErrInvalidExpression, "%q is not a valid step", stepPart)
+		}
+		step = uint(n)
+	}
+
+	var lo, hi uint
+	switch startPart, endPart, isRange := strings.Cut(rangePart, "-"); {
+	case rangePart == "*":
+		lo, hi = b.min, b.max
+	case isRange:
+		var err error
+		if lo, err = parseValue(startPart, b); err != nil {
+			return 0, err
+		}
+		if hi, err = parseValue(endPart, b); err != nil {
+			return 0, err
+		}
+		if lo > hi {
+			return 0, eris.Wrapf(ErrInvalidExpression, "range %q is backwards", rangePart)
+		}
+	default:
+		var err error
+		if lo, err = parseValue(rangePart, b); err != nil {
+			return 0, err
+		}
+		hi = lo
+		if hasStep {
+			// "a/n" is every n from a up to the maximum
+			hi = b.max
+		}
+	}
+
+	var set uint64
+	for v := lo; v <= hi; v += step {
+		set |= 1 << v
+	}
+	return set, nil
+}
+
+func parseValue(s string, b bounds) (uint, error) {
+	for i, name := range b.names {
+		if strings.EqualFold(s, name) {
+			// Month names start at 1, day names at 0
+			return uint(i) + b.min, nil //nolint:gosec // the index of a name is small
+		}
+	}
+	n, err := strconv.ParseUint(s, 10, 8)
+	if err != nil || uint(n) < b.min || uint(n) > b.max {
+		return 0, eris.Wrapf(ErrInvalidExpression, "%q is not a value between %d and %d", s, b.min, b.max)
+	}
+	return uint(n), nil
+}
+
+// Next returns the first time after t that the schedule is scheduled at, in UTC. The zero time is returned if the
+// schedule doesn't match any time in the next few years.
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(maxSearchYears, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !s.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = t.Truncate(time.Hour).Add(time.Hour)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (s *Schedule) matchesDay(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	weekday := t.Weekday()
+	nth := (t.Day() - 1) / 7 //nolint:mnd // days in a week
+	dowMatch := s.dow&(1<<uint(weekday)) != 0 || s.nthDow[weekday]&(1<<uint(nth)) != 0
+	if s.domRestricted && s.dowRestricted {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
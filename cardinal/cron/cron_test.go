@@ -0,0 +1,70 @@
+package cron_test
+
+import (
+	"testing"
+	"time"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal/cron"
+)
+
+func TestNext(t *testing.T) {
+	// 2024-01-01 was a Monday
+	from := time.Date(2024, 1, 1, 10, 30, 15, 0, time.UTC)
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, 1, 1, 10, 31, 0, 0, time.UTC)},
+		{"0 0 * * *", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 1, 1, 10, 45, 0, 0, time.UTC)},
+		{"10-40/10 10 * * *", time.Date(2024, 1, 1, 10, 40, 0, 0, time.UTC)},
+		{"0 9,18 * * *", time.Date(2024, 1, 1, 18, 0, 0, 0, time.UTC)},
+		{"0 0 * * MON#1", time.Date(2024, 2, 5, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * fri", time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, 1, 7, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 JAN-MAR *", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// The day of month or the day of week must match if both are restricted
+		{"0 0 15 * SAT", time.Date(2024, 1, 6, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.expr, func(t *testing.T) {
+			schedule, err := cron.Parse(tc.expr)
+			assert.NilError(t, err)
+			assert.Equal(t, schedule.Next(from), tc.want)
+		})
+	}
+}
+
+func TestNextIsStrictlyAfter(t *testing.T) {
+	schedule, err := cron.Parse("0 0 * * *")
+	assert.NilError(t, err)
+	midnight := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, schedule.Next(midnight), midnight.AddDate(0, 0, 1))
+	assert.Equal(t, schedule.Next(midnight.Add(-time.Millisecond)), midnight)
+}
+
+func TestParseRejectsInvalidExpressions(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"* * * * MON#6",
+		"* * * FOO *",
+		"@weekdays",
+	} {
+		_, err := cron.Parse(expr)
+		assert.ErrorIs(t, err, cron.ErrInvalidExpression, expr)
+	}
+}
@@ -0,0 +1,40 @@
+package cardinal_test
+
+import (
+	"testing"
+
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+func TestRemovedEntityIDsAreStaleWhenTheirIndexIsRecycled(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil, cardinal.WithEntityIDRecycling())
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[EnergyComponent](world))
+	tf.StartWorld()
+	wCtx := cardinal.NewWorldContext(world)
+
+	ids, err := cardinal.CreateMany(wCtx, 2, EnergyComponent{Amt: 1})
+	assert.NilError(t, err)
+	tf.DoTick()
+	assert.NilError(t, cardinal.Remove(wCtx, ids[0]))
+	tf.DoTick()
+
+	id, err := cardinal.Create(wCtx, EnergyComponent{Amt: 2})
+	assert.NilError(t, err)
+	assert.Equal(t, id.Index(), ids[0].Index())
+	assert.Equal(t, id.Generation(), uint32(1))
+	tf.DoTick()
+
+	_, err = cardinal.GetComponent[EnergyComponent](wCtx, ids[0])
+	assert.Check(t, eris.Is(err, cardinal.ErrStaleEntityID))
+	assert.Check(t, eris.Is(err, cardinal.ErrEntityDoesNotExist))
+	_, err = cardinal.GetComponent[EnergyComponent](wCtx, types.NewEntityID(100, 0))
+	assert.Check(t, eris.Is(err, cardinal.ErrEntityNeverExisted))
+	energy, err := cardinal.GetComponent[EnergyComponent](wCtx, id)
+	assert.NilError(t, err)
+	assert.Equal(t, energy.Amt, int64(2))
+}
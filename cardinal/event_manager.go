@@ -0,0 +1,75 @@
+package cardinal
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+var _ EventManager = &eventManager{}
+
+type EventManager interface {
+	RegisterEvent(name string, eventType reflect.Type) error
+	// GetRegisteredEvents returns the names of the registered events, in sorted order.
+	GetRegisteredEvents() []string
+	BuildEventFields() []types.FieldDetail
+	getEventType(name string) (reflect.Type, bool)
+}
+
+type eventManager struct {
+	registeredEvents map[string]reflect.Type
+}
+
+func newEventManager() EventManager {
+	return &eventManager{
+		registeredEvents: map[string]reflect.Type{},
+	}
+}
+
+// RegisterEvent registers an event type with the event manager. There can only be one event with a given name.
+func (m *eventManager) RegisterEvent(name string, eventType reflect.Type) error {
+	if name == "" {
+		return eris.New("cannot register event without name")
+	}
+	if _, ok := m.registeredEvents[name]; ok {
+		return eris.Errorf("event %q is already registered", name)
+	}
+	m.registeredEvents[name] = eventType
+	return nil
+}
+
+func (m *eventManager) GetRegisteredEvents() []string {
+	names := make([]string, 0, len(m.registeredEvents))
+	for name := range m.registeredEvents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (m *eventManager) BuildEventFields() []types.FieldDetail {
+	names := m.GetRegisteredEvents()
+	eventsFields := make([]types.FieldDetail, 0, len(names))
+	for _, name := range names {
+		eventType := m.registeredEvents[name]
+		schema, err := types.GetJSONSchema(eventType)
+		if err != nil {
+			// Events are checked to be json serializable when they are registered.
+			continue
+		}
+		eventsFields = append(eventsFields, types.FieldDetail{
+			Name:   name,
+			Fields: types.GetFieldInformation(eventType),
+			Schema: schema,
+		})
+	}
+	return eventsFields
+}
+
+func (m *eventManager) getEventType(name string) (reflect.Type, bool) {
+	eventType, ok := m.registeredEvents[name]
+	return eventType, ok
+}
@@ -0,0 +1,162 @@
+package gamestate
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+// ComponentChangeKind is the kind of change made to a component of an entity.
+type ComponentChangeKind int
+
+const (
+	// ComponentAdded means the component was added to the entity, or the entity was created with it.
+	ComponentAdded ComponentChangeKind = iota
+	// ComponentSet means the value of the component was changed.
+	ComponentSet
+	// ComponentRemoved means the component was removed from the entity, or the entity was removed.
+	ComponentRemoved
+)
+
+// ComponentChange is a change made to a component of an entity by the pending state changes. Old is the value of the
+// component before the changes, and is nil if the component was added. New is the value of the component after the
+// changes, and is nil if the component was removed.
+type ComponentChange struct {
+	Kind      ComponentChangeKind
+	EntityID  types.EntityID
+	Component types.ComponentMetadata
+	Old       any
+	New       any
+}
+
+// PendingComponentChanges returns the changes made to the components of entities since the pending state changes were
+// last finalized or discarded, ordered by entity ID and then component ID. Only the net change of each component is
+// returned, e.g. a component that was added and then removed again has no change, and a component that was set several
+// times has a single change from the finalized value to the latest value. Setting a component to its finalized value
+// is not a change.
+func (m *EntityCommandBuffer) PendingComponentChanges() ([]ComponentChange, error) {
+	ids, err := m.PendingEntityChanges()
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []ComponentChange
+	for _, id := range ids {
+		oldComps, err := m.finalizedComponentTypesForEntity(id)
+		if err != nil {
+			return nil, err
+		}
+		newComps, err := m.pendingComponentTypesForEntity(id)
+		if err != nil {
+			return nil, err
+		}
+
+		compIDs := make([]types.ComponentID, 0, len(oldComps)+len(newComps))
+		comps := make(map[types.ComponentID]types.ComponentMetadata, len(oldComps)+len(newComps))
+		for _, comp := range slices.Concat(oldComps, newComps) {
+			if _, ok := comps[comp.ID()]; !ok {
+				compIDs = append(compIDs, comp.ID())
+				comps[comp.ID()] = comp
+			}
+		}
+		slices.Sort(compIDs)
+
+		for _, compID := range compIDs {
+			change, ok, err := m.pendingComponentChange(id, comps[compID], oldComps, newComps)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				changes = append(changes, change)
+			}
+		}
+	}
+	return changes, nil
+}
+
+// pendingComponentChange returns the change made to the given component of the given entity, and false if the
+// component hasn't changed.
+func (m *EntityCommandBuffer) pendingComponentChange(
+	id types.EntityID, comp types.ComponentMetadata, oldComps, newComps []types.ComponentMetadata,
+) (ComponentChange, bool, error) {
+	change := ComponentChange{EntityID: id, Component: comp}
+	hadComp := slices.ContainsFunc(oldComps, func(c types.ComponentMetadata) bool { return c.ID() == comp.ID() })
+	hasComp := slices.ContainsFunc(newComps, func(c types.ComponentMetadata) bool { return c.ID() == comp.ID() })
+
+	if hadComp && hasComp {
+		// Only components with a cached value can have been set. Values that were only read are cached too, so the
+		// values are compared below to find the ones that changed.
+		if _, err := m.compValues.Get(compKey{comp.ID(), id}); err != nil {
+			return change, false, nil //nolint:nilerr // the component value hasn't been read or set
+		}
+	}
+
+	var oldBz []byte
+	if hadComp {
+		var err error
+		oldBz, err = m.dbStorage.GetBytes(context.Background(), storageComponentKey(comp.ID(), id))
+		if errors.Is(err, redis.Nil) {
+			// The value has never been set, so the component had its default value
+			oldBz, err = comp.New()
+		}
+		if err != nil {
+			return change, false, eris.Wrap(err, "")
+		}
+		if change.Old, err = comp.Decode(oldBz); err != nil {
+			return change, false, err
+		}
+	}
+	if hasComp {
+		var err error
+		if change.New, err = m.GetComponentForEntity(comp, id); err != nil {
+			return change, false, err
+		}
+	}
+
+	switch {
+	case !hadComp:
+		change.Kind = ComponentAdded
+	case !hasComp:
+		change.Kind = ComponentRemoved
+	default:
+		newBz, err := comp.Encode(change.New)
+		if err != nil {
+			return change, false, err
+		}
+		if bytes.Equal(oldBz, newBz) {
+			return change, false, nil
+		}
+		change.Kind = ComponentSet
+	}
+	return change, true, nil
+}
+
+// finalizedComponentTypesForEntity returns the components that the given entity had when the pending state changes
+// were last finalized or discarded. Entities that didn't exist have no components.
+func (m *EntityCommandBuffer) finalizedComponentTypesForEntity(id types.EntityID) ([]types.ComponentMetadata, error) {
+	archID, err := m.entityIDToOriginArchID.Get(id)
+	if err != nil {
+		// The entity has only had component values set, so it is still in the same archetype
+		return m.GetComponentTypesForEntity(id)
+	}
+	if archID == doesNotExistArchetypeID {
+		return nil, nil
+	}
+	return m.GetComponentTypesForArchID(archID)
+}
+
+// pendingComponentTypesForEntity returns the components that the given entity has now. Entities that have been
+// removed have no components.
+func (m *EntityCommandBuffer) pendingComponentTypesForEntity(id types.EntityID) ([]types.ComponentMetadata, error) {
+	comps, err := m.GetComponentTypesForEntity(id)
+	if eris.Is(err, ErrEntityDoesNotExist) {
+		return nil, nil
+	}
+	return comps, err
+}
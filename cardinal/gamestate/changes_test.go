@@ -0,0 +1,73 @@
+package gamestate_test
+
+import (
+	"context"
+	"testing"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal/gamestate"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+func TestPendingComponentChangesAreNetChanges(t *testing.T) {
+	manager := newCmdBufferForTest(t)
+	ctx := context.Background()
+
+	ids, err := manager.CreateManyEntities(4, fooComp)
+	assert.NilError(t, err)
+	for _, id := range ids {
+		assert.NilError(t, manager.SetComponentForEntity(fooComp, id, Foo{Value: 1}))
+	}
+	changes, err := manager.PendingComponentChanges()
+	assert.NilError(t, err)
+	assert.Equal(t, len(changes), 4)
+	for i, change := range changes {
+		assert.Equal(t, change.Kind, gamestate.ComponentAdded)
+		assert.Equal(t, change.EntityID, ids[i])
+		assert.Equal(t, change.Component.ID(), fooComp.ID())
+		assert.Equal(t, change.Old, nil)
+		assert.Equal(t, change.New, Foo{Value: 1})
+	}
+	assert.NilError(t, manager.FinalizeTick(ctx))
+
+	// A value that is set several times is a single change, and a value that is set back is no change
+	assert.NilError(t, manager.SetComponentForEntity(fooComp, ids[0], Foo{Value: 2}))
+	assert.NilError(t, manager.SetComponentForEntity(fooComp, ids[0], Foo{Value: 3}))
+	assert.NilError(t, manager.SetComponentForEntity(fooComp, ids[1], Foo{Value: 2}))
+	assert.NilError(t, manager.SetComponentForEntity(fooComp, ids[1], Foo{Value: 1}))
+	// Reading a value is no change
+	_, err = manager.GetComponentForEntity(fooComp, ids[2])
+	assert.NilError(t, err)
+	assert.NilError(t, manager.AddComponentToEntity(barComp, ids[2]))
+	assert.NilError(t, manager.RemoveEntity(ids[3]))
+	// An entity that is created and removed again has no changes
+	id, err := manager.CreateEntity(fooComp)
+	assert.NilError(t, err)
+	assert.NilError(t, manager.RemoveEntity(id))
+
+	changes, err = manager.PendingComponentChanges()
+	assert.NilError(t, err)
+	want := []struct {
+		kind     gamestate.ComponentChangeKind
+		id       types.EntityID
+		comp     types.ComponentMetadata
+		old, new any
+	}{
+		{gamestate.ComponentSet, ids[0], fooComp, Foo{Value: 1}, Foo{Value: 3}},
+		{gamestate.ComponentAdded, ids[2], barComp, nil, Bar{}},
+		{gamestate.ComponentRemoved, ids[3], fooComp, Foo{Value: 1}, nil},
+	}
+	assert.Equal(t, len(changes), len(want))
+	for i, change := range changes {
+		assert.Equal(t, change.Kind, want[i].kind)
+		assert.Equal(t, change.EntityID, want[i].id)
+		assert.Equal(t, change.Component.ID(), want[i].comp.ID())
+		assert.Equal(t, change.Old, want[i].old)
+		assert.Equal(t, change.New, want[i].new)
+	}
+
+	assert.NilError(t, manager.DiscardPending())
+	changes, err = manager.PendingComponentChanges()
+	assert.NilError(t, err)
+	assert.Equal(t, len(changes), 0)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"slices"
 
 	"github.com/redis/go-redis/v9"
 	"github.com/rotisserie/eris"
@@ -30,7 +31,10 @@ type EntityCommandBuffer struct {
 
 	compValues         VolatileStorage[compKey, any]
 	compValuesToDelete VolatileStorage[compKey, bool]
-	typeToComponent    VolatileStorage[types.ComponentID, types.ComponentMetadata]
+	// modifiedEntities tracks the entities that had a component value set. compValues can't be used for this because
+	// it also caches component values that were only read.
+	modifiedEntities VolatileStorage[types.EntityID, bool]
+	typeToComponent  VolatileStorage[types.ComponentID, types.ComponentMetadata]
 
 	activeEntities VolatileStorage[types.ArchetypeID, activeEntities]
 
@@ -39,6 +43,16 @@ type EntityCommandBuffer struct {
 	pendingEntityIDs  uint64
 	isEntityIDLoaded  bool
 
+	// Entity index recycling, see SetEntityIDRecycling. freeEntityIndices are the lowest indices of the entities
+	// removed in earlier ticks, in ascending order, of which the first reusedEntityIndices have been reused in this
+	// tick. They are read from storage in batches as they are reused, until allFreeEntityIndicesRead.
+	// removedEntityIndices maps the indices of the entities removed in this tick to their next generation.
+	recycleEntityIDs         bool
+	freeEntityIndices        []uint64
+	reusedEntityIndices      int
+	allFreeEntityIndicesRead bool
+	removedEntityIndices     map[uint64]uint32
+
 	// Archetype EntityID management.
 	entityIDToArchID       VolatileStorage[types.EntityID, types.ArchetypeID]
 	entityIDToOriginArchID VolatileStorage[types.EntityID, types.ArchetypeID]
@@ -46,8 +60,20 @@ type EntityCommandBuffer struct {
 	archIDToComps  VolatileStorage[types.ArchetypeID, []types.ComponentMetadata]
 	pendingArchIDs []types.ArchetypeID
 
+	// Relations between entities. relations also caches relations that were only read.
+	relations         VolatileStorage[relationKey, []types.EntityID]
+	modifiedRelations VolatileStorage[relationKey, bool]
+
+	// Values of resources by name. resources also caches resources that were only read.
+	resources         VolatileStorage[string, json.RawMessage]
+	modifiedResources VolatileStorage[string, bool]
+
+	// Pending changes to schedules, by schedule and entity. A nil time unschedules the entity.
+	scheduleChanges VolatileStorage[scheduleKey, *uint64]
+
 	// OpenTelemetry tracer
 	tracer trace.Tracer
+	logger *zerolog.Logger
 }
 
 // NewEntityCommandBuffer creates a new command buffer manager that is able to queue up a series of states changes and
@@ -57,22 +83,38 @@ func NewEntityCommandBuffer(storage PrimitiveStorage[string]) (*EntityCommandBuf
 		dbStorage:          storage,
 		compValues:         NewMapStorage[compKey, any](),
 		compValuesToDelete: NewMapStorage[compKey, bool](),
+		modifiedEntities:   NewMapStorage[types.EntityID, bool](),
 
 		activeEntities: NewMapStorage[types.ArchetypeID, activeEntities](),
 		archIDToComps:  NewMapStorage[types.ArchetypeID, []types.ComponentMetadata](),
 
 		entityIDToArchID:       NewMapStorage[types.EntityID, types.ArchetypeID](),
 		entityIDToOriginArchID: NewMapStorage[types.EntityID, types.ArchetypeID](),
+		removedEntityIndices:   map[uint64]uint32{},
+
+		relations:         NewMapStorage[relationKey, []types.EntityID](),
+		modifiedRelations: NewMapStorage[relationKey, bool](),
+
+		resources:         NewMapStorage[string, json.RawMessage](),
+		modifiedResources: NewMapStorage[string, bool](),
+
+		scheduleChanges: NewMapStorage[scheduleKey, *uint64](),
 
 		// This field cannot be set until RegisterComponents is called
 		typeToComponent: nil,
 
 		tracer: otel.Tracer("ecb"),
+		logger: &log.Logger,
 	}
 
 	return m, nil
 }
 
+// SetLogger sets the logger the command buffer logs to. By default, the global logger is used.
+func (m *EntityCommandBuffer) SetLogger(logger *zerolog.Logger) {
+	m.logger = logger
+}
+
 func (m *EntityCommandBuffer) RegisterComponents(comps []types.ComponentMetadata) error {
 	m.typeToComponent = NewMapStorage[types.ComponentID, types.ComponentMetadata]()
 	for _, comp := range comps {
@@ -91,6 +133,10 @@ func (m *EntityCommandBuffer) DiscardPending() error {
 	if err != nil {
 		return err
 	}
+	err = m.modifiedEntities.Clear()
+	if err != nil {
+		return err
+	}
 
 	// Any entity archetypes movements need to be undone
 	err = m.activeEntities.Clear()
@@ -114,6 +160,10 @@ func (m *EntityCommandBuffer) DiscardPending() error {
 
 	m.isEntityIDLoaded = false
 	m.pendingEntityIDs = 0
+	m.freeEntityIndices = nil
+	m.reusedEntityIndices = 0
+	m.allFreeEntityIndicesRead = false
+	clear(m.removedEntityIndices)
 
 	for _, archID := range m.pendingArchIDs {
 		err = m.archIDToComps.Delete(archID)
@@ -122,7 +172,22 @@ func (m *EntityCommandBuffer) DiscardPending() error {
 		}
 	}
 	m.pendingArchIDs = m.pendingArchIDs[:0]
-	return nil
+
+	if err = m.relations.Clear(); err != nil {
+		return err
+	}
+	if err = m.modifiedRelations.Clear(); err != nil {
+		return err
+	}
+
+	if err = m.resources.Clear(); err != nil {
+		return err
+	}
+	if err = m.modifiedResources.Clear(); err != nil {
+		return err
+	}
+
+	return m.scheduleChanges.Clear()
 }
 
 // RemoveEntity removes the given entity from the ECS data model.
@@ -155,6 +220,10 @@ func (m *EntityCommandBuffer) RemoveEntity(idToRemove types.EntityID) error {
 		return err
 	}
 
+	if m.recycleEntityIDs {
+		m.removedEntityIndices[idToRemove.Index()] = idToRemove.Generation() + 1
+	}
+
 	comps, err := m.GetComponentTypesForArchID(archID)
 	if err != nil {
 		return err
@@ -211,7 +280,7 @@ func (m *EntityCommandBuffer) CreateManyEntities(num int, comps ...types.Compone
 		}
 		active.ids = append(active.ids, currID)
 		active.modified = true
-		ecslog.Entity(&log.Logger, zerolog.DebugLevel, currID, archID, comps)
+		ecslog.Entity(m.logger, zerolog.DebugLevel, currID, archID, comps)
 	}
 	err = m.setActiveEntities(archID, active)
 	if err != nil {
@@ -234,9 +303,38 @@ func (m *EntityCommandBuffer) SetComponentForEntity(
 	}
 
 	key := compKey{cType.ID(), id}
+	if err = m.modifiedEntities.Set(id, true); err != nil {
+		return err
+	}
 	return m.compValues.Set(key, value)
 }
 
+// PendingEntityChanges returns the IDs of all entities that were created, removed, had a component added or removed,
+// or had a component value set since the pending state changes were last finalized or discarded. The IDs are sorted
+// in ascending order.
+func (m *EntityCommandBuffer) PendingEntityChanges() ([]types.EntityID, error) {
+	movedIDs, err := m.entityIDToOriginArchID.Keys()
+	if err != nil {
+		return nil, err
+	}
+	modifiedIDs, err := m.modifiedEntities.Keys()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[types.EntityID]struct{}, len(movedIDs)+len(modifiedIDs))
+	ids := make([]types.EntityID, 0, len(movedIDs)+len(modifiedIDs))
+	for _, id := range append(movedIDs, modifiedIDs...) {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
+
 // GetComponentForEntity returns the saved component data for the given entity.
 func (m *EntityCommandBuffer) GetComponentForEntity(cType types.ComponentMetadata, id types.EntityID) (any, error) {
 	ctx := context.Background()
@@ -448,12 +546,19 @@ func (m *EntityCommandBuffer) getArchetypeForEntity(id types.EntityID) (types.Ar
 	if err == nil {
 		return archID, nil
 	}
+	if _, err = m.entityIDToOriginArchID.Get(id); err == nil {
+		// The entity has been removed in this tick, but storage won't know until the tick is finalized
+		return 0, eris.Wrap(ErrEntityDoesNotExist, ErrStaleEntityID.Error())
+	}
 	key := storageArchetypeIDForEntityID(id)
 	num, err := m.dbStorage.GetInt(context.Background(), key)
 	if err != nil {
 		// todo: Make redis.Nil a general error on storage
 		if errors.Is(err, redis.Nil) {
-			return 0, eris.Wrap(redis.Nil, ErrEntityDoesNotExist.Error())
+			if err = m.loadEntityIDs(); err != nil {
+				return 0, err
+			}
+			return 0, entityNotFoundError(m.dbStorage, id, m.nextEntityIDSaved+m.pendingEntityIDs)
 		}
 		return 0, eris.Wrap(err, "")
 	}
@@ -465,31 +570,6 @@ func (m *EntityCommandBuffer) getArchetypeForEntity(id types.EntityID) (types.Ar
 	return archID, nil
 }
 
-// nextEntityID returns the next available entity EntityID.
-func (m *EntityCommandBuffer) nextEntityID() (types.EntityID, error) {
-	if !m.isEntityIDLoaded {
-		// The next valid entity EntityID needs to be loaded from dbStorage.
-		ctx := context.Background()
-		nextID, err := m.dbStorage.GetUInt64(ctx, storageNextEntityIDKey())
-		err = eris.Wrap(err, "")
-		if err != nil {
-			// todo: make redis.Nil a general error on storage.
-			if !eris.Is(eris.Cause(err), redis.Nil) {
-				return 0, err
-			}
-			// redis.Nil means there's no value at this key. Start with an EntityID of 0
-			nextID = 0
-		}
-		m.nextEntityIDSaved = nextID
-		m.pendingEntityIDs = 0
-		m.isEntityIDLoaded = true
-	}
-
-	id := m.nextEntityIDSaved + m.pendingEntityIDs
-	m.pendingEntityIDs++
-	return types.EntityID(id), nil
-}
-
 // getOrMakeArchIDForComponents converts the given set of components into an archetype EntityID.
 // If the set of components has already been assigned an archetype EntityID, that EntityID is returned.
 // If this is a new set of components, an archetype EntityID is generated.
@@ -510,7 +590,7 @@ func (m *EntityCommandBuffer) getOrMakeArchIDForComponents(
 	if err != nil {
 		return 0, err
 	}
-	log.Debug().Int("archetype_id", int(id)).Msg("created")
+	m.logger.Debug().Int("archetype_id", int(id)).Msg("created")
 	return id, nil
 }
 
@@ -2,6 +2,7 @@ package gamestate_test
 
 import (
 	"context"
+	"errors"
 	"runtime"
 	"testing"
 	"time"
@@ -439,6 +440,53 @@ func TestEntityCanBeRemoved(t *testing.T) {
 	}
 }
 
+func TestEntityRemovedBeforeTickIsFinalizedDoesNotExist(t *testing.T) {
+	manager := newCmdBufferForTest(t)
+	ctx := context.Background()
+
+	id, err := manager.CreateEntity(fooComp)
+	assert.NilError(t, err)
+	assert.NilError(t, manager.FinalizeTick(ctx))
+
+	// The entity is still in storage until the tick is finalized, but it should no longer be found
+	assert.NilError(t, manager.RemoveEntity(id))
+	_, err = manager.GetComponentForEntity(fooComp, id)
+	assert.ErrorIs(t, err, gamestate.ErrEntityDoesNotExist)
+	assert.ErrorIs(t, manager.RemoveEntity(id), gamestate.ErrEntityDoesNotExist)
+}
+
+func TestPendingEntityChangesIncludeOnlyWrittenEntities(t *testing.T) {
+	manager := newCmdBufferForTest(t)
+	ctx := context.Background()
+
+	ids, err := manager.CreateManyEntities(4, fooComp)
+	assert.NilError(t, err)
+	changes, err := manager.PendingEntityChanges()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, ids, changes)
+	assert.NilError(t, manager.FinalizeTick(ctx))
+
+	changes, err = manager.PendingEntityChanges()
+	assert.NilError(t, err)
+	assert.Equal(t, 0, len(changes))
+
+	// Reading a component must not mark the entity as changed
+	_, err = manager.GetComponentForEntity(fooComp, ids[0])
+	assert.NilError(t, err)
+	assert.NilError(t, manager.SetComponentForEntity(fooComp, ids[1], Foo{}))
+	assert.NilError(t, manager.AddComponentToEntity(barComp, ids[2]))
+	assert.NilError(t, manager.RemoveEntity(ids[3]))
+
+	changes, err = manager.PendingEntityChanges()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, ids[1:], changes)
+
+	// Removed entities can't be found by the read-only manager once the tick is finalized
+	assert.NilError(t, manager.FinalizeTick(ctx))
+	_, err = manager.ToReadOnly().GetComponentTypesForEntity(ids[3])
+	assert.Check(t, errors.Is(err, gamestate.ErrEntityDoesNotExist))
+}
+
 func TestMovedEntitiesCanBeFoundInNewArchetype(t *testing.T) {
 	manager := newCmdBufferForTest(t)
 
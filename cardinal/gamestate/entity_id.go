@@ -0,0 +1,181 @@
+package gamestate
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+// SetEntityIDRecycling sets whether the indices of removed entities are reused for new entities, with the next
+// generation of the index (see types.EntityID). The indices of entities removed in a tick can be reused from the next
+// tick on, lowest index first. Recycling is disabled by default, in which case every entity gets a new index.
+//
+// Recycling changes the IDs that are assigned to new entities, so it must not be changed for a world that is being
+// recovered from its transactions.
+func (m *EntityCommandBuffer) SetEntityIDRecycling(enabled bool) {
+	m.recycleEntityIDs = enabled
+}
+
+// freeEntityIndicesBatchSize is the number of free entity indices that are read from storage at a time.
+const freeEntityIndicesBatchSize = 64
+
+// nextEntityID returns the next available entity EntityID.
+func (m *EntityCommandBuffer) nextEntityID() (types.EntityID, error) {
+	if err := m.loadEntityIDs(); err != nil {
+		return 0, err
+	}
+
+	if m.recycleEntityIDs {
+		index, ok, err := m.nextFreeEntityIndex()
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			generation, err := getEntityGenerationFromStorage(m.dbStorage, index)
+			if err != nil {
+				return 0, err
+			}
+			return types.NewEntityID(index, uint32(generation)), nil //nolint:gosec // free generations fit in 21 bits
+		}
+	}
+
+	index := m.nextEntityIDSaved + m.pendingEntityIDs
+	if index > types.MaxEntityIndex {
+		return 0, eris.Errorf("entity index %d exceeds the maximum entity index", index)
+	}
+	m.pendingEntityIDs++
+	return types.NewEntityID(index, 0), nil
+}
+
+// nextFreeEntityIndex reuses the lowest free entity index that hasn't been reused in this tick yet. False is returned
+// if there are no free indices left.
+func (m *EntityCommandBuffer) nextFreeEntityIndex() (uint64, bool, error) {
+	if m.reusedEntityIndices == len(m.freeEntityIndices) && !m.allFreeEntityIndicesRead {
+		// Free indices are only written when the tick is finalized, so the indices that have been read are still the
+		// lowest ones in storage
+		batch, err := getFreeEntityIndicesFromStorage(m.dbStorage, len(m.freeEntityIndices), freeEntityIndicesBatchSize)
+		if err != nil {
+			return 0, false, err
+		}
+		m.freeEntityIndices = append(m.freeEntityIndices, batch...)
+		m.allFreeEntityIndicesRead = len(batch) < freeEntityIndicesBatchSize
+	}
+	if m.reusedEntityIndices == len(m.freeEntityIndices) {
+		return 0, false, nil
+	}
+	index := m.freeEntityIndices[m.reusedEntityIndices]
+	m.reusedEntityIndices++
+	return index, true, nil
+}
+
+// loadEntityIDs loads the next entity index from storage.
+func (m *EntityCommandBuffer) loadEntityIDs() error {
+	if m.isEntityIDLoaded {
+		return nil
+	}
+	nextID, err := getNextEntityIndexFromStorage(m.dbStorage)
+	if err != nil {
+		return err
+	}
+	m.nextEntityIDSaved = nextID
+	m.pendingEntityIDs = 0
+	m.freeEntityIndices = nil
+	m.reusedEntityIndices = 0
+	m.allFreeEntityIndicesRead = false
+	m.isEntityIDLoaded = true
+	return nil
+}
+
+// addFreeEntityIndicesToPipe adds the generations of the indices of the entities removed in this tick to the given
+// redis pipe, and adds and removes the indices that have been freed and reused in this tick from the free indices in
+// storage.
+func (m *EntityCommandBuffer) addFreeEntityIndicesToPipe(ctx context.Context, pipe PrimitiveStorage[string]) error {
+	key := storageFreeEntityIndicesKey()
+	for _, index := range m.freeEntityIndices[:m.reusedEntityIndices] {
+		if err := pipe.RemoveFromSortedSet(ctx, key, strconv.FormatUint(index, 10)); err != nil {
+			return eris.Wrap(err, "")
+		}
+	}
+	for index, generation := range m.removedEntityIndices {
+		if err := pipe.Set(ctx, storageEntityGenerationKey(index), uint64(generation)); err != nil {
+			return eris.Wrap(err, "")
+		}
+		// Indices whose generation would overflow are retired
+		if generation > types.MaxEntityGeneration {
+			continue
+		}
+		if err := pipe.AddToSortedSet(ctx, key, index, strconv.FormatUint(index, 10)); err != nil {
+			return eris.Wrap(err, "")
+		}
+	}
+	return nil
+}
+
+// entityNotFoundError returns the error for an entity ID that isn't assigned to an entity, which tells apart a stale
+// ID of a removed entity from an ID that has never been assigned. nextIndex is the next entity index.
+func entityNotFoundError(storage PrimitiveStorage[string], id types.EntityID, nextIndex uint64) error {
+	// todo: this is redis specific, should be changed to a general error on storage
+	err := eris.Wrap(redis.Nil, ErrEntityDoesNotExist.Error())
+	if id.Index() >= nextIndex {
+		return eris.Wrap(err, ErrEntityNeverExisted.Error())
+	}
+	// All indices below the next index have been assigned in generation 0. Indices of removed entities have the next
+	// generation stored if recycling is enabled.
+	generation, err2 := getEntityGenerationFromStorage(storage, id.Index())
+	if err2 != nil {
+		return err2
+	}
+	if uint64(id.Generation()) < max(generation, 1) {
+		return eris.Wrap(err, ErrStaleEntityID.Error())
+	}
+	return eris.Wrap(err, ErrEntityNeverExisted.Error())
+}
+
+func getNextEntityIndexFromStorage(storage PrimitiveStorage[string]) (uint64, error) {
+	nextID, err := storage.GetUInt64(context.Background(), storageNextEntityIDKey())
+	if err != nil {
+		// todo: make redis.Nil a general error on storage.
+		if eris.Is(eris.Cause(err), redis.Nil) {
+			// redis.Nil means there's no value at this key. Start with an EntityID of 0
+			return 0, nil
+		}
+		return 0, eris.Wrap(err, "")
+	}
+	return nextID, nil
+}
+
+// getEntityGenerationFromStorage returns the generation of the next entity with the given index, which is 0 if the
+// generation has never been stored.
+func getEntityGenerationFromStorage(storage PrimitiveStorage[string], index uint64) (uint64, error) {
+	generation, err := storage.GetUInt64(context.Background(), storageEntityGenerationKey(index))
+	if err != nil {
+		if eris.Is(eris.Cause(err), redis.Nil) {
+			return 0, nil
+		}
+		return 0, eris.Wrap(err, "")
+	}
+	return generation, nil
+}
+
+// getFreeEntityIndicesFromStorage returns at most count of the free entity indices in storage, in ascending order,
+// skipping the first offset.
+func getFreeEntityIndicesFromStorage(storage PrimitiveStorage[string], offset int, count int) ([]uint64, error) {
+	members, err := storage.GetSortedSetMembers(context.Background(), storageFreeEntityIndicesKey(),
+		int64(offset), int64(count))
+	if err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+	indices := make([]uint64, 0, len(members))
+	for _, member := range members {
+		index, err := strconv.ParseUint(member, 10, 64)
+		if err != nil {
+			return nil, eris.Wrapf(err, "invalid free entity index %q", member)
+		}
+		indices = append(indices, index)
+	}
+	return indices, nil
+}
@@ -0,0 +1,127 @@
+package gamestate_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal/gamestate"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+func TestMissingEntitiesAreStaleOrNeverExisted(t *testing.T) {
+	manager := newCmdBufferForTest(t)
+	ctx := context.Background()
+
+	ids, err := manager.CreateManyEntities(2, fooComp)
+	assert.NilError(t, err)
+	assert.NilError(t, manager.FinalizeTick(ctx))
+	assert.NilError(t, manager.RemoveEntity(ids[0]))
+
+	_, err = manager.GetComponentTypesForEntity(ids[0])
+	assert.Check(t, eris.Is(err, gamestate.ErrStaleEntityID))
+	assert.Check(t, eris.Is(err, gamestate.ErrEntityDoesNotExist))
+	assert.NilError(t, manager.FinalizeTick(ctx))
+
+	for _, reader := range []gamestate.Reader{manager, manager.ToReadOnly()} {
+		_, err = reader.GetComponentTypesForEntity(ids[0])
+		assert.Check(t, eris.Is(err, gamestate.ErrStaleEntityID))
+		assert.Check(t, eris.Is(err, gamestate.ErrEntityDoesNotExist))
+		_, err = reader.GetComponentTypesForEntity(types.NewEntityID(ids[1].Index()+1, 0))
+		assert.Check(t, eris.Is(err, gamestate.ErrEntityNeverExisted))
+		assert.Check(t, eris.Is(err, gamestate.ErrEntityDoesNotExist))
+		_, err = reader.GetComponentTypesForEntity(types.NewEntityID(ids[1].Index(), 1))
+		assert.Check(t, eris.Is(err, gamestate.ErrEntityNeverExisted))
+	}
+
+	// Without recycling, indices aren't reused
+	id, err := manager.CreateEntity(fooComp)
+	assert.NilError(t, err)
+	assert.Equal(t, id, types.NewEntityID(2, 0))
+}
+
+func TestEntityIndicesAreRecycledWithTheNextGeneration(t *testing.T) {
+	manager, client := newCmdBufferAndRedisClientForTest(t, nil)
+	manager.SetEntityIDRecycling(true)
+	ctx := context.Background()
+
+	ids, err := manager.CreateManyEntities(3, fooComp)
+	assert.NilError(t, err)
+	assert.NilError(t, manager.FinalizeTick(ctx))
+	assert.NilError(t, manager.RemoveEntity(ids[2]))
+	assert.NilError(t, manager.RemoveEntity(ids[1]))
+
+	// Indices removed in a tick are only reused from the next tick on
+	id, err := manager.CreateEntity(fooComp)
+	assert.NilError(t, err)
+	assert.Equal(t, id, types.NewEntityID(3, 0))
+	assert.NilError(t, manager.FinalizeTick(ctx))
+
+	// A new command buffer continues with the free indices from storage
+	manager, _ = newCmdBufferAndRedisClientForTest(t, client)
+	manager.SetEntityIDRecycling(true)
+	newIDs, err := manager.CreateManyEntities(3, barComp)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, newIDs, []types.EntityID{
+		types.NewEntityID(ids[1].Index(), 1),
+		types.NewEntityID(ids[2].Index(), 1),
+		types.NewEntityID(4, 0),
+	})
+	assert.Equal(t, newIDs[0].Generation(), uint32(1))
+	assert.NilError(t, manager.FinalizeTick(ctx))
+
+	// The IDs of the removed entities don't refer to the new entities
+	_, err = manager.GetComponentForEntity(fooComp, ids[1])
+	assert.Check(t, eris.Is(err, gamestate.ErrStaleEntityID))
+	comps, err := manager.GetComponentTypesForEntity(newIDs[0])
+	assert.NilError(t, err)
+	assert.Equal(t, comps[0].ID(), barComp.ID())
+	_, err = manager.GetComponentTypesForEntity(types.NewEntityID(ids[1].Index(), 2))
+	assert.Check(t, eris.Is(err, gamestate.ErrEntityNeverExisted))
+
+	// Discarded changes don't use up free indices
+	assert.NilError(t, manager.RemoveEntity(newIDs[0]))
+	assert.NilError(t, manager.FinalizeTick(ctx))
+	id, err = manager.CreateEntity(fooComp)
+	assert.NilError(t, err)
+	assert.Equal(t, id, types.NewEntityID(ids[1].Index(), 2))
+	assert.NilError(t, manager.DiscardPending())
+	id, err = manager.CreateEntity(fooComp)
+	assert.NilError(t, err)
+	assert.Equal(t, id, types.NewEntityID(ids[1].Index(), 2))
+}
+
+// TestFreeEntityIndicesAreUpdatedIncrementally tests that the free indices in storage are added and removed one by one
+// as entities are removed and created, and that more free indices can be reused in a tick than are read at a time.
+func TestFreeEntityIndicesAreUpdatedIncrementally(t *testing.T) {
+	manager, client := newCmdBufferAndRedisClientForTest(t, nil)
+	manager.SetEntityIDRecycling(true)
+	ctx := context.Background()
+	const freeKey = "ECB:FREE-ENTITY-INDICES"
+
+	ids, err := manager.CreateManyEntities(200, fooComp)
+	assert.NilError(t, err)
+	assert.NilError(t, manager.FinalizeTick(ctx))
+	for _, id := range ids[:150] {
+		assert.NilError(t, manager.RemoveEntity(id))
+	}
+	assert.NilError(t, manager.FinalizeTick(ctx))
+	assert.Equal(t, client.ZCard(ctx, freeKey).Val(), int64(150))
+
+	newIDs, err := manager.CreateManyEntities(100, fooComp)
+	assert.NilError(t, err)
+	for i, id := range newIDs {
+		assert.Equal(t, id, types.NewEntityID(ids[i].Index(), 1))
+	}
+	assert.NilError(t, manager.FinalizeTick(ctx))
+	assert.Equal(t, client.ZCard(ctx, freeKey).Val(), int64(50))
+
+	newIDs, err = manager.CreateManyEntities(51, fooComp)
+	assert.NilError(t, err)
+	assert.Equal(t, newIDs[0], types.NewEntityID(ids[100].Index(), 1))
+	assert.Equal(t, newIDs[50], types.NewEntityID(200, 0))
+	assert.NilError(t, manager.FinalizeTick(ctx))
+	assert.Equal(t, client.ZCard(ctx, freeKey).Val(), int64(0))
+}
@@ -11,6 +11,11 @@ var (
 	ErrEntityMustHaveAtLeastOneComponent = errors.New("entities must have at least 1 component")
 	ErrMustRegisterComponent             = errors.New("must register component")
 
+	// ErrStaleEntityID and ErrEntityNeverExisted tell apart why an entity does not exist. Errors that match them also
+	// match ErrEntityDoesNotExist.
+	ErrStaleEntityID      = errors.New("entity id is stale, the entity has been removed")
+	ErrEntityNeverExisted = errors.New("entity id has never been assigned")
+
 	// ErrComponentMismatchWithSavedState is an error that is returned when a ComponentID from
 	// the saved state is not found in the passed in list of components.
 	ErrComponentMismatchWithSavedState = errors.New("registered components do not match with the saved state")
@@ -42,3 +42,36 @@ func storageArchIDsToCompTypesKey() string {
 func storageLastFinalizedTickKey() string {
 	return "ECB:LAST-FINALIZED-TICK"
 }
+
+// storageRelationKey is the key that maps an entity ID to the entities it is related to by the given relation, or to
+// the entities that are related to it if incoming is true.
+// Note, the outgoing and incoming keys of a relation represent the same information.
+func storageRelationKey(relation string, id types.EntityID, incoming bool) string {
+	if incoming {
+		return fmt.Sprintf("ECB:RELATION:%s:TO-ENTITY-ID-%d", relation, id)
+	}
+	return fmt.Sprintf("ECB:RELATION:%s:FROM-ENTITY-ID-%d", relation, id)
+}
+
+// storageResourceKey is the key that stores the value of the resource with the given name.
+func storageResourceKey(name string) string {
+	return fmt.Sprintf("ECB:RESOURCE:%s", name)
+}
+
+// storageFreeEntityIndicesKey is the key of the sorted set that holds the indices of removed entities that can be
+// reused for new entities, scored by index. It is only used if entity ID recycling is enabled.
+func storageFreeEntityIndicesKey() string {
+	return "ECB:FREE-ENTITY-INDICES"
+}
+
+// storageEntityGenerationKey is the key that stores the generation of the next entity with the given index. It is
+// only set for the indices of removed entities if entity ID recycling is enabled.
+func storageEntityGenerationKey(index uint64) string {
+	return fmt.Sprintf("ECB:ENTITY-GENERATION:INDEX-%d", index)
+}
+
+// storageScheduleKey is the key of the sorted set that holds the entities of the schedule with the given name, scored
+// by the time they are scheduled at.
+func storageScheduleKey(schedule string) string {
+	return fmt.Sprintf("ECB:SCHEDULE:%s", schedule)
+}
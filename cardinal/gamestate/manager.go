@@ -23,6 +23,16 @@ type Reader interface {
 	// One Archetype Many Entities
 	GetEntitiesForArchID(archID types.ArchetypeID) ([]types.EntityID, error)
 
+	// Relations
+	GetRelationTargets(relation string, id types.EntityID) ([]types.EntityID, error)
+	GetRelationSources(relation string, id types.EntityID) ([]types.EntityID, error)
+
+	// Resources
+	GetResource(name string) (json.RawMessage, bool, error)
+
+	// Schedules
+	GetScheduled(schedule string, until uint64) ([]types.EntityID, error)
+
 	// Misc
 	SearchFrom(filter filter.ComponentFilter, start int) *ArchetypeIterator
 	ArchetypeCount() int
@@ -41,6 +51,17 @@ type Writer interface {
 	AddComponentToEntity(cType types.ComponentMetadata, id types.EntityID) error
 	RemoveComponentFromEntity(cType types.ComponentMetadata, id types.EntityID) error
 
+	// Relations
+	AddRelation(relation string, from, to types.EntityID) error
+	RemoveRelation(relation string, from, to types.EntityID) error
+
+	// Resources
+	SetResource(name string, value json.RawMessage) error
+
+	// Schedules
+	Schedule(schedule string, id types.EntityID, at uint64) error
+	Unschedule(schedule string, id types.EntityID) error
+
 	// Misc
 	Close() error
 	RegisterComponents([]types.ComponentMetadata) error
@@ -49,6 +70,10 @@ type Writer interface {
 type TickStorage interface {
 	GetLastFinalizedTick() (tick uint64, err error)
 	FinalizeTick(ctx context.Context) error
+	// PendingEntityChanges returns the IDs of the entities changed by the tick that has not been finalized yet.
+	PendingEntityChanges() ([]types.EntityID, error)
+	// PendingComponentChanges returns the component changes made by the tick that has not been finalized yet.
+	PendingComponentChanges() ([]ComponentChange, error)
 }
 
 // Manager represents all the methods required to track Component, Entity, and Archetype information
@@ -19,6 +19,14 @@ type PrimitiveStorage[K comparable] interface {
 	Incr(ctx context.Context, key K) error
 	Decr(ctx context.Context, key K) error
 	Delete(ctx context.Context, key K) error
+	// AddToSortedSet adds the member to the sorted set at key with the given score, or moves it to that score if it
+	// is already in the set.
+	AddToSortedSet(ctx context.Context, key K, score uint64, member string) error
+	RemoveFromSortedSet(ctx context.Context, key K, member string) error
+	// GetSortedSetRange returns the members of the sorted set at key whose score is at most maxScore, by score.
+	GetSortedSetRange(ctx context.Context, key K, maxScore uint64) ([]string, error)
+	// GetSortedSetMembers returns at most count members of the sorted set at key by score, skipping the first offset.
+	GetSortedSetMembers(ctx context.Context, key K, offset int64, count int64) ([]string, error)
 	StartTransaction(ctx context.Context) (Transaction[K], error)
 	EndTransaction(ctx context.Context) error
 	Close(ctx context.Context) error
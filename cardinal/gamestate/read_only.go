@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 
+	"github.com/redis/go-redis/v9"
 	"github.com/rotisserie/eris"
 
 	"pkg.world.dev/world-engine/cardinal/codec"
@@ -62,6 +63,16 @@ func (r *readOnlyManager) GetComponentForEntityInRawJSON(
 	ctx := context.Background()
 	key := storageComponentKey(cType.ID(), id)
 	res, err := r.storage.GetBytes(ctx, key)
+	if errors.Is(err, redis.Nil) {
+		// Like the EntityCommandBuffer, tell a component that isn't on the entity apart from a missing entity
+		comps, compsErr := r.GetComponentTypesForEntity(id)
+		if compsErr != nil {
+			return nil, compsErr
+		}
+		if !filter.MatchComponentMetadata(comps, cType) {
+			return nil, eris.Wrap(ErrComponentNotOnEntity, "")
+		}
+	}
 	return res, eris.Wrap(err, "")
 }
 
@@ -85,6 +96,13 @@ func (r *readOnlyManager) GetComponentTypesForEntity(id types.EntityID) ([]types
 	archIDKey := storageArchetypeIDForEntityID(id)
 	num, err := r.storage.GetInt(ctx, archIDKey)
 	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			nextIndex, err := getNextEntityIndexFromStorage(r.storage)
+			if err != nil {
+				return nil, err
+			}
+			return nil, entityNotFoundError(r.storage, id, nextIndex)
+		}
 		return nil, eris.Wrap(err, "")
 	}
 	archID := types.ArchetypeID(num)
@@ -2,6 +2,7 @@ package gamestate
 
 import (
 	"context"
+	"strconv"
 
 	"github.com/redis/go-redis/v9"
 	"github.com/rotisserie/eris"
@@ -105,6 +106,37 @@ func (r *RedisStorage) Delete(ctx context.Context, key string) error {
 	return eris.Wrap(r.currentClient.Del(ctx, key).Err(), "")
 }
 
+// AddToSortedSet adds the member to the sorted set at key. Redis stores scores as doubles, so scores above 2^53 lose
+// precision.
+func (r *RedisStorage) AddToSortedSet(ctx context.Context, key string, score uint64, member string) error {
+	return eris.Wrap(r.currentClient.ZAdd(ctx, key, redis.Z{Score: float64(score), Member: member}).Err(), "")
+}
+
+func (r *RedisStorage) RemoveFromSortedSet(ctx context.Context, key string, member string) error {
+	return eris.Wrap(r.currentClient.ZRem(ctx, key, member).Err(), "")
+}
+
+func (r *RedisStorage) GetSortedSetRange(ctx context.Context, key string, maxScore uint64) ([]string, error) {
+	members, err := r.currentClient.ZRangeByScore(ctx, key, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatUint(maxScore, 10),
+	}).Result()
+	if err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+	return members, nil
+}
+
+func (r *RedisStorage) GetSortedSetMembers(
+	ctx context.Context, key string, offset int64, count int64,
+) ([]string, error) {
+	members, err := r.currentClient.ZRange(ctx, key, offset, offset+count-1).Result()
+	if err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+	return members, nil
+}
+
 func (r *RedisStorage) Close(ctx context.Context) error {
 	return eris.Wrap(r.currentClient.Shutdown(ctx).Err(), "")
 }
@@ -171,9 +203,13 @@ func (m *EntityCommandBuffer) makePipeOfRedisCommands(ctx context.Context) (Prim
 	}{
 		{"component_changes", m.addComponentChangesToPipe},
 		{"next_entity_id", m.addNextEntityIDToPipe},
+		{"free_entity_indices", m.addFreeEntityIndicesToPipe},
 		{"pending_arch_ids", m.addPendingArchIDsToPipe},
 		{"entity_id_to_arch_id", m.addEntityIDToArchIDToPipe},
 		{"active_entity_ids", m.addActiveEntityIDsToPipe},
+		{"relations", m.addRelationChangesToPipe},
+		{"resources", m.addResourceChangesToPipe},
+		{"schedules", m.addScheduleChangesToPipe},
 	}
 
 	for _, operation := range operations {
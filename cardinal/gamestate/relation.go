@@ -0,0 +1,149 @@
+package gamestate
+
+import (
+	"context"
+	"slices"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/codec"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+// relationKey identifies the entities that an entity is related to by a relation, or the entities that are related to
+// it if incoming is true. Both directions are stored, so that relations can be looked up from either end.
+type relationKey struct {
+	relation string
+	id       types.EntityID
+	incoming bool
+}
+
+// GetRelationTargets returns the entities that the given entity is related to by the given relation, in ascending
+// order.
+func (m *EntityCommandBuffer) GetRelationTargets(relation string, id types.EntityID) ([]types.EntityID, error) {
+	return m.getRelated(relationKey{relation, id, false})
+}
+
+// GetRelationSources returns the entities that are related to the given entity by the given relation, in ascending
+// order.
+func (m *EntityCommandBuffer) GetRelationSources(relation string, id types.EntityID) ([]types.EntityID, error) {
+	return m.getRelated(relationKey{relation, id, true})
+}
+
+// AddRelation relates the entity from to the entity to by the given relation. Adding a relation that already exists
+// has no effect.
+func (m *EntityCommandBuffer) AddRelation(relation string, from, to types.EntityID) error {
+	if err := m.updateRelated(relationKey{relation, from, false}, func(ids []types.EntityID) []types.EntityID {
+		return insertSorted(ids, to)
+	}); err != nil {
+		return err
+	}
+	return m.updateRelated(relationKey{relation, to, true}, func(ids []types.EntityID) []types.EntityID {
+		return insertSorted(ids, from)
+	})
+}
+
+// RemoveRelation removes the relation of the entity from to the entity to. Removing a relation that doesn't exist has
+// no effect.
+func (m *EntityCommandBuffer) RemoveRelation(relation string, from, to types.EntityID) error {
+	if err := m.updateRelated(relationKey{relation, from, false}, func(ids []types.EntityID) []types.EntityID {
+		return deleteSorted(ids, to)
+	}); err != nil {
+		return err
+	}
+	return m.updateRelated(relationKey{relation, to, true}, func(ids []types.EntityID) []types.EntityID {
+		return deleteSorted(ids, from)
+	})
+}
+
+func (m *EntityCommandBuffer) getRelated(key relationKey) ([]types.EntityID, error) {
+	if ids, err := m.relations.Get(key); err == nil {
+		return slices.Clone(ids), nil
+	}
+	ids, err := getRelatedFromStorage(m.dbStorage, key)
+	if err != nil {
+		return nil, err
+	}
+	if err = m.relations.Set(key, ids); err != nil {
+		return nil, err
+	}
+	return slices.Clone(ids), nil
+}
+
+func (m *EntityCommandBuffer) updateRelated(key relationKey, update func([]types.EntityID) []types.EntityID) error {
+	ids, err := m.getRelated(key)
+	if err != nil {
+		return err
+	}
+	if err = m.relations.Set(key, update(ids)); err != nil {
+		return err
+	}
+	return m.modifiedRelations.Set(key, true)
+}
+
+// addRelationChangesToPipe adds the relations that have been changed to the given redis pipe. Entities without any
+// relations of a kind don't have a key for it.
+func (m *EntityCommandBuffer) addRelationChangesToPipe(ctx context.Context, pipe PrimitiveStorage[string]) error {
+	keys, err := m.modifiedRelations.Keys()
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		ids, err := m.relations.Get(key)
+		if err != nil {
+			return err
+		}
+		redisKey := storageRelationKey(key.relation, key.id, key.incoming)
+		if len(ids) == 0 {
+			if err = pipe.Delete(ctx, redisKey); err != nil {
+				return eris.Wrap(err, "")
+			}
+			continue
+		}
+		bz, err := codec.Encode(ids)
+		if err != nil {
+			return err
+		}
+		if err = pipe.Set(ctx, redisKey, bz); err != nil {
+			return eris.Wrap(err, "")
+		}
+	}
+	return nil
+}
+
+func (r *readOnlyManager) GetRelationTargets(relation string, id types.EntityID) ([]types.EntityID, error) {
+	return getRelatedFromStorage(r.storage, relationKey{relation, id, false})
+}
+
+func (r *readOnlyManager) GetRelationSources(relation string, id types.EntityID) ([]types.EntityID, error) {
+	return getRelatedFromStorage(r.storage, relationKey{relation, id, true})
+}
+
+func getRelatedFromStorage(storage PrimitiveStorage[string], key relationKey) ([]types.EntityID, error) {
+	bz, err := storage.GetBytes(context.Background(), storageRelationKey(key.relation, key.id, key.incoming))
+	if err != nil {
+		// todo: this is redis specific, should be changed to a general error on storage
+		if eris.Is(eris.Cause(err), redis.Nil) {
+			return []types.EntityID{}, nil
+		}
+		return nil, eris.Wrap(err, "")
+	}
+	return codec.Decode[[]types.EntityID](bz)
+}
+
+func insertSorted(ids []types.EntityID, id types.EntityID) []types.EntityID {
+	i, found := slices.BinarySearch(ids, id)
+	if found {
+		return ids
+	}
+	return slices.Insert(ids, i, id)
+}
+
+func deleteSorted(ids []types.EntityID, id types.EntityID) []types.EntityID {
+	i, found := slices.BinarySearch(ids, id)
+	if !found {
+		return ids
+	}
+	return slices.Delete(ids, i, i+1)
+}
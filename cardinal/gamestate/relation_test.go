@@ -0,0 +1,58 @@
+package gamestate_test
+
+import (
+	"context"
+	"testing"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+func TestRelationsArePersistedWhenTheTickIsFinalized(t *testing.T) {
+	manager, client := newCmdBufferAndRedisClientForTest(t, nil)
+	ctx := context.Background()
+
+	assert.NilError(t, manager.AddRelation("member", 3, 1))
+	assert.NilError(t, manager.AddRelation("member", 2, 1))
+	assert.NilError(t, manager.AddRelation("member", 2, 1))
+	assert.NilError(t, manager.AddRelation("member", 2, 4))
+	assert.NilError(t, manager.FinalizeTick(ctx))
+
+	// A new command buffer loads the relations from storage
+	manager, _ = newCmdBufferAndRedisClientForTest(t, client)
+	sources, err := manager.GetRelationSources("member", 1)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, sources, []types.EntityID{2, 3})
+	targets, err := manager.ToReadOnly().GetRelationTargets("member", 2)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, targets, []types.EntityID{1, 4})
+
+	assert.NilError(t, manager.RemoveRelation("member", 2, 1))
+	assert.NilError(t, manager.RemoveRelation("member", 2, 4))
+	assert.NilError(t, manager.FinalizeTick(ctx))
+	targets, err = manager.ToReadOnly().GetRelationTargets("member", 2)
+	assert.NilError(t, err)
+	assert.Equal(t, len(targets), 0)
+	sources, err = manager.ToReadOnly().GetRelationSources("member", 1)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, sources, []types.EntityID{3})
+}
+
+func TestDiscardedRelationChangesAreReverted(t *testing.T) {
+	manager := newCmdBufferForTest(t)
+	ctx := context.Background()
+
+	assert.NilError(t, manager.AddRelation("member", 2, 1))
+	assert.NilError(t, manager.FinalizeTick(ctx))
+
+	assert.NilError(t, manager.RemoveRelation("member", 2, 1))
+	assert.NilError(t, manager.AddRelation("member", 3, 1))
+	sources, err := manager.GetRelationSources("member", 1)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, sources, []types.EntityID{3})
+
+	assert.NilError(t, manager.DiscardPending())
+	sources, err = manager.GetRelationSources("member", 1)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, sources, []types.EntityID{2})
+}
@@ -0,0 +1,72 @@
+package gamestate
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rotisserie/eris"
+)
+
+// GetResource returns the value of the resource with the given name. False is returned if the resource has never been
+// set.
+func (m *EntityCommandBuffer) GetResource(name string) (json.RawMessage, bool, error) {
+	if value, err := m.resources.Get(name); err == nil {
+		return slices.Clone(value), value != nil, nil
+	}
+	value, ok, err := getResourceFromStorage(m.dbStorage, name)
+	if err != nil {
+		return nil, false, err
+	}
+	// Resources that have never been set are cached as nil
+	if err = m.resources.Set(name, value); err != nil {
+		return nil, false, err
+	}
+	return slices.Clone(value), ok, nil
+}
+
+// SetResource sets the value of the resource with the given name.
+func (m *EntityCommandBuffer) SetResource(name string, value json.RawMessage) error {
+	if value == nil {
+		return eris.Errorf("value of resource %q must not be nil", name)
+	}
+	if err := m.resources.Set(name, slices.Clone(value)); err != nil {
+		return err
+	}
+	return m.modifiedResources.Set(name, true)
+}
+
+// addResourceChangesToPipe adds the resources that have been set to the given redis pipe.
+func (m *EntityCommandBuffer) addResourceChangesToPipe(ctx context.Context, pipe PrimitiveStorage[string]) error {
+	names, err := m.modifiedResources.Keys()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		value, err := m.resources.Get(name)
+		if err != nil {
+			return err
+		}
+		if err = pipe.Set(ctx, storageResourceKey(name), []byte(value)); err != nil {
+			return eris.Wrap(err, "")
+		}
+	}
+	return nil
+}
+
+func (r *readOnlyManager) GetResource(name string) (json.RawMessage, bool, error) {
+	return getResourceFromStorage(r.storage, name)
+}
+
+func getResourceFromStorage(storage PrimitiveStorage[string], name string) (json.RawMessage, bool, error) {
+	bz, err := storage.GetBytes(context.Background(), storageResourceKey(name))
+	if err != nil {
+		// todo: this is redis specific, should be changed to a general error on storage
+		if eris.Is(eris.Cause(err), redis.Nil) {
+			return nil, false, nil
+		}
+		return nil, false, eris.Wrap(err, "")
+	}
+	return bz, true, nil
+}
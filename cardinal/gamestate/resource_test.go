@@ -0,0 +1,38 @@
+package gamestate_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"pkg.world.dev/world-engine/assert"
+)
+
+func TestResourcesArePersistedWhenTheTickIsFinalized(t *testing.T) {
+	manager, client := newCmdBufferAndRedisClientForTest(t, nil)
+	ctx := context.Background()
+
+	_, ok, err := manager.GetResource("config")
+	assert.NilError(t, err)
+	assert.Check(t, !ok)
+
+	assert.NilError(t, manager.SetResource("config", json.RawMessage(`{"Value":1}`)))
+	value, ok, err := manager.GetResource("config")
+	assert.NilError(t, err)
+	assert.Check(t, ok)
+	assert.Equal(t, string(value), `{"Value":1}`)
+	// The resource isn't saved until the tick is finalized
+	_, ok, err = manager.ToReadOnly().GetResource("config")
+	assert.NilError(t, err)
+	assert.Check(t, !ok)
+	assert.NilError(t, manager.FinalizeTick(ctx))
+
+	assert.NilError(t, manager.SetResource("config", json.RawMessage(`{"Value":2}`)))
+	assert.NilError(t, manager.DiscardPending())
+
+	manager, _ = newCmdBufferAndRedisClientForTest(t, client)
+	value, ok, err = manager.GetResource("config")
+	assert.NilError(t, err)
+	assert.Check(t, ok)
+	assert.Equal(t, string(value), `{"Value":1}`)
+}
@@ -0,0 +1,106 @@
+package gamestate
+
+import (
+	"context"
+	"slices"
+	"strconv"
+
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+// scheduleKey identifies an entity in a schedule.
+type scheduleKey struct {
+	schedule string
+	id       types.EntityID
+}
+
+// Schedule schedules the given entity at the given time in the schedule with the given name. An entity is scheduled
+// at most once in a schedule, so scheduling it again moves it to the new time. Times are compared as doubles by
+// storage, so times above 2^53 may be rounded.
+func (m *EntityCommandBuffer) Schedule(schedule string, id types.EntityID, at uint64) error {
+	return m.scheduleChanges.Set(scheduleKey{schedule, id}, &at)
+}
+
+// Unschedule removes the given entity from the schedule with the given name. Unscheduling an entity that isn't
+// scheduled has no effect.
+func (m *EntityCommandBuffer) Unschedule(schedule string, id types.EntityID) error {
+	return m.scheduleChanges.Set(scheduleKey{schedule, id}, nil)
+}
+
+// GetScheduled returns the entities that are scheduled at or before the given time in the schedule with the given
+// name, in ascending order. Only the entities that are due are read from storage.
+func (m *EntityCommandBuffer) GetScheduled(schedule string, until uint64) ([]types.EntityID, error) {
+	ids, err := getScheduledFromStorage(m.dbStorage, schedule, until)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := m.scheduleChanges.Keys()
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		if key.schedule != schedule {
+			continue
+		}
+		at, err := m.scheduleChanges.Get(key)
+		if err != nil {
+			return nil, err
+		}
+		if at != nil && *at <= until {
+			ids = insertSorted(ids, key.id)
+		} else {
+			ids = deleteSorted(ids, key.id)
+		}
+	}
+	return ids, nil
+}
+
+// addScheduleChangesToPipe adds the entities that have been scheduled or unscheduled to the given redis pipe.
+func (m *EntityCommandBuffer) addScheduleChangesToPipe(ctx context.Context, pipe PrimitiveStorage[string]) error {
+	keys, err := m.scheduleChanges.Keys()
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		at, err := m.scheduleChanges.Get(key)
+		if err != nil {
+			return err
+		}
+		redisKey := storageScheduleKey(key.schedule)
+		member := strconv.FormatUint(uint64(key.id), 10)
+		if at == nil {
+			err = pipe.RemoveFromSortedSet(ctx, redisKey, member)
+		} else {
+			err = pipe.AddToSortedSet(ctx, redisKey, *at, member)
+		}
+		if err != nil {
+			return eris.Wrap(err, "")
+		}
+	}
+	return nil
+}
+
+func (r *readOnlyManager) GetScheduled(schedule string, until uint64) ([]types.EntityID, error) {
+	return getScheduledFromStorage(r.storage, schedule, until)
+}
+
+func getScheduledFromStorage(
+	storage PrimitiveStorage[string], schedule string, until uint64,
+) ([]types.EntityID, error) {
+	members, err := storage.GetSortedSetRange(context.Background(), storageScheduleKey(schedule), until)
+	if err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+	ids := make([]types.EntityID, 0, len(members))
+	for _, member := range members {
+		id, err := strconv.ParseUint(member, 10, 64)
+		if err != nil {
+			return nil, eris.Wrapf(err, "invalid entity ID %q in schedule %q", member, schedule)
+		}
+		ids = append(ids, types.EntityID(id))
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
@@ -0,0 +1,50 @@
+package gamestate_test
+
+import (
+	"context"
+	"testing"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+func TestSchedulesArePersistedWhenTheTickIsFinalized(t *testing.T) {
+	manager, client := newCmdBufferAndRedisClientForTest(t, nil)
+	ctx := context.Background()
+
+	assert.NilError(t, manager.Schedule("timer", 5, 30))
+	assert.NilError(t, manager.Schedule("timer", 2, 10))
+	assert.NilError(t, manager.Schedule("timer", 4, 20))
+	assert.NilError(t, manager.Schedule("timer", 3, 40))
+	assert.NilError(t, manager.Schedule("other", 1, 10))
+	// Scheduling an entity again moves it
+	assert.NilError(t, manager.Schedule("timer", 3, 15))
+	due, err := manager.GetScheduled("timer", 20)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, due, []types.EntityID{2, 3, 4})
+	assert.NilError(t, manager.FinalizeTick(ctx))
+
+	// A new command buffer loads the schedules from storage
+	manager, _ = newCmdBufferAndRedisClientForTest(t, client)
+	due, err = manager.ToReadOnly().GetScheduled("timer", 20)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, due, []types.EntityID{2, 3, 4})
+
+	// Pending changes are merged with the stored schedule
+	assert.NilError(t, manager.Unschedule("timer", 3))
+	assert.NilError(t, manager.Schedule("timer", 4, 50))
+	assert.NilError(t, manager.Schedule("timer", 5, 1))
+	due, err = manager.GetScheduled("timer", 20)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, due, []types.EntityID{2, 5})
+	// The read only manager only sees the finalized schedule
+	due, err = manager.ToReadOnly().GetScheduled("timer", 20)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, due, []types.EntityID{2, 3, 4})
+
+	// Discarded changes are not persisted
+	assert.NilError(t, manager.DiscardPending())
+	due, err = manager.GetScheduled("timer", 100)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, due, []types.EntityID{2, 3, 4, 5})
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"reflect"
 	"regexp"
+	"slices"
 
 	ethereumAbi "github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/rotisserie/eris"
@@ -12,6 +13,7 @@ import (
 	"pkg.world.dev/world-engine/cardinal/abi"
 	"pkg.world.dev/world-engine/cardinal/codec"
 	"pkg.world.dev/world-engine/cardinal/types"
+	"pkg.world.dev/world-engine/cardinal/validation"
 	"pkg.world.dev/world-engine/sign"
 )
 
@@ -40,12 +42,30 @@ type MessageType[In, Out any] struct {
 	group      string
 	inEVMType  *ethereumAbi.Type
 	outEVMType *ethereumAbi.Type
+	// rules are the validation rules of the "In" type, which are checked when a message is submitted.
+	rules *validation.Rules
+	// version is the version of the "In" type. It is 1, unless older versions were registered with
+	// WithMessageVersion.
+	version uint32
+	// upgrades decode the payloads of the older versions of the message, and upgrade them to the "In" type.
+	upgrades map[uint32]messageUpgrade[In]
+}
+
+// messageUpgrade decodes the payload of an older version of a message, and upgrades it to the message's "In" type.
+type messageUpgrade[In any] struct {
+	// payloadType is the type of the payload of the older version.
+	payloadType reflect.Type
+	decode      func([]byte) (In, error)
 }
 
 // NewMessageType creates a new message type. It accepts two generic type parameters: the first for the message input,
 // which defines the data needed to make a state transition, and the second for the message output, commonly used
 // for the results of a state transition. By default, messages will be grouped under the "game" group, however an option
 // may be passed in to change this.
+//
+// Messages are validated when they are submitted, against the rules in the `cardinal` tags of the fields of the input
+// type and its Validate method, if it implements validation.Validator. See the validation package for the rules.
+// Invalid messages are rejected before they reach the tx pool.
 func NewMessageType[In, Out any](
 	name string,
 	opts ...MessageOption[In, Out],
@@ -64,6 +84,22 @@ func NewMessageType[In, Out any](
 		panic(fmt.Sprintf("Invalid MessageType: %q: message group and name must only contain alphanumerics, "+
 			"dashes (-), and/or underscores (_). Must also start/end with an alphanumeric.", msg.FullName()))
 	}
+	rules, err := validation.Compile(reflect.TypeOf(new(In)).Elem())
+	if err != nil {
+		panic(fmt.Sprintf("Invalid MessageType: %q: %v", msg.FullName(), err))
+	}
+	msg.rules = rules
+	msg.version = 1
+	for version := range msg.upgrades {
+		msg.version = max(msg.version, version+1)
+	}
+	// Every version before the "In" type must be registered, so that the version of a payload is never ambiguous
+	for version := uint32(1); version < msg.version; version++ {
+		if _, ok := msg.upgrades[version]; !ok {
+			panic(fmt.Sprintf("Invalid MessageType: %q: version %d is missing, versions must not have gaps",
+				msg.FullName(), version))
+		}
+	}
 	return msg
 }
 
@@ -77,6 +113,22 @@ func (t *MessageType[In, Out]) Group() string {
 
 func (t *MessageType[In, Out]) FullName() string { return t.group + "." + t.name }
 
+// Version returns the version of the message's "In" type.
+func (t *MessageType[In, Out]) Version() uint32 {
+	return t.version
+}
+
+// Versions returns every version of the message that can be submitted, in ascending order. The last one is the
+// version of the message's "In" type.
+func (t *MessageType[In, Out]) Versions() []uint32 {
+	versions := make([]uint32, 0, len(t.upgrades)+1)
+	for version := range t.upgrades {
+		versions = append(versions, version)
+	}
+	slices.Sort(versions)
+	return append(versions, t.version)
+}
+
 func (t *MessageType[In, Out]) IsEVMCompatible() bool {
 	return t.inEVMType != nil && t.outEVMType != nil
 }
@@ -170,6 +222,38 @@ func (t *MessageType[In, Out]) Decode(bytes []byte) (any, error) {
 	return codec.Decode[In](bytes)
 }
 
+// Validate checks a decoded message against the validation rules of the message's "In" type. A *validation.Error
+// listing the invalid fields is returned if the message is invalid.
+func (t *MessageType[In, Out]) Validate(msg any) error {
+	return t.rules.Validate(msg)
+}
+
+// DecodeVersion decodes the payload of the given version of the message. Payloads of older versions are upgraded to
+// the message's "In" type.
+func (t *MessageType[In, Out]) DecodeVersion(version uint32, bytes []byte) (any, error) {
+	if version == t.version {
+		return t.Decode(bytes)
+	}
+	upgrade, ok := t.upgrades[version]
+	if !ok {
+		return nil, eris.Wrapf(types.ErrMessageVersionNotFound, "message %q has no version %d", t.FullName(), version)
+	}
+	return upgrade.decode(bytes)
+}
+
+// PayloadType returns the type of the payload of the given version of the message. The payload of the latest version
+// is the message's "In" type.
+func (t *MessageType[In, Out]) PayloadType(version uint32) (reflect.Type, error) {
+	if version == t.version {
+		return reflect.TypeOf(new(In)).Elem(), nil
+	}
+	upgrade, ok := t.upgrades[version]
+	if !ok {
+		return nil, eris.Wrapf(types.ErrMessageVersionNotFound, "message %q has no version %d", t.FullName(), version)
+	}
+	return upgrade.payloadType, nil
+}
+
 // ABIEncode encodes the input to the message's matching evm type. If the input is not either of the message's
 // evm types, an error is returned.
 func (t *MessageType[In, Out]) ABIEncode(v any) ([]byte, error) {
@@ -220,6 +304,16 @@ func (t *MessageType[In, Out]) GetInFieldInformation() map[string]any {
 	return types.GetFieldInformation(reflect.TypeOf(new(In)).Elem())
 }
 
+// GetInSchema returns the JSON schema of the message's "In" type.
+func (t *MessageType[In, Out]) GetInSchema() ([]byte, error) {
+	return types.GetJSONSchema(reflect.TypeOf(new(In)).Elem())
+}
+
+// GetOutSchema returns the JSON schema of the message's "Out" type.
+func (t *MessageType[In, Out]) GetOutSchema() ([]byte, error) {
+	return types.GetJSONSchema(reflect.TypeOf(new(Out)).Elem())
+}
+
 // -------------------------- Options --------------------------
 
 func WithMsgEVMSupport[In, Out any]() MessageOption[In, Out] {
@@ -249,6 +343,51 @@ func WithCustomMessageGroup[In, Out any](group string) MessageOption[In, Out] {
 	}
 }
 
+// WithMessageVersion registers an older version of the message, whose payloads have the type Old, so that clients
+// built against it keep working once the "In" type changes. Payloads of the older version are submitted to
+// /tx/{group}/{name}/v{version}, and upgraded to the "In" type before they reach systems. The "In" type is the
+// version after the newest older version, so a message that shipped without versions is version 1, and its first
+// change is registered as:
+//
+//	cardinal.NewMessageType[MoveV2, MoveResult]("move",
+//		cardinal.WithMessageVersion[MoveV2, MoveResult](1, func(old MoveV1) (MoveV2, error) {
+//			return MoveV2{Direction: old.Direction, Steps: 1}, nil
+//		}),
+//	)
+//
+// Every version before the "In" type must be registered, starting at version 1.
+func WithMessageVersion[In, Out, Old any](version uint32, upgrade func(Old) (In, error)) MessageOption[In, Out] {
+	return func(mt *MessageType[In, Out]) {
+		if !isStruct[Old]() {
+			panic(fmt.Sprintf("Invalid MessageType: %q: the payload of version %d must be a struct", mt.FullName(), version))
+		}
+		if version == 0 {
+			panic(fmt.Sprintf("Invalid MessageType: %q: versions start at 1", mt.FullName()))
+		}
+		if _, ok := mt.upgrades[version]; ok {
+			panic(fmt.Sprintf("Invalid MessageType: %q: version %d is registered twice", mt.FullName(), version))
+		}
+		if mt.upgrades == nil {
+			mt.upgrades = make(map[uint32]messageUpgrade[In])
+		}
+		mt.upgrades[version] = messageUpgrade[In]{
+			payloadType: reflect.TypeOf(new(Old)).Elem(),
+			decode: func(bz []byte) (In, error) {
+				var in In
+				old, err := codec.Decode[Old](bz)
+				if err != nil {
+					return in, err
+				}
+				in, err = upgrade(old)
+				if err != nil {
+					return in, eris.Wrapf(err, "failed to upgrade version %d of message %q", version, mt.FullName())
+				}
+				return in, nil
+			},
+		}
+	}
+}
+
 // -------------------------- Helpers --------------------------
 
 func isStruct[T any]() bool {
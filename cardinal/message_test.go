@@ -2,10 +2,12 @@ package cardinal
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"pkg.world.dev/world-engine/assert"
 	"pkg.world.dev/world-engine/cardinal/txpool"
+	"pkg.world.dev/world-engine/cardinal/types"
 	"pkg.world.dev/world-engine/sign"
 )
 
@@ -192,3 +194,93 @@ func TestIsValidMessageText(t *testing.T) {
 		})
 	}
 }
+
+func TestMessageVersionsAreUpgradedToTheLatestVersion(t *testing.T) {
+	type MoveV1 struct{ Direction string }
+	type MoveV2 struct {
+		Direction string
+		Steps     int
+	}
+	type MoveV3 struct {
+		Directions []string
+	}
+	msg := NewMessageType[MoveV3, EmptyMsgResult]("move",
+		WithMessageVersion[MoveV3, EmptyMsgResult](1, func(old MoveV1) (MoveV3, error) {
+			return MoveV3{Directions: []string{old.Direction}}, nil
+		}),
+		WithMessageVersion[MoveV3, EmptyMsgResult](2, func(old MoveV2) (MoveV3, error) {
+			if old.Steps < 0 {
+				return MoveV3{}, errors.New("steps can't be negative")
+			}
+			directions := make([]string, old.Steps)
+			for i := range directions {
+				directions[i] = old.Direction
+			}
+			return MoveV3{Directions: directions}, nil
+		}),
+	)
+	assert.Equal(t, msg.Version(), uint32(3))
+	assert.DeepEqual(t, msg.Versions(), []uint32{1, 2, 3})
+
+	v1, err := msg.DecodeVersion(1, []byte(`{"Direction":"up"}`))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, v1, MoveV3{Directions: []string{"up"}})
+
+	v2, err := msg.DecodeVersion(2, []byte(`{"Direction":"up","Steps":2}`))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, v2, MoveV3{Directions: []string{"up", "up"}})
+
+	v3, err := msg.DecodeVersion(3, []byte(`{"Directions":["left"]}`))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, v3, MoveV3{Directions: []string{"left"}})
+
+	_, err = msg.DecodeVersion(2, []byte(`{"Direction":"up","Steps":-1}`))
+	assert.ErrorContains(t, err, "steps can't be negative")
+	_, err = msg.DecodeVersion(4, []byte(`{}`))
+	assert.ErrorIs(t, err, types.ErrMessageVersionNotFound)
+}
+
+func TestUnversionedMessagesAreVersion1(t *testing.T) {
+	type Foo struct{}
+	msg := NewMessageType[Foo, EmptyMsgResult]("foo")
+	assert.Equal(t, msg.Version(), uint32(1))
+	assert.DeepEqual(t, msg.Versions(), []uint32{1})
+}
+
+func TestInvalidMessageVersionsPanic(t *testing.T) {
+	type Foo struct{}
+	type Old struct{}
+	upgrade := func(Old) (Foo, error) { return Foo{}, nil }
+	assert.Panics(t, func() {
+		NewMessageType[Foo, EmptyMsgResult]("foo", WithMessageVersion[Foo, EmptyMsgResult](0, upgrade))
+	})
+	assert.Panics(t, func() {
+		NewMessageType[Foo, EmptyMsgResult]("foo",
+			WithMessageVersion[Foo, EmptyMsgResult](1, upgrade),
+			WithMessageVersion[Foo, EmptyMsgResult](1, upgrade),
+		)
+	})
+	assert.Panics(t, func() {
+		NewMessageType[Foo, EmptyMsgResult]("foo",
+			WithMessageVersion[Foo, EmptyMsgResult](1, func([]int) (Foo, error) { return Foo{}, nil }))
+	})
+	// versions must not have gaps
+	assert.Panics(t, func() {
+		NewMessageType[Foo, EmptyMsgResult]("foo",
+			WithMessageVersion[Foo, EmptyMsgResult](1, upgrade),
+			WithMessageVersion[Foo, EmptyMsgResult](3, upgrade),
+		)
+	})
+	assert.Panics(t, func() {
+		NewMessageType[Foo, EmptyMsgResult]("foo", WithMessageVersion[Foo, EmptyMsgResult](2, upgrade))
+	})
+}
+
+func TestMessagesWithTagsOfOtherLibrariesCanBeRegistered(t *testing.T) {
+	type Signup struct {
+		Email string `json:"email" validate:"required,email"`
+	}
+	assert.NotPanics(t, func() {
+		NewMessageType[Signup, EmptyMsgResult]("signup")
+	})
+}
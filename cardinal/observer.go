@@ -0,0 +1,102 @@
+package cardinal
+
+import (
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/gamestate"
+	"pkg.world.dev/world-engine/cardinal/types"
+	"pkg.world.dev/world-engine/cardinal/worldstage"
+)
+
+// observer is a registered component lifecycle observer, see OnAdd, OnSet and OnRemove.
+type observer struct {
+	kind      gamestate.ComponentChangeKind
+	component types.ComponentMetadata
+	fn        func(wCtx WorldContext, change gamestate.ComponentChange) error
+}
+
+// OnAdd registers an observer that is called with the value of a component of type T when it is added to an entity,
+// including when an entity is created with it.
+//
+// Observers run in an observer phase at the end of each tick, after all systems have run, with the net changes made
+// by the systems during the tick. E.g. an observer isn't called for a component that was added and removed again in
+// the same tick. The changes are observed in order of entity ID and then component ID, and the observers of a change
+// are called in the order they were registered. Changes made by observers are saved, but aren't observed.
+func OnAdd[T types.Component](w *World, fn func(wCtx WorldContext, id types.EntityID, value T) error) error {
+	return registerObserver[T](w, gamestate.ComponentAdded, func(wCtx WorldContext, c gamestate.ComponentChange) error {
+		return fn(wCtx, c.EntityID, componentValue[T](c.New))
+	})
+}
+
+// OnSet registers an observer that is called with the old and new value of a component of type T when its value is
+// changed. Setting a component several times in a tick is a single change from the value at the start of the tick to
+// the value at the end of it. See OnAdd for when observers run.
+func OnSet[T types.Component](
+	w *World, fn func(wCtx WorldContext, id types.EntityID, oldValue, newValue T) error,
+) error {
+	return registerObserver[T](w, gamestate.ComponentSet, func(wCtx WorldContext, c gamestate.ComponentChange) error {
+		return fn(wCtx, c.EntityID, componentValue[T](c.Old), componentValue[T](c.New))
+	})
+}
+
+// OnRemove registers an observer that is called with the last value of a component of type T when it is removed from
+// an entity, including when the entity is removed. See OnAdd for when observers run.
+func OnRemove[T types.Component](w *World, fn func(wCtx WorldContext, id types.EntityID, oldValue T) error) error {
+	return registerObserver[T](w, gamestate.ComponentRemoved, func(wCtx WorldContext, c gamestate.ComponentChange) error {
+		return fn(wCtx, c.EntityID, componentValue[T](c.Old))
+	})
+}
+
+func registerObserver[T types.Component](
+	w *World, kind gamestate.ComponentChangeKind, fn func(WorldContext, gamestate.ComponentChange) error,
+) error {
+	if w.worldStage.Current() != worldstage.Init {
+		return eris.Errorf(
+			"world state is %s, expected %s to register observers",
+			w.worldStage.Current(),
+			worldstage.Init,
+		)
+	}
+	var t T
+	comp, err := w.GetComponentByName(t.Name())
+	if err != nil {
+		return eris.Wrapf(err, "component %q must be registered before its observers", t.Name())
+	}
+	w.observers = append(w.observers, observer{kind: kind, component: comp, fn: fn})
+	return nil
+}
+
+// runObservers calls the registered observers with the component changes made by the systems in the current tick.
+func (w *World) runObservers(wCtx WorldContext) error {
+	if len(w.observers) == 0 {
+		return nil
+	}
+	changes, err := w.entityStore.PendingComponentChanges()
+	if err != nil {
+		return err
+	}
+	for _, change := range changes {
+		for _, o := range w.observers {
+			if o.kind != change.Kind || o.component.ID() != change.Component.ID() {
+				continue
+			}
+			if err = o.fn(wCtx, change); err != nil {
+				return eris.Wrapf(err, "observer of component %q on entity %d generated an error",
+					o.component.Name(), change.EntityID)
+			}
+		}
+	}
+	return nil
+}
+
+// componentValue returns a component value of type T, which is stored either as a T or as a *T.
+func componentValue[T types.Component](value any) T {
+	if t, ok := value.(T); ok {
+		return t
+	}
+	if t, ok := value.(*T); ok && t != nil {
+		return *t
+	}
+	var zero T
+	return zero
+}
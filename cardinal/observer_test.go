@@ -0,0 +1,81 @@
+package cardinal_test
+
+import (
+	"fmt"
+	"testing"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+func TestObserversSeeTheComponentChangesOfATick(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+
+	var events []string
+	assert.IsError(t, cardinal.OnAdd[EnergyComponent](world,
+		func(cardinal.WorldContext, types.EntityID, EnergyComponent) error { return nil }))
+	assert.NilError(t, cardinal.RegisterComponent[EnergyComponent](world))
+	assert.NilError(t, cardinal.RegisterComponent[OwnableComponent](world))
+	assert.NilError(t, cardinal.OnAdd[EnergyComponent](world,
+		func(_ cardinal.WorldContext, id types.EntityID, value EnergyComponent) error {
+			events = append(events, fmt.Sprintf("add %d %d", id, value.Amt))
+			return nil
+		}))
+	assert.NilError(t, cardinal.OnSet[EnergyComponent](world,
+		func(_ cardinal.WorldContext, id types.EntityID, oldValue, newValue EnergyComponent) error {
+			events = append(events, fmt.Sprintf("set %d %d->%d", id, oldValue.Amt, newValue.Amt))
+			return nil
+		}))
+	assert.NilError(t, cardinal.OnRemove[EnergyComponent](world,
+		func(_ cardinal.WorldContext, id types.EntityID, oldValue EnergyComponent) error {
+			events = append(events, fmt.Sprintf("remove %d %d", id, oldValue.Amt))
+			return nil
+		}))
+
+	var step func(wCtx cardinal.WorldContext) error
+	assert.NilError(t, cardinal.RegisterSystems(world, func(wCtx cardinal.WorldContext) error {
+		if step == nil {
+			return nil
+		}
+		return step(wCtx)
+	}))
+	tf.StartWorld()
+
+	var ids []types.EntityID
+	step = func(wCtx cardinal.WorldContext) error {
+		var err error
+		ids, err = cardinal.CreateMany(wCtx, 2, EnergyComponent{Amt: 10})
+		return err
+	}
+	tf.DoTick()
+	assert.DeepEqual(t, events, []string{
+		fmt.Sprintf("add %d 10", ids[0]),
+		fmt.Sprintf("add %d 10", ids[1]),
+	})
+
+	events = nil
+	step = func(wCtx cardinal.WorldContext) error {
+		for _, amt := range []int64{20, 30} {
+			if err := cardinal.SetComponent(wCtx, ids[0], &EnergyComponent{Amt: amt}); err != nil {
+				return err
+			}
+		}
+		// Components other than the observed ones are not observed
+		if err := cardinal.AddComponentTo[OwnableComponent](wCtx, ids[0]); err != nil {
+			return err
+		}
+		return cardinal.Remove(wCtx, ids[1])
+	}
+	tf.DoTick()
+	assert.DeepEqual(t, events, []string{
+		fmt.Sprintf("set %d 10->30", ids[0]),
+		fmt.Sprintf("remove %d 10", ids[1]),
+	})
+
+	events = nil
+	step = nil
+	tf.DoTick()
+	assert.Equal(t, len(events), 0)
+}
@@ -16,13 +16,23 @@ import (
 
 // WorldOption represents an option that can be used to augment how the cardinal.World will be run.
 type WorldOption struct {
+	config         *WorldConfig
 	serverOption   server.Option
 	routerOption   router.Option
+	loggerOption   func(*zerolog.Logger)
 	cardinalOption Option
 }
 
 type Option func(*World)
 
+// WithConfig creates the world with the given config. Environment variables and the TOML config file are not read
+// when this option is used, so several worlds with different configs can be created in the same process.
+func WithConfig(cfg WorldConfig) WorldOption {
+	return WorldOption{
+		config: &cfg,
+	}
+}
+
 // WithPort sets the port that the HTTP server will run on.
 func WithPort(port string) WorldOption {
 	return WorldOption{
@@ -30,6 +40,23 @@ func WithPort(port string) WorldOption {
 	}
 }
 
+// WithGRPCPort serves Cardinal's gRPC API on the given port, next to the HTTP server. The gRPC API is disabled unless
+// this option is used.
+func WithGRPCPort(port string) WorldOption {
+	return WorldOption{
+		serverOption: server.WithGRPCPort(port),
+	}
+}
+
+// WithAdminKey enables the admin API at /admin/operations, which creates and removes entities and edits their
+// components during the next tick. Requests must carry the given key in the X-Admin-Key header. The admin API is
+// disabled by default and should only be used for development and playtests.
+func WithAdminKey(key string) WorldOption {
+	return WorldOption{
+		serverOption: server.WithAdminKey(key),
+	}
+}
+
 // WithReceiptHistorySize specifies how many ticks worth of transaction receipts should be kept in memory. The default
 // is 10. A smaller number uses less memory, but limits the amount of historical receipts available.
 func WithReceiptHistorySize(size int) WorldOption {
@@ -71,6 +98,46 @@ func WithHashCacheSize(sizeKB uint) WorldOption {
 	}
 }
 
+// WithNonceReplayProtection requires every transaction to carry a nonce (see sign.NewTransactionWithNonce) that its
+// signer hasn't used before, instead of rejecting transactions by their timestamp and the hashes of recent
+// transactions. Nonces are used up in redis, so a transaction can't be replayed after a restart, and the clocks of
+// senders don't need to be synchronized. The WithMessageExpiration and WithHashCacheSize options are ignored.
+// This setting is ignored if the DisableSignatureVerification option is used
+func WithNonceReplayProtection() WorldOption {
+	return WorldOption{
+		serverOption: server.WithNonceReplayProtection(),
+	}
+}
+
+// WithStrictCanonicalBodies rejects transactions whose bodies aren't canonical json (RFC 8785). By default, bodies in
+// any encoding are accepted, and signatures are verified over the hash of their canonical encoding.
+// This setting is ignored if the DisableSignatureVerification option is used
+func WithStrictCanonicalBodies() WorldOption {
+	return WorldOption{
+		serverOption: server.WithStrictCanonicalBodies(),
+	}
+}
+
+// WithLegacyBodyHashing also accepts transactions from clients that predate canonical body hashing, which sign the hash
+// of the body as they encoded it.
+// This setting is ignored if the DisableSignatureVerification option is used
+func WithLegacyBodyHashing() WorldOption {
+	return WorldOption{
+		serverOption: server.WithLegacyBodyHashing(),
+	}
+}
+
+// WithPrivateReceipts makes the receipts of transactions signed by a persona visible over the /events websocket only to
+// connections that have authenticated as that persona. They are also left out of the receipt lists of
+// /query/receipts/list and the gRPC API. By default, all receipts are broadcast to every connection.
+func WithPrivateReceipts() WorldOption {
+	return WorldOption{
+		cardinalOption: func(world *World) {
+			world.privateReceipts = true
+		},
+	}
+}
+
 // WithTickChannel sets the channel that will be used to decide when world.doTick is executed. If unset, a loop interval
 // of 1 second will be set. To set some other time, use: WithTickChannel(time.Tick(<some-duration>)). Tests can pass
 // in a channel controlled by the test for fine-grained control over when ticks are executed.
@@ -92,6 +159,19 @@ func WithTickDoneChannel(ch chan<- uint64) WorldOption {
 	}
 }
 
+// WithEntityIDRecycling reuses the indices of removed entities for new entities, with a new generation, to keep the
+// entity indices compact. IDs of removed entities stay stale instead of referring to the new entities, see
+// ErrStaleEntityID. The indices of entities removed in a tick are reused from the next tick on.
+// Recycling changes the IDs of new entities, so it must not be enabled or disabled for an existing world, which would
+// assign different IDs when recovering from its transactions.
+func WithEntityIDRecycling() WorldOption {
+	return WorldOption{
+		cardinalOption: func(world *World) {
+			world.entityIDRecycling = true
+		},
+	}
+}
+
 func WithStoreManager(s gamestate.Manager) WorldOption {
 	return WorldOption{
 		cardinalOption: func(world *World) {
@@ -126,10 +206,11 @@ func WithMockJobQueue() WorldOption {
 	}
 }
 
+// WithCustomLogger replaces the logger of the world. The global logger is left untouched.
 func WithCustomLogger(logger zerolog.Logger) WorldOption {
 	return WorldOption{
-		cardinalOption: func(_ *World) {
-			log.Logger = logger
+		loggerOption: func(l *zerolog.Logger) {
+			*l = logger
 		},
 	}
 }
@@ -142,10 +223,11 @@ func WithCustomRouter(rtr router.Router) WorldOption {
 	}
 }
 
+// WithPrettyLog makes the world log in a human-friendly format instead of JSON.
 func WithPrettyLog() WorldOption {
 	return WorldOption{
-		cardinalOption: func(_ *World) {
-			log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+		loggerOption: func(l *zerolog.Logger) {
+			*l = l.Output(zerolog.ConsoleWriter{Out: os.Stderr})
 		},
 	}
 }
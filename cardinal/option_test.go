@@ -1,13 +1,16 @@
 package cardinal_test
 
 import (
+	"bytes"
 	"io"
 	"os"
+	"strings"
+	"sync"
 	"testing"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/goccy/go-json"
 	"github.com/rs/zerolog"
-	"github.com/rs/zerolog/log"
 
 	"pkg.world.dev/world-engine/assert"
 	"pkg.world.dev/world-engine/cardinal"
@@ -23,20 +26,62 @@ func TestOptionFunctionSignatures(_ *testing.T) {
 }
 
 func TestWithPrettyLog_LogIsNotJSONFormatted(t *testing.T) {
-	cardinal.NewTestFixture(t, nil, cardinal.WithPrettyLog())
+	// The world is created without a test fixture, which enables pretty logging through CARDINAL_LOG_PRETTY, so that
+	// only the option makes the log pretty.
+	mr := miniredis.RunT(t)
+	t.Setenv("REDIS_ADDRESS", mr.Addr())
+	t.Setenv("CARDINAL_LOG_PRETTY", "false")
 
-	// Create a pipe to capture the output
+	// Create a pipe to capture the output. WithPrettyLog writes to os.Stderr, so it is replaced before the world is
+	// created.
+	stderr := os.Stderr
 	r, w, _ := os.Pipe()
 	os.Stderr = w
+	t.Cleanup(func() { os.Stderr = stderr })
 
-	// Test log. WithPrettyLog overwrites the global logger, so this shouild be pretty printed too.
-	log.Info().Msg("test")
+	// Creating the world logs that it is running in development mode, before anything else is done with its logger.
+	_, err := cardinal.NewWorld(cardinal.WithPrettyLog())
+	assert.NilError(t, err)
 	_ = w.Close()
 
-	// Read the output and check that it is not JSON formatted (which is what a non-pretty logger would do)
+	// Read the output and check that the line is not JSON formatted (which is what a non-pretty logger would do)
 	output, err := io.ReadAll(r)
 	assert.NilError(t, err)
-	assert.Assert(t, !isValidJSON(output))
+	var line string
+	for _, l := range strings.Split(string(output), "\n") {
+		if strings.Contains(l, "development mode") {
+			line = l
+		}
+	}
+	assert.Assert(t, line != "")
+	assert.Assert(t, !isValidJSON([]byte(line)))
+}
+
+func TestWithCustomLogger_WorldStagesAreLoggedToTheCustomLogger(t *testing.T) {
+	buf := &lockedBuffer{}
+	tf := cardinal.NewTestFixture(t, nil, cardinal.WithCustomLogger(zerolog.New(buf).Level(zerolog.InfoLevel)))
+	tf.StartWorld()
+	tf.DoTick()
+
+	assert.Assert(t, strings.Contains(buf.String(), "New world stage"))
+}
+
+// lockedBuffer is a bytes.Buffer that can be written to by the game loop while the test reads it.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
 }
 
 // isValidJSON tests if a string is valid JSON.
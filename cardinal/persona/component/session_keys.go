@@ -0,0 +1,53 @@
+package component
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SessionKeysComponent holds the session keys of a persona. A session key can sign the persona's transactions for a
+// limited set of messages until it expires or has been used up. It is only added to the personas that have session
+// keys.
+type SessionKeysComponent struct {
+	Sessions []SessionKey
+}
+
+type SessionKey struct {
+	Address string
+	// Scope holds the message groups (e.g. "game") and the full names of messages (e.g. "game.move") the session key
+	// can sign.
+	Scope []string
+	// AuthorizedTick is the tick the session key was authorized in.
+	AuthorizedTick uint64
+	// ExpiresAtTick is the first tick the session key can no longer sign transactions for. Zero means no tick expiry.
+	ExpiresAtTick uint64
+	// ExpiresAt is the unix timestamp in milliseconds the session key expires at. Zero means no timestamp expiry.
+	ExpiresAt uint64
+	// MaxUses is the number of transactions the session key can sign. Zero means no limit.
+	MaxUses uint64
+}
+
+func (SessionKeysComponent) Name() string {
+	return "SessionKeysComponent"
+}
+
+// ID identifies the session key of the given persona. Authorizing the same address again starts a new session.
+func (s SessionKey) ID(personaTag string) string {
+	return fmt.Sprintf("%s:%s:%d", strings.ToLower(personaTag), strings.ToLower(s.Address), s.AuthorizedTick)
+}
+
+// InScope reports whether the session key can sign the message with the given full name.
+func (s SessionKey) InScope(fullName string) bool {
+	group, _, _ := strings.Cut(fullName, ".")
+	for _, scope := range s.Scope {
+		if scope == fullName || scope == group {
+			return true
+		}
+	}
+	return false
+}
+
+// IsExpired reports whether the session key has expired at the given tick and unix timestamp in milliseconds.
+func (s SessionKey) IsExpired(tick uint64, timestamp uint64) bool {
+	return (s.ExpiresAtTick != 0 && tick >= s.ExpiresAtTick) || (s.ExpiresAt != 0 && timestamp >= s.ExpiresAt)
+}
@@ -0,0 +1,17 @@
+package component
+
+// SignerKeysComponent holds the labeled signer keys of a persona, which can sign transactions for the persona in
+// addition to the SignerAddress of its SignerComponent. It is kept apart from SignerComponent so that the stored schema
+// of SignerComponent doesn't change, and is only added to the personas that have signer keys.
+type SignerKeysComponent struct {
+	Keys []SignerKey
+}
+
+type SignerKey struct {
+	Label   string
+	Address string
+}
+
+func (SignerKeysComponent) Name() string {
+	return "SignerKeysComponent"
+}
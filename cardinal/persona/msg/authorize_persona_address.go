@@ -1,5 +1,7 @@
 package msg
 
+var AuthorizePersonaAddressMessageName = "authorize-persona-address"
+
 type AuthorizePersonaAddress struct {
 	Address string `json:"address"`
 }
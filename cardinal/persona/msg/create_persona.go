@@ -2,7 +2,8 @@ package msg
 
 var CreatePersonaMessageName = "create-persona"
 
-// CreatePersona allows for the associating of a persona tag with a signer address.
+// CreatePersona allows for the associating of a persona tag with a signer address. The signer address is either an
+// Ethereum address, or the public key of another signature scheme prefixed with the scheme, like "ed25519:0x...".
 type CreatePersona struct {
 	PersonaTag    string `json:"personaTag"`
 	SignerAddress string `json:"signerAddress"`
@@ -0,0 +1,22 @@
+package msg
+
+// MessageGroup is the group of the persona messages, other than AuthorizePersonaAddress.
+const MessageGroup = "persona"
+
+// RequiresPersonaSigner reports whether the message with the given full name changes who can act as a persona. These
+// messages must be signed by the persona's signer address, rather than by any of its signer keys or session keys, so
+// that a stolen key can't be used to take over the persona.
+func RequiresPersonaSigner(fullName string) bool {
+	switch fullName {
+	// AuthorizePersonaAddress predates the persona group, and is registered in the default group
+	case "game." + AuthorizePersonaAddressMessageName,
+		MessageGroup + "." + RevokePersonaAddressMessageName,
+		MessageGroup + "." + RotatePersonaSignerMessageName,
+		MessageGroup + "." + AddPersonaSignerKeyMessageName,
+		MessageGroup + "." + RemovePersonaSignerKeyMessageName,
+		MessageGroup + "." + AuthorizeSessionKeyMessageName,
+		MessageGroup + "." + RevokeSessionKeyMessageName:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package msg
+
+var (
+	AddPersonaSignerKeyMessageName    = "add-persona-signer-key"
+	RemovePersonaSignerKeyMessageName = "remove-persona-signer-key"
+)
+
+// AddPersonaSignerKey registers another signer key for the persona the transaction is signed for. Transactions signed
+// by any of a persona's signer keys are accepted for the persona, which lets a player use a separate key on each of
+// their devices. The label tells the keys apart. The signer may be of any registered signature scheme.
+type AddPersonaSignerKey struct {
+	Label         string `json:"label" cardinal:"min=1,max=32"`
+	SignerAddress string `json:"signerAddress"`
+}
+
+// Validate requires the signer address to be a valid signer.
+func (a AddPersonaSignerKey) Validate() error {
+	return validateSigner("signerAddress", a.SignerAddress)
+}
+
+type AddPersonaSignerKeyResult struct {
+	Success bool `json:"success"`
+}
+
+// RemovePersonaSignerKey removes the signer key with the given label from the persona the transaction is signed for.
+type RemovePersonaSignerKey struct {
+	Label string `json:"label" cardinal:"min=1,max=32"`
+}
+
+type RemovePersonaSignerKeyResult struct {
+	Success bool `json:"success"`
+}
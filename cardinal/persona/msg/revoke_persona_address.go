@@ -0,0 +1,13 @@
+package msg
+
+var RevokePersonaAddressMessageName = "revoke-persona-address"
+
+// RevokePersonaAddress removes an address that was authorized with AuthorizePersonaAddress from the persona the
+// transaction is signed for.
+type RevokePersonaAddress struct {
+	Address string `json:"address"`
+}
+
+type RevokePersonaAddressResult struct {
+	Success bool `json:"success"`
+}
@@ -0,0 +1,20 @@
+package msg
+
+var RotatePersonaSignerMessageName = "rotate-persona-signer"
+
+// RotatePersonaSigner replaces the signer address of the persona the transaction is signed for. It must be signed by
+// the persona's current signer address. If the new signer address is one of the persona's signer keys, the key is
+// promoted to be the signer address. The new signer may be of any registered signature scheme. The persona's session
+// keys are revoked.
+type RotatePersonaSigner struct {
+	SignerAddress string `json:"signerAddress"`
+}
+
+// Validate requires the signer address to be a valid signer.
+func (r RotatePersonaSigner) Validate() error {
+	return validateSigner("signerAddress", r.SignerAddress)
+}
+
+type RotatePersonaSignerResult struct {
+	Success bool `json:"success"`
+}
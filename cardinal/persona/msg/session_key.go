@@ -0,0 +1,43 @@
+package msg
+
+import "errors"
+
+var (
+	AuthorizeSessionKeyMessageName = "authorize-session-key"
+	RevokeSessionKeyMessageName    = "revoke-session-key"
+)
+
+// AuthorizeSessionKey authorizes an ephemeral key to sign transactions for the persona the transaction is signed for,
+// so that players don't have to sign every move with their main wallet. The session key can only sign the messages in
+// its scope, which holds message groups (e.g. "game") and full message names (e.g. "game.move"). It expires at the
+// given tick, at the given unix timestamp in milliseconds, or once it has signed MaxUses transactions. Authorizing an
+// address that already is a session key replaces its session. The session key may be of any registered signature
+// scheme.
+type AuthorizeSessionKey struct {
+	SessionAddress string   `json:"sessionAddress"`
+	Scope          []string `json:"scope" cardinal:"min=1,max=32"`
+	ExpiresAtTick  uint64   `json:"expiresAtTick,omitempty"`
+	ExpiresAt      uint64   `json:"expiresAt,omitempty"`
+	MaxUses        uint64   `json:"maxUses,omitempty"`
+}
+
+// Validate requires session keys to expire, and the session address to be a valid signer.
+func (a AuthorizeSessionKey) Validate() error {
+	if a.ExpiresAtTick == 0 && a.ExpiresAt == 0 {
+		return errors.New("either expiresAtTick or expiresAt is required")
+	}
+	return validateSigner("sessionAddress", a.SessionAddress)
+}
+
+type AuthorizeSessionKeyResult struct {
+	Success bool `json:"success"`
+}
+
+// RevokeSessionKey revokes the session of the given session key of the persona the transaction is signed for.
+type RevokeSessionKey struct {
+	SessionAddress string `json:"sessionAddress"`
+}
+
+type RevokeSessionKeyResult struct {
+	Success bool `json:"success"`
+}
@@ -0,0 +1,15 @@
+package msg
+
+import (
+	"pkg.world.dev/world-engine/cardinal/validation"
+	"pkg.world.dev/world-engine/sign"
+)
+
+// validateSigner checks that the field holds a signer of a registered signature scheme: either an Ethereum address, or
+// a public key prefixed with its scheme, like "ed25519:0x...". See sign.FormatSigner.
+func validateSigner(field, signer string) error {
+	if _, err := sign.NormalizeSigner(signer); err != nil {
+		return &validation.Error{Fields: []validation.FieldError{{Field: field, Rule: "signer", Message: err.Error()}}}
+	}
+	return nil
+}
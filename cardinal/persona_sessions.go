@@ -0,0 +1,69 @@
+package cardinal
+
+import (
+	"time"
+
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/persona"
+)
+
+// PersonaSessionsQueryRequest is the desired request body for the query-persona-sessions endpoint.
+type PersonaSessionsQueryRequest struct {
+	PersonaTag string `json:"personaTag"`
+}
+
+// PersonaSessionsQueryResponse is used as the response body for the query-persona-sessions endpoint. It holds the
+// session keys of the persona that haven't expired or been used up.
+type PersonaSessionsQueryResponse struct {
+	Sessions []PersonaSession `json:"sessions"`
+}
+
+type PersonaSession struct {
+	SessionAddress string   `json:"sessionAddress"`
+	Scope          []string `json:"scope"`
+	AuthorizedTick uint64   `json:"authorizedTick"`
+	ExpiresAtTick  uint64   `json:"expiresAtTick,omitempty"`
+	ExpiresAt      uint64   `json:"expiresAt,omitempty"`
+	MaxUses        uint64   `json:"maxUses,omitempty"`
+	Uses           uint64   `json:"uses"`
+}
+
+func PersonaSessionsQuery(wCtx WorldContext, req *PersonaSessionsQueryRequest) (*PersonaSessionsQueryResponse, error) {
+	res := &PersonaSessionsQueryResponse{Sessions: []PersonaSession{}}
+	id, _, err := findPersona(wCtx, req.PersonaTag)
+	if eris.Is(err, persona.ErrPersonaTagHasNoSigner) {
+		return res, nil
+	} else if err != nil {
+		return nil, err
+	}
+	sessions, err := getSessionKeys(wCtx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Session keys are checked against the wall clock when transactions are received, rather than the tick timestamp
+	now := uint64(time.Now().UnixMilli())
+	for _, session := range sessions {
+		if session.IsExpired(wCtx.CurrentTick(), now) {
+			continue
+		}
+		uses, err := wCtx.getSessionUses(session.ID(req.PersonaTag))
+		if err != nil {
+			return nil, err
+		}
+		if session.MaxUses != 0 && uses >= session.MaxUses {
+			continue
+		}
+		res.Sessions = append(res.Sessions, PersonaSession{
+			SessionAddress: session.Address,
+			Scope:          session.Scope,
+			AuthorizedTick: session.AuthorizedTick,
+			ExpiresAtTick:  session.ExpiresAtTick,
+			ExpiresAt:      session.ExpiresAt,
+			MaxUses:        session.MaxUses,
+			Uses:           uses,
+		})
+	}
+	return res, nil
+}
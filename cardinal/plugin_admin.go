@@ -0,0 +1,168 @@
+package cardinal
+
+import (
+	"slices"
+
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/admin"
+	"pkg.world.dev/world-engine/cardinal/gamestate"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+var _ Plugin = (*adminPlugin)(nil)
+
+// adminPlugin registers the message and system behind the dev-mode admin API. The message is always registered,
+// so that the IDs of the messages recorded to the base shard don't depend on whether the admin API is enabled. It is
+// only accepted from the admin API, which is disabled unless an admin key is configured.
+type adminPlugin struct {
+}
+
+func newAdminPlugin() *adminPlugin {
+	return &adminPlugin{}
+}
+
+func (p *adminPlugin) Register(world *World) error {
+	err := RegisterMessage[admin.Operations, admin.OperationsResult](
+		world,
+		admin.OperationsMessageName,
+		WithCustomMessageGroup[admin.Operations, admin.OperationsResult](admin.MessageGroup),
+	)
+	if err != nil {
+		return err
+	}
+	return RegisterSystems(world, adminOperationsSystem)
+}
+
+// adminOperationsSystem applies the operations queued through the admin API. The operations go through the ECB like
+// the state changes of any other system, so recovering from the base shard replays them deterministically.
+func adminOperationsSystem(wCtx WorldContext) error {
+	return EachMessage[admin.Operations, admin.OperationsResult](
+		wCtx,
+		func(txData TxData[admin.Operations]) (admin.OperationsResult, error) {
+			result := admin.OperationsResult{Created: make([]types.EntityID, 0)}
+			if txData.Tx.PersonaTag != admin.PersonaTag {
+				return result, eris.New("admin operations can only be submitted through the admin API")
+			}
+			for i, op := range txData.Msg.Operations {
+				id, created, err := applyAdminOperation(wCtx, op)
+				if err != nil {
+					return result, eris.Wrapf(err, "admin operation %d (%s) failed", i, op.Type)
+				}
+				if created {
+					result.Created = append(result.Created, id)
+				}
+			}
+			return result, nil
+		},
+	)
+}
+
+// applyAdminOperation applies a single admin operation. It returns the ID of the entity created by the operation, if
+// any.
+func applyAdminOperation(wCtx WorldContext, op admin.Operation) (types.EntityID, bool, error) {
+	switch op.Type {
+	case admin.OpCreate:
+		if len(op.Components) == 0 {
+			return 0, false, eris.Wrap(ErrEntityMustHaveAtLeastOneComponent, "")
+		}
+		// Components are created in name order, so that replaying the operation is deterministic.
+		names := make([]string, 0, len(op.Components))
+		for name := range op.Components {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		comps := make([]types.ComponentMetadata, 0, len(names))
+		values := make([]any, 0, len(names))
+		for _, name := range names {
+			c, value, err := decodeAdminComponent(wCtx, name, op.Components[name])
+			if err != nil {
+				return 0, false, err
+			}
+			comps = append(comps, c)
+			values = append(values, value)
+		}
+		var id types.EntityID
+		err := applyToStore(wCtx, func(store gamestate.Manager) error {
+			var err error
+			if id, err = store.CreateEntity(comps...); err != nil {
+				return err
+			}
+			for i, c := range comps {
+				if err = store.SetComponentForEntity(c, id, values[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		return id, err == nil, err
+
+	case admin.OpRemove:
+		return 0, false, Remove(wCtx, op.Entity)
+
+	case admin.OpSetComponent:
+		c, value, err := decodeAdminComponent(wCtx, op.Component, op.Value)
+		if err != nil {
+			return 0, false, err
+		}
+		return 0, false, applyToStore(wCtx, func(store gamestate.Manager) error {
+			return store.SetComponentForEntity(c, op.Entity, value)
+		})
+
+	case admin.OpAddComponent:
+		c, err := wCtx.getComponentByName(op.Component)
+		if err != nil {
+			return 0, false, err
+		}
+		var value any
+		if len(op.Value) > 0 {
+			if _, value, err = decodeAdminComponent(wCtx, op.Component, op.Value); err != nil {
+				return 0, false, err
+			}
+		}
+		return 0, false, applyToStore(wCtx, func(store gamestate.Manager) error {
+			if err := store.AddComponentToEntity(c, op.Entity); err != nil {
+				return err
+			}
+			if value == nil {
+				return nil
+			}
+			return store.SetComponentForEntity(c, op.Entity, value)
+		})
+
+	case admin.OpRemoveComponent:
+		c, err := wCtx.getComponentByName(op.Component)
+		if err != nil {
+			return 0, false, err
+		}
+		return 0, false, applyToStore(wCtx, func(store gamestate.Manager) error {
+			return store.RemoveComponentFromEntity(c, op.Entity)
+		})
+
+	default:
+		return 0, false, eris.Errorf("unknown admin operation type %q", op.Type)
+	}
+}
+
+// decodeAdminComponent looks up the component with the given name and decodes the given value into it.
+func decodeAdminComponent(wCtx WorldContext, name string, bz []byte) (types.ComponentMetadata, any, error) {
+	c, err := wCtx.getComponentByName(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(bz) == 0 {
+		bz = []byte("{}")
+	}
+	value, err := c.Decode(bz)
+	if err != nil {
+		return nil, nil, eris.Wrapf(err, "invalid value for component %q", name)
+	}
+	return c, value, nil
+}
+
+// applyToStore applies changes directly to the store. Like the public entity functions, it panics on errors that
+// aren't caused by the operation itself.
+func applyToStore(wCtx WorldContext, fn func(store gamestate.Manager) error) (err error) {
+	defer func() { panicOnFatalError(wCtx, err) }()
+	return fn(wCtx.storeManager())
+}
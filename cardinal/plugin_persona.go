@@ -12,24 +12,24 @@ import (
 	"pkg.world.dev/world-engine/cardinal/persona/component"
 	"pkg.world.dev/world-engine/cardinal/persona/msg"
 	"pkg.world.dev/world-engine/cardinal/types"
+	"pkg.world.dev/world-engine/sign"
 )
 
-var (
-	_ Plugin = (*personaPlugin)(nil)
-
-	// TODO: Replace these global variables when indexing/fast-searching is supported.
-	// See https://linear.app/arguslabs/issue/WORLD-1057/spec-out-component-indexing
-	// These global variables are used to quickly identify already-created persona tags. The map should exactly match
-	// the persona tag information stored in the ECS layer. When Cardinal restarts, this map needs to be rebuilt.
-	//
-	// globalPersonaTagToAddressIndex keeps track of the mapping of persona-tags->signer-address so it doesn't need to
-	// be recomputed each tick.
-	globalPersonaTagToAddressIndex personaIndex
-	// tickOfPersonaTagToAddressIndex is the tick that the globalPersonaTagToAddressIndex was built on. In normal usage,
-	// wCtx.CurrentTick should always be greater than this number, but during tests the currentTick will be reset.
-	// Tracking this number at the global is easier than updating each test to reset these global value.
-	tickOfPersonaTagToAddressIndex uint64
-)
+var _ Plugin = (*personaPlugin)(nil)
+
+// personaTagIndex keeps track of the mapping of persona-tags->signer-address so it doesn't need to be recomputed each
+// tick. It is used to quickly identify already-created persona tags. The map should exactly match the persona tag
+// information stored in the ECS layer. When Cardinal restarts, this map needs to be rebuilt. Each World owns its own
+// index, so several worlds can run in the same process.
+//
+// TODO: Replace this index when indexing/fast-searching is supported.
+// See https://linear.app/arguslabs/issue/WORLD-1057/spec-out-component-indexing
+type personaTagIndex struct {
+	entries personaIndex
+	// tick is the tick that the entries were built on. In normal usage, wCtx.CurrentTick should always be greater than
+	// this number, but during tests the currentTick will be reset.
+	tick uint64
+}
 
 type personaIndex = map[string]personaIndexEntry
 
@@ -96,11 +96,11 @@ func (p *personaPlugin) RegisterMessages(world *World) error {
 		RegisterMessage[msg.CreatePersona, msg.CreatePersonaResult](
 			world,
 			msg.CreatePersonaMessageName,
-			WithCustomMessageGroup[msg.CreatePersona, msg.CreatePersonaResult]("persona"),
+			WithCustomMessageGroup[msg.CreatePersona, msg.CreatePersonaResult](msg.MessageGroup),
 			WithMsgEVMSupport[msg.CreatePersona, msg.CreatePersonaResult]()),
 		RegisterMessage[msg.AuthorizePersonaAddress, msg.AuthorizePersonaAddressResult](
 			world,
-			"authorize-persona-address",
+			msg.AuthorizePersonaAddressMessageName,
 		))
 }
 
@@ -112,7 +112,8 @@ func (p *personaPlugin) RegisterMessages(world *World) error {
 // users who want to interact with the game via smart contract can link their EVM address to their persona tag, enabling
 // them to mutate their owned state from the context of the EVM.
 func authorizePersonaAddressSystem(wCtx WorldContext) error {
-	if err := buildGlobalPersonaIndex(wCtx); err != nil {
+	index, err := buildPersonaIndex(wCtx)
+	if err != nil {
 		return err
 	}
 	return EachMessage[msg.AuthorizePersonaAddress, msg.AuthorizePersonaAddressResult](
@@ -125,7 +126,7 @@ func authorizePersonaAddressSystem(wCtx WorldContext) error {
 
 			// Check if the Persona Tag exists
 			lowerPersona := strings.ToLower(tx.PersonaTag)
-			data, ok := globalPersonaTagToAddressIndex[lowerPersona]
+			data, ok := index[lowerPersona]
 			if !ok {
 				return result, eris.Errorf("persona %s does not exist", tx.PersonaTag)
 			}
@@ -165,7 +166,8 @@ func authorizePersonaAddressSystem(wCtx WorldContext) error {
 // createPersonaSystem is a system that will associate persona tags with signature addresses. Each persona tag
 // may have at most 1 signer, so additional attempts to register a signer with a persona tag will be ignored.
 func createPersonaSystem(wCtx WorldContext) error {
-	if err := buildGlobalPersonaIndex(wCtx); err != nil {
+	index, err := buildPersonaIndex(wCtx)
+	if err != nil {
 		return err
 	}
 	return EachMessage[msg.CreatePersona, msg.CreatePersonaResult](
@@ -185,11 +187,20 @@ func createPersonaSystem(wCtx WorldContext) error {
 
 			// Temporarily convert tag to lowercase to check against mapping of lowercase tags
 			lowerPersona := strings.ToLower(txMsg.PersonaTag)
-			if _, ok := globalPersonaTagToAddressIndex[lowerPersona]; ok {
+			if _, ok := index[lowerPersona]; ok {
 				// This PersonaTag has already been registered. Don't do anything
 				err = eris.Errorf("persona tag %s has already been registered", txMsg.PersonaTag)
 				return result, err
 			}
+
+			// Signers of other signature schemes than secp256k1 are checked and normalized. Ethereum addresses are
+			// stored as they are given, as they always have been.
+			signerAddress := txMsg.SignerAddress
+			if scheme, _ := sign.ParseSigner(signerAddress); scheme != sign.SchemeSecp256k1 {
+				if signerAddress, err = sign.NormalizeSigner(signerAddress); err != nil {
+					return result, eris.Wrap(err, "invalid signer address")
+				}
+			}
 			id, err := Create(wCtx, component.SignerComponent{})
 			if err != nil {
 				return result, eris.Wrap(err, "")
@@ -197,14 +208,14 @@ func createPersonaSystem(wCtx WorldContext) error {
 			if err = SetComponent[component.SignerComponent](
 				wCtx, id, &component.SignerComponent{
 					PersonaTag:          txMsg.PersonaTag,
-					SignerAddress:       txMsg.SignerAddress,
+					SignerAddress:       signerAddress,
 					AuthorizedAddresses: make([]string, 0),
 				},
 			); err != nil {
 				return result, eris.Wrap(err, "")
 			}
-			globalPersonaTagToAddressIndex[lowerPersona] = personaIndexEntry{
-				SignerAddress: txMsg.SignerAddress,
+			index[lowerPersona] = personaIndexEntry{
+				SignerAddress: signerAddress,
 				EntityID:      id,
 			}
 			result.Success = true
@@ -217,15 +228,18 @@ func createPersonaSystem(wCtx WorldContext) error {
 // Persona Index
 // -----------------------------------------------------------------------------
 
-func buildGlobalPersonaIndex(wCtx WorldContext) error {
+// buildPersonaIndex returns the persona tag index of the world, building it from the ECS layer first if it hasn't been
+// built yet.
+func buildPersonaIndex(wCtx WorldContext) (personaIndex, error) {
+	index := wCtx.personaTagIndex()
 	// Rebuild the index if we haven't built it yet OR if we're in test and the CurrentTick has been reset.
-	if globalPersonaTagToAddressIndex != nil && tickOfPersonaTagToAddressIndex < wCtx.CurrentTick() {
-		return nil
+	if index.entries != nil && index.tick < wCtx.CurrentTick() {
+		return index.entries, nil
 	}
-	tickOfPersonaTagToAddressIndex = wCtx.CurrentTick()
-	globalPersonaTagToAddressIndex = map[string]personaIndexEntry{}
+	index.tick = wCtx.CurrentTick()
+	index.entries = map[string]personaIndexEntry{}
 	var errs []error
-	s := NewSearch().Entity(filter.Exact(filter.Component[component.SignerComponent]()))
+	s := NewSearch().Entity(filter.Contains(filter.Component[component.SignerComponent]()))
 	err := s.Each(wCtx,
 		func(id types.EntityID) bool {
 			sc, err := GetComponent[component.SignerComponent](wCtx, id)
@@ -234,7 +248,7 @@ func buildGlobalPersonaIndex(wCtx WorldContext) error {
 				return true
 			}
 			lowerPersona := strings.ToLower(sc.PersonaTag)
-			globalPersonaTagToAddressIndex[lowerPersona] = personaIndexEntry{
+			index.entries[lowerPersona] = personaIndexEntry{
 				SignerAddress: sc.SignerAddress,
 				EntityID:      id,
 			}
@@ -242,10 +256,10 @@ func buildGlobalPersonaIndex(wCtx WorldContext) error {
 		},
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if len(errs) != 0 {
-		return errors.Join(errs...)
+		return nil, errors.Join(errs...)
 	}
-	return nil
+	return index.entries, nil
 }
@@ -0,0 +1,396 @@
+package cardinal
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/persona/component"
+	"pkg.world.dev/world-engine/cardinal/persona/msg"
+	"pkg.world.dev/world-engine/cardinal/types"
+	"pkg.world.dev/world-engine/sign"
+)
+
+// maxPersonaSessionKeys is the maximum number of session keys a persona can have. Expired session keys don't count.
+const maxPersonaSessionKeys = 16
+
+// maxPersonaSignerKeys is the maximum number of signer keys a persona can have, in addition to its signer address.
+// Signatures are checked against each of the keys, so the number is kept small.
+const maxPersonaSignerKeys = 8
+
+var _ Plugin = (*personaKeysPlugin)(nil)
+
+// personaKeysPlugin registers the messages that manage the signer of a persona after it has been created: rotating
+// the signer address, registering labeled signer keys, authorizing session keys, and revoking authorized addresses,
+// as well as the query listing the persona's sessions. The server only accepts
+// these messages when they are signed by the persona's signer address (see msg.RequiresPersonaSigner).
+type personaKeysPlugin struct {
+}
+
+func newPersonaKeysPlugin() *personaKeysPlugin {
+	return &personaKeysPlugin{}
+}
+
+// Register registers the components, messages and systems of the plugin.
+func (p *personaKeysPlugin) Register(world *World) error {
+	err := errors.Join(
+		RegisterComponent[component.SignerKeysComponent](world),
+		RegisterComponent[component.SessionKeysComponent](world),
+	)
+	if err != nil {
+		return err
+	}
+	err = errors.Join(
+		RegisterMessage[msg.RotatePersonaSigner, msg.RotatePersonaSignerResult](
+			world,
+			msg.RotatePersonaSignerMessageName,
+			WithCustomMessageGroup[msg.RotatePersonaSigner, msg.RotatePersonaSignerResult](msg.MessageGroup)),
+		RegisterMessage[msg.AddPersonaSignerKey, msg.AddPersonaSignerKeyResult](
+			world,
+			msg.AddPersonaSignerKeyMessageName,
+			WithCustomMessageGroup[msg.AddPersonaSignerKey, msg.AddPersonaSignerKeyResult](msg.MessageGroup)),
+		RegisterMessage[msg.RemovePersonaSignerKey, msg.RemovePersonaSignerKeyResult](
+			world,
+			msg.RemovePersonaSignerKeyMessageName,
+			WithCustomMessageGroup[msg.RemovePersonaSignerKey, msg.RemovePersonaSignerKeyResult](msg.MessageGroup)),
+		RegisterMessage[msg.RevokePersonaAddress, msg.RevokePersonaAddressResult](
+			world,
+			msg.RevokePersonaAddressMessageName,
+			WithCustomMessageGroup[msg.RevokePersonaAddress, msg.RevokePersonaAddressResult](msg.MessageGroup)),
+		RegisterMessage[msg.AuthorizeSessionKey, msg.AuthorizeSessionKeyResult](
+			world,
+			msg.AuthorizeSessionKeyMessageName,
+			WithCustomMessageGroup[msg.AuthorizeSessionKey, msg.AuthorizeSessionKeyResult](msg.MessageGroup)),
+		RegisterMessage[msg.RevokeSessionKey, msg.RevokeSessionKeyResult](
+			world,
+			msg.RevokeSessionKeyMessageName,
+			WithCustomMessageGroup[msg.RevokeSessionKey, msg.RevokeSessionKeyResult](msg.MessageGroup)),
+	)
+	if err != nil {
+		return err
+	}
+	err = RegisterQuery[PersonaSessionsQueryRequest, PersonaSessionsQueryResponse](world, "sessions",
+		PersonaSessionsQuery,
+		WithCustomQueryGroup[PersonaSessionsQueryRequest, PersonaSessionsQueryResponse]("persona"))
+	if err != nil {
+		return err
+	}
+	return RegisterSystems(world, rotatePersonaSignerSystem, personaSignerKeysSystem, revokePersonaAddressSystem,
+		personaSessionKeysSystem)
+}
+
+// rotatePersonaSignerSystem replaces the signer address of personas. The new signer address is used to validate
+// the persona's transactions from the tick after the one the rotation was applied in. The session keys of the persona
+// are revoked, and must be authorized again by the new signer.
+func rotatePersonaSignerSystem(wCtx WorldContext) error {
+	index, err := buildPersonaIndex(wCtx)
+	if err != nil {
+		return err
+	}
+	return EachMessage[msg.RotatePersonaSigner, msg.RotatePersonaSignerResult](
+		wCtx,
+		func(txData TxData[msg.RotatePersonaSigner]) (result msg.RotatePersonaSignerResult, err error) {
+			lowerPersona := strings.ToLower(txData.Tx.PersonaTag)
+			data, ok := index[lowerPersona]
+			if !ok {
+				return result, eris.Errorf("persona %s does not exist", txData.Tx.PersonaTag)
+			}
+			newSigner, err := sign.NormalizeSigner(txData.Msg.SignerAddress)
+			if err != nil {
+				return result, eris.Wrap(err, "invalid signer address")
+			}
+
+			err = UpdateComponent[component.SignerComponent](
+				wCtx, data.EntityID, func(s *component.SignerComponent) *component.SignerComponent {
+					s.SignerAddress = newSigner
+					return s
+				},
+			)
+			if err != nil {
+				return result, eris.Wrap(err, "unable to update signer component with signer address")
+			}
+			// A signer key that is promoted to be the signer address is no longer a signer key
+			if err = updateSignerKeys(wCtx, data.EntityID, func(keys []component.SignerKey) ([]component.SignerKey, error) {
+				return slices.DeleteFunc(keys, func(key component.SignerKey) bool {
+					return sameAddress(key.Address, newSigner)
+				}), nil
+			}); err != nil {
+				return result, err
+			}
+			// Session keys were authorized by an earlier signer, which may have been rotated because it was compromised
+			if err = updateSessionKeys(wCtx, data.EntityID, func([]component.SessionKey) (
+				[]component.SessionKey, error,
+			) {
+				return nil, nil
+			}); err != nil {
+				return result, err
+			}
+
+			data.SignerAddress = newSigner
+			index[lowerPersona] = data
+			result.Success = true
+			return result, nil
+		},
+	)
+}
+
+// personaSignerKeysSystem adds and removes the labeled signer keys of personas.
+func personaSignerKeysSystem(wCtx WorldContext) error {
+	index, err := buildPersonaIndex(wCtx)
+	if err != nil {
+		return err
+	}
+	addErr := EachMessage[msg.AddPersonaSignerKey, msg.AddPersonaSignerKeyResult](
+		wCtx,
+		func(txData TxData[msg.AddPersonaSignerKey]) (result msg.AddPersonaSignerKeyResult, err error) {
+			data, ok := index[strings.ToLower(txData.Tx.PersonaTag)]
+			if !ok {
+				return result, eris.Errorf("persona %s does not exist", txData.Tx.PersonaTag)
+			}
+			address, err := sign.NormalizeSigner(txData.Msg.SignerAddress)
+			if err != nil {
+				return result, eris.Wrap(err, "invalid signer address")
+			}
+			newKey := component.SignerKey{
+				Label:   txData.Msg.Label,
+				Address: address,
+			}
+			if sameAddress(newKey.Address, data.SignerAddress) {
+				return result, eris.Errorf("%s is already the signer address of persona %s",
+					newKey.Address, txData.Tx.PersonaTag)
+			}
+			err = updateSignerKeys(wCtx, data.EntityID, func(keys []component.SignerKey) ([]component.SignerKey, error) {
+				for _, key := range keys {
+					if key.Label == newKey.Label {
+						return nil, eris.Errorf("persona %s already has a signer key labeled %q",
+							txData.Tx.PersonaTag, newKey.Label)
+					}
+					if sameAddress(key.Address, newKey.Address) {
+						return nil, eris.Errorf("%s is already the signer key %q of persona %s",
+							newKey.Address, key.Label, txData.Tx.PersonaTag)
+					}
+				}
+				if len(keys) >= maxPersonaSignerKeys {
+					return nil, eris.Errorf("persona %s already has %d signer keys", txData.Tx.PersonaTag, len(keys))
+				}
+				return append(keys, newKey), nil
+			})
+			if err != nil {
+				return result, err
+			}
+			result.Success = true
+			return result, nil
+		},
+	)
+	removeErr := EachMessage[msg.RemovePersonaSignerKey, msg.RemovePersonaSignerKeyResult](
+		wCtx,
+		func(txData TxData[msg.RemovePersonaSignerKey]) (result msg.RemovePersonaSignerKeyResult, err error) {
+			data, ok := index[strings.ToLower(txData.Tx.PersonaTag)]
+			if !ok {
+				return result, eris.Errorf("persona %s does not exist", txData.Tx.PersonaTag)
+			}
+			err = updateSignerKeys(wCtx, data.EntityID, func(keys []component.SignerKey) ([]component.SignerKey, error) {
+				i := slices.IndexFunc(keys, func(key component.SignerKey) bool {
+					return key.Label == txData.Msg.Label
+				})
+				if i == -1 {
+					return nil, eris.Errorf("persona %s has no signer key labeled %q",
+						txData.Tx.PersonaTag, txData.Msg.Label)
+				}
+				return slices.Delete(keys, i, i+1), nil
+			})
+			if err != nil {
+				return result, err
+			}
+			result.Success = true
+			return result, nil
+		},
+	)
+	return errors.Join(addErr, removeErr)
+}
+
+// revokePersonaAddressSystem removes addresses that were authorized with authorizePersonaAddressSystem.
+func revokePersonaAddressSystem(wCtx WorldContext) error {
+	index, err := buildPersonaIndex(wCtx)
+	if err != nil {
+		return err
+	}
+	return EachMessage[msg.RevokePersonaAddress, msg.RevokePersonaAddressResult](
+		wCtx,
+		func(txData TxData[msg.RevokePersonaAddress]) (result msg.RevokePersonaAddressResult, err error) {
+			data, ok := index[strings.ToLower(txData.Tx.PersonaTag)]
+			if !ok {
+				return result, eris.Errorf("persona %s does not exist", txData.Tx.PersonaTag)
+			}
+			// Addresses are authorized in the same normalized form
+			address := strings.ReplaceAll(strings.ToLower(txData.Msg.Address), " ", "")
+
+			sc, err := GetComponent[component.SignerComponent](wCtx, data.EntityID)
+			if err != nil {
+				return result, eris.Wrap(err, "unable to get signer component")
+			}
+			if !slices.Contains(sc.AuthorizedAddresses, address) {
+				return result, eris.Errorf("address %s is not authorized for persona %s", address, txData.Tx.PersonaTag)
+			}
+			sc.AuthorizedAddresses = slices.DeleteFunc(sc.AuthorizedAddresses, func(addr string) bool {
+				return addr == address
+			})
+			if err = SetComponent[component.SignerComponent](wCtx, data.EntityID, sc); err != nil {
+				return result, eris.Wrap(err, "unable to update signer component")
+			}
+			result.Success = true
+			return result, nil
+		},
+	)
+}
+
+// personaSessionKeysSystem authorizes and revokes the session keys of personas. Expired session keys are dropped
+// whenever the session keys of a persona change.
+func personaSessionKeysSystem(wCtx WorldContext) error {
+	index, err := buildPersonaIndex(wCtx)
+	if err != nil {
+		return err
+	}
+	authorizeErr := EachMessage[msg.AuthorizeSessionKey, msg.AuthorizeSessionKeyResult](
+		wCtx,
+		func(txData TxData[msg.AuthorizeSessionKey]) (result msg.AuthorizeSessionKeyResult, err error) {
+			data, ok := index[strings.ToLower(txData.Tx.PersonaTag)]
+			if !ok {
+				return result, eris.Errorf("persona %s does not exist", txData.Tx.PersonaTag)
+			}
+			address, err := sign.NormalizeSigner(txData.Msg.SessionAddress)
+			if err != nil {
+				return result, eris.Wrap(err, "invalid session address")
+			}
+			session := component.SessionKey{
+				Address:        address,
+				Scope:          txData.Msg.Scope,
+				AuthorizedTick: wCtx.CurrentTick(),
+				ExpiresAtTick:  txData.Msg.ExpiresAtTick,
+				ExpiresAt:      txData.Msg.ExpiresAt,
+				MaxUses:        txData.Msg.MaxUses,
+			}
+			if session.IsExpired(wCtx.CurrentTick(), wCtx.Timestamp()) {
+				return result, eris.Errorf("session key %s of persona %s has already expired",
+					session.Address, txData.Tx.PersonaTag)
+			}
+			err = updateSessionKeys(wCtx, data.EntityID, func(sessions []component.SessionKey) (
+				[]component.SessionKey, error,
+			) {
+				sessions = slices.DeleteFunc(sessions, func(s component.SessionKey) bool {
+					return sameAddress(s.Address, session.Address)
+				})
+				if len(sessions) >= maxPersonaSessionKeys {
+					return nil, eris.Errorf("persona %s already has %d session keys",
+						txData.Tx.PersonaTag, len(sessions))
+				}
+				return append(sessions, session), nil
+			})
+			if err != nil {
+				return result, err
+			}
+			result.Success = true
+			return result, nil
+		},
+	)
+	revokeErr := EachMessage[msg.RevokeSessionKey, msg.RevokeSessionKeyResult](
+		wCtx,
+		func(txData TxData[msg.RevokeSessionKey]) (result msg.RevokeSessionKeyResult, err error) {
+			data, ok := index[strings.ToLower(txData.Tx.PersonaTag)]
+			if !ok {
+				return result, eris.Errorf("persona %s does not exist", txData.Tx.PersonaTag)
+			}
+			err = updateSessionKeys(wCtx, data.EntityID, func(sessions []component.SessionKey) (
+				[]component.SessionKey, error,
+			) {
+				i := slices.IndexFunc(sessions, func(s component.SessionKey) bool {
+					return sameAddress(s.Address, txData.Msg.SessionAddress)
+				})
+				if i == -1 {
+					return nil, eris.Errorf("%s is not a session key of persona %s",
+						txData.Msg.SessionAddress, txData.Tx.PersonaTag)
+				}
+				return slices.Delete(sessions, i, i+1), nil
+			})
+			if err != nil {
+				return result, err
+			}
+			result.Success = true
+			return result, nil
+		},
+	)
+	return errors.Join(authorizeErr, revokeErr)
+}
+
+// updateSessionKeys replaces the session keys of the persona entity with the unexpired keys returned by fn, like
+// updateSignerKeys.
+func updateSessionKeys(
+	wCtx WorldContext, id types.EntityID, fn func([]component.SessionKey) ([]component.SessionKey, error),
+) error {
+	current, err := GetComponent[component.SessionKeysComponent](wCtx, id)
+	hasSessions := err == nil
+	if err != nil && !eris.Is(err, ErrComponentNotOnEntity) {
+		return eris.Wrap(err, "unable to get session keys component")
+	}
+	var sessions []component.SessionKey
+	if hasSessions {
+		sessions = slices.DeleteFunc(slices.Clone(current.Sessions), func(s component.SessionKey) bool {
+			return s.IsExpired(wCtx.CurrentTick(), wCtx.Timestamp())
+		})
+	}
+	sessions, err = fn(sessions)
+	if err != nil {
+		return err
+	}
+	return eris.Wrap(
+		setPersonaComponent(wCtx, id, hasSessions, len(sessions) > 0, &component.SessionKeysComponent{Sessions: sessions}),
+		"unable to update session keys component",
+	)
+}
+
+// updateSignerKeys replaces the signer keys of the persona entity with the keys returned by fn. The SignerKeysComponent
+// is only kept on personas that have signer keys. Nothing is changed if fn returns an error.
+func updateSignerKeys(
+	wCtx WorldContext, id types.EntityID, fn func([]component.SignerKey) ([]component.SignerKey, error),
+) error {
+	current, err := GetComponent[component.SignerKeysComponent](wCtx, id)
+	hasKeys := err == nil
+	if err != nil && !eris.Is(err, ErrComponentNotOnEntity) {
+		return eris.Wrap(err, "unable to get signer keys component")
+	}
+	var keys []component.SignerKey
+	if hasKeys {
+		keys = slices.Clone(current.Keys)
+	}
+	keys, err = fn(keys)
+	if err != nil {
+		return err
+	}
+	return eris.Wrap(
+		setPersonaComponent(wCtx, id, hasKeys, len(keys) > 0, &component.SignerKeysComponent{Keys: keys}),
+		"unable to update signer keys component",
+	)
+}
+
+// setPersonaComponent sets the component of the persona entity, which is only kept on the entity while want is true.
+// has tells whether the entity currently has the component.
+func setPersonaComponent[T types.Component](wCtx WorldContext, id types.EntityID, has, want bool, comp *T) error {
+	switch {
+	case !want && has:
+		return RemoveComponentFrom[T](wCtx, id)
+	case !want:
+		return nil
+	case !has:
+		if err := AddComponentTo[T](wCtx, id); err != nil {
+			return err
+		}
+	}
+	return SetComponent[T](wCtx, id, comp)
+}
+
+// sameAddress reports whether the two signers are the same, regardless of how they are encoded.
+func sameAddress(a, b string) bool {
+	return sign.SameSigner(a, b)
+}
@@ -1,7 +1,9 @@
 package cardinal
 
 import (
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/rotisserie/eris"
 
@@ -9,6 +11,8 @@ import (
 	"pkg.world.dev/world-engine/cardinal/types"
 )
 
+var ErrTaskNotFound = errors.New("task not found")
+
 // -----------------------------------------------------------------------------
 // Public API accessible via cardinal.<function_name>
 // -----------------------------------------------------------------------------
@@ -65,74 +69,271 @@ func (t taskMetadata) isTriggered(tick uint64, timestamp uint64) bool {
 	return timestamp >= *t.TriggerAtTimestamp
 }
 
+// taskRecurrence is an internal component of recurring tasks. A recurring task is triggered again one interval after
+// each run, until it has run MaxRuns times. A MaxRuns of 0 lets the task recur until it is cancelled. It is kept apart
+// from taskMetadata, so that the schema of taskMetadata stays the same for the tasks of existing worlds.
+type taskRecurrence struct {
+	IntervalTicks  uint64
+	IntervalMillis uint64
+	MaxRuns        uint64
+	Runs           uint64
+}
+
+func (taskRecurrence) Name() string {
+	return "taskRecurrence"
+}
+
+// registerTaskRecurrence registers the taskRecurrence component. It is called by StartGame after the game registered
+// its components, rather than by TaskPlugin, so that its ID doesn't shift the IDs of the game's components.
+func registerTaskRecurrence(w *World) error {
+	return RegisterComponent[taskRecurrence](w)
+}
+
+// reschedule records a run of a recurring task, and moves its trigger condition one interval past the given tick or
+// timestamp. It returns false if the task has run MaxRuns times, and should not be triggered again.
+func (r *taskRecurrence) reschedule(metadata *taskMetadata, tick uint64, timestamp uint64) bool {
+	r.Runs++
+	if r.MaxRuns != 0 && r.Runs >= r.MaxRuns {
+		return false
+	}
+	if metadata.TriggerAtTick != nil {
+		next := tick + r.IntervalTicks
+		metadata.TriggerAtTick = &next
+	} else {
+		next := timestamp + r.IntervalMillis
+		metadata.TriggerAtTimestamp = &next
+	}
+	return true
+}
+
 // -----------------------------------------------------------------------------
 // Systems
 // -----------------------------------------------------------------------------
 
-// taskSystem is a system that is registered when RegisterTask is called. It is responsible for iterating through all
-// entities with the Task type T and executing the task if the trigger condition is met.
+// taskSystem is a system that is registered when RegisterTask is called. It is responsible for executing the tasks of
+// type T whose trigger condition is met. Only the tasks that are due are read, see taskSchedules. One-shot tasks are
+// removed after they have been executed, and recurring tasks are rescheduled until they have run their maximum number
+// of times.
 func taskSystem[T Task](wCtx WorldContext) error {
-	var internalErr error
-	err := NewSearch().Entity(filter.Contains(filter.Component[T](), filter.Component[taskMetadata]())).Each(wCtx,
-		func(id types.EntityID) bool {
-			taskMetadata, err := GetComponent[taskMetadata](wCtx, id)
-			if err != nil {
-				internalErr = err
-				return false
+	ids, err := dueTasks[T](wCtx)
+	if err != nil {
+		return eris.Wrap(err, "encountered an error while loading the due tasks")
+	}
+
+	var t T
+	for _, id := range ids {
+		taskMetadata, err := GetComponent[taskMetadata](wCtx, id)
+		if eris.Is(err, ErrEntityDoesNotExist) || eris.Is(err, ErrComponentNotOnEntity) {
+			// The task has been cancelled
+			if err = unqueueTask(wCtx, t, id); err != nil {
+				return eris.Wrap(err, "encountered an error while dropping a cancelled task")
 			}
+			continue
+		} else if err != nil {
+			return eris.Wrap(err, "encountered an error while executing a task")
+		}
+		if !taskMetadata.isTriggered(wCtx.CurrentTick(), wCtx.Timestamp()) {
+			// The trigger condition has been rounded by the schedule, and the task stays scheduled until it is met
+			continue
+		}
 
-			if taskMetadata.isTriggered(wCtx.CurrentTick(), wCtx.Timestamp()) {
-				task, err := GetComponent[T](wCtx, id)
-				if err != nil {
-					internalErr = err
-					return false
-				}
-
-				if err = (*task).Handle(wCtx); err != nil {
-					internalErr = err
-					return false
-				}
-
-				if err = Remove(wCtx, id); err != nil {
-					internalErr = err
-					return false
-				}
+		task, err := GetComponent[T](wCtx, id)
+		if eris.Is(err, ErrComponentNotOnEntity) {
+			// The entity is no longer a task of this type
+			if err = unqueueTask(wCtx, t, id); err != nil {
+				return eris.Wrap(err, "encountered an error while dropping a cancelled task")
 			}
-			return true
-		},
-	)
-	if internalErr != nil {
-		return eris.Wrap(internalErr, "encountered an error while executing a task")
-	}
-	if err != nil {
-		return eris.Wrap(err, "encountered an error while iterating over tasks")
+			continue
+		} else if err != nil {
+			return eris.Wrap(err, "encountered an error while executing a task")
+		}
+
+		if err = unqueueTask(wCtx, t, id); err != nil {
+			return eris.Wrap(err, "encountered an error while executing a task")
+		}
+		if err = (*task).Handle(wCtx); err != nil {
+			return eris.Wrap(err, "encountered an error while executing a task")
+		}
+
+		if err = finishTask(wCtx, *task, id, taskMetadata); err != nil && !eris.Is(err, ErrEntityDoesNotExist) {
+			return eris.Wrap(err, "encountered an error while executing a task")
+		}
 	}
 
 	return nil
 }
 
+// finishTask removes a task that has been executed, or reschedules it if it is a recurring task that hasn't run its
+// maximum number of times yet.
+func finishTask(wCtx WorldContext, task Task, id types.EntityID, metadata *taskMetadata) error {
+	recurrence, err := GetComponent[taskRecurrence](wCtx, id)
+	if eris.Is(err, ErrComponentNotOnEntity) {
+		return Remove(wCtx, id)
+	} else if err != nil {
+		return err
+	}
+	if !recurrence.reschedule(metadata, wCtx.CurrentTick(), wCtx.Timestamp()) {
+		return Remove(wCtx, id)
+	}
+	if err = SetComponent(wCtx, id, recurrence); err != nil {
+		return err
+	}
+	if err = SetComponent(wCtx, id, metadata); err != nil {
+		return err
+	}
+	return queueTask(wCtx, task, id, *metadata)
+}
+
 // -----------------------------------------------------------------------------
 // Internal functions used by WorldContext to schedule tasks
 // -----------------------------------------------------------------------------
 
-// createTickTask creates a task entity that will be executed by taskSystem at the designated tick.
-func createTickTask(wCtx WorldContext, tick uint64, task Task) error {
-	_, err := Create(wCtx, task, taskMetadata{TriggerAtTick: &tick})
+// createTickTask creates a task entity that will be executed by taskSystem at the designated tick. A non-zero interval
+// makes the task recur every interval ticks after that.
+func createTickTask(
+	wCtx WorldContext, tick uint64, interval uint64, maxRuns uint64, task Task,
+) (types.EntityID, error) {
+	metadata := taskMetadata{TriggerAtTick: &tick}
+	id, err := createTask(wCtx, task, metadata, taskRecurrence{IntervalTicks: interval, MaxRuns: maxRuns})
 	if err != nil {
-		return eris.Wrap(err, "failed to create tick task entity")
+		return 0, eris.Wrap(err, "failed to create tick task entity")
 	}
-	return nil
+	return id, nil
+}
+
+// createTimestampTask creates a task entity that will be executed by taskSystem at the designated timestamp. A
+// non-zero interval makes the task recur every interval milliseconds after that.
+func createTimestampTask(
+	wCtx WorldContext, timestamp uint64, interval uint64, maxRuns uint64, task Task,
+) (types.EntityID, error) {
+	metadata := taskMetadata{TriggerAtTimestamp: &timestamp}
+	id, err := createTask(wCtx, task, metadata, taskRecurrence{IntervalMillis: interval, MaxRuns: maxRuns})
+	if err != nil {
+		return 0, eris.Wrap(err, "failed to create timestamp task entity")
+	}
+	return id, nil
 }
 
-// createTimestampTask creates a task entity that will be executed by taskSystem at the designated timestamp.
-func createTimestampTask(wCtx WorldContext, timestamp uint64, task Task) error {
-	_, err := Create(wCtx, task, taskMetadata{TriggerAtTimestamp: &timestamp})
+// createTask creates a task entity, which only has a taskRecurrence component if the task has an interval.
+func createTask(
+	wCtx WorldContext, task Task, metadata taskMetadata, recurrence taskRecurrence,
+) (id types.EntityID, err error) {
+	if recurrence.IntervalTicks > 0 || recurrence.IntervalMillis > 0 {
+		id, err = Create(wCtx, task, metadata, recurrence)
+	} else {
+		id, err = Create(wCtx, task, metadata)
+	}
 	if err != nil {
-		return eris.Wrap(err, "failed to create timestamp task entity")
+		return 0, err
+	}
+	if err = queueTask(wCtx, task, id, metadata); err != nil {
+		return 0, eris.Wrap(err, "failed to schedule task")
+	}
+	return id, nil
+}
+
+// cancelTask removes a pending task entity, so that it is not executed (again).
+func cancelTask(wCtx WorldContext, id types.EntityID) error {
+	if _, err := GetComponent[taskMetadata](wCtx, id); err != nil {
+		if eris.Is(err, ErrEntityDoesNotExist) || eris.Is(err, ErrComponentNotOnEntity) {
+			return eris.Wrapf(ErrTaskNotFound, "entity %d", id)
+		}
+		return err
+	}
+	if err := Remove(wCtx, id); err != nil {
+		return eris.Wrap(err, "failed to remove task entity")
 	}
 	return nil
 }
 
+// -----------------------------------------------------------------------------
+// Queries
+// -----------------------------------------------------------------------------
+
+// PendingTasksQueryRequest is the desired request body for the query-pending-tasks endpoint. If Type is set, only the
+// tasks of the task type with that name are listed.
+type PendingTasksQueryRequest struct {
+	Type string `json:"type,omitempty"`
+}
+
+// PendingTasksQueryResponse is used as the response body for the query-pending-tasks endpoint. It holds the tasks
+// that have been scheduled and not yet finished or been cancelled, in the order they were scheduled in.
+type PendingTasksQueryResponse struct {
+	Tasks []PendingTask `json:"tasks"`
+}
+
+// PendingTask describes a scheduled task. The timestamps and intervals are in milliseconds.
+type PendingTask struct {
+	ID                 types.EntityID `json:"id"`
+	Type               string         `json:"type"`
+	TriggerAtTick      *uint64        `json:"triggerAtTick,omitempty"`
+	TriggerAtTimestamp *uint64        `json:"triggerAtTimestamp,omitempty"`
+	IntervalTicks      uint64         `json:"intervalTicks,omitempty"`
+	IntervalMillis     uint64         `json:"intervalMillis,omitempty"`
+	MaxRuns            uint64         `json:"maxRuns,omitempty"`
+	Runs               uint64         `json:"runs"`
+}
+
+func PendingTasksQuery(wCtx WorldContext, req *PendingTasksQueryRequest) (*PendingTasksQueryResponse, error) {
+	res := &PendingTasksQueryResponse{Tasks: []PendingTask{}}
+	ids, err := NewSearch().Entity(filter.Contains(filter.Component[taskMetadata]())).Collect(wCtx)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		metadata, err := GetComponent[taskMetadata](wCtx, id)
+		if err != nil {
+			return nil, err
+		}
+		taskType, err := taskTypeOf(wCtx, id)
+		if err != nil {
+			return nil, err
+		}
+		if req.Type != "" && req.Type != taskType {
+			continue
+		}
+		recurrence, err := GetComponent[taskRecurrence](wCtx, id)
+		if eris.Is(err, ErrComponentNotOnEntity) {
+			recurrence = &taskRecurrence{}
+		} else if err != nil {
+			return nil, err
+		}
+		res.Tasks = append(res.Tasks, PendingTask{
+			ID:                 id,
+			Type:               taskType,
+			TriggerAtTick:      metadata.TriggerAtTick,
+			TriggerAtTimestamp: metadata.TriggerAtTimestamp,
+			IntervalTicks:      recurrence.IntervalTicks,
+			IntervalMillis:     recurrence.IntervalMillis,
+			MaxRuns:            recurrence.MaxRuns,
+			Runs:               recurrence.Runs,
+		})
+	}
+	return res, nil
+}
+
+// taskTypeOf returns the name of the Task component of a task entity.
+func taskTypeOf(wCtx WorldContext, id types.EntityID) (string, error) {
+	comps, err := wCtx.storeReader().GetComponentTypesForEntity(id)
+	if err != nil {
+		return "", err
+	}
+	for _, c := range comps {
+		if c.Name() != (taskMetadata{}).Name() && c.Name() != (taskRecurrence{}).Name() {
+			return c.Name(), nil
+		}
+	}
+	return "", eris.Errorf("task entity %d has no task component", id)
+}
+
+// durationToMillis converts a duration that is used to schedule a task to milliseconds.
+func durationToMillis(d time.Duration) (uint64, error) {
+	if d.Milliseconds() < 0 {
+		return 0, eris.New("duration value must be positive")
+	}
+	return uint64(d.Milliseconds()), nil
+}
+
 // -----------------------------------------------------------------------------
 // Plugin Definition
 // -----------------------------------------------------------------------------
@@ -151,5 +352,10 @@ func (*TaskPlugin) Register(w *World) error {
 	if err != nil {
 		return eris.Wrap(err, "failed to register task entry component")
 	}
+	err = RegisterQuery[PendingTasksQueryRequest, PendingTasksQueryResponse](w, "pending", PendingTasksQuery,
+		WithCustomQueryGroup[PendingTasksQueryRequest, PendingTasksQueryResponse]("task"))
+	if err != nil {
+		return eris.Wrap(err, "failed to register pending tasks query")
+	}
 	return nil
 }
@@ -9,6 +9,7 @@ import (
 	"pkg.world.dev/world-engine/assert"
 	"pkg.world.dev/world-engine/cardinal"
 	"pkg.world.dev/world-engine/cardinal/filter"
+	"pkg.world.dev/world-engine/cardinal/types"
 )
 
 // -----------------------------------------------------------------------------
@@ -216,7 +217,8 @@ func TestPluginTask_ScheduleTimeTask(t *testing.T) {
 
 				// Schedule tasks
 				for _, testTask := range tc.testTasks {
-					assert.NilError(t, wCtx.ScheduleTimeTask(testTask.delay, testTask.task))
+					_, err = wCtx.ScheduleTimeTask(testTask.delay, testTask.task)
+					assert.NilError(t, err)
 				}
 
 				return nil
@@ -268,7 +270,7 @@ func TestPluginTask_ScheduleTimeTask_Recovery(t *testing.T) {
 		assert.NilError(t, err)
 
 		// Schedule tasks
-		err = wCtx.ScheduleTimeTask(10*time.Millisecond, StorageSetterTask{Payload: "test"})
+		_, err = wCtx.ScheduleTimeTask(10*time.Millisecond, StorageSetterTask{Payload: "test"})
 		assert.NilError(t, err)
 
 		return nil
@@ -425,7 +427,8 @@ func TestPluginTask_ScheduleTickTask(t *testing.T) {
 
 				// Schedule tasks
 				for _, testTask := range tc.testTasks {
-					assert.NilError(t, wCtx.ScheduleTickTask(testTask.delay, testTask.task))
+					_, err = wCtx.ScheduleTickTask(testTask.delay, testTask.task)
+					assert.NilError(t, err)
 				}
 
 				return nil
@@ -477,7 +480,7 @@ func TestPluginTask_ScheduleTickTask_Recovery(t *testing.T) {
 		assert.NilError(t, err)
 
 		// Schedule tasks
-		err = wCtx.ScheduleTickTask(2, StorageSetterTask{Payload: "test"})
+		_, err = wCtx.ScheduleTickTask(2, StorageSetterTask{Payload: "test"})
 		assert.NilError(t, err)
 
 		return nil
@@ -511,3 +514,137 @@ func TestPluginTask_ScheduleTickTask_Recovery(t *testing.T) {
 	assert.NilError(t, err)
 	assert.Equal(t, gotStorage.Storage, "test")
 }
+
+// -----------------------------------------------------------------------------
+// Recurring and cancelled task tests
+// -----------------------------------------------------------------------------
+
+// TestPluginTask_ScheduleRecurringTickTask tests that a recurring task is executed every interval until it has run its
+// maximum number of times, and is listed as pending until then.
+func TestPluginTask_ScheduleRecurringTickTask(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+
+	assert.NilError(t, cardinal.RegisterComponent[Counter](world))
+	assert.NilError(t, cardinal.RegisterTask[CounterTask](world))
+
+	var taskID types.EntityID
+	err := cardinal.RegisterInitSystems(world, func(wCtx cardinal.WorldContext) error {
+		_, err := cardinal.Create(wCtx, Counter{})
+		assert.NilError(t, err)
+
+		taskID, err = wCtx.ScheduleRecurringTickTask(2, 3, CounterTask{})
+		assert.NilError(t, err)
+		return nil
+	})
+	assert.NilError(t, err)
+
+	// Execute the init system
+	tf.DoTick()
+
+	getCount := func() int {
+		wCtx := cardinal.NewReadOnlyWorldContext(world)
+		id, err := cardinal.NewSearch().Entity(filter.Contains(filter.Component[Counter]())).First(wCtx)
+		assert.NilError(t, err)
+		counter, err := cardinal.GetComponent[Counter](wCtx, id)
+		assert.NilError(t, err)
+		return counter.Count
+	}
+	getPendingTasks := func() []cardinal.PendingTask {
+		res, err := cardinal.PendingTasksQuery(cardinal.NewReadOnlyWorldContext(world),
+			&cardinal.PendingTasksQueryRequest{Type: CounterTask{}.Name()})
+		assert.NilError(t, err)
+		return res.Tasks
+	}
+
+	tf.DoTick()
+	assert.Equal(t, getCount(), 0)
+	tf.DoTick()
+	assert.Equal(t, getCount(), 1)
+
+	pending := getPendingTasks()
+	assert.Equal(t, len(pending), 1)
+	assert.Equal(t, pending[0].ID, taskID)
+	assert.Equal(t, pending[0].Type, CounterTask{}.Name())
+	assert.Equal(t, pending[0].Runs, uint64(1))
+	assert.Equal(t, pending[0].MaxRuns, uint64(3))
+	assert.Equal(t, *pending[0].TriggerAtTick, uint64(4))
+
+	for i := 0; i < 10; i++ {
+		tf.DoTick()
+	}
+	assert.Equal(t, getCount(), 3)
+	assert.Equal(t, len(getPendingTasks()), 0)
+}
+
+// TestPluginTask_CancelTask tests that a cancelled task is no longer executed, and that cancelling it again, or
+// cancelling an entity that is not a task, fails.
+func TestPluginTask_CancelTask(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+
+	assert.NilError(t, cardinal.RegisterComponent[Counter](world))
+	assert.NilError(t, cardinal.RegisterComponent[Storage](world))
+	assert.NilError(t, cardinal.RegisterTask[CounterTask](world))
+	assert.NilError(t, cardinal.RegisterTask[StorageSetterTask](world))
+
+	var recurringID, oneShotID, counterID types.EntityID
+	err := cardinal.RegisterInitSystems(world, func(wCtx cardinal.WorldContext) error {
+		var err error
+		counterID, err = cardinal.Create(wCtx, Counter{})
+		assert.NilError(t, err)
+		_, err = cardinal.Create(wCtx, Storage{})
+		assert.NilError(t, err)
+
+		recurringID, err = wCtx.ScheduleRecurringTimeTask(time.Millisecond, 0, CounterTask{})
+		assert.NilError(t, err)
+		oneShotID, err = wCtx.ScheduleTickTask(5, StorageSetterTask{Payload: "test"})
+		assert.NilError(t, err)
+		return nil
+	})
+	assert.NilError(t, err)
+
+	var cancelErrs []error
+	err = cardinal.RegisterSystems(world, func(wCtx cardinal.WorldContext) error {
+		if wCtx.CurrentTick() == 3 {
+			cancelErrs = append(cancelErrs,
+				wCtx.CancelTask(recurringID),
+				wCtx.CancelTask(oneShotID),
+				wCtx.CancelTask(oneShotID),
+				wCtx.CancelTask(counterID),
+			)
+		}
+		return nil
+	})
+	assert.NilError(t, err)
+
+	for i := 0; i < 4; i++ {
+		time.Sleep(2 * time.Millisecond)
+		tf.DoTick()
+	}
+	assert.NilError(t, cancelErrs[0])
+	assert.NilError(t, cancelErrs[1])
+	assert.True(t, eris.Is(cancelErrs[2], cardinal.ErrTaskNotFound))
+	assert.True(t, eris.Is(cancelErrs[3], cardinal.ErrTaskNotFound))
+
+	wCtx := cardinal.NewReadOnlyWorldContext(world)
+	res, err := cardinal.PendingTasksQuery(wCtx, &cardinal.PendingTasksQueryRequest{})
+	assert.NilError(t, err)
+	assert.Equal(t, len(res.Tasks), 0)
+	counter, err := cardinal.GetComponent[Counter](wCtx, counterID)
+	assert.NilError(t, err)
+	count := counter.Count
+
+	for i := 0; i < 4; i++ {
+		time.Sleep(2 * time.Millisecond)
+		tf.DoTick()
+	}
+	counter, err = cardinal.GetComponent[Counter](wCtx, counterID)
+	assert.NilError(t, err)
+	assert.Equal(t, counter.Count, count)
+	storageID, err := cardinal.NewSearch().Entity(filter.Contains(filter.Component[Storage]())).First(wCtx)
+	assert.NilError(t, err)
+	storage, err := cardinal.GetComponent[Storage](wCtx, storageID)
+	assert.NilError(t, err)
+	assert.Equal(t, storage.Storage, "")
+}
@@ -24,6 +24,10 @@ type query interface {
 	IsEVMCompatible() bool
 	// GetRequestFieldInformation returns a map of the fields of the query's request type and their types.
 	GetRequestFieldInformation() map[string]any
+	// GetRequestSchema returns the JSON schema of the query's request type.
+	GetRequestSchema() ([]byte, error)
+	// GetReplySchema returns the JSON schema of the query's reply type.
+	GetReplySchema() ([]byte, error)
 
 	// handleQuery handles queries with concrete struct types, rather than encoded bytes.
 	handleQuery(WorldContext, any) (any, error)
@@ -258,6 +262,16 @@ func (r *queryType[Request, Reply]) GetRequestFieldInformation() map[string]any
 	return types.GetFieldInformation(reflect.TypeOf(new(Request)).Elem())
 }
 
+// GetRequestSchema returns the JSON schema of the request type.
+func (r *queryType[Request, Reply]) GetRequestSchema() ([]byte, error) {
+	return types.GetJSONSchema(reflect.TypeOf(new(Request)).Elem())
+}
+
+// GetReplySchema returns the JSON schema of the reply type.
+func (r *queryType[Request, Reply]) GetReplySchema() ([]byte, error) {
+	return types.GetJSONSchema(reflect.TypeOf(new(Reply)).Elem())
+}
+
 func validateQuery[Request any, Reply any](
 	name string,
 	handler func(wCtx WorldContext, req *Request) (*Reply, error),
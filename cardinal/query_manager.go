@@ -16,6 +16,7 @@ type QueryManager interface {
 	HandleQueryEVM(group string, name string, abiRequest []byte) ([]byte, error)
 	getQuery(group string, name string) (query, error)
 	BuildQueryFields() []types.FieldDetail
+	BuildQuerySchemas() ([]types.QuerySchema, error)
 }
 
 type queryManager struct {
@@ -84,11 +85,39 @@ func (m *queryManager) BuildQueryFields() []types.FieldDetail {
 	queriesFields := make([]types.FieldDetail, 0, len(queries))
 	for _, q := range queries {
 		// Extracting the fields of the q
+		// A query whose schema can't be generated is still listed with its fields.
+		request, _ := q.GetRequestSchema()
+		reply, _ := q.GetReplySchema()
 		queriesFields = append(queriesFields, types.FieldDetail{
-			Name:   q.Name(),
-			Fields: q.GetRequestFieldInformation(),
-			URL:    utils.GetQueryURL(q.Group(), q.Name()),
+			Name:         q.Name(),
+			Fields:       q.GetRequestFieldInformation(),
+			URL:          utils.GetQueryURL(q.Group(), q.Name()),
+			Schema:       request,
+			ResultSchema: reply,
 		})
 	}
 	return queriesFields
 }
+
+// BuildQuerySchemas returns the JSON schemas of the requests and replies of all the registered queries.
+func (m *queryManager) BuildQuerySchemas() ([]types.QuerySchema, error) {
+	queries := m.GetRegisteredQueries()
+	schemas := make([]types.QuerySchema, 0, len(queries))
+	for _, q := range queries {
+		request, err := q.GetRequestSchema()
+		if err != nil {
+			return nil, eris.Wrapf(err, "failed to build request schema of query %s/%s", q.Group(), q.Name())
+		}
+		reply, err := q.GetReplySchema()
+		if err != nil {
+			return nil, eris.Wrapf(err, "failed to build reply schema of query %s/%s", q.Group(), q.Name())
+		}
+		schemas = append(schemas, types.QuerySchema{
+			Group:   q.Group(),
+			Name:    q.Name(),
+			Request: request,
+			Reply:   reply,
+		})
+	}
+	return schemas, nil
+}
@@ -30,6 +30,8 @@ type Receipt struct {
 	TxHash types.TxHash
 	Result any
 	Errs   []error
+	// PersonaTag is the persona the receipt is private to. It is empty for public receipts.
+	PersonaTag string
 }
 
 func (r Receipt) MarshalJSON() ([]byte, error) {
@@ -102,6 +104,18 @@ func (h *History) SetResult(hash types.TxHash, result any) {
 	h.history[tick][hash] = rec
 }
 
+// SetPersonaTag makes the receipt of the given transaction hash in the current tick private to the given persona.
+// Transactions without a receipt are left without one.
+func (h *History) SetPersonaTag(hash types.TxHash, personaTag string) {
+	tick := int(h.currTick.Load() % h.ticksToStore)
+	rec, ok := h.history[tick][hash]
+	if !ok {
+		return
+	}
+	rec.PersonaTag = personaTag
+	h.history[tick][hash] = rec
+}
+
 // GetReceipt gets the receipt (the transaction result and the list of errors) for the given transaction hash in the
 // current tick. To get receipts from previous ticks use GetReceiptsForTick.
 func (h *History) GetReceipt(hash types.TxHash) (Receipt, bool) {
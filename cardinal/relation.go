@@ -0,0 +1,224 @@
+package cardinal
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/types"
+	"pkg.world.dev/world-engine/cardinal/worldstage"
+)
+
+// ParentRelation is the built-in relation of a child entity to its parent, see SetParent. Removing a parent removes
+// its children.
+const ParentRelation = "parent"
+
+var (
+	ErrRelationNotRegistered     = errors.New("relation has not been registered")
+	ErrRelationAlreadyRegistered = errors.New("relation has already been registered")
+	ErrRelationCycle             = errors.New("relation would create a cycle")
+)
+
+// RelationPolicy decides what happens to the entities that are related to an entity when that entity is removed.
+type RelationPolicy int
+
+const (
+	// DetachOnRemove removes the relations to a removed entity, and keeps the entities that were related to it.
+	DetachOnRemove RelationPolicy = iota
+	// CascadeOnRemove removes the entities that are related to a removed entity as well.
+	CascadeOnRemove
+)
+
+// RegisterRelation registers a relation between entities, e.g. the members of a guild. An entity can be related to any
+// number of entities by a relation, and any number of entities can be related to it. The policy decides what happens
+// to the entities that are related to an entity when it is removed. The relations of a removed entity to other
+// entities are always removed.
+func RegisterRelation(w *World, name string, policy RelationPolicy) error {
+	if w.worldStage.Current() != worldstage.Init {
+		return eris.Errorf(
+			"world state is %s, expected %s to register relations",
+			w.worldStage.Current(),
+			worldstage.Init,
+		)
+	}
+	if name == "" {
+		return eris.New("relation name must not be empty")
+	}
+	if _, ok := w.relations[name]; ok {
+		return eris.Wrapf(ErrRelationAlreadyRegistered, "%q", name)
+	}
+	w.relations[name] = policy
+	return nil
+}
+
+// Relate relates the entity from to the entity to by the given relation. Relating entities that are already related
+// has no effect.
+func Relate(wCtx WorldContext, relation string, from, to types.EntityID) (err error) {
+	defer func() { panicOnFatalError(wCtx, err) }()
+
+	if err = checkRelation(wCtx, relation, from, to); err != nil {
+		return err
+	}
+	return wCtx.storeManager().AddRelation(relation, from, to)
+}
+
+// Unrelate removes the relation of the entity from to the entity to. Unrelating entities that aren't related has no
+// effect.
+func Unrelate(wCtx WorldContext, relation string, from, to types.EntityID) (err error) {
+	defer func() { panicOnFatalError(wCtx, err) }()
+
+	if wCtx.isReadOnly() {
+		return ErrEntityMutationOnReadOnly
+	}
+	if _, ok := wCtx.getRelations()[relation]; !ok {
+		return eris.Wrapf(ErrRelationNotRegistered, "%q", relation)
+	}
+	return wCtx.storeManager().RemoveRelation(relation, from, to)
+}
+
+// RelatedTo returns the entities that are related to the given entity by the given relation, in ascending order.
+func RelatedTo(wCtx WorldContext, relation string, id types.EntityID) (ids []types.EntityID, err error) {
+	defer func() { panicOnFatalError(wCtx, err) }()
+
+	if _, ok := wCtx.getRelations()[relation]; !ok {
+		return nil, eris.Wrapf(ErrRelationNotRegistered, "%q", relation)
+	}
+	return wCtx.storeReader().GetRelationSources(relation, id)
+}
+
+// RelatedFrom returns the entities that the given entity is related to by the given relation, in ascending order.
+func RelatedFrom(wCtx WorldContext, relation string, id types.EntityID) (ids []types.EntityID, err error) {
+	defer func() { panicOnFatalError(wCtx, err) }()
+
+	if _, ok := wCtx.getRelations()[relation]; !ok {
+		return nil, eris.Wrapf(ErrRelationNotRegistered, "%q", relation)
+	}
+	return wCtx.storeReader().GetRelationTargets(relation, id)
+}
+
+// SetParent makes the given parent the parent of the given child, replacing its previous parent. An entity can't be
+// its own ancestor. Removing the parent removes the child as well.
+func SetParent(wCtx WorldContext, child, parent types.EntityID) (err error) {
+	defer func() { panicOnFatalError(wCtx, err) }()
+
+	if err = checkRelation(wCtx, ParentRelation, child, parent); err != nil {
+		return err
+	}
+	// Make sure the child isn't an ancestor of the parent
+	for ancestor, ok := parent, true; ok; {
+		if ancestor == child {
+			return eris.Wrapf(ErrRelationCycle, "entity %d is an ancestor of entity %d", child, parent)
+		}
+		if ancestor, ok, err = GetParent(wCtx, ancestor); err != nil {
+			return err
+		}
+	}
+
+	if err = RemoveParent(wCtx, child); err != nil {
+		return err
+	}
+	return wCtx.storeManager().AddRelation(ParentRelation, child, parent)
+}
+
+// RemoveParent removes the parent of the given child, if it has one. The child is kept.
+func RemoveParent(wCtx WorldContext, child types.EntityID) (err error) {
+	defer func() { panicOnFatalError(wCtx, err) }()
+
+	if wCtx.isReadOnly() {
+		return ErrEntityMutationOnReadOnly
+	}
+	parents, err := wCtx.storeManager().GetRelationTargets(ParentRelation, child)
+	if err != nil {
+		return err
+	}
+	for _, parent := range parents {
+		if err = wCtx.storeManager().RemoveRelation(ParentRelation, child, parent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetParent returns the parent of the given entity. False is returned if the entity doesn't have a parent.
+func GetParent(wCtx WorldContext, child types.EntityID) (parent types.EntityID, ok bool, err error) {
+	defer func() { panicOnFatalError(wCtx, err) }()
+
+	parents, err := wCtx.storeReader().GetRelationTargets(ParentRelation, child)
+	if err != nil || len(parents) == 0 {
+		return 0, false, err
+	}
+	return parents[0], true, nil
+}
+
+// Children returns the children of the given entity, in ascending order.
+func Children(wCtx WorldContext, parent types.EntityID) ([]types.EntityID, error) {
+	return RelatedTo(wCtx, ParentRelation, parent)
+}
+
+// checkRelation checks that the relation has been registered, and that both entities exist and are different.
+func checkRelation(wCtx WorldContext, relation string, from, to types.EntityID) error {
+	if wCtx.isReadOnly() {
+		return ErrEntityMutationOnReadOnly
+	}
+	if _, ok := wCtx.getRelations()[relation]; !ok {
+		return eris.Wrapf(ErrRelationNotRegistered, "%q", relation)
+	}
+	if from == to {
+		return eris.Wrapf(ErrRelationCycle, "entity %d can't be related to itself", from)
+	}
+	for _, id := range []types.EntityID{from, to} {
+		if _, err := wCtx.storeReader().GetComponentTypesForEntity(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeEntity removes an entity along with its relations. The entities that are related to it are removed as well if
+// the policy of the relation is CascadeOnRemove. removing holds the entities that are being removed, so that cycles of
+// relations don't remove an entity twice.
+func removeEntity(wCtx WorldContext, id types.EntityID, removing map[types.EntityID]bool) error {
+	store := wCtx.storeManager()
+	if _, err := store.GetComponentTypesForEntity(id); err != nil {
+		return err
+	}
+	removing[id] = true
+
+	relations := wCtx.getRelations()
+	names := make([]string, 0, len(relations))
+	for name := range relations {
+		names = append(names, name)
+	}
+	// Relations are handled in name order, so that cascading removals are deterministic
+	sort.Strings(names)
+	for _, relation := range names {
+		sources, err := store.GetRelationSources(relation, id)
+		if err != nil {
+			return err
+		}
+		for _, source := range sources {
+			if err = store.RemoveRelation(relation, source, id); err != nil {
+				return err
+			}
+			if relations[relation] == CascadeOnRemove && !removing[source] {
+				if err = removeEntity(wCtx, source, removing); err != nil {
+					return eris.Wrapf(err, "failed to remove entity %d related to entity %d by %q", source, id,
+						relation)
+				}
+			}
+		}
+
+		targets, err := store.GetRelationTargets(relation, id)
+		if err != nil {
+			return err
+		}
+		for _, target := range targets {
+			if err = store.RemoveRelation(relation, id, target); err != nil {
+				return err
+			}
+		}
+	}
+
+	return store.RemoveEntity(id)
+}
@@ -0,0 +1,94 @@
+package cardinal_test
+
+import (
+	"testing"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+func TestRemovingAParentRemovesItsDescendants(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[EnergyComponent](world))
+	tf.StartWorld()
+	wCtx := cardinal.NewWorldContext(world)
+
+	ids, err := cardinal.CreateMany(wCtx, 5, EnergyComponent{})
+	assert.NilError(t, err)
+	root, child, grandchild, other, orphan := ids[0], ids[1], ids[2], ids[3], ids[4]
+	assert.NilError(t, cardinal.SetParent(wCtx, child, root))
+	assert.NilError(t, cardinal.SetParent(wCtx, grandchild, child))
+	assert.NilError(t, cardinal.SetParent(wCtx, orphan, root))
+	// An entity can't become its own ancestor
+	assert.ErrorIs(t, cardinal.SetParent(wCtx, root, grandchild), cardinal.ErrRelationCycle)
+	// Setting a new parent replaces the old one
+	assert.NilError(t, cardinal.SetParent(wCtx, orphan, other))
+	assert.NilError(t, cardinal.RemoveParent(wCtx, orphan))
+	tf.DoTick()
+
+	children, err := cardinal.Children(wCtx, root)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, children, []types.EntityID{child})
+	parent, ok, err := cardinal.GetParent(wCtx, grandchild)
+	assert.NilError(t, err)
+	assert.Check(t, ok)
+	assert.Equal(t, parent, child)
+	_, ok, err = cardinal.GetParent(wCtx, orphan)
+	assert.NilError(t, err)
+	assert.Check(t, !ok)
+
+	assert.NilError(t, cardinal.Remove(wCtx, root))
+	tf.DoTick()
+	for _, id := range []types.EntityID{root, child, grandchild} {
+		_, err = world.GameStateManager().GetComponentTypesForEntity(id)
+		assert.Check(t, err != nil)
+		children, err = cardinal.Children(wCtx, id)
+		assert.NilError(t, err)
+		assert.Equal(t, len(children), 0)
+	}
+	for _, id := range []types.EntityID{other, orphan} {
+		_, err = cardinal.GetComponent[EnergyComponent](wCtx, id)
+		assert.NilError(t, err)
+	}
+}
+
+func TestRemovingAnEntityDetachesItsRelations(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[EnergyComponent](world))
+	assert.NilError(t, cardinal.RegisterRelation(world, "member", cardinal.DetachOnRemove))
+	assert.ErrorIs(t, cardinal.RegisterRelation(world, "member", cardinal.CascadeOnRemove),
+		cardinal.ErrRelationAlreadyRegistered)
+	tf.StartWorld()
+	wCtx := cardinal.NewWorldContext(world)
+
+	ids, err := cardinal.CreateMany(wCtx, 4, EnergyComponent{})
+	assert.NilError(t, err)
+	guildA, guildB, alice, bob := ids[0], ids[1], ids[2], ids[3]
+	assert.ErrorIs(t, cardinal.Relate(wCtx, "friend", alice, bob), cardinal.ErrRelationNotRegistered)
+	for _, member := range []types.EntityID{alice, bob} {
+		assert.NilError(t, cardinal.Relate(wCtx, "member", member, guildA))
+	}
+	assert.NilError(t, cardinal.Relate(wCtx, "member", alice, guildB))
+	tf.DoTick()
+
+	guilds, err := cardinal.RelatedFrom(wCtx, "member", alice)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, guilds, []types.EntityID{guildA, guildB})
+
+	// Removing a guild keeps its members, and removing a member removes it from its guilds
+	assert.NilError(t, cardinal.Remove(wCtx, guildA))
+	assert.NilError(t, cardinal.Remove(wCtx, bob))
+	tf.DoTick()
+
+	_, err = cardinal.GetComponent[EnergyComponent](wCtx, alice)
+	assert.NilError(t, err)
+	guilds, err = cardinal.RelatedFrom(wCtx, "member", alice)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, guilds, []types.EntityID{guildB})
+	members, err := cardinal.RelatedTo(wCtx, "member", guildB)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, members, []types.EntityID{alice})
+}
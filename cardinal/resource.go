@@ -0,0 +1,158 @@
+package cardinal
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/component"
+	"pkg.world.dev/world-engine/cardinal/types"
+	"pkg.world.dev/world-engine/cardinal/worldstage"
+)
+
+var (
+	ErrResourceNotRegistered     = errors.New("resource has not been registered")
+	ErrResourceAlreadyRegistered = errors.New("resource has already been registered")
+)
+
+// resourceType is a registered resource, see RegisterResource.
+type resourceType struct {
+	metadata     types.ComponentMetadata
+	defaultValue json.RawMessage
+}
+
+// RegisterResource registers a resource, which is a single value of type T that is global to the world, e.g. the
+// configuration of the current season. Unlike a component on a unique entity, a resource is read and written by its
+// type without a search. Resources are stored with the state of the world, so changes to them are saved atomically
+// with the changes to components at the end of a tick. A resource has the given default value until it is first set.
+func RegisterResource[T types.Component](w *World, defaultValue T) error {
+	if w.worldStage.Current() != worldstage.Init {
+		return eris.Errorf(
+			"world state is %s, expected %s to register resources",
+			w.worldStage.Current(),
+			worldstage.Init,
+		)
+	}
+	metadata, err := component.NewComponentMetadata[T]()
+	if err != nil {
+		return err
+	}
+	if _, ok := w.resources[metadata.Name()]; ok {
+		return eris.Wrapf(ErrResourceAlreadyRegistered, "%q", metadata.Name())
+	}
+	bz, err := metadata.Encode(defaultValue)
+	if err != nil {
+		return eris.Wrapf(err, "failed to encode the default value of resource %q", metadata.Name())
+	}
+
+	if len(w.resources) == 0 {
+		// The query is only registered by worlds that use resources
+		err = RegisterQuery[ResourceQueryRequest, ResourceQueryResponse](w, "get", ResourceQuery,
+			WithCustomQueryGroup[ResourceQueryRequest, ResourceQueryResponse]("resource"))
+		if err != nil {
+			return eris.Wrap(err, "failed to register resource query")
+		}
+	}
+	w.resources[metadata.Name()] = resourceType{metadata: metadata, defaultValue: bz}
+	return nil
+}
+
+// GetResource returns the value of the resource of type T.
+func GetResource[T types.Component](wCtx WorldContext) (resource *T, err error) {
+	defer func() { panicOnFatalError(wCtx, err) }()
+
+	var t T
+	bz, err := getResource(wCtx, t.Name())
+	if err != nil {
+		return nil, err
+	}
+	value, err := wCtx.getResources()[t.Name()].metadata.Decode(bz)
+	if err != nil {
+		return nil, eris.Wrapf(err, "failed to decode resource %q", t.Name())
+	}
+	t = componentValue[T](value)
+	return &t, nil
+}
+
+// SetResource sets the value of the resource of type T.
+func SetResource[T types.Component](wCtx WorldContext, resource *T) (err error) {
+	defer func() { panicOnFatalError(wCtx, err) }()
+
+	// Error if the context is read only
+	if wCtx.isReadOnly() {
+		return ErrEntityMutationOnReadOnly
+	}
+
+	var t T
+	res, ok := wCtx.getResources()[t.Name()]
+	if !ok {
+		return eris.Wrapf(ErrResourceNotRegistered, "%q", t.Name())
+	}
+	bz, err := res.metadata.Encode(resource)
+	if err != nil {
+		return eris.Wrapf(err, "failed to encode resource %q", t.Name())
+	}
+	return wCtx.storeManager().SetResource(t.Name(), bz)
+}
+
+// getResource returns the value of the resource with the given name as JSON, which is its default value if it has
+// never been set.
+func getResource(wCtx WorldContext, name string) (json.RawMessage, error) {
+	res, ok := wCtx.getResources()[name]
+	if !ok {
+		return nil, eris.Wrapf(ErrResourceNotRegistered, "%q", name)
+	}
+	bz, ok, err := wCtx.storeReader().GetResource(name)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return res.defaultValue, nil
+	}
+	return bz, nil
+}
+
+// ResourceQueryRequest is the desired request body for the query-resource endpoint.
+type ResourceQueryRequest struct {
+	Name string `json:"name"`
+}
+
+// ResourceQueryResponse is used as the response body for the query-resource endpoint. Value is the current value of
+// the resource.
+type ResourceQueryResponse struct {
+	Name  string          `json:"name"`
+	Value json.RawMessage `json:"value"`
+}
+
+// ResourceQuery returns the current value of a registered resource.
+func ResourceQuery(wCtx WorldContext, req *ResourceQueryRequest) (*ResourceQueryResponse, error) {
+	bz, err := getResource(wCtx, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	return &ResourceQueryResponse{Name: req.Name, Value: bz}, nil
+}
+
+// BuildResourceFields returns the registered resources along with the JSON schemas of their types, ordered by name.
+func (w *World) BuildResourceFields() []types.FieldDetail {
+	names := make([]string, 0, len(w.resources))
+	for name := range w.resources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fields := make([]types.FieldDetail, 0, len(names))
+	for _, name := range names {
+		metadata := w.resources[name].metadata
+		r, _ := metadata.Decode(metadata.GetSchema())
+		fields = append(fields, types.FieldDetail{
+			Name:   name,
+			Fields: types.GetFieldInformation(reflect.TypeOf(r)),
+			Schema: metadata.GetSchema(),
+		})
+	}
+	return fields
+}
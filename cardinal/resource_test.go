@@ -0,0 +1,59 @@
+package cardinal_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal"
+)
+
+type SeasonConfig struct {
+	Season     int
+	XPModifier float64
+}
+
+func (SeasonConfig) Name() string {
+	return "SeasonConfig"
+}
+
+func TestResourceIsSavedWithTheTick(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterResource(world, SeasonConfig{Season: 1, XPModifier: 1}))
+	assert.ErrorIs(t, cardinal.RegisterResource(world, SeasonConfig{}), cardinal.ErrResourceAlreadyRegistered)
+
+	assert.NilError(t, cardinal.RegisterSystems(world, func(wCtx cardinal.WorldContext) error {
+		config, err := cardinal.GetResource[SeasonConfig](wCtx)
+		if err != nil {
+			return err
+		}
+		config.Season++
+		return cardinal.SetResource(wCtx, config)
+	}))
+	tf.StartWorld()
+
+	// The resource has its default value until it is set
+	config, err := cardinal.GetResource[SeasonConfig](cardinal.NewReadOnlyWorldContext(world))
+	assert.NilError(t, err)
+	assert.Equal(t, *config, SeasonConfig{Season: 1, XPModifier: 1})
+
+	tf.DoTick()
+	tf.DoTick()
+	config, err = cardinal.GetResource[SeasonConfig](cardinal.NewReadOnlyWorldContext(world))
+	assert.NilError(t, err)
+	assert.Equal(t, *config, SeasonConfig{Season: 3, XPModifier: 1})
+	assert.ErrorIs(t, cardinal.SetResource(cardinal.NewReadOnlyWorldContext(world), config),
+		cardinal.ErrEntityMutationOnReadOnly)
+
+	bz, err := world.HandleQuery("resource", "get", []byte(`{"name":"SeasonConfig"}`))
+	assert.NilError(t, err)
+	var res cardinal.ResourceQueryResponse
+	assert.NilError(t, json.Unmarshal(bz, &res))
+	assert.Equal(t, res.Name, "SeasonConfig")
+	assert.Equal(t, string(res.Value), `{"Season":3,"XPModifier":1}`)
+
+	fields := world.BuildResourceFields()
+	assert.Equal(t, len(fields), 1)
+	assert.Equal(t, fields[0].Name, "SeasonConfig")
+}
@@ -101,7 +101,9 @@ OuterLoop:
 				if err != nil {
 					return eris.Wrap(err, "failed to unmarshal transaction data")
 				}
-				msgValue, err := msgType.Decode(protoTx.GetBody())
+				// Transactions sequenced before messages were versioned are the first version of their message.
+				version := max(protoTx.GetVersion(), 1)
+				msgValue, err := msgType.DecodeVersion(version, protoTx.GetBody())
 				if err != nil {
 					return err
 				}
@@ -125,12 +127,16 @@ OuterLoop:
 
 func protoTxToSignTx(t *shard.Transaction) *sign.Transaction {
 	tx := &sign.Transaction{
-		PersonaTag: t.GetPersonaTag(),
-		Namespace:  t.GetNamespace(),
-		Timestamp:  t.GetTimestamp(),
-		Signature:  t.GetSignature(),
-		Hash:       common.Hash{},
-		Body:       t.GetBody(),
+		PersonaTag:    t.GetPersonaTag(),
+		Namespace:     t.GetNamespace(),
+		Timestamp:     t.GetTimestamp(),
+		Salt:          uint16(t.GetSalt()), //nolint:gosec // the salt of a transaction fits in a uint16
+		Nonce:         t.GetNonce(),
+		Signature:     t.GetSignature(),
+		Hash:          common.Hash{},
+		Body:          t.GetBody(),
+		SignatureType: t.GetSignatureType(),
+		Scheme:        t.GetScheme(),
 	}
 	// HashHex will populate the hash.
 	tx.HashHex()
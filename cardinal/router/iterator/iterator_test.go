@@ -157,6 +157,51 @@ func TestIteratorHappyPath(t *testing.T) {
 	assert.NilError(t, err)
 }
 
+type barV1 struct{ Name string }
+type barIn struct{ Names []string }
+
+func TestIteratorDecodesTransactionsWithTheirVersion(t *testing.T) {
+	barMsg := cardinal.NewMessageType[barIn, fooOut]("bar",
+		cardinal.WithMessageVersion[barIn, fooOut](1, func(old barV1) (barIn, error) {
+			return barIn{Names: []string{old.Name}}, nil
+		}),
+	)
+	assert.NilError(t, barMsg.SetID(20))
+
+	// The first transaction was sequenced before the message was versioned.
+	bodies := [][]byte{[]byte(`{"Name":"a"}`), []byte(`{"Name":"b"}`), []byte(`{"Names":["c","d"]}`)}
+	versions := []uint32{0, 1, 2}
+	txs := make([]*shard.TxData, 0, len(bodies))
+	for i, body := range bodies {
+		txBz, err := proto.Marshal(&shard.Transaction{PersonaTag: "ty", Body: body, Version: versions[i]})
+		assert.NilError(t, err)
+		txs = append(txs, &shard.TxData{TxId: uint64(barMsg.ID()), GameShardTransaction: txBz})
+	}
+	querier := &mockQuerier{
+		ret: []*shard.QueryTransactionsResponse{
+			{
+				Epochs: []*shard.Epoch{{Epoch: 1, Txs: txs}},
+				Page:   &shard.PageResponse{},
+			},
+		},
+	}
+	it := iterator.New(
+		func(id types.MessageID) (types.Message, bool) {
+			return barMsg, id == barMsg.ID()
+		},
+		"ns",
+		querier,
+	)
+	err := it.Each(func(batch []*iterator.TxBatch, _, _ uint64) error {
+		assert.Len(t, batch, 3)
+		assert.DeepEqual(t, batch[0].MsgValue, barIn{Names: []string{"a"}})
+		assert.DeepEqual(t, batch[1].MsgValue, barIn{Names: []string{"b"}})
+		assert.DeepEqual(t, batch[2].MsgValue, barIn{Names: []string{"c", "d"}})
+		return nil
+	})
+	assert.NilError(t, err)
+}
+
 func TestIteratorStartRange(t *testing.T) {
 	querier := &mockQuerier{retErr: errors.New("whatever")}
 	it := iterator.New(nil, "", querier)
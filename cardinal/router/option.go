@@ -2,6 +2,7 @@ package router
 
 import (
 	"github.com/argus-labs/go-jobqueue"
+	"github.com/rs/zerolog"
 
 	shard "pkg.world.dev/world-engine/rift/shard/v2"
 )
@@ -24,3 +25,10 @@ func WithMockJobQueue() Option {
 		rtr.sequencerJobQueue = sequencerJobQueue
 	}
 }
+
+// WithLogger sets the logger the router logs to. By default, the global logger is used.
+func WithLogger(logger *zerolog.Logger) Option {
+	return func(rtr *router) {
+		rtr.logger = logger
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/argus-labs/go-jobqueue"
 	"github.com/rotisserie/eris"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/codes"
@@ -68,6 +69,7 @@ type router struct {
 	routerKey  string
 
 	tracer trace.Tracer
+	logger *zerolog.Logger
 }
 
 func New(namespace, sequencerAddr, routerKey string, world Provider, opts ...Option) (Router, error) {
@@ -78,6 +80,7 @@ func New(namespace, sequencerAddr, routerKey string, world Provider, opts ...Opt
 		port:      defaultPort,
 		routerKey: routerKey,
 		tracer:    tracer,
+		logger:    &log.Logger,
 	}
 	for _, opt := range opts {
 		opt(rtr)
@@ -108,13 +111,13 @@ func New(namespace, sequencerAddr, routerKey string, world Provider, opts ...Opt
 		}
 	}
 
-	rtr.server = newEvmServer(world, routerKey)
+	rtr.server = newEvmServer(world, routerKey, rtr.logger)
 	routerv1.RegisterMsgServer(rtr.server.grpcServer, rtr.server)
 	return rtr, nil
 }
 
 func (r *router) RegisterGameShard(ctx context.Context) error {
-	log.Info().Msg("Registering game shard with EVM base shard")
+	r.logger.Info().Msg("Registering game shard with EVM base shard")
 
 	_, err := r.ShardSequencer.RegisterGameShard(ctx, &shard.RegisterGameShardRequest{
 		Namespace:     r.namespace,
@@ -124,7 +127,7 @@ func (r *router) RegisterGameShard(ctx context.Context) error {
 		return eris.Wrap(err, "failed to register game shard to base shard")
 	}
 
-	log.Info().Msg("Game shard registered with EVM base shard")
+	r.logger.Info().Msg("Game shard registered with EVM base shard")
 	return nil
 }
 
@@ -139,15 +142,30 @@ func (r *router) SubmitTxBlob(
 
 	messageIDtoTxs := make(map[uint64]*shard.Transactions)
 	for msgID, txs := range processedTxs {
+		// The version of every transaction is recorded, so that it is decoded the same way during recovery once newer
+		// versions of the message have been added.
+		latestVersion := uint32(0)
+		if msgType, ok := r.provider.GetMessageByID(msgID); ok {
+			latestVersion = msgType.Version()
+		}
 		protoTxs := make([]*shard.Transaction, 0, len(txs))
 		for _, txData := range txs {
 			tx := txData.Tx
+			version := txData.Version
+			if version == 0 {
+				version = latestVersion
+			}
 			protoTxs = append(protoTxs, &shard.Transaction{
-				PersonaTag: tx.PersonaTag,
-				Namespace:  tx.Namespace,
-				Timestamp:  tx.Timestamp,
-				Signature:  tx.Signature,
-				Body:       tx.Body,
+				PersonaTag:    tx.PersonaTag,
+				Namespace:     tx.Namespace,
+				Timestamp:     tx.Timestamp,
+				Signature:     tx.Signature,
+				Body:          tx.Body,
+				Version:       version,
+				Salt:          uint32(tx.Salt),
+				Nonce:         tx.Nonce,
+				SignatureType: tx.SignatureType,
+				Scheme:        tx.Scheme,
 			})
 		}
 		messageIDtoTxs[uint64(msgID)] = &shard.Transactions{Txs: protoTxs}
@@ -182,7 +200,7 @@ func (r *router) Shutdown() {
 }
 
 func (r *router) Start() error {
-	log.Info().Msg("Rollup mode enabled - starting router")
+	r.logger.Info().Msg("Rollup mode enabled - starting router")
 
 	listener, err := net.Listen("tcp", ":"+r.port)
 	if err != nil {
@@ -191,12 +209,12 @@ func (r *router) Start() error {
 	go func() {
 		err = eris.Wrap(r.server.grpcServer.Serve(listener), "error serving gRPC server")
 		if err != nil {
-			log.Fatal().Err(err).Msg(eris.ToString(err, true))
+			r.logger.Fatal().Err(err).Msg(eris.ToString(err, true))
 		}
 	}()
 	r.serverAddr = listener.Addr().String()
 
-	log.Info().Msg("Router started")
+	r.logger.Info().Msg("Router started")
 	return nil
 }
 
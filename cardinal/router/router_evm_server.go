@@ -6,7 +6,7 @@ import (
 	"fmt"
 	"slices"
 
-	"github.com/rs/zerolog/log"
+	"github.com/rs/zerolog"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -34,12 +34,14 @@ type evmServer struct {
 	provider   Provider
 	grpcServer *grpc.Server
 	routerKey  string
+	logger     *zerolog.Logger
 }
 
-func newEvmServer(p Provider, routerKey string) *evmServer {
+func newEvmServer(p Provider, routerKey string, logger *zerolog.Logger) *evmServer {
 	e := &evmServer{
 		provider:  p,
 		routerKey: routerKey,
+		logger:    logger,
 	}
 	e.grpcServer = grpc.NewServer(grpc.UnaryInterceptor(e.serverCallInterceptor))
 	return e
@@ -160,15 +162,15 @@ func (e *evmServer) SendMessage(
 func (e *evmServer) QueryShard(_ context.Context, req *routerv1.QueryShardRequest) (
 	*routerv1.QueryShardResponse, error,
 ) {
-	log.Debug().Msgf("get request for %q", req.GetResource())
+	e.logger.Debug().Msgf("get request for %q", req.GetResource())
 
 	// TODO(scott): the group name should not be hardcoded
 	reply, err := e.provider.HandleQueryEVM("game", req.GetResource(), req.GetRequest())
 	if err != nil {
-		log.Error().Err(err).Msg("failed to handle query")
+		e.logger.Error().Err(err).Msg("failed to handle query")
 		return nil, err
 	}
 
-	log.Debug().Msgf("sending back reply: %v", reply)
+	e.logger.Debug().Msgf("sending back reply: %v", reply)
 	return &routerv1.QueryShardResponse{Response: reply}, nil
 }
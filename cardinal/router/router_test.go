@@ -5,14 +5,20 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
 	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
 
 	"pkg.world.dev/world-engine/assert"
 	"pkg.world.dev/world-engine/cardinal/persona/component"
+	"pkg.world.dev/world-engine/cardinal/router/iterator"
 	"pkg.world.dev/world-engine/cardinal/router/mocks"
+	"pkg.world.dev/world-engine/cardinal/txpool"
 	"pkg.world.dev/world-engine/cardinal/types"
 	routerv1 "pkg.world.dev/world-engine/rift/router/v1"
 	shard "pkg.world.dev/world-engine/rift/shard/v2"
@@ -57,6 +63,26 @@ func (f *mockMsg) Decode(bytes []byte) (any, error) {
 	return f.msgValue, err
 }
 
+func (f *mockMsg) DecodeVersion(_ uint32, bytes []byte) (any, error) {
+	return f.Decode(bytes)
+}
+
+func (f *mockMsg) Version() uint32 {
+	return 1
+}
+
+func (f *mockMsg) Versions() []uint32 {
+	return []uint32{1}
+}
+
+func (f *mockMsg) PayloadType(_ uint32) (reflect.Type, error) {
+	return reflect.TypeOf(f.msgValue), nil
+}
+
+func (f *mockMsg) Validate(_ any) error {
+	return nil
+}
+
 func (f *mockMsg) DecodeEVMBytes(_ []byte) (any, error) {
 	return f.decodeEVMBytes()
 }
@@ -73,6 +99,14 @@ func (f *mockMsg) GetInFieldInformation() map[string]any {
 	return map[string]any{"foo": "bar"}
 }
 
+func (f *mockMsg) GetInSchema() ([]byte, error) {
+	return []byte(`{}`), nil
+}
+
+func (f *mockMsg) GetOutSchema() ([]byte, error) {
+	return []byte(`{}`), nil
+}
+
 var _ shard.TransactionHandlerClient = &fakeTxHandler{}
 
 type fakeTxHandler struct {
@@ -302,9 +336,87 @@ func TestRegisterCalledWithCorrectParams(t *testing.T) {
 	assert.Equal(t, txHandler.req.GetRouterAddress(), rtr.serverAddr)
 }
 
+// sequencerFake records the transactions submitted to it, and returns the epochs it is given when it is queried.
+type sequencerFake struct {
+	fakeTxHandler
+	submitted chan *shard.SubmitTransactionsRequest
+	epochs    []*shard.Epoch
+}
+
+func (f *sequencerFake) Submit(
+	_ context.Context,
+	in *shard.SubmitTransactionsRequest,
+	_ ...grpc.CallOption,
+) (*shard.SubmitTransactionsResponse, error) {
+	f.submitted <- in
+	return &shard.SubmitTransactionsResponse{}, nil
+}
+
+func (f *sequencerFake) QueryTransactions(
+	_ context.Context,
+	_ *shard.QueryTransactionsRequest,
+	_ ...grpc.CallOption,
+) (*shard.QueryTransactionsResponse, error) {
+	return &shard.QueryTransactionsResponse{Epochs: f.epochs, Page: &shard.PageResponse{}}, nil
+}
+
+// TestSubmittedTransactionsAreRecoveredWithTheirHash tests that the fields of a transaction that its hash and
+// signature depend on are submitted to the base shard, and are restored when the transaction is recovered.
+func TestSubmittedTransactionsAreRecoveredWithTheirHash(t *testing.T) {
+	rtr, provider := getTestRouterAndProvider(t)
+	sequencer := &sequencerFake{submitted: make(chan *shard.SubmitTransactionsRequest, 1)}
+	rtr.ShardSequencer = sequencer
+	rtr.tracer = otel.Tracer("router")
+	WithMockJobQueue()(rtr)
+	msg := &mockMsg{id: 1, msgValue: &map[string]any{}}
+	provider.EXPECT().GetMessageByID(msg.ID()).Return(msg, true).AnyTimes()
+
+	tx := &sign.Transaction{
+		PersonaTag:    "ty",
+		Namespace:     "ns",
+		Timestamp:     1000,
+		Salt:          3,
+		Nonce:         7,
+		Signature:     "signature",
+		Body:          []byte(`{"a":1}`),
+		SignatureType: sign.SignatureTypeEIP712,
+		Scheme:        "ed25519",
+	}
+	wantHash := tx.HashHex()
+	txs := txpool.TxMap{msg.ID(): {{MsgID: msg.ID(), Tx: tx}}}
+	assert.NilError(t, rtr.SubmitTxBlob(context.Background(), txs, 1, 1000))
+
+	req := <-sequencer.submitted
+	for msgID, protoTxs := range req.GetTransactions() {
+		for _, protoTx := range protoTxs.GetTxs() {
+			bz, err := proto.Marshal(protoTx)
+			assert.NilError(t, err)
+			sequencer.epochs = append(sequencer.epochs, &shard.Epoch{
+				Epoch: req.GetEpoch(),
+				Txs:   []*shard.TxData{{TxId: msgID, GameShardTransaction: bz}},
+			})
+		}
+	}
+	recovered := 0
+	err := rtr.TransactionIterator().Each(func(batches []*iterator.TxBatch, _, _ uint64) error {
+		for _, batch := range batches {
+			assert.Equal(t, batch.Tx.Salt, tx.Salt)
+			assert.Equal(t, batch.Tx.Nonce, tx.Nonce)
+			assert.Equal(t, batch.Tx.SignatureType, tx.SignatureType)
+			assert.Equal(t, batch.Tx.Scheme, tx.Scheme)
+			assert.Equal(t, batch.Tx.HashHex(), wantHash)
+			recovered++
+		}
+		return nil
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, recovered, 1)
+}
+
 func getTestRouterAndProvider(t *testing.T) (*router, *mocks.MockProvider) {
 	ctrl := gomock.NewController(t)
 	provider := mocks.NewMockProvider(ctrl)
 
-	return &router{provider: provider, server: newEvmServer(provider, "")}, provider
+	logger := &log.Logger
+	return &router{provider: provider, server: newEvmServer(provider, "", logger), logger: logger}, provider
 }
@@ -0,0 +1,120 @@
+package cardinal
+
+import (
+	"time"
+
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/cron"
+	"pkg.world.dev/world-engine/cardinal/filter"
+	"pkg.world.dev/world-engine/cardinal/worldstage"
+)
+
+// RegisterScheduledSystem registers a system that runs at the times of a cron expression, e.g. "0 0 * * *" runs it
+// every day at 00:00 UTC. See the cron package for the format of the expression.
+//
+// The schedule is evaluated against the timestamp of the tick (WorldContext.Timestamp) rather than the local clock,
+// and the time of the next run is stored in the ECS layer, so recovery replays the runs in the same ticks. The system
+// runs in the first tick whose timestamp is at or after a scheduled time. If the timestamps of two consecutive ticks
+// span several scheduled times, e.g. because Cardinal was down, the system runs once in the later tick.
+//
+// The schedule of the system is stored under the given name, which must be unique among the systems of the world.
+// Renaming a scheduled system starts its schedule over. The first run is the first scheduled time after the system is
+// first evaluated, or after its cron expression has been changed.
+func RegisterScheduledSystem(w *World, name string, expr string, sys System) error {
+	if w.worldStage.Current() != worldstage.Init {
+		return eris.Errorf(
+			"world state is %s, expected %s to register systems",
+			w.worldStage.Current(),
+			worldstage.Init,
+		)
+	}
+	if name == "" {
+		return eris.New("scheduled system name must not be empty")
+	}
+	schedule, err := cron.Parse(expr)
+	if err != nil {
+		return err
+	}
+
+	err = w.SystemManager.registerSystem(false, name, func(wCtx WorldContext) error {
+		due, err := isScheduledSystemDue(wCtx, name, expr, schedule)
+		if err != nil {
+			return eris.Wrapf(err, "failed to evaluate the schedule of system %q", name)
+		}
+		if !due {
+			return nil
+		}
+		return sys(wCtx)
+	})
+	if err != nil {
+		return err
+	}
+	w.hasScheduledSystems = true
+	return nil
+}
+
+// registerSystemSchedule registers the systemSchedule component if the world has scheduled systems. It is called by
+// StartGame after the game registered its components, so that its ID doesn't shift the IDs of the game's components,
+// and only by worlds that use scheduled systems, so that it doesn't change the IDs of the components of other worlds.
+func registerSystemSchedule(w *World) error {
+	if !w.hasScheduledSystems {
+		return nil
+	}
+	return RegisterComponent[systemSchedule](w)
+}
+
+// systemSchedule is an internal component that stores when a scheduled system runs next.
+type systemSchedule struct {
+	System string
+	Expr   string
+	// NextRunAt is the UNIX timestamp in milliseconds of the next run. It is 0 if the expression doesn't match any
+	// time in the next few years.
+	NextRunAt uint64
+}
+
+func (systemSchedule) Name() string {
+	return "systemSchedule"
+}
+
+// isScheduledSystemDue reports whether the scheduled system with the given name should run in this tick, and if so,
+// moves its next run to the first scheduled time after this tick.
+func isScheduledSystemDue(wCtx WorldContext, systemName string, expr string, schedule *cron.Schedule) (bool, error) {
+	now := wCtx.Timestamp()
+	id, err := NewSearch().Entity(filter.Contains(filter.Component[systemSchedule]())).
+		Where(FilterFunction[systemSchedule](func(s systemSchedule) bool { return s.System == systemName })).
+		First(wCtx)
+	if err != nil {
+		return false, err
+	}
+	if id == badEntityID {
+		_, err = Create(wCtx, systemSchedule{System: systemName, Expr: expr, NextRunAt: nextRunAt(schedule, now)})
+		return false, err
+	}
+
+	state, err := GetComponent[systemSchedule](wCtx, id)
+	if err != nil {
+		return false, err
+	}
+	if state.Expr != expr {
+		// The expression has been changed since the last run, so it starts over from this tick
+		state.Expr = expr
+		state.NextRunAt = nextRunAt(schedule, now)
+		return false, SetComponent(wCtx, id, state)
+	}
+	if state.NextRunAt == 0 || now < state.NextRunAt {
+		return false, nil
+	}
+	// Any scheduled times between the next run and now have been missed, and are covered by this run
+	state.NextRunAt = nextRunAt(schedule, now)
+	return true, SetComponent(wCtx, id, state)
+}
+
+// nextRunAt returns the first scheduled time after the given timestamp, both as UNIX timestamps in milliseconds.
+func nextRunAt(schedule *cron.Schedule, timestamp uint64) uint64 {
+	next := schedule.Next(time.UnixMilli(int64(timestamp))) //nolint:gosec // timestamps fit in an int64
+	if next.IsZero() {
+		return 0
+	}
+	return uint64(next.UnixMilli()) //nolint:gosec // scheduled times are after the epoch
+}
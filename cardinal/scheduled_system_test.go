@@ -0,0 +1,102 @@
+package cardinal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+// TestScheduledSystemRunsAtTickTimestamps tests that a scheduled system runs in the first tick at or after each
+// scheduled time, and runs once when consecutive ticks span several scheduled times.
+func TestScheduledSystemRunsAtTickTimestamps(t *testing.T) {
+	tf := NewTestFixture(t, nil)
+	world := tf.World
+
+	runs := 0
+	dailyReset := func(WorldContext) error {
+		runs++
+		return nil
+	}
+	assert.IsError(t, RegisterScheduledSystem(world, "dailyReset", "0 0 * *", dailyReset))
+	assert.NilError(t, RegisterScheduledSystem(world, "dailyReset", "0 0 * * *", dailyReset))
+	tf.StartWorld()
+
+	tests := []struct {
+		at   time.Time
+		runs int
+	}{
+		{time.Date(2024, 1, 1, 23, 58, 0, 0, time.UTC), 0},
+		{time.Date(2024, 1, 1, 23, 59, 59, 0, time.UTC), 0},
+		{time.Date(2024, 1, 2, 0, 0, 0, 500, time.UTC), 1},
+		{time.Date(2024, 1, 2, 0, 1, 0, 0, time.UTC), 1},
+		// Cardinal was down for several days
+		{time.Date(2024, 1, 5, 12, 0, 0, 0, time.UTC), 2},
+		{time.Date(2024, 1, 5, 13, 0, 0, 0, time.UTC), 2},
+		{time.Date(2024, 1, 6, 0, 0, 0, 0, time.UTC), 3},
+	}
+	for _, tc := range tests {
+		assert.NilError(t, world.doTick(context.Background(), uint64(tc.at.UnixMilli())))
+		assert.Equal(t, runs, tc.runs, tc.at)
+	}
+}
+
+// TestScheduledSystemsAreKeyedByName tests that the same system function can be scheduled under several names, each
+// with its own schedule, and that a name can't be used twice.
+func TestScheduledSystemsAreKeyedByName(t *testing.T) {
+	tf := NewTestFixture(t, nil)
+	world := tf.World
+
+	runs := map[string]int{}
+	reset := func(name string) System {
+		return func(WorldContext) error {
+			runs[name]++
+			return nil
+		}
+	}
+	assert.NilError(t, RegisterScheduledSystem(world, "daily", "0 0 * * *", reset("daily")))
+	assert.NilError(t, RegisterScheduledSystem(world, "hourly", "0 * * * *", reset("hourly")))
+	assert.IsError(t, RegisterScheduledSystem(world, "daily", "0 12 * * *", reset("daily")))
+	assert.IsError(t, RegisterScheduledSystem(world, "", "0 12 * * *", reset("")))
+	tf.StartWorld()
+
+	for _, at := range []time.Time{
+		time.Date(2024, 1, 1, 22, 30, 0, 0, time.UTC),
+		time.Date(2024, 1, 1, 23, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+	} {
+		assert.NilError(t, world.doTick(context.Background(), uint64(at.UnixMilli())))
+	}
+	assert.Equal(t, runs["daily"], 1)
+	assert.Equal(t, runs["hourly"], 2)
+}
+
+type scheduledGameComponent struct{}
+
+func (scheduledGameComponent) Name() string {
+	return "scheduledGameComponent"
+}
+
+// TestScheduledSystemsDoNotShiftComponentIDs tests that registering a scheduled system doesn't change the IDs of the
+// components that the game registers after it.
+func TestScheduledSystemsDoNotShiftComponentIDs(t *testing.T) {
+	componentID := func(opts ...func(*World)) types.ComponentID {
+		tf := NewTestFixture(t, nil)
+		for _, opt := range opts {
+			opt(tf.World)
+		}
+		assert.NilError(t, RegisterComponent[scheduledGameComponent](tf.World))
+		tf.StartWorld()
+		comp, err := tf.World.GetComponentByName(scheduledGameComponent{}.Name())
+		assert.NilError(t, err)
+		return comp.ID()
+	}
+
+	want := componentID()
+	got := componentID(func(w *World) {
+		assert.NilError(t, RegisterScheduledSystem(w, "daily", "0 0 * * *", func(WorldContext) error { return nil }))
+	})
+	assert.Equal(t, got, want)
+}
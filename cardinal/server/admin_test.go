@@ -0,0 +1,99 @@
+package server_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+
+	"pkg.world.dev/world-engine/cardinal"
+	"pkg.world.dev/world-engine/cardinal/admin"
+	"pkg.world.dev/world-engine/cardinal/server/handler"
+	"pkg.world.dev/world-engine/cardinal/server/utils"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+const testAdminKey = "admin-key"
+
+func (s *ServerTestSuite) TestAdminOperationsEditEntityStateDuringTheNextTick() {
+	s.setupWorld(cardinal.WithAdminKey(testAdminKey))
+	s.fixture.DoTick()
+
+	create := admin.Operations{Operations: []admin.Operation{{
+		Type:       admin.OpCreate,
+		Components: map[string]json.RawMessage{"location": json.RawMessage(`{"X":3,"Y":4}`)},
+	}}}
+
+	// The admin key is required
+	res := s.postAdminOperations("wrong-key", create)
+	s.Require().Equal(fiber.StatusUnauthorized, res.StatusCode)
+
+	res = s.postAdminOperations(testAdminKey, create)
+	body := s.readBody(res.Body)
+	s.Require().Equal(fiber.StatusOK, res.StatusCode, body)
+	var txRes handler.PostTransactionResponse
+	s.Require().NoError(json.Unmarshal([]byte(body), &txRes))
+	s.fixture.DoTick()
+
+	receipts, err := s.world.GetTransactionReceiptsForTick(txRes.Tick)
+	s.Require().NoError(err)
+	s.Require().Len(receipts, 1)
+	s.Require().Empty(receipts[0].Errs)
+	result, ok := receipts[0].Result.(admin.OperationsResult)
+	s.Require().True(ok)
+	s.Require().Len(result.Created, 1)
+	id := result.Created[0]
+	s.Require().Equal(LocationComponent{X: 3, Y: 4}, s.getLocation(id))
+
+	// Components of existing entities can be set
+	res = s.postAdminOperations(testAdminKey, admin.Operations{Operations: []admin.Operation{{
+		Type:      admin.OpSetComponent,
+		Entity:    id,
+		Component: "location",
+		Value:     json.RawMessage(`{"X":9,"Y":9}`),
+	}}})
+	s.Require().Equal(fiber.StatusOK, res.StatusCode, s.readBody(res.Body))
+	s.fixture.DoTick()
+	s.Require().Equal(LocationComponent{X: 9, Y: 9}, s.getLocation(id))
+
+	// Operations on unknown components are rejected right away
+	res = s.postAdminOperations(testAdminKey, admin.Operations{Operations: []admin.Operation{{
+		Type:      admin.OpRemoveComponent,
+		Entity:    id,
+		Component: "does-not-exist",
+	}}})
+	s.Require().Equal(fiber.StatusBadRequest, res.StatusCode)
+
+	// Admin operations can't be submitted as a regular transaction
+	res = s.fixture.Post(utils.GetTxURL(admin.MessageGroup, admin.OperationsMessageName), create)
+	s.Require().Equal(fiber.StatusNotFound, res.StatusCode)
+}
+
+func (s *ServerTestSuite) TestAdminOperationsAreDisabledByDefault() {
+	s.setupWorld()
+	s.fixture.DoTick()
+
+	res := s.postAdminOperations("", admin.Operations{})
+	s.Require().Equal(fiber.StatusNotFound, res.StatusCode)
+}
+
+func (s *ServerTestSuite) postAdminOperations(key string, ops admin.Operations) *http.Response {
+	bz, err := json.Marshal(ops)
+	s.Require().NoError(err)
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost,
+		"http://"+s.fixture.BaseURL+"/admin/operations", bytes.NewReader(bz))
+	s.Require().NoError(err)
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add(handler.AdminKeyHeader, key)
+	res, err := http.DefaultClient.Do(req)
+	s.Require().NoError(err)
+	return res
+}
+
+func (s *ServerTestSuite) getLocation(id types.EntityID) LocationComponent {
+	loc, err := cardinal.GetComponent[LocationComponent](cardinal.NewReadOnlyWorldContext(s.world), id)
+	s.Require().NoError(err)
+	return *loc
+}
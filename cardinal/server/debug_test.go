@@ -1,7 +1,10 @@
 package server_test
 
 import (
+	"bufio"
 	"encoding/json"
+	"fmt"
+	"slices"
 
 	"pkg.world.dev/world-engine/cardinal"
 	"pkg.world.dev/world-engine/cardinal/server/handler"
@@ -60,3 +63,98 @@ func (s *ServerTestSuite) TestDebugStateQuery_NoState() {
 
 	s.Require().Equal(len(results), 0)
 }
+
+func (s *ServerTestSuite) TestDebugStatePagesAndStreamMatchTheWholeState() {
+	s.setupWorld()
+	s.fixture.DoTick()
+	wCtx := cardinal.NewWorldContext(s.world)
+	_, err := cardinal.CreateMany(wCtx, 5, LocationComponent{})
+	s.Require().NoError(err)
+	s.CreateRandomPersona()
+
+	res := s.fixture.Post("debug/state", handler.DebugStateRequest{})
+	s.Require().Equal(200, res.StatusCode)
+	var all []types.DebugStateElement
+	s.Require().NoError(json.NewDecoder(res.Body).Decode(&all))
+	wantIDs := make([]types.EntityID, 0, len(all))
+	for _, element := range all {
+		wantIDs = append(wantIDs, element.ID)
+	}
+	slices.Sort(wantIDs)
+
+	// Walk through the state two entities at a time
+	var gotIDs []types.EntityID
+	req := handler.DebugStateRequest{Limit: 2}
+	for {
+		res = s.fixture.Post("debug/state", req)
+		s.Require().Equal(200, res.StatusCode)
+		var page handler.DebugStatePageResponse
+		s.Require().NoError(json.NewDecoder(res.Body).Decode(&page))
+		s.Require().LessOrEqual(len(page.Entities), 2)
+		for _, element := range page.Entities {
+			gotIDs = append(gotIDs, element.ID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		req.Cursor = page.NextCursor
+	}
+	s.Require().Equal(wantIDs, gotIDs)
+
+	res = s.fixture.Post("debug/state", handler.DebugStateRequest{Cursor: "not-a-cursor"})
+	s.Require().Equal(400, res.StatusCode)
+
+	// The stream contains every entity exactly once
+	res = s.fixture.Get("debug/state/stream")
+	s.Require().Equal(200, res.StatusCode)
+	gotIDs = gotIDs[:0]
+	scanner := bufio.NewScanner(res.Body)
+	for scanner.Scan() {
+		var element types.DebugStateElement
+		s.Require().NoError(json.Unmarshal(scanner.Bytes(), &element))
+		gotIDs = append(gotIDs, element.ID)
+	}
+	s.Require().NoError(scanner.Err())
+	slices.Sort(gotIDs)
+	s.Require().Equal(wantIDs, gotIDs)
+}
+
+func (s *ServerTestSuite) TestDebugInspectEntitiesAndArchetypes() {
+	s.setupWorld()
+	s.fixture.DoTick()
+	wCtx := cardinal.NewWorldContext(s.world)
+	ids, err := cardinal.CreateMany(wCtx, 3, LocationComponent{X: 7})
+	s.Require().NoError(err)
+	s.fixture.DoTick()
+
+	res := s.fixture.Get(fmt.Sprintf("debug/entity/%d", ids[0]))
+	s.Require().Equal(200, res.StatusCode)
+	var entity handler.DebugEntityResponse
+	s.Require().NoError(json.NewDecoder(res.Body).Decode(&entity))
+	s.Require().Equal(ids[0], entity.ID)
+	var loc LocationComponent
+	s.Require().NoError(json.Unmarshal(entity.Components["location"], &loc))
+	s.Require().Equal(LocationComponent{X: 7}, loc)
+
+	res = s.fixture.Get("debug/entity/1000000")
+	s.Require().Equal(404, res.StatusCode)
+
+	res = s.fixture.Get("debug/archetypes")
+	s.Require().Equal(200, res.StatusCode)
+	var archetypes []handler.DebugArchetype
+	s.Require().NoError(json.NewDecoder(res.Body).Decode(&archetypes))
+	idx := slices.IndexFunc(archetypes, func(a handler.DebugArchetype) bool { return a.ID == entity.ArchetypeID })
+	s.Require().NotEqual(-1, idx)
+	s.Require().Equal(3, archetypes[idx].EntityCount)
+	s.Require().Len(archetypes[idx].Components, 1)
+	s.Require().Equal("location", archetypes[idx].Components[0].Name)
+
+	res = s.fixture.Get(fmt.Sprintf("debug/archetypes/%d", entity.ArchetypeID))
+	s.Require().Equal(200, res.StatusCode)
+	var archetype handler.DebugArchetype
+	s.Require().NoError(json.NewDecoder(res.Body).Decode(&archetype))
+	s.Require().Equal(archetypes[idx], archetype)
+
+	res = s.fixture.Get(fmt.Sprintf("debug/archetypes/%d", len(archetypes)))
+	s.Require().Equal(404, res.StatusCode)
+}
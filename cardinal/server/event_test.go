@@ -6,11 +6,16 @@ import (
 	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/gorilla/websocket"
 
 	"pkg.world.dev/world-engine/assert"
 	"pkg.world.dev/world-engine/cardinal"
+	"pkg.world.dev/world-engine/cardinal/server/handler"
+	"pkg.world.dev/world-engine/cardinal/types"
+	"pkg.world.dev/world-engine/sign"
 )
 
 type SendEnergyTx struct {
@@ -114,6 +119,251 @@ func TestEventsThroughSystems(t *testing.T) {
 	assert.Equal(t, counter2.Load(), int32(numberToTest*numberToTest))
 }
 
+func TestTargetedEventsAreOnlySentToAuthenticatedPersona(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world, addr := tf.World, tf.BaseURL
+	privateKey, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	signerAddr := crypto.PubkeyToAddress(privateKey.PublicKey).Hex()
+	personaTag := "alice"
+
+	emitPrivateEvent := func(wCtx cardinal.WorldContext) error {
+		return wCtx.EmitEventTo(personaTag, map[string]any{"message": "secret"})
+	}
+	assert.NilError(t, cardinal.RegisterSystems(world, emitPrivateEvent))
+	tf.CreatePersona(personaTag, signerAddr)
+
+	url := wsURL(addr, "events")
+	authed, _, err := websocket.DefaultDialer.Dial(url, nil)
+	assert.NilError(t, err)
+	defer authed.Close()
+	other, _, err := websocket.DefaultDialer.Dial(url, nil)
+	assert.NilError(t, err)
+	defer other.Close()
+
+	// A signature over the wrong challenge must not authenticate the connection
+	assert.NilError(t, other.WriteJSON(handler.WebSocketRequest{Type: handler.WebSocketRequestChallenge}))
+	var res handler.WebSocketResponse
+	assert.NilError(t, other.ReadJSON(&res))
+	assert.Equal(t, res.Type, handler.WebSocketRequestChallenge)
+	badSig, err := sign.SignChallenge(privateKey, world.Namespace(), "not-the-challenge")
+	assert.NilError(t, err)
+	assert.NilError(t, other.WriteJSON(handler.WebSocketRequest{
+		Type:       handler.WebSocketRequestAuthenticate,
+		PersonaTag: personaTag,
+		Signature:  badSig,
+	}))
+	assert.NilError(t, other.ReadJSON(&res))
+	assert.Equal(t, res.Type, handler.WebSocketResponseError)
+
+	// Answering the real challenge authenticates the connection
+	assert.NilError(t, authed.WriteJSON(handler.WebSocketRequest{Type: handler.WebSocketRequestChallenge}))
+	assert.NilError(t, authed.ReadJSON(&res))
+	assert.Equal(t, res.Type, handler.WebSocketRequestChallenge)
+	sig, err := sign.SignChallenge(privateKey, world.Namespace(), res.Challenge)
+	assert.NilError(t, err)
+	assert.NilError(t, authed.WriteJSON(handler.WebSocketRequest{
+		Type:       handler.WebSocketRequestAuthenticate,
+		PersonaTag: personaTag,
+		Signature:  sig,
+	}))
+	assert.NilError(t, authed.ReadJSON(&res))
+	assert.Equal(t, res.Type, handler.WebSocketResponseAuthenticated)
+
+	tf.DoTick()
+
+	// Both connections get the public tick results, which must not contain the targeted event
+	for _, conn := range []*websocket.Conn{authed, other} {
+		var public cardinal.TickResults
+		assert.NilError(t, conn.ReadJSON(&public))
+		assert.Equal(t, len(public.Events), 0)
+		assert.Equal(t, public.PersonaTag, "")
+	}
+
+	// Only the authenticated connection gets the targeted event
+	var private cardinal.TickResults
+	assert.NilError(t, authed.ReadJSON(&private))
+	assert.Equal(t, private.PersonaTag, personaTag)
+	assert.Equal(t, len(private.Events), 1)
+	event := map[string]any{}
+	assert.NilError(t, json.Unmarshal(private.Events[0], &event))
+	assert.Equal(t, event["message"], "secret")
+
+	assert.NilError(t, other.SetReadDeadline(time.Now().Add(200*time.Millisecond)))
+	_, _, err = other.ReadMessage()
+	assert.IsError(t, err)
+}
+
+func TestCQLSubscriptionReceivesDiffs(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil, cardinal.WithDisableSignatureVerification())
+	world, addr := tf.World, tf.BaseURL
+	assert.NilError(t, cardinal.RegisterComponent[Alpha](world))
+	assert.NilError(t, cardinal.RegisterComponent[Beta](world))
+
+	// The system performs the step of the test that is stored in step during each tick
+	var step atomic.Int32
+	var first, second types.EntityID
+	assert.NilError(t, cardinal.RegisterSystems(world, func(wCtx cardinal.WorldContext) error {
+		var err error
+		switch step.Load() {
+		case 1:
+			if first, err = cardinal.Create(wCtx, Alpha{Something: 1}); err != nil {
+				return err
+			}
+			second, err = cardinal.Create(wCtx, Alpha{Something: 5})
+		case 2:
+			if err = cardinal.SetComponent(wCtx, first, &Alpha{Something: 7}); err != nil {
+				return err
+			}
+			err = cardinal.SetComponent(wCtx, second, &Alpha{Something: 6})
+		case 3:
+			// Setting an unchanged value must not produce an update
+			if err = cardinal.SetComponent(wCtx, first, &Alpha{Something: 7}); err != nil {
+				return err
+			}
+			err = cardinal.Remove(wCtx, second)
+		}
+		return err
+	}))
+	tf.StartWorld()
+	step.Store(1)
+	tf.DoTick()
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL(addr, "events"), nil)
+	assert.NilError(t, err)
+	defer conn.Close()
+	assert.NilError(t, conn.WriteJSON(handler.WebSocketRequest{
+		Type:           handler.WebSocketRequestSubscribe,
+		SubscriptionID: "big",
+		CQL:            "CONTAINS(alpha) & alpha.something > 2",
+	}))
+	var res handler.WebSocketResponse
+	assert.NilError(t, conn.ReadJSON(&res))
+	assert.Equal(t, res.Type, handler.WebSocketResponseSubscribed)
+	assert.Equal(t, res.SubscriptionID, "big")
+	assert.Equal(t, len(*res.Results), 1)
+	assert.Equal(t, (*res.Results)[0].ID, second)
+
+	// Subscription ids are unique per connection
+	assert.NilError(t, conn.WriteJSON(handler.WebSocketRequest{
+		Type:           handler.WebSocketRequestSubscribe,
+		SubscriptionID: "big",
+		CQL:            "CONTAINS(beta)",
+	}))
+	assert.NilError(t, conn.ReadJSON(&res))
+	assert.Equal(t, res.Type, handler.WebSocketResponseError)
+
+	step.Store(2)
+	tf.DoTick()
+	diff := readCQLDiff(t, conn)
+	assert.Equal(t, diff.SubscriptionID, "big")
+	assert.Equal(t, len(diff.Entered), 1)
+	assert.Equal(t, diff.Entered[0].ID, first)
+	assert.Equal(t, len(diff.Updated), 1)
+	assert.Equal(t, diff.Updated[0].ID, second)
+	var alpha Alpha
+	assert.NilError(t, json.Unmarshal(diff.Updated[0].Data[0], &alpha))
+	assert.Equal(t, alpha.Something, 6)
+	assert.Equal(t, len(diff.Left), 0)
+
+	step.Store(3)
+	tf.DoTick()
+	diff = readCQLDiff(t, conn)
+	assert.Equal(t, len(diff.Entered), 0)
+	assert.Equal(t, len(diff.Updated), 0)
+	assert.DeepEqual(t, diff.Left, []types.EntityID{second})
+
+	// No diffs are sent after unsubscribing
+	assert.NilError(t, conn.WriteJSON(handler.WebSocketRequest{
+		Type:           handler.WebSocketRequestUnsubscribe,
+		SubscriptionID: "big",
+	}))
+	for res.Type != handler.WebSocketResponseUnsubscribed {
+		assert.NilError(t, conn.ReadJSON(&res))
+	}
+	step.Store(1)
+	tf.DoTick()
+	for {
+		assert.NilError(t, conn.SetReadDeadline(time.Now().Add(200*time.Millisecond)))
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			break
+		}
+		var msg struct{ Type string }
+		assert.NilError(t, json.Unmarshal(message, &msg))
+		assert.Check(t, msg.Type != handler.WebSocketResponseCQLDiff, "unexpected cql diff after unsubscribing")
+	}
+}
+
+// readCQLDiff reads messages from the websocket until a CQL diff arrives, skipping the tick results.
+func readCQLDiff(t *testing.T, conn *websocket.Conn) handler.CQLDiff {
+	for {
+		_, message, err := conn.ReadMessage()
+		assert.NilError(t, err)
+		var diff handler.CQLDiff
+		assert.NilError(t, json.Unmarshal(message, &diff))
+		if diff.Type == handler.WebSocketResponseCQLDiff {
+			return diff
+		}
+	}
+}
+
 func wsURL(addr, path string) string {
 	return fmt.Sprintf("ws://%s/%s", addr, path)
 }
+
+type PlayerMoved struct {
+	Player string `json:"player"`
+	X, Y   int
+}
+
+func (PlayerMoved) Name() string { return "player-moved" }
+
+type Unregistered struct{}
+
+func (Unregistered) Name() string { return "unregistered" }
+
+func TestTypedEventsAreEmittedWithTheirName(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil, cardinal.WithDisableSignatureVerification())
+	world, addr := tf.World, tf.BaseURL
+	assert.NilError(t, cardinal.RegisterEvent[PlayerMoved](world))
+	assert.IsError(t, cardinal.RegisterEvent[PlayerMoved](world))
+
+	var unregisteredErr atomic.Value
+	emitEvents := func(wCtx cardinal.WorldContext) error {
+		if err := cardinal.EmitTypedEvent(wCtx, Unregistered{}); err != nil {
+			unregisteredErr.Store(err)
+		}
+		return cardinal.EmitTypedEvent(wCtx, PlayerMoved{Player: "alice", X: 1, Y: 2})
+	}
+	assert.NilError(t, cardinal.RegisterSystems(world, emitEvents))
+	tf.StartWorld()
+
+	dial, _, err := websocket.DefaultDialer.Dial(wsURL(addr, "events"), nil)
+	assert.NilError(t, err)
+	defer dial.Close()
+	tf.DoTick()
+
+	_, message, err := dial.ReadMessage()
+	assert.NilError(t, err)
+	var tickResults cardinal.TickResults
+	assert.NilError(t, json.Unmarshal(message, &tickResults))
+	assert.Equal(t, len(tickResults.Events), 1)
+
+	var event struct {
+		Event string      `json:"event"`
+		Data  PlayerMoved `json:"data"`
+	}
+	assert.NilError(t, json.Unmarshal(tickResults.Events[0], &event))
+	assert.Equal(t, event.Event, "player-moved")
+	assert.Equal(t, event.Data, PlayerMoved{Player: "alice", X: 1, Y: 2})
+	assert.Check(t, unregisteredErr.Load() != nil, "emitting an unregistered event must fail")
+
+	// Registered events are described by /world
+	res := tf.Get("/world")
+	var worldRes handler.GetWorldResponse
+	assert.NilError(t, json.NewDecoder(res.Body).Decode(&worldRes))
+	assert.Equal(t, len(worldRes.Events), 1)
+	assert.Equal(t, worldRes.Events[0].Name, "player-moved")
+	assert.Check(t, len(worldRes.Events[0].Schema) > 0)
+}
@@ -0,0 +1,323 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"sync"
+
+	"github.com/rotisserie/eris"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"pkg.world.dev/world-engine/cardinal/receipt"
+	"pkg.world.dev/world-engine/cardinal/server/handler"
+	servertypes "pkg.world.dev/world-engine/cardinal/server/types"
+	"pkg.world.dev/world-engine/cardinal/server/validator"
+	"pkg.world.dev/world-engine/cardinal/types"
+	"pkg.world.dev/world-engine/cardinal/validation"
+	cardinalv1 "pkg.world.dev/world-engine/rift/cardinal/v1"
+	"pkg.world.dev/world-engine/sign"
+)
+
+// tickResultsBufferSize is the number of tick results that may be queued up for a single TickResults stream. Streams
+// that fall further behind than this are closed, so that a slow client can't hold up the tick loop.
+const tickResultsBufferSize = 64
+
+var _ cardinalv1.CardinalServer = (*grpcServer)(nil)
+
+// grpcServer exposes the same transaction, query, receipt, world, and CQL endpoints as the HTTP server over gRPC.
+// Both transports share the same signature validator, so a transaction can't be replayed across them.
+type grpcServer struct {
+	cardinalv1.UnimplementedCardinalServer
+
+	world     servertypes.ProviderWorld
+	msgIndex  map[string]map[string]types.Message
+	validator *validator.SignatureValidator
+	worldRes  handler.GetWorldResponse
+	server    *grpc.Server
+	logger    *zerolog.Logger
+
+	subscribersMux sync.Mutex
+	subscribers    map[chan *cardinalv1.TickResultsResponse]struct{}
+	done           chan struct{}
+}
+
+func newGRPCServer(
+	world servertypes.ProviderWorld,
+	components []types.ComponentMetadata,
+	messages []types.Message,
+	msgIndex map[string]map[string]types.Message,
+	validator *validator.SignatureValidator,
+	logger *zerolog.Logger,
+) *grpcServer {
+	g := &grpcServer{
+		world:       world,
+		msgIndex:    msgIndex,
+		validator:   validator,
+		worldRes:    handler.BuildWorldResponse(world, components, messages, world.Namespace()),
+		server:      grpc.NewServer(),
+		logger:      logger,
+		subscribers: map[chan *cardinalv1.TickResultsResponse]struct{}{},
+		done:        make(chan struct{}),
+	}
+	cardinalv1.RegisterCardinalServer(g.server, g)
+	return g
+}
+
+// stop closes all TickResults streams and waits for in-flight requests to complete.
+func (g *grpcServer) stop() {
+	close(g.done)
+	g.server.GracefulStop()
+}
+
+func (g *grpcServer) SubmitTransaction(
+	_ context.Context, req *cardinalv1.SubmitTransactionRequest,
+) (*cardinalv1.SubmitTransactionResponse, error) {
+	msgType, ok := g.msgIndex[req.GetGroup()][req.GetName()]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "message %s/%s not found", req.GetGroup(), req.GetName())
+	}
+	version, ok := handler.ResolveVersion(msgType, req.GetVersion())
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "message %s/%s has no version %d", req.GetGroup(), req.GetName(),
+			req.GetVersion())
+	}
+
+	tx, err := g.transactionFromRequest(req.GetTransaction())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	res, err := handler.SubmitTransaction(g.world, msgType, version, tx, g.validator)
+	if err != nil {
+		g.logger.Error().Err(err).Msg("failed to submit transaction over gRPC")
+		return nil, grpcStatusFromError(err)
+	}
+	return &cardinalv1.SubmitTransactionResponse{TxHash: res.TxHash, Tick: res.Tick}, nil
+}
+
+func (g *grpcServer) Query(_ context.Context, req *cardinalv1.QueryRequest) (*cardinalv1.QueryResponse, error) {
+	resBz, err := g.world.HandleQuery(req.GetGroup(), req.GetName(), req.GetBody())
+	if eris.Is(err, types.ErrQueryNotFound) {
+		return nil, status.Error(codes.NotFound, "query not found")
+	} else if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "encountered an error in query: "+err.Error())
+	}
+	return &cardinalv1.QueryResponse{Body: resBz}, nil
+}
+
+func (g *grpcServer) ListReceipts(
+	_ context.Context, req *cardinalv1.ListReceiptsRequest,
+) (*cardinalv1.ListReceiptsResponse, error) {
+	reply := handler.ListReceipts(g.world, req.GetStartTick())
+	res := &cardinalv1.ListReceiptsResponse{
+		StartTick: reply.StartTick,
+		EndTick:   reply.EndTick,
+		Receipts:  make([]*cardinalv1.Receipt, 0, len(reply.Receipts)),
+	}
+	for _, r := range reply.Receipts {
+		pbReceipt, err := newReceipt(r.TxHash, r.Tick, r.Result, r.Errors)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		res.Receipts = append(res.Receipts, pbReceipt)
+	}
+	return res, nil
+}
+
+func (g *grpcServer) GetWorld(context.Context, *cardinalv1.GetWorldRequest) (*cardinalv1.GetWorldResponse, error) {
+	components, err := newFieldDetails(g.worldRes.Components)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	messages, err := newFieldDetails(g.worldRes.Messages)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	queries, err := newFieldDetails(g.world.BuildQueryFields())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &cardinalv1.GetWorldResponse{
+		Namespace:  g.worldRes.Namespace,
+		Components: components,
+		Messages:   messages,
+		Queries:    queries,
+	}, nil
+}
+
+func (g *grpcServer) EvaluateCQL(
+	_ context.Context, req *cardinalv1.EvaluateCQLRequest,
+) (*cardinalv1.EvaluateCQLResponse, error) {
+	result, err := g.world.EvaluateCQL(req.GetCql())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	res := &cardinalv1.EvaluateCQLResponse{Results: make([]*cardinalv1.EntityState, 0, len(result))}
+	for _, entity := range result {
+		data := make([][]byte, 0, len(entity.Data))
+		for _, component := range entity.Data {
+			data = append(data, component)
+		}
+		res.Results = append(res.Results, &cardinalv1.EntityState{Id: uint64(entity.ID), Data: data})
+	}
+	return res, nil
+}
+
+// TickResults streams the public receipts and events of every tick that completes after the stream was opened.
+func (g *grpcServer) TickResults(_ *cardinalv1.TickResultsRequest, stream cardinalv1.Cardinal_TickResultsServer) error {
+	ch := make(chan *cardinalv1.TickResultsResponse, tickResultsBufferSize)
+	g.subscribersMux.Lock()
+	g.subscribers[ch] = struct{}{}
+	g.subscribersMux.Unlock()
+
+	defer func() {
+		g.subscribersMux.Lock()
+		delete(g.subscribers, ch)
+		g.subscribersMux.Unlock()
+	}()
+
+	// Sending the header lets clients know they are subscribed and won't miss the results of the next tick.
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-g.done:
+			return status.Error(codes.Unavailable, "server is shutting down")
+		case res, ok := <-ch:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "client is not keeping up with tick results")
+			}
+			if err := stream.Send(res); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// publishTickResults fans the tick results out to all open TickResults streams.
+func (g *grpcServer) publishTickResults(tick uint64, receipts []receipt.Receipt, events [][]byte) {
+	g.subscribersMux.Lock()
+	defer g.subscribersMux.Unlock()
+	if len(g.subscribers) == 0 {
+		return
+	}
+
+	res := &cardinalv1.TickResultsResponse{
+		Tick:     tick,
+		Receipts: make([]*cardinalv1.Receipt, 0, len(receipts)),
+		Events:   events,
+	}
+	for _, r := range receipts {
+		errs := make([]string, 0, len(r.Errs))
+		for _, err := range r.Errs {
+			errs = append(errs, err.Error())
+		}
+		pbReceipt, err := newReceipt(string(r.TxHash), tick, r.Result, errs)
+		if err != nil {
+			g.logger.Error().Err(err).Msgf("failed to encode receipt %s for tick results stream", r.TxHash)
+			continue
+		}
+		res.Receipts = append(res.Receipts, pbReceipt)
+	}
+
+	for ch := range g.subscribers {
+		select {
+		case ch <- res:
+		default:
+			// The subscriber's buffer is full. Drop it rather than blocking the tick.
+			close(ch)
+			delete(g.subscribers, ch)
+		}
+	}
+}
+
+// transactionFromRequest converts the transaction in a gRPC request into a sign.Transaction. When signatures are
+// verified, the transaction goes through the same checks as a transaction posted over HTTP.
+func (g *grpcServer) transactionFromRequest(in *cardinalv1.Transaction) (*sign.Transaction, error) {
+	if in == nil {
+		return nil, eris.New("transaction is required")
+	}
+	if in.GetSalt() > math.MaxUint16 {
+		return nil, eris.New("salt must fit in 16 bits")
+	}
+	tx := &sign.Transaction{
+		PersonaTag:    in.GetPersonaTag(),
+		Namespace:     in.GetNamespace(),
+		Timestamp:     in.GetTimestamp(),
+		Salt:          uint16(in.GetSalt()),
+		Nonce:         in.GetNonce(),
+		Signature:     in.GetSignature(),
+		Body:          in.GetBody(),
+		SignatureType: in.GetSignatureType(),
+		Scheme:        in.GetScheme(),
+	}
+	if g.validator == nil || g.validator.IsDisabled {
+		tx.HashHex() // populates the hash
+		return tx, nil
+	}
+	bz, err := tx.Marshal()
+	if err != nil {
+		return nil, eris.Wrap(err, "unparseable transaction")
+	}
+	return sign.UnmarshalTransaction(bz)
+}
+
+// grpcStatusFromError is the gRPC counterpart of the HTTP handler's error mapping.
+func grpcStatusFromError(err error) error {
+	var validationErr *validation.Error
+	switch {
+	case eris.Is(err, handler.ErrTxDecodeFailed):
+		return status.Error(codes.InvalidArgument, "failed to decode tx message")
+	case eris.As(err, &validationErr):
+		return status.Error(codes.InvalidArgument, validationErr.Error())
+	case eris.Is(err, validator.ErrDuplicateMessage):
+		return status.Error(codes.AlreadyExists, "duplicate message")
+	case eris.Is(err, validator.ErrMessageExpired):
+		return status.Error(codes.DeadlineExceeded, "message expired")
+	case eris.Is(err, validator.ErrBadTimestamp):
+		return status.Error(codes.InvalidArgument, "bad timestamp")
+	case eris.Is(err, validator.ErrNonCanonicalBody):
+		return status.Error(codes.InvalidArgument, "body is not canonical json")
+	case eris.Is(err, validator.ErrNoPersonaTag):
+		return status.Error(codes.InvalidArgument, "no persona tag")
+	case eris.Is(err, validator.ErrNoNonce):
+		return status.Error(codes.InvalidArgument, "no nonce")
+	case eris.Is(err, validator.ErrInvalidSignature):
+		return status.Error(codes.Unauthenticated, "signature validation failed")
+	default:
+		return status.Error(codes.Internal, "failed to submit transaction")
+	}
+}
+
+func newReceipt(txHash string, tick uint64, result any, errs []string) (*cardinalv1.Receipt, error) {
+	var resultBz []byte
+	if result != nil {
+		var err error
+		resultBz, err = json.Marshal(result)
+		if err != nil {
+			return nil, eris.Wrap(err, "failed to marshal receipt result")
+		}
+	}
+	return &cardinalv1.Receipt{TxHash: txHash, Tick: tick, Result: resultBz, Errors: errs}, nil
+}
+
+func newFieldDetails(details []types.FieldDetail) ([]*cardinalv1.FieldDetail, error) {
+	res := make([]*cardinalv1.FieldDetail, 0, len(details))
+	for _, detail := range details {
+		fields, err := json.Marshal(detail.Fields)
+		if err != nil {
+			return nil, eris.Wrapf(err, "failed to marshal fields of %q", detail.Name)
+		}
+		res = append(res, &cardinalv1.FieldDetail{Name: detail.Name, Fields: fields, Url: detail.URL})
+	}
+	return res, nil
+}
@@ -0,0 +1,298 @@
+package server_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"pkg.world.dev/world-engine/cardinal"
+	"pkg.world.dev/world-engine/cardinal/persona/msg"
+	cardinalv1 "pkg.world.dev/world-engine/rift/cardinal/v1"
+	"pkg.world.dev/world-engine/sign"
+)
+
+// TestGRPCSubmitTransactionQueryAndTickResults tests that the gRPC API shares its behavior with the HTTP API.
+func (s *ServerTestSuite) TestGRPCSubmitTransactionQueryAndTickResults() {
+	client := s.setupGRPCWorld()
+	s.fixture.DoTick()
+	personaTag := s.CreateRandomPersona()
+	ctx := context.Background()
+
+	stream, err := client.TickResults(ctx, &cardinalv1.TickResultsRequest{})
+	s.Require().NoError(err)
+	_, err = stream.Header() // blocks until the stream is subscribed
+	s.Require().NoError(err)
+
+	tx, err := sign.NewTransaction(s.privateKey, personaTag, s.world.Namespace(), MoveMsgInput{Direction: "up"})
+	s.Require().NoError(err)
+	req := &cardinalv1.SubmitTransactionRequest{
+		Group:       "game",
+		Name:        moveMsgName,
+		Transaction: grpcTransaction(tx),
+	}
+	res, err := client.SubmitTransaction(ctx, req)
+	s.Require().NoError(err)
+	s.Require().Equal(tx.HashHex(), res.GetTxHash())
+
+	// The same transaction must not be accepted twice
+	_, err = client.SubmitTransaction(ctx, req)
+	s.Require().Equal(codes.AlreadyExists, status.Code(err))
+
+	s.fixture.DoTick()
+
+	tickResults, err := stream.Recv()
+	s.Require().NoError(err)
+	s.Require().Len(tickResults.GetReceipts(), 1)
+	s.Require().Equal(res.GetTxHash(), tickResults.GetReceipts()[0].GetTxHash())
+	s.Require().Empty(tickResults.GetReceipts()[0].GetErrors())
+
+	queryBz, err := json.Marshal(QueryLocationRequest{Persona: personaTag})
+	s.Require().NoError(err)
+	queryRes, err := client.Query(ctx, &cardinalv1.QueryRequest{Group: "game", Name: "location", Body: queryBz})
+	s.Require().NoError(err)
+	var loc LocationComponent
+	s.Require().NoError(json.Unmarshal(queryRes.GetBody(), &loc))
+	s.Require().Equal(LocationComponent{0, 1}, loc)
+
+	receipts, err := client.ListReceipts(ctx, &cardinalv1.ListReceiptsRequest{})
+	s.Require().NoError(err)
+	s.Require().NotEmpty(receipts.GetReceipts())
+
+	_, err = client.Query(ctx, &cardinalv1.QueryRequest{Group: "game", Name: "does-not-exist"})
+	s.Require().Equal(codes.NotFound, status.Code(err))
+}
+
+// TestGRPCRejectsUnsignedTransaction tests that signature validation is applied to transactions sent over gRPC.
+func (s *ServerTestSuite) TestGRPCRejectsUnsignedTransaction() {
+	client := s.setupGRPCWorld()
+	s.fixture.DoTick()
+
+	tx, err := sign.NewTransaction(s.privateKey, "unclaimed-persona", s.world.Namespace(), MoveMsgInput{Direction: "up"})
+	s.Require().NoError(err)
+	_, err = client.SubmitTransaction(context.Background(), &cardinalv1.SubmitTransactionRequest{
+		Group:       "game",
+		Name:        moveMsgName,
+		Transaction: grpcTransaction(tx),
+	})
+	s.Require().Equal(codes.Unauthenticated, status.Code(err))
+}
+
+// TestGRPCEIP712Transaction tests that transactions signed over their EIP-712 typed data are accepted over gRPC.
+func (s *ServerTestSuite) TestGRPCEIP712Transaction() {
+	client := s.setupGRPCWorld()
+	s.fixture.DoTick()
+	personaTag := s.CreateRandomPersona()
+	ctx := context.Background()
+
+	tx, err := sign.NewEIP712Transaction(s.privateKey, personaTag, s.world.Namespace(), 0, MoveMsgInput{Direction: "up"})
+	s.Require().NoError(err)
+	res, err := client.SubmitTransaction(ctx, &cardinalv1.SubmitTransactionRequest{
+		Group:       "game",
+		Name:        moveMsgName,
+		Transaction: grpcTransaction(tx),
+	})
+	s.Require().NoError(err)
+	s.Require().Equal(tx.HashHex(), res.GetTxHash())
+
+	// The signature doesn't hold for the transaction hash
+	tx, err = sign.NewEIP712Transaction(s.privateKey, personaTag, s.world.Namespace(), 0, MoveMsgInput{Direction: "up"})
+	s.Require().NoError(err)
+	in := grpcTransaction(tx)
+	in.SignatureType = ""
+	_, err = client.SubmitTransaction(ctx, &cardinalv1.SubmitTransactionRequest{
+		Group:       "game",
+		Name:        moveMsgName,
+		Transaction: in,
+	})
+	s.Require().Equal(codes.Unauthenticated, status.Code(err))
+}
+
+// TestGRPCSignatureSchemes tests that transactions signed with ed25519 and webauthn-p256 keys are accepted over gRPC.
+func (s *ServerTestSuite) TestGRPCSignatureSchemes() {
+	client := s.setupGRPCWorld()
+	s.fixture.DoTick()
+	ctx := context.Background()
+	submit := func(group, name string, tx *sign.Transaction) error {
+		_, err := client.SubmitTransaction(ctx, &cardinalv1.SubmitTransactionRequest{
+			Group:       group,
+			Name:        name,
+			Transaction: grpcTransaction(tx),
+		})
+		return err
+	}
+
+	personaTag := "grpc_persona"
+	edSigner := sign.NewEd25519Signer(ed25519.NewKeyFromSeed(bytes.Repeat([]byte{2}, ed25519.SeedSize)))
+	createPersona := msg.CreatePersona{PersonaTag: personaTag, SignerAddress: edSigner.Address()}
+	tx, err := sign.NewSystemTransactionWithSigner(edSigner, s.world.Namespace(), 0, createPersona)
+	s.Require().NoError(err)
+	s.Require().NoError(submit(msg.MessageGroup, msg.CreatePersonaMessageName, tx))
+	s.fixture.DoTick()
+
+	passkey := newWebAuthnSigner(s.T())
+	addKey := msg.AddPersonaSignerKey{Label: "passkey", SignerAddress: passkey.Address()}
+	tx, err = sign.NewTransactionWithSigner(edSigner, personaTag, s.world.Namespace(), 0, addKey)
+	s.Require().NoError(err)
+	s.Require().NoError(submit(msg.MessageGroup, msg.AddPersonaSignerKeyMessageName, tx))
+	s.fixture.DoTick()
+
+	tx, err = sign.NewTransactionWithSigner(passkey, personaTag, s.world.Namespace(), 0, MoveMsgInput{Direction: "up"})
+	s.Require().NoError(err)
+	s.Require().NoError(submit("game", moveMsgName, tx))
+
+	// The signature doesn't hold for the default scheme
+	tx, err = sign.NewTransactionWithSigner(passkey, personaTag, s.world.Namespace(), 0, MoveMsgInput{Direction: "up"})
+	s.Require().NoError(err)
+	tx.Scheme = ""
+	s.Require().Equal(codes.Unauthenticated, status.Code(submit("game", moveMsgName, tx)))
+}
+
+// TestGRPCOlderMessageVersion tests that transactions for older versions of a message can be submitted over gRPC.
+func (s *ServerTestSuite) TestGRPCOlderMessageVersion() {
+	port := s.freeGRPCPort()
+	s.setupWorld(cardinal.WithGRPCPort(port))
+	s.Require().NoError(cardinal.RegisterMessage[WalkMsg, MoveMessageOutput](s.world, "walk",
+		cardinal.WithMessageVersion[WalkMsg, MoveMessageOutput](1, func(old WalkMsgV1) (WalkMsg, error) {
+			return WalkMsg{Direction: old.Direction, Steps: 1}, nil
+		}),
+	))
+	var walks []WalkMsg
+	s.Require().NoError(cardinal.RegisterSystems(s.world, func(wCtx cardinal.WorldContext) error {
+		return cardinal.EachMessage[WalkMsg, MoveMessageOutput](wCtx,
+			func(tx cardinal.TxData[WalkMsg]) (MoveMessageOutput, error) {
+				walks = append(walks, tx.Msg)
+				return MoveMessageOutput{}, nil
+			})
+	}))
+	s.fixture.StartWorld()
+	client := s.dialGRPC(port)
+	s.fixture.DoTick()
+	personaTag := s.CreateRandomPersona()
+
+	submit := func(version uint32, msg any) error {
+		tx, err := sign.NewTransaction(s.privateKey, personaTag, s.world.Namespace(), msg)
+		s.Require().NoError(err)
+		_, err = client.SubmitTransaction(context.Background(), &cardinalv1.SubmitTransactionRequest{
+			Group:       "game",
+			Name:        "walk",
+			Transaction: grpcTransaction(tx),
+			Version:     version,
+		})
+		return err
+	}
+	s.Require().NoError(submit(1, WalkMsgV1{Direction: "up"}))
+	s.Require().NoError(submit(2, WalkMsg{Direction: "left", Steps: 2}))
+	s.Require().NoError(submit(0, WalkMsg{Direction: "down", Steps: 3}))
+	s.Require().Equal(codes.NotFound, status.Code(submit(3, WalkMsg{Direction: "down", Steps: 3})))
+	s.fixture.DoTick()
+
+	s.Require().ElementsMatch([]WalkMsg{
+		{Direction: "up", Steps: 1},
+		{Direction: "left", Steps: 2},
+		{Direction: "down", Steps: 3},
+	}, walks)
+}
+
+// setupGRPCWorld sets up the test world with the gRPC API enabled and returns a client connected to it.
+func (s *ServerTestSuite) setupGRPCWorld(opts ...cardinal.WorldOption) cardinalv1.CardinalClient {
+	port := s.freeGRPCPort()
+	s.setupWorld(append(opts, cardinal.WithGRPCPort(port))...)
+	s.fixture.StartWorld()
+	return s.dialGRPC(port)
+}
+
+// freeGRPCPort returns a port that is free for the gRPC server of the test world.
+func (s *ServerTestSuite) freeGRPCPort() string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Require().NoError(err)
+	port := strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+	s.Require().NoError(listener.Close())
+	return port
+}
+
+// dialGRPC returns a client connected to the gRPC server of the started test world.
+func (s *ServerTestSuite) dialGRPC(port string) cardinalv1.CardinalClient {
+	conn, err := grpc.NewClient("127.0.0.1:"+port, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	s.Require().NoError(err)
+	s.T().Cleanup(func() { _ = conn.Close() })
+
+	// Wait for the gRPC server to accept connections
+	client := cardinalv1.NewCardinalClient(conn)
+	s.Require().Eventually(func() bool {
+		_, err := client.GetWorld(context.Background(), &cardinalv1.GetWorldRequest{})
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	return client
+}
+
+// grpcTransaction converts a signed transaction into its gRPC form.
+func grpcTransaction(tx *sign.Transaction) *cardinalv1.Transaction {
+	return &cardinalv1.Transaction{
+		PersonaTag:    tx.PersonaTag,
+		Namespace:     tx.Namespace,
+		Timestamp:     tx.Timestamp,
+		Salt:          uint32(tx.Salt),
+		Signature:     tx.Signature,
+		Body:          tx.Body,
+		Nonce:         tx.Nonce,
+		SignatureType: tx.SignatureType,
+		Scheme:        tx.Scheme,
+	}
+}
+
+// webAuthnSigner signs like a passkey that asserts the challenge of the signed hash.
+type webAuthnSigner struct {
+	key *ecdsa.PrivateKey
+}
+
+func newWebAuthnSigner(t *testing.T) *webAuthnSigner {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &webAuthnSigner{key: key}
+}
+
+func (*webAuthnSigner) Scheme() string {
+	return sign.SchemeWebAuthnP256
+}
+
+func (w *webAuthnSigner) Address() string {
+	publicKey, _ := w.key.PublicKey.ECDH()
+	return sign.FormatSigner(sign.SchemeWebAuthnP256, "0x"+common.Bytes2Hex(publicKey.Bytes()))
+}
+
+func (w *webAuthnSigner) Sign(hash common.Hash) (string, error) {
+	clientData, err := json.Marshal(map[string]any{
+		"type":      "webauthn.get",
+		"challenge": sign.WebAuthnChallenge(hash),
+		"origin":    "https://game.example",
+	})
+	if err != nil {
+		return "", err
+	}
+	rpIDHash := sha256.Sum256([]byte("game.example"))
+	authData := append(rpIDHash[:], 0x01, 0, 0, 0, 1) // the user present flag, and the signature counter
+	clientDataHash := sha256.Sum256(clientData)
+	signed := sha256.Sum256(bytes.Join([][]byte{authData, clientDataHash[:]}, nil))
+	sig, err := ecdsa.SignASN1(rand.Reader, w.key, signed[:])
+	if err != nil {
+		return "", err
+	}
+	return sign.WebAuthnAssertion{AuthenticatorData: authData, ClientDataJSON: clientData, Signature: sig}.Encode()
+}
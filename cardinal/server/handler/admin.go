@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"math/rand/v2"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/admin"
+	servertypes "pkg.world.dev/world-engine/cardinal/server/types"
+	"pkg.world.dev/world-engine/cardinal/types"
+	"pkg.world.dev/world-engine/sign"
+)
+
+// AdminKeyHeader is the header that carries the admin key in requests to the admin API.
+const AdminKeyHeader = "X-Admin-Key"
+
+// PostAdminOperations godoc
+//
+//	@Summary      Queues admin operations on the entity state
+//	@Description  Queues operations that create and remove entities, set their components, and add or remove
+//	@Description  components. The operations are applied in order during the next tick, and are recorded like any
+//	@Description  other transaction. The result, including the IDs of created entities, is available in the receipt
+//	@Description  of the returned transaction hash. Only served when an admin key is configured.
+//	@Accept       application/json
+//	@Produce      application/json
+//	@Param        X-Admin-Key  header    string                   true  "Admin key"
+//	@Param        operations   body      admin.Operations         true  "Operations to apply"
+//	@Success      200          {object}  PostTransactionResponse  "Transaction hash and tick"
+//	@Failure      400          {string}  string                   "Invalid operations"
+//	@Failure      401          {string}  string                   "Invalid admin key"
+//	@Router       /admin/operations [post]
+func PostAdminOperations(
+	world servertypes.ProviderWorld, adminMsg types.Message, adminKey string,
+) func(*fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		key := ctx.Get(AdminKeyHeader)
+		if subtle.ConstantTimeCompare([]byte(key), []byte(adminKey)) != 1 {
+			return fiber.NewError(fiber.StatusUnauthorized, "Unauthorized - invalid admin key")
+		}
+
+		ops := new(admin.Operations)
+		if err := ctx.BodyParser(ops); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Bad Request - unparseable body")
+		}
+		if err := validateAdminOperations(world, ops.Operations); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Bad Request - "+err.Error())
+		}
+
+		body, err := json.Marshal(ops)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "failed to encode operations")
+		}
+		msg, err := adminMsg.Decode(body)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "failed to decode operations")
+		}
+		tx := &sign.Transaction{
+			PersonaTag: admin.PersonaTag,
+			Namespace:  world.Namespace(),
+			Timestamp:  sign.TimestampNow(),
+			Salt:       uint16(rand.UintN(1 << 16)), //nolint:gosec // only used to tell transactions apart
+			Body:       body,
+		}
+		tx.HashHex() // populates the hash
+
+		tick, txHash := world.AddTransaction(adminMsg.ID(), msg, tx)
+		return ctx.JSON(&PostTransactionResponse{TxHash: string(txHash), Tick: tick})
+	}
+}
+
+// validateAdminOperations rejects operations that are certain to fail, so that the error is reported right away
+// instead of in the receipt of the next tick.
+func validateAdminOperations(world servertypes.ProviderWorld, ops []admin.Operation) error {
+	if len(ops) == 0 {
+		return eris.New("no operations")
+	}
+	for i, op := range ops {
+		var components map[string]json.RawMessage
+		switch op.Type {
+		case admin.OpCreate:
+			if len(op.Components) == 0 {
+				return eris.Errorf("operation %d: create needs at least one component", i)
+			}
+			components = op.Components
+		case admin.OpRemove:
+		case admin.OpSetComponent, admin.OpAddComponent, admin.OpRemoveComponent:
+			components = map[string]json.RawMessage{op.Component: op.Value}
+		default:
+			return eris.Errorf("operation %d: unknown type %q", i, op.Type)
+		}
+		for name, value := range components {
+			c, err := world.GetComponentByName(name)
+			if err != nil {
+				return eris.Errorf("operation %d: unknown component %q", i, name)
+			}
+			if len(value) == 0 {
+				continue
+			}
+			if _, err := c.Decode(value); err != nil {
+				return eris.Errorf("operation %d: invalid value for component %q", i, name)
+			}
+		}
+	}
+	return nil
+}
@@ -1,24 +1,34 @@
 package handler
 
 import (
+	"errors"
+
 	"github.com/gofiber/fiber/v2"
+	"github.com/rotisserie/eris"
 
+	"pkg.world.dev/world-engine/cardinal/server/handler/cql"
 	servertypes "pkg.world.dev/world-engine/cardinal/server/types"
 	"pkg.world.dev/world-engine/cardinal/types"
 )
 
 type CQLQueryRequest struct {
 	CQL string
+	// Cursor continues a query from the NextCursor of a previous response.
+	Cursor string `json:"cursor,omitempty"`
 }
 
 type CQLQueryResponse struct {
 	Results []types.EntityStateElement `json:"results"`
+	// NextCursor is set when the query has a LIMIT and there are more results.
+	NextCursor string `json:"nextCursor,omitempty"`
 }
 
 // PostCQL godoc
 //
 //	@Summary      Executes a CQL (Cardinal Query Language) query
-//	@Description  Executes a CQL (Cardinal Query Language) query
+//	@Description  Executes a CQL (Cardinal Query Language) query. Besides filtering on components, queries can
+//	@Description  compare component fields, SELECT components or fields, ORDER BY fields, and paginate with LIMIT,
+//	@Description  OFFSET, and the returned cursor.
 //	@Accept       application/json
 //	@Produce      application/json
 //	@Param        cql  body      CQLQueryRequest   true  "CQL query to be executed"
@@ -33,10 +43,14 @@ func PostCQL(
 		if err := ctx.BodyParser(req); err != nil {
 			return err
 		}
-		result, err := world.EvaluateCQL(req.CQL)
+		result, nextCursor, err := world.EvaluateCQLPage(req.CQL, req.Cursor)
 		if err != nil {
+			var parseErr *cql.ParseError
+			if errors.As(err, &parseErr) || eris.Is(err, cql.ErrInvalidCursor) {
+				return fiber.NewError(fiber.StatusBadRequest, err.Error())
+			}
 			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 		}
-		return ctx.JSON(CQLQueryResponse{Results: result})
+		return ctx.JSON(CQLQueryResponse{Results: result, NextCursor: nextCursor})
 	}
 }
@@ -3,9 +3,11 @@ package cql
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/alecthomas/participle/v2"
+	"github.com/alecthomas/participle/v2/lexer"
 	"github.com/rotisserie/eris"
 
 	"pkg.world.dev/world-engine/cardinal/filter"
@@ -20,6 +22,8 @@ const (
 var (
 	operatorMap       = map[string]cqlOperator{"&": opAnd, "|": opOr}
 	internalCQLParser = participle.MustBuild[cqlTerm]()
+	// internalQueryParser parses full queries, which can also compare component fields, select, order, and paginate.
+	internalQueryParser = participle.MustBuild[cqlQuery](participle.Unquote("String"))
 )
 
 type componentByName func(string) (types.Component, error)
@@ -44,11 +48,12 @@ type cqlTerm struct {
 }
 
 type cqlValue struct {
-	All           *cqlAll      `@("ALL" "(" ")")`
-	Exact         *cqlExact    `| @@`
-	Contains      *cqlContains `| @@`
-	Not           *cqlNot      `| @@`
-	Subexpression *cqlTerm     `| "(" @@ ")"`
+	All           *cqlAll        `@("ALL" "(" ")")`
+	Exact         *cqlExact      `| @@`
+	Contains      *cqlContains   `| @@`
+	Not           *cqlNot        `| @@`
+	Comparison    *cqlComparison `| @@`
+	Subexpression *cqlTerm       `| "(" @@ ")"`
 }
 
 type cqlFactor struct {
@@ -66,6 +71,40 @@ type cqlComponent struct {
 
 type cqlOperator int
 
+type cqlQuery struct {
+	Select  []*cqlFieldRef  `( "SELECT" @@ ( "," @@ )* "WHERE" )?`
+	Where   *cqlTerm        `@@`
+	OrderBy []*cqlOrderItem `( "ORDER" "BY" @@ ( "," @@ )* )?`
+	Limit   *int            `( "LIMIT" @Int )?`
+	Offset  *int            `( "OFFSET" @Int )?`
+}
+
+// cqlFieldRef refers to a component, or to a (nested) field of a component when Path is not empty.
+type cqlFieldRef struct {
+	Pos       lexer.Position
+	Component string   `@Ident`
+	Path      []string `( "." @Ident )*`
+}
+
+type cqlComparison struct {
+	Field    *cqlFieldRef  `@@`
+	Operator string        `( @( "<" "="? | ">" "="? | "=" "=" | "!" "=" )`
+	Value    *cqlLiteral   `  @@`
+	In       []*cqlLiteral `| "IN" "(" @@ ( "," @@ )* ")" )`
+}
+
+type cqlLiteral struct {
+	Pos    lexer.Position
+	Number *string `  @( "-"? ( Float | Int ) )`
+	Str    *string `| @String`
+	Bool   *string `| @( "true" | "false" )`
+}
+
+type cqlOrderItem struct {
+	Field *cqlFieldRef `@@`
+	Desc  bool         `( @"DESC" | "ASC" )?`
+}
+
 // Capture basically tells the parser library how to transform a string token that's parsed into the operator type.
 func (o *cqlOperator) Capture(s []string) error {
 	if len(s) == 0 {
@@ -133,12 +172,41 @@ func (v *cqlValue) String() string {
 		return v.All.String()
 	case v.Not != nil:
 		return "!(" + v.Not.SubExpression.String() + ")"
+	case v.Comparison != nil:
+		return v.Comparison.String()
 	case v.Subexpression != nil:
 		return "(" + v.Subexpression.String() + ")"
 	}
 	panic("logic error displaying CQL ast. Check the code in cql.go")
 }
 
+func (r *cqlFieldRef) String() string {
+	return strings.Join(append([]string{r.Component}, r.Path...), ".")
+}
+
+func (c *cqlComparison) String() string {
+	if c.Value != nil {
+		return fmt.Sprintf("%s %s %s", c.Field, c.Operator, c.Value)
+	}
+	values := make([]string, 0, len(c.In))
+	for _, v := range c.In {
+		values = append(values, v.String())
+	}
+	return fmt.Sprintf("%s IN (%s)", c.Field, strings.Join(values, ", "))
+}
+
+func (l *cqlLiteral) String() string {
+	switch {
+	case l.Number != nil:
+		return *l.Number
+	case l.Str != nil:
+		return strconv.Quote(*l.Str)
+	case l.Bool != nil:
+		return *l.Bool
+	}
+	panic("logic error displaying CQL literal. Check the code in cql.go")
+}
+
 func (f *cqlFactor) String() string {
 	out := f.Base.String()
 	return out
@@ -196,6 +264,9 @@ func valueToComponentFilter(value *cqlValue, stringToComponent componentByName)
 		return filter.Contains(components...), nil
 	} else if value.Subexpression != nil {
 		return termToComponentFilter(value.Subexpression, stringToComponent)
+	} else if value.Comparison != nil {
+		return nil, newParseError(value.Comparison.Field.Pos,
+			"field comparisons can only be evaluated as part of a query, use ParseQuery")
 	}
 	return nil, eris.New("unknown error during conversion from CQL AST to ComponentFilter")
 }
@@ -241,10 +312,12 @@ func termToComponentFilter(term *cqlTerm, stringToComponent componentByName) (fi
 	return acc, nil
 }
 
+// Parse parses a CQL filter expression into a ComponentFilter. Filter expressions only describe which components an
+// entity must have; use ParseQuery for queries that also compare fields, select, order, or paginate.
 func Parse(cqlText string, stringToComponent componentByName) (filter.ComponentFilter, error) {
 	term, err := internalCQLParser.ParseString("", cqlText)
 	if err != nil {
-		return nil, eris.Wrap(err, "failed to parse CQL string")
+		return nil, eris.Wrap(fromParticipleError(err), "failed to parse CQL string")
 	}
 	resultFilter, err := termToComponentFilter(term, stringToComponent)
 	if err != nil {
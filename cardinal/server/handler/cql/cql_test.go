@@ -1,9 +1,14 @@
 package cql
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"reflect"
 	"testing"
 
+	"github.com/rotisserie/eris"
+
 	"pkg.world.dev/world-engine/assert"
 	"pkg.world.dev/world-engine/cardinal/filter"
 	"pkg.world.dev/world-engine/cardinal/types"
@@ -123,3 +128,124 @@ func TestParser(t *testing.T) {
 	testResult2 = filter.All()
 	assert.Assert(t, reflect.DeepEqual(result, testResult2))
 }
+
+type namedComponent string
+
+func (n namedComponent) Name() string { return string(n) }
+
+func TestParseQueryReportsErrorPositions(t *testing.T) {
+	stringToComponent := func(name string) (types.Component, error) {
+		if name != "health" {
+			return nil, eris.Errorf("component %q not found", name)
+		}
+		return namedComponent(name), nil
+	}
+
+	_, err := ParseQuery("CONTAINS(health) & health.HP >", stringToComponent)
+	var parseErr *ParseError
+	assert.Assert(t, errors.As(err, &parseErr))
+	assert.Equal(t, parseErr.Pos.Column, 31)
+
+	_, err = ParseQuery("CONTAINS(health) & player.Name == \"x\"", stringToComponent)
+	assert.Assert(t, errors.As(err, &parseErr))
+	assert.Equal(t, parseErr.Pos.Column, 20)
+
+	_, err = ParseQuery("CONTAINS(health) ORDER BY health", stringToComponent)
+	assert.Assert(t, errors.As(err, &parseErr))
+}
+
+func TestQueryComparesSelectsAndPaginates(t *testing.T) {
+	health := namedComponent("health")
+	player := namedComponent("player")
+	stringToComponent := func(name string) (types.Component, error) {
+		switch name {
+		case "health":
+			return health, nil
+		case "player":
+			return player, nil
+		}
+		return nil, eris.Errorf("component %q not found", name)
+	}
+
+	query, err := ParseQuery(
+		`SELECT health.HP, player WHERE health.HP < 10 & player.Name IN ("a", "b") ORDER BY health.HP DESC LIMIT 2`,
+		stringToComponent,
+	)
+	assert.NilError(t, err)
+
+	var matches []*Entity
+	for i := 0; i < 8; i++ {
+		name := "a"
+		if i%4 == 3 {
+			name = "c"
+		}
+		entity := &Entity{
+			ID:         types.EntityID(i),
+			Components: []types.Component{health, player},
+			Data: []json.RawMessage{
+				json.RawMessage(fmt.Sprintf(`{"HP":%d,"Max":100}`, i*2)),
+				json.RawMessage(fmt.Sprintf(`{"Name":%q}`, name)),
+			},
+		}
+		ok, err := query.Matches(entity)
+		assert.NilError(t, err)
+		if ok {
+			matches = append(matches, entity)
+		}
+	}
+	// HP 0, 2, 4, and 8 match; HP 6 has the wrong name and 10 or more is too high
+	assert.Equal(t, len(matches), 4)
+
+	page, cursor, err := query.Paginate(matches, "")
+	assert.NilError(t, err)
+	assert.Equal(t, len(page), 2)
+	assert.Equal(t, page[0].ID, types.EntityID(4))
+	assert.Equal(t, page[1].ID, types.EntityID(2))
+	assert.Check(t, cursor != "")
+
+	data, err := query.Project(page[0])
+	assert.NilError(t, err)
+	assert.Equal(t, len(data), 2)
+	assert.Equal(t, string(data[0]), `{"Name":"a"}`)
+	assert.Equal(t, string(data[1]), `{"HP":8}`)
+
+	page, cursor, err = query.Paginate(matches, cursor)
+	assert.NilError(t, err)
+	assert.Equal(t, len(page), 2)
+	assert.Equal(t, page[0].ID, types.EntityID(1))
+	assert.Equal(t, page[1].ID, types.EntityID(0))
+	assert.Equal(t, cursor, "")
+
+	_, _, err = query.Paginate(matches, "not a cursor")
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestPageScanIsBoundedForQueriesSortedByID(t *testing.T) {
+	health := namedComponent("health")
+	stringToComponent := func(string) (types.Component, error) { return health, nil }
+
+	query, err := ParseQuery("health.HP < 10 LIMIT 2 OFFSET 1", stringToComponent)
+	assert.NilError(t, err)
+	scan, err := query.PageScan("")
+	assert.NilError(t, err)
+	assert.Equal(t, scan, PageScan{MaxMatches: 4})
+
+	matches := []*Entity{{ID: 3}, {ID: 5}, {ID: 8}, {ID: 9}}
+	_, cursor, err := query.Paginate(matches, "")
+	assert.NilError(t, err)
+	scan, err = query.PageScan(cursor)
+	assert.NilError(t, err)
+	assert.Equal(t, scan, PageScan{After: 8, HasAfter: true, MaxMatches: 4})
+
+	_, err = query.PageScan("not a cursor")
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	// Every match is needed to sort by a field, or to return all of them
+	for _, cqlText := range []string{"health.HP < 10 ORDER BY health.HP LIMIT 2", "health.HP < 10"} {
+		query, err = ParseQuery(cqlText, stringToComponent)
+		assert.NilError(t, err)
+		scan, err = query.PageScan("")
+		assert.NilError(t, err)
+		assert.Equal(t, scan, PageScan{})
+	}
+}
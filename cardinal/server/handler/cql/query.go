@@ -0,0 +1,646 @@
+package cql
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+
+	"github.com/alecthomas/participle/v2"
+	"github.com/alecthomas/participle/v2/lexer"
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/filter"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+// ErrInvalidCursor is returned when a cursor passed to Paginate was not returned by a previous call.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ParseError is returned when a CQL string is not a valid query. Pos points at the offending token.
+type ParseError struct {
+	Pos lexer.Position
+	Msg string
+}
+
+func newParseError(pos lexer.Position, msg string) *ParseError {
+	return &ParseError{Pos: pos, Msg: msg}
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Pos.Line, e.Pos.Column, e.Msg)
+}
+
+func fromParticipleError(err error) error {
+	var participleErr participle.Error
+	if errors.As(err, &participleErr) {
+		return newParseError(participleErr.Position(), participleErr.Message())
+	}
+	return err
+}
+
+// Query is a parsed CQL query.
+//
+// A query is a filter expression that may also compare component fields, optionally preceded by the components or
+// fields to SELECT, and followed by ORDER BY, LIMIT, and OFFSET clauses:
+//
+//	SELECT Health.HP, Player WHERE CONTAINS(Player) & Health.HP < 10 & Player.Name IN ("a", "b")
+//	ORDER BY Health.HP DESC LIMIT 20 OFFSET 40
+//
+// Comparing a field is only true for entities that have the component and whose field has a value of the same type.
+// Field comparisons are evaluated per entity by Matches, as there are no field indexes to look them up in.
+type Query struct {
+	// Filter selects the archetypes that can contain matching entities. When the query compares fields, entities of
+	// these archetypes must still be checked with Matches.
+	Filter filter.ComponentFilter
+	// Limit is the maximum number of entities in a page. Zero means there is no limit.
+	Limit int
+	// Offset is the number of matching entities to skip before the page starts.
+	Offset int
+
+	matcher matcher
+	selects []*fieldRef
+	orderBy []orderKey
+}
+
+// Entity is the state of an entity that a Query is evaluated against. Data holds the raw JSON of each of Components.
+type Entity struct {
+	ID         types.EntityID
+	Components []types.Component
+	Data       []json.RawMessage
+
+	decoded map[string]any
+}
+
+type fieldRef struct {
+	component types.Component
+	path      []string
+}
+
+type orderKey struct {
+	field *fieldRef
+	desc  bool
+}
+
+// ParseQuery parses a CQL query. Syntax errors and references to unknown components are returned as a *ParseError.
+func ParseQuery(cqlText string, stringToComponent componentByName) (*Query, error) {
+	parsed, err := internalQueryParser.ParseString("", cqlText)
+	if err != nil {
+		return nil, fromParticipleError(err)
+	}
+
+	q := &Query{}
+	var hasComparisons bool
+	q.Filter, q.matcher, hasComparisons, err = compileTerm(parsed.Where, stringToComponent)
+	if err != nil {
+		return nil, err
+	}
+	if !hasComparisons {
+		// The archetype filter is exact, so there is nothing left to check for each entity.
+		q.matcher = nil
+	}
+
+	for _, ref := range parsed.Select {
+		field, err := resolveFieldRef(ref, stringToComponent)
+		if err != nil {
+			return nil, err
+		}
+		q.selects = append(q.selects, field)
+	}
+	for _, item := range parsed.OrderBy {
+		if len(item.Field.Path) == 0 {
+			return nil, newParseError(item.Field.Pos, "ORDER BY requires a component field")
+		}
+		field, err := resolveFieldRef(item.Field, stringToComponent)
+		if err != nil {
+			return nil, err
+		}
+		q.orderBy = append(q.orderBy, orderKey{field: field, desc: item.Desc})
+	}
+	if parsed.Limit != nil {
+		if *parsed.Limit < 0 {
+			return nil, eris.New("LIMIT must not be negative")
+		}
+		q.Limit = *parsed.Limit
+	}
+	if parsed.Offset != nil {
+		if *parsed.Offset < 0 {
+			return nil, eris.New("OFFSET must not be negative")
+		}
+		q.Offset = *parsed.Offset
+	}
+	return q, nil
+}
+
+// Matches returns true if the entity, which must belong to an archetype matched by Filter, matches the query.
+func (q *Query) Matches(e *Entity) (bool, error) {
+	if q.matcher == nil {
+		return true, nil
+	}
+	return q.matcher.matches(e)
+}
+
+// Project returns the data of the entity that the query selects. Without a SELECT clause all components are
+// returned. Selecting fields returns each selected component with only the selected fields.
+func (q *Query) Project(e *Entity) ([]json.RawMessage, error) {
+	if len(q.selects) == 0 {
+		return e.Data, nil
+	}
+
+	result := make([]json.RawMessage, 0, len(q.selects))
+	partials := map[string]map[string]any{}
+	order := make([]string, 0, len(q.selects))
+	for _, sel := range q.selects {
+		name := sel.component.Name()
+		if len(sel.path) == 0 {
+			data, ok := e.raw(name)
+			if !ok {
+				continue
+			}
+			result = append(result, data)
+			continue
+		}
+		value, ok, err := e.field(sel)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if _, ok := partials[name]; !ok {
+			partials[name] = map[string]any{}
+			order = append(order, name)
+		}
+		setPath(partials[name], sel.path, value)
+	}
+	for _, name := range order {
+		bz, err := json.Marshal(partials[name])
+		if err != nil {
+			return nil, eris.Wrap(err, "failed to marshal selected fields")
+		}
+		result = append(result, bz)
+	}
+	return result, nil
+}
+
+type cursor struct {
+	Keys []any          `json:"k"`
+	ID   types.EntityID `json:"id"`
+}
+
+// Paginate sorts the matched entities and returns the requested page of them. Entities are sorted by the ORDER BY
+// clause, and by entity ID otherwise. When a cursor returned by a previous call is passed in, the page starts right
+// after the last entity of the previous page, so that pages stay consistent while entities are created or removed.
+// The returned cursor is empty when there are no more pages.
+func (q *Query) Paginate(entities []*Entity, after string) ([]*Entity, string, error) {
+	rows := make([]cursor, len(entities))
+	for i, e := range entities {
+		row, err := q.sortKey(e)
+		if err != nil {
+			return nil, "", err
+		}
+		rows[i] = row
+	}
+	idx := make([]int, len(entities))
+	for i := range idx {
+		idx[i] = i
+	}
+	slices.SortStableFunc(idx, func(a, b int) int {
+		return q.compareRows(rows[a], rows[b])
+	})
+
+	start := 0
+	if after != "" {
+		last, err := decodeCursor(after)
+		if err != nil {
+			return nil, "", err
+		}
+		start, _ = slices.BinarySearchFunc(idx, last, func(i int, target cursor) int {
+			if q.compareRows(rows[i], target) <= 0 {
+				return -1
+			}
+			return 1
+		})
+	}
+	start += q.Offset
+	if start > len(idx) {
+		start = len(idx)
+	}
+	end := len(idx)
+	if q.Limit > 0 && start+q.Limit < end {
+		end = start + q.Limit
+	}
+
+	page := make([]*Entity, 0, end-start)
+	for _, i := range idx[start:end] {
+		page = append(page, entities[i])
+	}
+	if end == len(idx) || end == start {
+		return page, "", nil
+	}
+	next, err := encodeCursor(rows[idx[end-1]])
+	if err != nil {
+		return nil, "", err
+	}
+	return page, next, nil
+}
+
+// PageScan bounds the matching entities that Paginate needs to find a page, when entities are scanned in ascending
+// ID order.
+type PageScan struct {
+	// After is the ID of the last entity of the previous page. Only entities after it are needed if HasAfter is set.
+	After    types.EntityID
+	HasAfter bool
+	// MaxMatches is the number of matching entities after which the scan can stop. Zero means every matching entity
+	// is needed.
+	MaxMatches int
+}
+
+// PageScan returns the matching entities that Paginate needs to find the page after the given cursor, and to know
+// whether another page follows it. The scan can only be bounded when entities are sorted by ID, that is when the
+// query has a LIMIT and no ORDER BY clause.
+func (q *Query) PageScan(after string) (PageScan, error) {
+	if len(q.orderBy) > 0 || q.Limit == 0 {
+		return PageScan{}, nil
+	}
+	scan := PageScan{MaxMatches: q.Offset + q.Limit + 1}
+	if after != "" {
+		last, err := decodeCursor(after)
+		if err != nil {
+			return PageScan{}, err
+		}
+		scan.After, scan.HasAfter = last.ID, true
+	}
+	return scan, nil
+}
+
+func (q *Query) sortKey(e *Entity) (cursor, error) {
+	row := cursor{ID: e.ID, Keys: make([]any, len(q.orderBy))}
+	for i, key := range q.orderBy {
+		value, _, err := e.field(key.field)
+		if err != nil {
+			return cursor{}, err
+		}
+		row.Keys[i] = value
+	}
+	return row, nil
+}
+
+// compareRows orders rows by the ORDER BY keys, breaking ties by entity ID. Missing values are always sorted last.
+func (q *Query) compareRows(a, b cursor) int {
+	for i, key := range q.orderBy {
+		if i >= len(a.Keys) || i >= len(b.Keys) {
+			break
+		}
+		aValue, bValue := a.Keys[i], b.Keys[i]
+		switch {
+		case aValue == nil && bValue == nil:
+			continue
+		case aValue == nil:
+			return 1
+		case bValue == nil:
+			return -1
+		}
+		c := compareOrdered(aValue, bValue)
+		if key.desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	switch {
+	case a.ID < b.ID:
+		return -1
+	case a.ID > b.ID:
+		return 1
+	}
+	return 0
+}
+
+func encodeCursor(row cursor) (string, error) {
+	bz, err := json.Marshal(row)
+	if err != nil {
+		return "", eris.Wrap(err, "failed to encode cursor")
+	}
+	return base64.RawURLEncoding.EncodeToString(bz), nil
+}
+
+func decodeCursor(s string) (cursor, error) {
+	bz, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor{}, eris.Wrap(ErrInvalidCursor, err.Error())
+	}
+	var row cursor
+	dec := json.NewDecoder(bytes.NewReader(bz))
+	dec.UseNumber()
+	if err := dec.Decode(&row); err != nil {
+		return cursor{}, eris.Wrap(ErrInvalidCursor, err.Error())
+	}
+	return row, nil
+}
+
+func (e *Entity) raw(componentName string) (json.RawMessage, bool) {
+	for i, c := range e.Components {
+		if c.Name() == componentName && i < len(e.Data) {
+			return e.Data[i], true
+		}
+	}
+	return nil, false
+}
+
+// field returns the value of the referenced field, or false if the entity doesn't have it.
+func (e *Entity) field(ref *fieldRef) (any, bool, error) {
+	name := ref.component.Name()
+	if e.decoded == nil {
+		e.decoded = map[string]any{}
+	}
+	value, ok := e.decoded[name]
+	if !ok {
+		data, found := e.raw(name)
+		if !found {
+			return nil, false, nil
+		}
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		if err := dec.Decode(&value); err != nil {
+			return nil, false, eris.Wrapf(err, "failed to decode component %q of entity %d", name, e.ID)
+		}
+		e.decoded[name] = value
+	}
+	for _, key := range ref.path {
+		obj, isObject := value.(map[string]any)
+		if !isObject {
+			return nil, false, nil
+		}
+		if value, ok = obj[key]; !ok {
+			return nil, false, nil
+		}
+	}
+	return value, true, nil
+}
+
+func setPath(obj map[string]any, path []string, value any) {
+	for _, key := range path[:len(path)-1] {
+		next, ok := obj[key].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			obj[key] = next
+		}
+		obj = next
+	}
+	obj[path[len(path)-1]] = value
+}
+
+func resolveFieldRef(ref *cqlFieldRef, stringToComponent componentByName) (*fieldRef, error) {
+	comp, err := stringToComponent(ref.Component)
+	if err != nil {
+		return nil, newParseError(ref.Pos, fmt.Sprintf("unknown component %q", ref.Component))
+	}
+	return &fieldRef{component: comp, path: ref.Path}, nil
+}
+
+// matcher checks a single entity against (part of) a query.
+type matcher interface {
+	matches(e *Entity) (bool, error)
+}
+
+type filterMatcher struct{ filter filter.ComponentFilter }
+
+type notMatcher struct{ matcher matcher }
+
+type binaryMatcher struct {
+	operator    cqlOperator
+	left, right matcher
+}
+
+type comparisonMatcher struct {
+	field    *fieldRef
+	operator string
+	values   []any
+}
+
+func (m filterMatcher) matches(e *Entity) (bool, error) {
+	return m.filter.MatchesComponents(e.Components), nil
+}
+
+func (m notMatcher) matches(e *Entity) (bool, error) {
+	ok, err := m.matcher.matches(e)
+	return !ok, err
+}
+
+func (m binaryMatcher) matches(e *Entity) (bool, error) {
+	left, err := m.left.matches(e)
+	if err != nil {
+		return false, err
+	}
+	// short circuit
+	if (m.operator == opAnd && !left) || (m.operator == opOr && left) {
+		return left, nil
+	}
+	return m.right.matches(e)
+}
+
+func (m comparisonMatcher) matches(e *Entity) (bool, error) {
+	value, ok, err := e.field(m.field)
+	if err != nil || !ok {
+		return false, err
+	}
+	if m.operator == "IN" {
+		for _, v := range m.values {
+			if c, comparable := compareValues(value, v); comparable && c == 0 {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	c, comparable := compareValues(value, m.values[0])
+	if !comparable {
+		return false, nil
+	}
+	switch m.operator {
+	case "==":
+		return c == 0, nil
+	case "!=":
+		return c != 0, nil
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	case ">=":
+		return c >= 0, nil
+	}
+	return false, eris.Errorf("unknown comparison operator %q", m.operator)
+}
+
+// compileTerm turns a term into a matcher for single entities, together with a filter that selects every archetype
+// that may contain matching entities. Field comparisons can only be decided per entity, so the filter is exact only
+// when the term doesn't compare any fields.
+func compileTerm(term *cqlTerm, stringToComponent componentByName) (
+	filter.ComponentFilter, matcher, bool, error,
+) {
+	if term.Left == nil {
+		return nil, nil, false, eris.New("not enough values in expression")
+	}
+	accFilter, accMatcher, hasComparisons, err := compileValue(term.Left.Base, stringToComponent)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	for _, opFactor := range term.Right {
+		f, m, comparisons, err := compileValue(opFactor.Factor.Base, stringToComponent)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		switch opFactor.Operator {
+		case opAnd:
+			accFilter = filter.And(accFilter, f)
+		case opOr:
+			accFilter = filter.Or(accFilter, f)
+		default:
+			return nil, nil, false, eris.New("invalid operator")
+		}
+		accMatcher = binaryMatcher{operator: opFactor.Operator, left: accMatcher, right: m}
+		hasComparisons = hasComparisons || comparisons
+	}
+	return accFilter, accMatcher, hasComparisons, nil
+}
+
+func compileValue(value *cqlValue, stringToComponent componentByName) (
+	filter.ComponentFilter, matcher, bool, error,
+) {
+	switch {
+	case value.Comparison != nil:
+		m, err := compileComparison(value.Comparison, stringToComponent)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		// Only entities that have the component can match the comparison.
+		return filter.Contains(filter.ComponentWrapper{Component: m.field.component}), m, true, nil
+	case value.Not != nil:
+		f, m, hasComparisons, err := compileValue(value.Not.SubExpression, stringToComponent)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		if hasComparisons {
+			// The complement of an inexact filter would exclude archetypes that may still match.
+			return filter.All(), notMatcher{matcher: m}, true, nil
+		}
+		return filter.Not(f), notMatcher{matcher: m}, false, nil
+	case value.Subexpression != nil:
+		return compileTerm(value.Subexpression, stringToComponent)
+	default:
+		f, err := valueToComponentFilter(value, stringToComponent)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		return f, filterMatcher{filter: f}, false, nil
+	}
+}
+
+func compileComparison(c *cqlComparison, stringToComponent componentByName) (*comparisonMatcher, error) {
+	if len(c.Field.Path) == 0 {
+		return nil, newParseError(c.Field.Pos, "comparisons require a component field")
+	}
+	field, err := resolveFieldRef(c.Field, stringToComponent)
+	if err != nil {
+		return nil, err
+	}
+	m := &comparisonMatcher{field: field, operator: c.Operator}
+	literals := c.In
+	if c.Value != nil {
+		literals = []*cqlLiteral{c.Value}
+	} else {
+		m.operator = "IN"
+	}
+	for _, literal := range literals {
+		v, err := literal.value()
+		if err != nil {
+			return nil, err
+		}
+		m.values = append(m.values, v)
+	}
+	return m, nil
+}
+
+func (l *cqlLiteral) value() (any, error) {
+	switch {
+	case l.Number != nil:
+		if _, ok := new(big.Float).SetString(*l.Number); !ok {
+			return nil, newParseError(l.Pos, fmt.Sprintf("invalid number %q", *l.Number))
+		}
+		return json.Number(*l.Number), nil
+	case l.Str != nil:
+		return *l.Str, nil
+	case l.Bool != nil:
+		return *l.Bool == "true", nil
+	}
+	return nil, newParseError(l.Pos, "missing value")
+}
+
+// compareValues compares two decoded JSON values. Values of different types are not comparable.
+func compareValues(a, b any) (int, bool) {
+	switch a := a.(type) {
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return 0, false
+		}
+		aFloat, aOK := new(big.Float).SetString(a.String())
+		bFloat, bOK := new(big.Float).SetString(b.String())
+		if !aOK || !bOK {
+			return 0, false
+		}
+		return aFloat.Cmp(bFloat), true
+	case string:
+		b, ok := b.(string)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case a < b:
+			return -1, true
+		case a > b:
+			return 1, true
+		}
+		return 0, true
+	case bool:
+		b, ok := b.(bool)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case a == b:
+			return 0, true
+		case !a:
+			return -1, true
+		}
+		return 1, true
+	}
+	return 0, false
+}
+
+// typeRank orders values of different types when sorting: numbers, then strings, then booleans, then everything else.
+func typeRank(v any) int {
+	switch v.(type) {
+	case json.Number:
+		return 0
+	case string:
+		return 1
+	case bool:
+		return 2 //nolint:mnd // rank
+	}
+	return 3 //nolint:mnd // rank
+}
+
+// compareOrdered is a total order over decoded JSON values used for sorting.
+func compareOrdered(a, b any) int {
+	if c, ok := compareValues(a, b); ok {
+		return c
+	}
+	return typeRank(a) - typeRank(b)
+}
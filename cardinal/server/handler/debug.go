@@ -1,31 +1,328 @@
 package handler
 
 import (
+	"bufio"
+	"encoding/json"
+	"slices"
+	"strconv"
+
 	"github.com/gofiber/fiber/v2"
+	"github.com/rotisserie/eris"
+	"github.com/rs/zerolog"
 
+	"pkg.world.dev/world-engine/cardinal/gamestate"
 	servertypes "pkg.world.dev/world-engine/cardinal/server/types"
 	"pkg.world.dev/world-engine/cardinal/types"
 )
 
-type DebugStateRequest struct{}
+const (
+	defaultDebugStatePageSize = 1000
+	maxDebugStatePageSize     = 10000
+
+	// debugStateFlushInterval is the number of entities written to a /debug/state/stream response between flushes.
+	debugStateFlushInterval = 100
+)
+
+type DebugStateRequest struct {
+	// Limit is the maximum number of entities in a page. When neither Limit nor Cursor are set, the whole state is
+	// returned as a list instead of a page.
+	Limit int `json:"limit,omitempty"`
+	// Cursor continues from the NextCursor of a previous page.
+	Cursor string `json:"cursor,omitempty"`
+}
 
 type DebugStateResponse = []types.DebugStateElement
 
+// DebugStatePageResponse is a page of entities, ordered by entity ID.
+type DebugStatePageResponse struct {
+	Entities []types.DebugStateElement `json:"entities"`
+	// NextCursor is set when there are more entities after this page.
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// DebugEntityResponse is an entity with all of its components.
+type DebugEntityResponse struct {
+	ID          types.EntityID             `json:"id"`
+	ArchetypeID types.ArchetypeID          `json:"archetypeId"`
+	Components  map[string]json.RawMessage `json:"components" swaggertype:"object"`
+}
+
+// DebugArchetype is an archetype with its components and the number of entities that belong to it.
+type DebugArchetype struct {
+	ID          types.ArchetypeID `json:"id"`
+	Components  []DebugComponent  `json:"components"`
+	EntityCount int               `json:"entityCount"`
+}
+
+type DebugComponent struct {
+	ID   types.ComponentID `json:"id"`
+	Name string            `json:"name"`
+}
+
 // GetState godoc
 //
-// @Summary      Retrieves a list of all entities in the game state
-// @Description  Retrieves a list of all entities in the game state
+// @Summary      Retrieves the entities in the game state
+// @Description  Retrieves the entities in the game state. Setting a limit or a cursor returns a page of entities
+// @Description  ordered by ID, together with the cursor of the next page. Without either, all entities are returned
+// @Description  as a list, which can be slow on large worlds.
+// @Accept       application/json
 // @Produce      application/json
-// @Success      200  {object}  DebugStateResponse "List of all entities"
+// @Param        page  body      DebugStateRequest       false  "Page of the state to retrieve"
+// @Success      200   {object}  DebugStatePageResponse  "A page of entities, or a list of all entities"
+// @Failure      400   {string}  string                  "Invalid request parameters"
 // @Router       /debug/state [post]
 func GetState(world servertypes.ProviderWorld) func(*fiber.Ctx) error {
 	return func(ctx *fiber.Ctx) error {
-		var result DebugStateResponse
-		result, err := world.GetDebugState()
+		req := new(DebugStateRequest)
+		if len(ctx.Body()) > 0 {
+			if err := ctx.BodyParser(req); err != nil {
+				return fiber.NewError(fiber.StatusBadRequest, "Bad Request - unparseable body")
+			}
+		}
+
+		if req.Limit == 0 && req.Cursor == "" {
+			var result DebugStateResponse
+			result, err := world.GetDebugState()
+			if err != nil {
+				return err
+			}
+			return ctx.JSON(&result)
+		}
+
+		limit := req.Limit
+		if limit <= 0 {
+			limit = defaultDebugStatePageSize
+		}
+		limit = min(limit, maxDebugStatePageSize)
+		var after *types.EntityID
+		if req.Cursor != "" {
+			id, err := strconv.ParseUint(req.Cursor, 10, 64)
+			if err != nil {
+				return fiber.NewError(fiber.StatusBadRequest, "Bad Request - invalid cursor")
+			}
+			cursorID := types.EntityID(id)
+			after = &cursorID
+		}
+
+		res, err := debugStatePage(world.StoreReader(), after, limit)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+		return ctx.JSON(res)
+	}
+}
+
+// StreamState godoc
+//
+// @Summary      Streams all entities in the game state
+// @Description  Streams all entities in the game state as newline delimited JSON, one entity per line. Entities are
+// @Description  read while the world keeps ticking, so the stream is not a consistent snapshot of a single tick.
+// @Produce      application/x-ndjson
+// @Success      200  {object}  types.DebugStateElement  "One entity per line"
+// @Router       /debug/state/stream [get]
+func StreamState(world servertypes.ProviderWorld, logger *zerolog.Logger) func(*fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		reader := world.StoreReader()
+		ctx.Set(fiber.HeaderContentType, "application/x-ndjson")
+		ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			enc := json.NewEncoder(w)
+			written := 0
+			err := eachEntity(reader, func(id types.EntityID) error {
+				element, ok, err := debugStateElement(reader, id)
+				if err != nil || !ok {
+					return err
+				}
+				if err := enc.Encode(element); err != nil {
+					return eris.Wrap(err, "failed to write entity")
+				}
+				written++
+				if written%debugStateFlushInterval == 0 {
+					return eris.Wrap(w.Flush(), "failed to flush entities")
+				}
+				return nil
+			})
+			if err != nil {
+				logger.Error().Err(err).Msg("failed to stream debug state")
+			}
+			if err := w.Flush(); err != nil {
+				logger.Debug().Err(err).Msg("failed to flush debug state stream")
+			}
+		})
+		return nil
+	}
+}
+
+// GetEntity godoc
+//
+// @Summary      Retrieves an entity with all of its components
+// @Description  Retrieves an entity with all of its components
+// @Produce      application/json
+// @Param        id   path      integer              true  "ID of the entity"
+// @Success      200  {object}  DebugEntityResponse  "The entity and its components"
+// @Failure      400  {string}  string               "Invalid entity ID"
+// @Failure      404  {string}  string               "Entity not found"
+// @Router       /debug/entity/{id} [get]
+func GetEntity(world servertypes.ProviderWorld) func(*fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		id, err := strconv.ParseUint(ctx.Params("id"), 10, 64)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Bad Request - invalid entity id")
+		}
+
+		reader := world.StoreReader()
+		element, ok, err := debugStateElement(reader, types.EntityID(id))
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		} else if !ok {
+			return fiber.NewError(fiber.StatusNotFound, "entity not found")
+		}
+		components, err := reader.GetComponentTypesForEntity(element.ID)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+		archID, err := reader.GetArchIDForComponents(components)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+		return ctx.JSON(DebugEntityResponse{ID: element.ID, ArchetypeID: archID, Components: element.Components})
+	}
+}
+
+// GetArchetypes godoc
+//
+// @Summary      Lists all archetypes with their components and entity counts
+// @Description  Lists all archetypes with their components and entity counts
+// @Produce      application/json
+// @Success      200  {object}  []DebugArchetype  "List of all archetypes"
+// @Router       /debug/archetypes [get]
+func GetArchetypes(world servertypes.ProviderWorld) func(*fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		reader := world.StoreReader()
+		count := reader.ArchetypeCount()
+		res := make([]DebugArchetype, 0, count)
+		for i := 0; i < count; i++ {
+			archetype, err := debugArchetype(reader, types.ArchetypeID(i))
+			if err != nil {
+				return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+			}
+			res = append(res, archetype)
+		}
+		return ctx.JSON(res)
+	}
+}
+
+// GetArchetype godoc
+//
+// @Summary      Retrieves the components of an archetype
+// @Description  Retrieves the components of an archetype and the number of entities that belong to it
+// @Produce      application/json
+// @Param        id   path      integer         true  "ID of the archetype"
+// @Success      200  {object}  DebugArchetype  "The archetype"
+// @Failure      400  {string}  string          "Invalid archetype ID"
+// @Failure      404  {string}  string          "Archetype not found"
+// @Router       /debug/archetypes/{id} [get]
+func GetArchetype(world servertypes.ProviderWorld) func(*fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		id, err := strconv.Atoi(ctx.Params("id"))
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Bad Request - invalid archetype id")
+		}
+		reader := world.StoreReader()
+		if id < 0 || id >= reader.ArchetypeCount() {
+			return fiber.NewError(fiber.StatusNotFound, "archetype not found")
+		}
+		archetype, err := debugArchetype(reader, types.ArchetypeID(id))
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+		return ctx.JSON(archetype)
+	}
+}
+
+// debugStatePage returns up to limit entities with an ID greater than after, ordered by ID. Only the entity IDs of
+// the whole state are loaded; components are only loaded for the entities in the page.
+func debugStatePage(reader gamestate.Reader, after *types.EntityID, limit int) (DebugStatePageResponse, error) {
+	ids := make([]types.EntityID, 0)
+	err := eachEntity(reader, func(id types.EntityID) error {
+		if after == nil || id > *after {
+			ids = append(ids, id)
+		}
+		return nil
+	})
+	if err != nil {
+		return DebugStatePageResponse{}, err
+	}
+	slices.Sort(ids)
+
+	res := DebugStatePageResponse{Entities: make([]types.DebugStateElement, 0, min(limit, len(ids)))}
+	if len(ids) > limit {
+		ids = ids[:limit]
+		res.NextCursor = strconv.FormatUint(uint64(ids[limit-1]), 10)
+	}
+	for _, id := range ids {
+		element, ok, err := debugStateElement(reader, id)
+		if err != nil {
+			return DebugStatePageResponse{}, err
+		}
+		// Entities removed by a tick since the IDs were collected are skipped.
+		if ok {
+			res.Entities = append(res.Entities, element)
+		}
+	}
+	return res, nil
+}
+
+// eachEntity calls fn with the ID of every entity, archetype by archetype.
+func eachEntity(reader gamestate.Reader, fn func(id types.EntityID) error) error {
+	for i := 0; i < reader.ArchetypeCount(); i++ {
+		ids, err := reader.GetEntitiesForArchID(types.ArchetypeID(i))
 		if err != nil {
 			return err
 		}
+		for _, id := range ids {
+			if err := fn(id); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// debugStateElement loads all components of the given entity. It returns false if the entity does not exist.
+func debugStateElement(reader gamestate.Reader, id types.EntityID) (types.DebugStateElement, bool, error) {
+	components, err := reader.GetComponentTypesForEntity(id)
+	if eris.Is(err, gamestate.ErrEntityDoesNotExist) {
+		return types.DebugStateElement{}, false, nil
+	} else if err != nil {
+		return types.DebugStateElement{}, false, err
+	}
+	element := types.DebugStateElement{ID: id, Components: make(map[string]json.RawMessage, len(components))}
+	for _, c := range components {
+		data, err := reader.GetComponentForEntityInRawJSON(c, id)
+		if err != nil {
+			return types.DebugStateElement{}, false, err
+		}
+		element.Components[c.Name()] = data
+	}
+	return element, true, nil
+}
 
-		return ctx.JSON(&result)
+func debugArchetype(reader gamestate.Reader, archID types.ArchetypeID) (DebugArchetype, error) {
+	components, err := reader.GetComponentTypesForArchID(archID)
+	if err != nil {
+		return DebugArchetype{}, err
+	}
+	ids, err := reader.GetEntitiesForArchID(archID)
+	if err != nil {
+		return DebugArchetype{}, err
+	}
+	archetype := DebugArchetype{
+		ID:          archID,
+		Components:  make([]DebugComponent, 0, len(components)),
+		EntityCount: len(ids),
+	}
+	for _, c := range components {
+		archetype.Components = append(archetype.Components, DebugComponent{ID: c.ID(), Name: c.Name()})
 	}
+	return archetype, nil
 }
@@ -1,22 +1,109 @@
 package handler
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync"
+
 	"github.com/gofiber/contrib/socketio"
 	"github.com/gofiber/contrib/websocket"
 	"github.com/gofiber/fiber/v2"
-	"github.com/rs/zerolog/log"
+	"github.com/rs/zerolog"
+
+	servertypes "pkg.world.dev/world-engine/cardinal/server/types"
+	"pkg.world.dev/world-engine/cardinal/types"
+	"pkg.world.dev/world-engine/sign"
+)
+
+const (
+	// WebSocketRequestChallenge asks the server for a new challenge to sign.
+	WebSocketRequestChallenge = "challenge"
+	// WebSocketRequestAuthenticate submits a signed challenge to authenticate the connection as a persona.
+	WebSocketRequestAuthenticate = "authenticate"
+	// WebSocketResponseAuthenticated is sent back after the connection was successfully authenticated.
+	WebSocketResponseAuthenticated = "authenticated"
+	// WebSocketRequestSubscribe registers a live CQL query under a client chosen subscription id.
+	WebSocketRequestSubscribe = "subscribe"
+	// WebSocketRequestUnsubscribe removes a live CQL query.
+	WebSocketRequestUnsubscribe = "unsubscribe"
+	// WebSocketResponseError is sent back when a request over the websocket could not be handled.
+	WebSocketResponseError = "error"
+	// WebSocketResponseSubscribed is sent back with the initial result set of a new live CQL query.
+	WebSocketResponseSubscribed = "subscribed"
+	// WebSocketResponseUnsubscribed is sent back after a live CQL query was removed.
+	WebSocketResponseUnsubscribed = "unsubscribed"
+	// WebSocketResponseCQLDiff is sent after every tick that changed the result set of a live CQL query.
+	WebSocketResponseCQLDiff = "cqlDiff"
+
+	challengeSizeBytes = 32
+
+	sessionHandlerAttribute = "sessionHandler"
+	challengeAttribute      = "challenge"
 )
 
+var (
+	errNoChallenge               = errors.New("no outstanding challenge, request one first")
+	errNoPersonaTag              = errors.New("persona tag is required")
+	errUnknownPersona            = errors.New("persona tag does not have a signer")
+	errInvalidChallengeSignature = errors.New("challenge signature validation failed")
+)
+
+// registerListenersOnce guards the socketio listeners, which are registered process wide. Each connection carries its
+// own sessionHandler as an attribute, so the listeners dispatch to the server that accepted the connection.
+var registerListenersOnce sync.Once
+
+// WebSocketRequest is a message sent by a client over the /events websocket.
+type WebSocketRequest struct {
+	Type       string `json:"type"`
+	PersonaTag string `json:"personaTag,omitempty"`
+	Signature  string `json:"signature,omitempty"`
+	// SubscriptionID identifies a live CQL query within the connection.
+	SubscriptionID string `json:"subscriptionId,omitempty"`
+	CQL            string `json:"cql,omitempty"`
+}
+
+// WebSocketResponse is a reply to a WebSocketRequest.
+type WebSocketResponse struct {
+	Type       string `json:"type"`
+	Challenge  string `json:"challenge,omitempty"`
+	PersonaTag string `json:"personaTag,omitempty"`
+	Error      string `json:"error,omitempty"`
+	// SubscriptionID and Results are set in replies to subscribe and unsubscribe requests.
+	SubscriptionID string                      `json:"subscriptionId,omitempty"`
+	Results        *[]types.EntityStateElement `json:"results,omitempty"`
+}
+
+type sessionHandler struct {
+	world         servertypes.ProviderWorld
+	sessions      *PersonaSessions
+	subscriptions *CQLSubscriptions
+	logger        *zerolog.Logger
+}
+
 // WebSocketEvents godoc
 //
 //	@Summary      Establishes a new websocket connection to retrieve system events
-//	@Description  Establishes a new websocket connection to retrieve system events
+//	@Description  Establishes a new websocket connection to retrieve system events. A connection can authenticate
+//	@Description  as a persona by requesting a challenge ({"type":"challenge"}) and answering it with a signature from
+//	@Description  the persona's signer ({"type":"authenticate","personaTag":"...","signature":"..."}). Authenticated
+//	@Description  connections also receive the events targeted at their persona. Clients can register live CQL
+//	@Description  queries ({"type":"subscribe","subscriptionId":"...","cql":"..."}), which reply with the current
+//	@Description  result set and then send a "cqlDiff" after every tick that changes it. Diffs list the entities that
+//	@Description  entered or left the result set, and the entities whose data changed.
 //	@Produce      application/json
 //	@Success      101  {string}  string  "Switch protocol to ws"
 //	@Router       /events [get]
-func WebSocketEvents() func(c *fiber.Ctx) error {
-	return socketio.New(func(_ *socketio.Websocket) {
-		log.Debug().Msg("new websocket connection established")
+func WebSocketEvents(
+	world servertypes.ProviderWorld, sessions *PersonaSessions, subscriptions *CQLSubscriptions, logger *zerolog.Logger,
+) func(c *fiber.Ctx) error {
+	registerListenersOnce.Do(registerWebSocketListeners)
+	handler := &sessionHandler{world: world, sessions: sessions, subscriptions: subscriptions, logger: logger}
+	return socketio.New(func(kws *socketio.Websocket) {
+		kws.SetAttribute(sessionHandlerAttribute, handler)
+		sessions.Connect(kws.GetUUID())
+		logger.Debug().Msg("new websocket connection established")
 	})
 }
 
@@ -29,3 +116,118 @@ func WebSocketUpgrader(c *fiber.Ctx) error {
 	}
 	return fiber.ErrUpgradeRequired
 }
+
+func registerWebSocketListeners() {
+	socketio.On(socketio.EventMessage, func(ep *socketio.EventPayload) {
+		if h := getSessionHandler(ep); h != nil {
+			h.handleMessage(ep.Kws, ep.Data)
+		}
+	})
+	removeSession := func(ep *socketio.EventPayload) {
+		if h := getSessionHandler(ep); h != nil {
+			h.sessions.Remove(ep.Kws.GetUUID())
+			h.subscriptions.Remove(ep.Kws.GetUUID())
+		}
+	}
+	socketio.On(socketio.EventDisconnect, removeSession)
+	socketio.On(socketio.EventClose, removeSession)
+}
+
+func getSessionHandler(ep *socketio.EventPayload) *sessionHandler {
+	if ep == nil || ep.Kws == nil {
+		return nil
+	}
+	h, ok := ep.Kws.GetAttribute(sessionHandlerAttribute).(*sessionHandler)
+	if !ok {
+		return nil
+	}
+	return h
+}
+
+func (h *sessionHandler) handleMessage(kws *socketio.Websocket, data []byte) {
+	var req WebSocketRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		h.reply(kws, WebSocketResponse{Type: WebSocketResponseError, Error: "unparseable request"})
+		return
+	}
+
+	switch req.Type {
+	case WebSocketRequestChallenge:
+		buf := make([]byte, challengeSizeBytes)
+		if _, err := rand.Read(buf); err != nil {
+			h.logger.Error().Err(err).Msg("failed to generate websocket challenge")
+			h.reply(kws, WebSocketResponse{Type: WebSocketResponseError, Error: "failed to generate challenge"})
+			return
+		}
+		challenge := hex.EncodeToString(buf)
+		kws.SetAttribute(challengeAttribute, challenge)
+		h.reply(kws, WebSocketResponse{Type: WebSocketRequestChallenge, Challenge: challenge})
+
+	case WebSocketRequestAuthenticate:
+		if err := h.authenticate(kws, req); err != nil {
+			h.logger.Debug().Err(err).Msgf("websocket authentication failed for persona %q", req.PersonaTag)
+			h.reply(kws, WebSocketResponse{Type: WebSocketResponseError, Error: err.Error()})
+			return
+		}
+		h.reply(kws, WebSocketResponse{Type: WebSocketResponseAuthenticated, PersonaTag: req.PersonaTag})
+
+	case WebSocketRequestSubscribe:
+		results, err := h.subscriptions.Subscribe(kws.GetUUID(), req.SubscriptionID, req.CQL)
+		if err != nil {
+			h.logger.Debug().Err(err).Msgf("failed to subscribe to cql query %q", req.CQL)
+			h.reply(kws, WebSocketResponse{
+				Type: WebSocketResponseError, SubscriptionID: req.SubscriptionID, Error: err.Error(),
+			})
+			return
+		}
+		h.reply(kws, WebSocketResponse{
+			Type: WebSocketResponseSubscribed, SubscriptionID: req.SubscriptionID, Results: &results,
+		})
+
+	case WebSocketRequestUnsubscribe:
+		if err := h.subscriptions.Unsubscribe(kws.GetUUID(), req.SubscriptionID); err != nil {
+			h.reply(kws, WebSocketResponse{
+				Type: WebSocketResponseError, SubscriptionID: req.SubscriptionID, Error: err.Error(),
+			})
+			return
+		}
+		h.reply(kws, WebSocketResponse{Type: WebSocketResponseUnsubscribed, SubscriptionID: req.SubscriptionID})
+
+	default:
+		h.reply(kws, WebSocketResponse{Type: WebSocketResponseError, Error: "unknown request type " + req.Type})
+	}
+}
+
+// authenticate checks that the request contains a signature over this connection's outstanding challenge that was
+// produced by the signer, or one of the signer keys, of the requested persona. Challenges are single use.
+func (h *sessionHandler) authenticate(kws *socketio.Websocket, req WebSocketRequest) error {
+	challenge, _ := kws.GetAttribute(challengeAttribute).(string)
+	if challenge == "" {
+		return errNoChallenge
+	}
+	kws.SetAttribute(challengeAttribute, "")
+
+	if req.PersonaTag == "" {
+		return errNoPersonaTag
+	}
+	signerAddresses, err := h.world.GetSignerAddressesForPersonaTag(req.PersonaTag, 0)
+	if err != nil {
+		return errUnknownPersona
+	}
+	for _, signerAddress := range signerAddresses {
+		if sign.VerifyChallenge(signerAddress, h.world.Namespace(), challenge, req.Signature) == nil {
+			h.sessions.Authenticate(kws.GetUUID(), req.PersonaTag)
+			return nil
+		}
+	}
+	return errInvalidChallengeSignature
+}
+
+func (h *sessionHandler) reply(kws *socketio.Websocket, res WebSocketResponse) {
+	bz, err := json.Marshal(res)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to marshal websocket response")
+		return
+	}
+	kws.Emit(bz)
+}
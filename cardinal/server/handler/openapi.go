@@ -0,0 +1,20 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetOpenAPI godoc
+//
+//	@Summary      Retrieves the OpenAPI document of the game
+//	@Description  Retrieves an OpenAPI 3 document with a path for every registered message and query, and the
+//	@Description  schemas of the registered components
+//	@Produce      application/json
+//	@Success      200  {object}  object  "OpenAPI document"
+//	@Router       /openapi.json [get]
+func GetOpenAPI(doc []byte) func(*fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		ctx.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		return ctx.Send(doc)
+	}
+}
@@ -44,39 +44,49 @@ func GetReceipts(world types.ProviderWorld) func(*fiber.Ctx) error {
 		if err := ctx.BodyParser(req); err != nil {
 			return err
 		}
-		reply := ListTxReceiptsResponse{}
-		reply.EndTick = world.CurrentTick()
-		size := world.ReceiptHistorySize()
-		if size > reply.EndTick {
-			reply.StartTick = 0
-		} else {
-			reply.StartTick = reply.EndTick - size
-		}
-		// StartTick and EndTick are now at the largest possible range of ticks.
-		// Check to see if we should narrow down the range at all.
-		if req.StartTick > reply.EndTick {
-			// User is asking for ticks in the future.
-			reply.StartTick = reply.EndTick
-		} else if req.StartTick > reply.StartTick {
-			reply.StartTick = req.StartTick
-		}
+		return ctx.JSON(ListReceipts(world, req.StartTick))
+	}
+}
 
-		for t := reply.StartTick; t < reply.EndTick; t++ {
-			currReceipts, err := world.GetTransactionReceiptsForTick(t)
-			if err != nil || len(currReceipts) == 0 {
+// ListReceipts collects the transaction receipts from startTick up to the current tick, narrowed down to the range of
+// ticks the world still keeps receipts for. Receipts that are private to a persona are left out, they are only
+// delivered to the persona's authenticated websocket sessions.
+func ListReceipts(world types.ProviderWorld, startTick uint64) ListTxReceiptsResponse {
+	reply := ListTxReceiptsResponse{}
+	reply.EndTick = world.CurrentTick()
+	size := world.ReceiptHistorySize()
+	if size > reply.EndTick {
+		reply.StartTick = 0
+	} else {
+		reply.StartTick = reply.EndTick - size
+	}
+	// StartTick and EndTick are now at the largest possible range of ticks.
+	// Check to see if we should narrow down the range at all.
+	if startTick > reply.EndTick {
+		// User is asking for ticks in the future.
+		reply.StartTick = reply.EndTick
+	} else if startTick > reply.StartTick {
+		reply.StartTick = startTick
+	}
+
+	for t := reply.StartTick; t < reply.EndTick; t++ {
+		currReceipts, err := world.GetTransactionReceiptsForTick(t)
+		if err != nil || len(currReceipts) == 0 {
+			continue
+		}
+		for _, r := range currReceipts {
+			if r.PersonaTag != "" {
 				continue
 			}
-			for _, r := range currReceipts {
-				reply.Receipts = append(reply.Receipts, ReceiptEntry{
-					TxHash: string(r.TxHash),
-					Tick:   t,
-					Result: r.Result,
-					Errors: convertErrorsToStrings(r.Errs),
-				})
-			}
+			reply.Receipts = append(reply.Receipts, ReceiptEntry{
+				TxHash: string(r.TxHash),
+				Tick:   t,
+				Result: r.Result,
+				Errors: convertErrorsToStrings(r.Errs),
+			})
 		}
-		return ctx.JSON(reply)
 	}
+	return reply
 }
 
 func convertErrorsToStrings(errs []error) []string {
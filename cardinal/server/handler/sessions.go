@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"strings"
+	"sync"
+)
+
+// PersonaSessions keeps track of the websocket connections accepted by a server, and which of them have proven
+// control of a persona. A persona may have any number of authenticated connections, but each connection is
+// authenticated as at most one persona.
+type PersonaSessions struct {
+	mux             *sync.RWMutex
+	sockets         map[string]struct{}
+	personaToSocket map[string]map[string]struct{}
+	socketToPersona map[string]string
+}
+
+func NewPersonaSessions() *PersonaSessions {
+	return &PersonaSessions{
+		mux:             &sync.RWMutex{},
+		sockets:         map[string]struct{}{},
+		personaToSocket: map[string]map[string]struct{}{},
+		socketToPersona: map[string]string{},
+	}
+}
+
+// Connect tracks a new websocket connection. Connections are tracked until they are removed, whether or not they
+// authenticate as a persona.
+func (p *PersonaSessions) Connect(socketUUID string) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	p.sockets[socketUUID] = struct{}{}
+}
+
+// Connected returns the UUIDs of all tracked websocket connections.
+func (p *PersonaSessions) Connected() []string {
+	p.mux.RLock()
+	defer p.mux.RUnlock()
+
+	uuids := make([]string, 0, len(p.sockets))
+	for uuid := range p.sockets {
+		uuids = append(uuids, uuid)
+	}
+	return uuids
+}
+
+// Authenticate marks the given websocket connection as belonging to the given persona. If the connection was
+// previously authenticated as another persona, that association is replaced.
+func (p *PersonaSessions) Authenticate(socketUUID string, personaTag string) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	p.remove(socketUUID)
+	personaTag = strings.ToLower(personaTag)
+	if _, ok := p.personaToSocket[personaTag]; !ok {
+		p.personaToSocket[personaTag] = map[string]struct{}{}
+	}
+	p.personaToSocket[personaTag][socketUUID] = struct{}{}
+	p.socketToPersona[socketUUID] = personaTag
+}
+
+// Remove forgets the given websocket connection and any persona association of it.
+func (p *PersonaSessions) Remove(socketUUID string) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	delete(p.sockets, socketUUID)
+	p.remove(socketUUID)
+}
+
+// Sockets returns the UUIDs of all websocket connections that are authenticated as the given persona.
+func (p *PersonaSessions) Sockets(personaTag string) []string {
+	p.mux.RLock()
+	defer p.mux.RUnlock()
+
+	sockets := p.personaToSocket[strings.ToLower(personaTag)]
+	uuids := make([]string, 0, len(sockets))
+	for uuid := range sockets {
+		uuids = append(uuids, uuid)
+	}
+	return uuids
+}
+
+func (p *PersonaSessions) remove(socketUUID string) {
+	personaTag, ok := p.socketToPersona[socketUUID]
+	if !ok {
+		return
+	}
+	delete(p.socketToPersona, socketUUID)
+	delete(p.personaToSocket[personaTag], socketUUID)
+	if len(p.personaToSocket[personaTag]) == 0 {
+		delete(p.personaToSocket, personaTag)
+	}
+}
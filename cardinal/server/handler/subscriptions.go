@@ -0,0 +1,236 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/gamestate"
+	"pkg.world.dev/world-engine/cardinal/server/handler/cql"
+	servertypes "pkg.world.dev/world-engine/cardinal/server/types"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+// maxSubscriptionsPerSocket limits the number of live CQL queries a single websocket connection can register, since
+// every subscription is evaluated against the changed entities of every tick.
+const maxSubscriptionsPerSocket = 16
+
+var (
+	errNoSubscriptionID       = errors.New("subscription id is required")
+	errDuplicateSubscription  = errors.New("subscription id is already in use")
+	errUnknownSubscription    = errors.New("subscription id is not known")
+	errTooManySubscriptions   = errors.New("too many subscriptions on this connection")
+	errPaginatedSubscriptions = errors.New("subscriptions do not support LIMIT or OFFSET")
+)
+
+// CQLDiff is sent over the websocket after each tick that changed the result set of a live CQL subscription.
+type CQLDiff struct {
+	Type           string `json:"type"`
+	SubscriptionID string `json:"subscriptionId"`
+	Tick           uint64 `json:"tick"`
+	// Entered contains the entities that started matching the query.
+	Entered []types.EntityStateElement `json:"entered,omitempty"`
+	// Updated contains the entities that still match the query, but whose selected data changed.
+	Updated []types.EntityStateElement `json:"updated,omitempty"`
+	// Left contains the IDs of the entities that no longer match the query, including removed entities.
+	Left []types.EntityID `json:"left,omitempty"`
+}
+
+// CQLSubscriptions keeps track of the live CQL queries registered over websocket connections. Instead of evaluating
+// every query against the whole world state each tick, only the entities changed by the tick are evaluated, and the
+// result set of each subscription is updated from them.
+type CQLSubscriptions struct {
+	world servertypes.ProviderWorld
+
+	mux             *sync.Mutex
+	socketToQueries map[string]map[string]*cqlSubscription
+}
+
+type cqlSubscription struct {
+	query *cql.Query
+	// members maps every entity in the result set to the encoding of the data that was last sent for it.
+	members map[types.EntityID][]byte
+}
+
+func NewCQLSubscriptions(world servertypes.ProviderWorld) *CQLSubscriptions {
+	return &CQLSubscriptions{
+		world:           world,
+		mux:             &sync.Mutex{},
+		socketToQueries: map[string]map[string]*cqlSubscription{},
+	}
+}
+
+// Subscribe registers a live CQL query under the given id for the given websocket connection, and returns the
+// current result set. Results are ordered like the results of /cql.
+func (c *CQLSubscriptions) Subscribe(socketUUID string, id string, cqlString string) (
+	[]types.EntityStateElement, error,
+) {
+	if id == "" {
+		return nil, errNoSubscriptionID
+	}
+	query, err := c.world.ParseCQL(cqlString)
+	if err != nil {
+		return nil, err
+	}
+	if query.Limit != 0 || query.Offset != 0 {
+		return nil, errPaginatedSubscriptions
+	}
+
+	// The lock is held while the initial result set is evaluated, so that the result set can't miss the changes of
+	// a tick that is being published concurrently.
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	queries := c.socketToQueries[socketUUID]
+	if _, ok := queries[id]; ok {
+		return nil, errDuplicateSubscription
+	}
+	if len(queries) >= maxSubscriptionsPerSocket {
+		return nil, errTooManySubscriptions
+	}
+
+	matches, err := c.world.EvaluateCQLQuery(query, "")
+	if err != nil {
+		return nil, err
+	}
+	matches, _, err = query.Paginate(matches, "")
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &cqlSubscription{query: query, members: make(map[types.EntityID][]byte, len(matches))}
+	results := make([]types.EntityStateElement, 0, len(matches))
+	for _, entity := range matches {
+		data, bz, err := project(query, entity)
+		if err != nil {
+			return nil, err
+		}
+		sub.members[entity.ID] = bz
+		results = append(results, types.EntityStateElement{ID: entity.ID, Data: data})
+	}
+
+	if queries == nil {
+		queries = map[string]*cqlSubscription{}
+		c.socketToQueries[socketUUID] = queries
+	}
+	queries[id] = sub
+	return results, nil
+}
+
+// Unsubscribe removes the live CQL query with the given id from the given websocket connection.
+func (c *CQLSubscriptions) Unsubscribe(socketUUID string, id string) error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	queries := c.socketToQueries[socketUUID]
+	if _, ok := queries[id]; !ok {
+		return errUnknownSubscription
+	}
+	delete(queries, id)
+	if len(queries) == 0 {
+		delete(c.socketToQueries, socketUUID)
+	}
+	return nil
+}
+
+// Remove removes all live CQL queries of the given websocket connection.
+func (c *CQLSubscriptions) Remove(socketUUID string) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	delete(c.socketToQueries, socketUUID)
+}
+
+// Diffs applies the entities changed by the given tick to the result sets of all subscriptions, and returns the
+// resulting non-empty diffs grouped by websocket connection.
+func (c *CQLSubscriptions) Diffs(tick uint64, changed []types.EntityID) (map[string][]CQLDiff, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if len(c.socketToQueries) == 0 || len(changed) == 0 {
+		return nil, nil
+	}
+
+	// Each changed entity is loaded once, no matter how many subscriptions there are. Removed entities are left
+	// without components, which no live entity can be.
+	entities := make([]*cql.Entity, 0, len(changed))
+	for _, id := range changed {
+		entity, err := c.world.GetCQLEntity(id)
+		if eris.Is(err, gamestate.ErrEntityDoesNotExist) {
+			entity = &cql.Entity{ID: id}
+		} else if err != nil {
+			return nil, err
+		}
+		entities = append(entities, entity)
+	}
+
+	diffs := map[string][]CQLDiff{}
+	for socketUUID, queries := range c.socketToQueries {
+		for id, sub := range queries {
+			diff, err := sub.apply(entities)
+			if err != nil {
+				return nil, eris.Wrapf(err, "failed to update subscription %q", id)
+			}
+			if len(diff.Entered) == 0 && len(diff.Updated) == 0 && len(diff.Left) == 0 {
+				continue
+			}
+			diff.Type = WebSocketResponseCQLDiff
+			diff.SubscriptionID = id
+			diff.Tick = tick
+			diffs[socketUUID] = append(diffs[socketUUID], diff)
+		}
+	}
+	return diffs, nil
+}
+
+// apply updates the result set of the subscription with the given changed entities and returns what changed.
+func (s *cqlSubscription) apply(entities []*cql.Entity) (CQLDiff, error) {
+	var diff CQLDiff
+	for _, entity := range entities {
+		matches := len(entity.Components) > 0 && s.query.Filter.MatchesComponents(entity.Components)
+		if matches {
+			var err error
+			if matches, err = s.query.Matches(entity); err != nil {
+				return diff, err
+			}
+		}
+
+		prev, wasMember := s.members[entity.ID]
+		if !matches {
+			if wasMember {
+				delete(s.members, entity.ID)
+				diff.Left = append(diff.Left, entity.ID)
+			}
+			continue
+		}
+
+		data, bz, err := project(s.query, entity)
+		if err != nil {
+			return diff, err
+		}
+		s.members[entity.ID] = bz
+		switch {
+		case !wasMember:
+			diff.Entered = append(diff.Entered, types.EntityStateElement{ID: entity.ID, Data: data})
+		case !bytes.Equal(prev, bz):
+			diff.Updated = append(diff.Updated, types.EntityStateElement{ID: entity.ID, Data: data})
+		}
+	}
+	return diff, nil
+}
+
+// project returns the data the query selects from the entity, together with its encoding, which is used to detect
+// whether the data changed.
+func project(query *cql.Query, entity *cql.Entity) ([]json.RawMessage, []byte, error) {
+	data, err := query.Project(entity)
+	if err != nil {
+		return nil, nil, err
+	}
+	bz, err := json.Marshal(data)
+	if err != nil {
+		return nil, nil, eris.Wrap(err, "failed to marshal entity data")
+	}
+	return data, bz, nil
+}
@@ -1,6 +1,14 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/log"
 	"github.com/rotisserie/eris"
@@ -8,16 +16,34 @@ import (
 	personaMsg "pkg.world.dev/world-engine/cardinal/persona/msg"
 	servertypes "pkg.world.dev/world-engine/cardinal/server/types"
 	"pkg.world.dev/world-engine/cardinal/server/validator"
+	"pkg.world.dev/world-engine/cardinal/txpool"
 	"pkg.world.dev/world-engine/cardinal/types"
+	"pkg.world.dev/world-engine/cardinal/validation"
 	"pkg.world.dev/world-engine/sign"
 )
 
+// maxTransactionBatchSize is the maximum number of transactions that can be submitted in a single batch.
+const maxTransactionBatchSize = 1024
+
+var (
+	// ErrTxDecodeFailed is returned when a transaction's body cannot be decoded into the message's input type.
+	ErrTxDecodeFailed = errors.New("failed to decode tx message")
+
+	errBadMessageType = errors.New("bad message type")
+)
+
 // PostTransactionResponse is the HTTP response for a successful transaction submission
 type PostTransactionResponse struct {
 	TxHash string
 	Tick   uint64
 }
 
+// ValidationErrorResponse is the HTTP response for a message that broke the validation rules of its type.
+type ValidationErrorResponse struct {
+	Error  string                  `json:"error"`
+	Fields []validation.FieldError `json:"fields"`
+}
+
 // PostTransaction godoc
 //
 //	@Summary      Submits a transaction
@@ -28,7 +54,7 @@ type PostTransactionResponse struct {
 //	@Param        txName   path      string                   true  "Name of a registered message"
 //	@Param        txBody   body      sign.Transaction         true  "Transaction details & message to be submitted"
 //	@Success      200      {object}  PostTransactionResponse  "Transaction hash and tick"
-//	@Failure      400      {string}  string                   "Invalid request parameter"
+//	@Failure      400      {object}  ValidationErrorResponse  "Invalid request parameter or message"
 //	@Failure      403      {string}  string                   "Forbidden"
 //	@Failure      408      {string}  string                   "Request Timeout - message expired"
 //	@Router       /tx/{txGroup}/{txName} [post]
@@ -41,6 +67,11 @@ func PostTransaction(
 			log.Errorf("Unknown msg type: %s", ctx.Params("name"))
 			return fiber.NewError(fiber.StatusNotFound, "Not Found - bad msg type")
 		}
+		version, ok := parseVersion(msgType, ctx.Params("version"))
+		if !ok {
+			log.Errorf("Unknown version %s of msg type: %s", ctx.Params("version"), ctx.Params("name"))
+			return fiber.NewError(fiber.StatusNotFound, "Not Found - bad msg version")
+		}
 
 		// extract the transaction from the fiber context
 		tx, err := extractTx(ctx, validator)
@@ -48,42 +79,109 @@ func PostTransaction(
 			return err
 		}
 
-		// make sure the transaction hasn't expired
-		if err = validator.ValidateTransactionTTL(tx); err != nil {
-			return httpResultFromError(err, false)
-		}
-
-		// Decode the message from the transaction
-		msg, err := msgType.Decode(tx.Body)
+		res, err := SubmitTransaction(world, msgType, version, tx, validator)
 		if err != nil {
-			log.Errorf("message %s Decode failed: %v", tx.Hash.String(), err)
-			return fiber.NewError(fiber.StatusBadRequest, "Bad Request - failed to decode tx message")
-		}
-
-		// there's a special case for the CreatePersona message
-		var signerAddress string
-		if msgType.Name() == personaMsg.CreatePersonaMessageName {
-			createPersonaMsg, ok := msg.(personaMsg.CreatePersona)
-			if !ok {
-				return fiber.NewError(fiber.StatusInternalServerError, "Internal Server Error - bad message type")
+			var validationErr *validation.Error
+			if eris.As(err, &validationErr) {
+				return ctx.Status(fiber.StatusBadRequest).JSON(ValidationErrorResponse{
+					Error:  "Bad Request - invalid tx message",
+					Fields: validationErr.Fields,
+				})
 			}
-			signerAddress = createPersonaMsg.SignerAddress
+			return httpResultFromError(err)
 		}
+		return ctx.JSON(res)
+	}
+}
+
+// SubmitTransaction runs the ingress checks that are shared by every transport (TTL, message decoding and validation,
+// and signature validation) and, if they all pass, adds the transaction to the world's tx pool. The body of the
+// transaction is a payload of the given version of the message.
+func SubmitTransaction(
+	world servertypes.ProviderWorld, msgType types.Message, version uint32, tx *sign.Transaction,
+	validator *validator.SignatureValidator,
+) (*PostTransactionResponse, error) {
+	msg, err := ValidateTransaction(msgType, version, tx, validator)
+	if err != nil {
+		return nil, err
+	}
+
+	// Add the transaction to the engine, along with its version so that it is decoded the same way during recovery
+	// TODO(scott): this should just deal with txpool instead of having to go through engine
+	tick, hashes := world.AddTransactions([]txpool.TxData{{MsgID: msgType.ID(), Msg: msg, Tx: tx, Version: version}})
+
+	return &PostTransactionResponse{
+		TxHash: string(hashes[0]),
+		Tick:   tick,
+	}, nil
+}
+
+// ValidateTransaction checks that the transaction hasn't expired or been seen before, decodes and validates its
+// message, and validates its signature. Messages of older versions are upgraded to the latest version. The decoded
+// message is returned.
+func ValidateTransaction(
+	msgType types.Message, version uint32, tx *sign.Transaction, validator *validator.SignatureValidator,
+) (any, error) {
+	// make sure the body is encoded the way the server requires
+	if err := validator.ValidateTransactionBody(tx); err != nil {
+		return nil, err
+	}
+
+	// make sure the transaction hasn't expired
+	if err := validator.ValidateTransactionTTL(tx); err != nil {
+		return nil, err
+	}
+
+	// Decode the message from the transaction
+	msg, err := msgType.DecodeVersion(version, tx.Body)
+	if err != nil {
+		log.Errorf("message %s Decode failed: %v", tx.Hash.String(), err)
+		return nil, eris.Wrap(ErrTxDecodeFailed, err.Error())
+	}
 
-		// Validate the transaction's signature
-		if err = validator.ValidateTransactionSignature(tx, signerAddress); err != nil {
-			return httpResultFromError(err, true)
+	// Invalid messages are rejected here rather than failing in a system after they have been sequenced. This runs
+	// before the signature is validated so that a corrected message with the same signature isn't a duplicate.
+	if err = msgType.Validate(msg); err != nil {
+		return nil, eris.Wrapf(err, "message %s is invalid", tx.Hash.String())
+	}
+
+	// there's a special case for the CreatePersona message
+	var signerAddress string
+	if msgType.Name() == personaMsg.CreatePersonaMessageName {
+		createPersonaMsg, ok := msg.(personaMsg.CreatePersona)
+		if !ok {
+			return nil, eris.Wrap(errBadMessageType, "")
 		}
+		signerAddress = createPersonaMsg.SignerAddress
+	}
 
-		// Add the transaction to the engine
-		// TODO(scott): this should just deal with txpool instead of having to go through engine
-		tick, hash := world.AddTransaction(msgType.ID(), msg, tx)
+	// The signature of an EIP-712 transaction is over typed data, whose types are generated from the payload type of
+	// the submitted version of the message
+	if tx.IsEIP712() {
+		payloadType, err := msgType.PayloadType(version)
+		if err != nil {
+			return nil, err
+		}
+		if err = validator.PrepareTypedData(tx, payloadType); err != nil {
+			return nil, err
+		}
+	}
 
-		return ctx.JSON(&PostTransactionResponse{
-			TxHash: string(hash),
-			Tick:   tick,
-		})
+	// Validate the transaction's signature. Messages that change the persona's signers can't be signed with one of
+	// its signer keys.
+	switch {
+	case personaMsg.RequiresPersonaSigner(msgType.FullName()):
+		err = validator.ValidateTransactionPrimarySignature(tx)
+	case signerAddress == "":
+		// the persona's session keys can sign the messages in their scope
+		err = validator.ValidateMessageTransactionSignature(tx, msgType.FullName())
+	default:
+		err = validator.ValidateTransactionSignature(tx, signerAddress)
+	}
+	if err != nil {
+		return nil, err
 	}
+	return msg, nil
 }
 
 // NOTE: duplication for cleaner swagger docs
@@ -106,6 +204,30 @@ func PostGameTransaction(
 	return PostTransaction(world, msgs, validator)
 }
 
+// NOTE: duplication for cleaner swagger docs
+// PostTransaction godoc
+//
+//	@Summary      Submits a transaction for an older version of a message
+//	@Description  Submits a transaction whose message is a payload of the given version. It is upgraded to the latest
+//	@Description  version of the message before it is executed.
+//	@Accept       application/json
+//	@Produce      application/json
+//	@Param        txGroup    path      string                   true  "Message group"
+//	@Param        txName     path      string                   true  "Name of a registered message"
+//	@Param        txVersion  path      string                   true  "Version of the message, e.g. v1"
+//	@Param        txBody     body      sign.Transaction         true  "Transaction details & message to be submitted"
+//	@Success      200        {object}  PostTransactionResponse  "Transaction hash and tick"
+//	@Failure      400        {object}  ValidationErrorResponse  "Invalid request parameter or message"
+//	@Failure      403        {string}  string                   "Forbidden"
+//	@Failure      404        {string}  string                   "Unknown message or version"
+//	@Failure      408        {string}  string                   "Request Timeout - message expired"
+//	@Router       /tx/{txGroup}/{txName}/{txVersion} [post]
+func PostVersionedTransaction(
+	world servertypes.ProviderWorld, msgs map[string]map[string]types.Message, validator *validator.SignatureValidator,
+) func(*fiber.Ctx) error {
+	return PostTransaction(world, msgs, validator)
+}
+
 // NOTE: duplication for cleaner swagger docs
 // PostTransaction godoc
 //
@@ -127,6 +249,176 @@ func PostPersonaTransaction(
 	return PostTransaction(world, msgs, validator)
 }
 
+// PostTransactionBatchItem is a single transaction in a batch, together with the message it is for.
+type PostTransactionBatchItem struct {
+	Group string `json:"group"`
+	Name  string `json:"name"`
+	// Version is the version of the message the transaction is for. It defaults to the latest version.
+	Version     uint32          `json:"version,omitempty"`
+	Transaction json.RawMessage `json:"transaction" swaggertype:"object"`
+}
+
+// PostTransactionBatchResult is the outcome of a single transaction in a batch. Status is the HTTP status code the
+// transaction would have gotten had it been submitted on its own.
+type PostTransactionBatchResult struct {
+	TxHash string                  `json:"txHash,omitempty"`
+	Status int                     `json:"status"`
+	Error  string                  `json:"error,omitempty"`
+	Fields []validation.FieldError `json:"fields,omitempty"`
+}
+
+// PostTransactionBatchResponse is the HTTP response for a batch transaction submission. Results are in the same order
+// as the transactions in the request. All accepted transactions will be executed in Tick.
+type PostTransactionBatchResponse struct {
+	Tick    uint64                       `json:"tick"`
+	Results []PostTransactionBatchResult `json:"results"`
+}
+
+// PostTransactionBatch godoc
+//
+//	@Summary      Submits a batch of transactions
+//	@Description  Validates each transaction independently and adds all the valid ones to the same tick
+//	@Accept       application/json
+//	@Produce      application/json
+//	@Param        txBatch  body      []PostTransactionBatchItem    true  "Transactions & the messages they are for"
+//	@Success      200      {object}  PostTransactionBatchResponse  "Tick and per-transaction results"
+//	@Failure      400      {string}  string                        "Invalid request parameter"
+//	@Router       /tx/batch [post]
+func PostTransactionBatch(
+	world servertypes.ProviderWorld, msgs map[string]map[string]types.Message, validator *validator.SignatureValidator,
+) func(*fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		var items []PostTransactionBatchItem
+		if err := json.Unmarshal(ctx.Body(), &items); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Bad Request - unparseable body")
+		}
+		if len(items) == 0 {
+			return fiber.NewError(fiber.StatusBadRequest, "Bad Request - empty batch")
+		}
+		if len(items) > maxTransactionBatchSize {
+			return fiber.NewError(fiber.StatusBadRequest,
+				fmt.Sprintf("Bad Request - batches are limited to %d transactions", maxTransactionBatchSize))
+		}
+		return ctx.JSON(SubmitTransactionBatch(world, msgs, items, validator))
+	}
+}
+
+// SubmitTransactionBatch validates every transaction in the batch independently and adds the valid ones to the
+// world's tx pool at once, so that they all end up in the same tick.
+func SubmitTransactionBatch(
+	world servertypes.ProviderWorld, msgs map[string]map[string]types.Message, items []PostTransactionBatchItem,
+	sigValidator *validator.SignatureValidator,
+) PostTransactionBatchResponse {
+	results := make([]PostTransactionBatchResult, len(items))
+	msgTypes := make([]types.Message, len(items))
+	versions := make([]uint32, len(items))
+	txs := make([]*sign.Transaction, len(items))
+
+	// Resolve and parse all the transactions first so that duplicates within the batch can be rejected before their
+	// signatures are validated concurrently.
+	seen := make(map[string]struct{}, len(items))
+	for i, item := range items {
+		msgType, ok := msgs[item.Group][item.Name]
+		if !ok {
+			results[i] = PostTransactionBatchResult{Status: fiber.StatusNotFound, Error: "Not Found - bad msg type"}
+			continue
+		}
+		version, ok := ResolveVersion(msgType, item.Version)
+		if !ok {
+			results[i] = PostTransactionBatchResult{Status: fiber.StatusNotFound, Error: "Not Found - bad msg version"}
+			continue
+		}
+		tx, err := parseTx(item.Transaction, sigValidator)
+		if err != nil {
+			results[i] = PostTransactionBatchResult{Status: fiber.StatusBadRequest, Error: err.Error()}
+			continue
+		}
+		txHash := tx.HashHex()
+		if _, ok := seen[txHash]; ok {
+			results[i] = batchResultFromError(txHash, eris.Wrap(validator.ErrDuplicateMessage, "duplicate in batch"))
+			continue
+		}
+		seen[txHash] = struct{}{}
+		msgTypes[i], versions[i], txs[i] = msgType, version, tx
+	}
+
+	// Validate the transactions concurrently
+	decoded := make([]any, len(items))
+	var wg sync.WaitGroup
+	for i := range items {
+		if txs[i] == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			msg, err := ValidateTransaction(msgTypes[i], versions[i], txs[i], sigValidator)
+			if err != nil {
+				results[i] = batchResultFromError(txs[i].HashHex(), err)
+				txs[i] = nil
+				return
+			}
+			decoded[i] = msg
+		}(i)
+	}
+	wg.Wait()
+
+	// Add all the valid transactions to the tx pool at once
+	accepted := make([]int, 0, len(items))
+	batch := make([]txpool.TxData, 0, len(items))
+	for i, tx := range txs {
+		if tx == nil {
+			continue
+		}
+		accepted = append(accepted, i)
+		batch = append(batch, txpool.TxData{MsgID: msgTypes[i].ID(), Msg: decoded[i], Tx: tx, Version: versions[i]})
+	}
+	tick, hashes := world.AddTransactions(batch)
+	for j, i := range accepted {
+		results[i] = PostTransactionBatchResult{TxHash: string(hashes[j]), Status: fiber.StatusOK}
+	}
+
+	return PostTransactionBatchResponse{Tick: tick, Results: results}
+}
+
+func batchResultFromError(txHash string, err error) PostTransactionBatchResult {
+	var fiberErr *fiber.Error
+	if !errors.As(httpResultFromError(err), &fiberErr) {
+		return PostTransactionBatchResult{TxHash: txHash, Status: fiber.StatusInternalServerError, Error: err.Error()}
+	}
+	result := PostTransactionBatchResult{TxHash: txHash, Status: fiberErr.Code, Error: fiberErr.Message}
+	var validationErr *validation.Error
+	if eris.As(err, &validationErr) {
+		result.Fields = validationErr.Fields
+	}
+	return result
+}
+
+// parseVersion returns the version of the message a transaction is for from the version in its URL, e.g. "v2". The
+// latest version is returned if the URL has no version.
+func parseVersion(msgType types.Message, param string) (uint32, bool) {
+	if param == "" {
+		return msgType.Version(), true
+	}
+	digits, ok := strings.CutPrefix(param, "v")
+	if !ok {
+		return 0, false
+	}
+	version, err := strconv.ParseUint(digits, 10, 32)
+	if err != nil || version == 0 {
+		return 0, false
+	}
+	return ResolveVersion(msgType, uint32(version))
+}
+
+// ResolveVersion checks that the message has the given version. Version 0 stands for the latest version.
+func ResolveVersion(msgType types.Message, version uint32) (uint32, bool) {
+	if version == 0 {
+		return msgType.Version(), true
+	}
+	return version, slices.Contains(msgType.Versions(), version)
+}
+
 func extractTx(ctx *fiber.Ctx, validator *validator.SignatureValidator) (*sign.Transaction, error) {
 	var tx *sign.Transaction
 	var err error
@@ -147,9 +439,40 @@ func extractTx(ctx *fiber.Ctx, validator *validator.SignatureValidator) (*sign.T
 	return tx, nil
 }
 
+// parseTx is the equivalent of extractTx for a transaction that is not the whole request body.
+func parseTx(bz []byte, validator *validator.SignatureValidator) (*sign.Transaction, error) {
+	if validator != nil && !validator.IsDisabled {
+		tx, err := sign.UnmarshalTransaction(bz)
+		if err != nil {
+			log.Errorf("transaction parse failed: %v", err)
+			return nil, eris.New("Bad Request - unparseable transaction")
+		}
+		return tx, nil
+	}
+	tx := new(sign.Transaction)
+	if err := json.Unmarshal(bz, tx); err != nil {
+		log.Errorf("transaction parse failed: %v", err)
+		return nil, eris.New("Bad Request - unparseable transaction")
+	}
+	return tx, nil
+}
+
 // turns the various errors into an appropriate HTTP result
-func httpResultFromError(err error, isSignatureValidation bool) error {
+func httpResultFromError(err error) error {
 	log.Error(err) // log the private internal details
+	if eris.Is(err, ErrTxDecodeFailed) {
+		return fiber.NewError(fiber.StatusBadRequest, "Bad Request - failed to decode tx message")
+	}
+	var validationErr *validation.Error
+	if eris.As(err, &validationErr) {
+		return fiber.NewError(fiber.StatusBadRequest, "Bad Request - invalid tx message")
+	}
+	if eris.Is(err, types.ErrMessageVersionNotFound) {
+		return fiber.NewError(fiber.StatusNotFound, "Not Found - bad msg version")
+	}
+	if eris.Is(err, errBadMessageType) {
+		return fiber.NewError(fiber.StatusInternalServerError, "Internal Server Error - bad message type")
+	}
 	if eris.Is(err, validator.ErrDuplicateMessage) {
 		return fiber.NewError(fiber.StatusForbidden, "Forbidden - duplicate message")
 	}
@@ -159,13 +482,20 @@ func httpResultFromError(err error, isSignatureValidation bool) error {
 	if eris.Is(err, validator.ErrBadTimestamp) {
 		return fiber.NewError(fiber.StatusBadRequest, "Bad Request - bad timestamp")
 	}
+	if eris.Is(err, validator.ErrNonCanonicalBody) {
+		return fiber.NewError(fiber.StatusBadRequest, "Bad Request - body is not canonical json")
+	}
 	if eris.Is(err, validator.ErrNoPersonaTag) {
 		return fiber.NewError(fiber.StatusBadRequest, "Bad Request - no persona tag")
 	}
+	if eris.Is(err, validator.ErrNoNonce) {
+		return fiber.NewError(fiber.StatusBadRequest, "Bad Request - no nonce")
+	}
 	if eris.Is(err, validator.ErrInvalidSignature) {
 		return fiber.NewError(fiber.StatusUnauthorized, "Unauthorized - signature validation failed")
 	}
-	if isSignatureValidation {
+	if eris.Is(err, validator.ErrCacheWriteFailed) || eris.Is(err, validator.ErrNonceUseFailed) ||
+		eris.Is(err, validator.ErrSessionUseFailed) {
 		return fiber.NewError(fiber.StatusInternalServerError, "Internal Server Error - signature validation failed")
 	}
 	return fiber.NewError(fiber.StatusInternalServerError, "Internal Server Error - ttl validation failed")
@@ -17,12 +17,15 @@ type GetWorldResponse struct {
 	Components []types.FieldDetail `json:"components"` // list of component names
 	Messages   []types.FieldDetail `json:"messages"`
 	Queries    []types.FieldDetail `json:"queries"`
+	Events     []types.FieldDetail `json:"events"`
+	Resources  []types.FieldDetail `json:"resources"`
 }
 
 // GetWorld godoc
 //
 //	@Summary      Retrieves details of the game world
-//	@Description  Contains the registered components, messages, queries, and namespace
+//	@Description  Contains the registered components, messages, queries, events, resources, and namespace, along with
+//	@Description  the JSON schemas of their types
 //	@Accept       application/json
 //	@Produce      application/json
 //	@Success      200  {object}  GetWorldResponse  "Details of the game world"
@@ -34,6 +37,22 @@ func GetWorld(
 	messages []types.Message,
 	namespace string,
 ) func(*fiber.Ctx) error {
+	// Components and messages can't change once the server is running, so they are only collected once.
+	res := BuildWorldResponse(world, components, messages, namespace)
+	return func(ctx *fiber.Ctx) error {
+		reply := res
+		reply.Queries = world.BuildQueryFields()
+		return ctx.JSON(reply)
+	}
+}
+
+// BuildWorldResponse collects the registered components, messages, and queries of the world.
+func BuildWorldResponse(
+	world servertypes.ProviderWorld,
+	components []types.ComponentMetadata,
+	messages []types.Message,
+	namespace string,
+) GetWorldResponse {
 	// Collecting name of all registered components
 	comps := make([]types.FieldDetail, 0, len(components))
 	for _, component := range components {
@@ -41,6 +60,7 @@ func GetWorld(
 		comps = append(comps, types.FieldDetail{
 			Name:   component.Name(),
 			Fields: types.GetFieldInformation(reflect.TypeOf(c)),
+			Schema: component.GetSchema(),
 		})
 	}
 
@@ -48,21 +68,25 @@ func GetWorld(
 	messagesFields := make([]types.FieldDetail, 0, len(messages))
 	for _, message := range messages {
 		// Extracting the fields of the message
+		// A message whose schema can't be generated is still listed with its fields.
+		in, _ := message.GetInSchema()
+		out, _ := message.GetOutSchema()
 		messagesFields = append(messagesFields, types.FieldDetail{
-			Name:   message.Name(),
-			Fields: message.GetInFieldInformation(),
-			URL:    utils.GetTxURL(message.Group(), message.Name()),
+			Name:         message.Name(),
+			Fields:       message.GetInFieldInformation(),
+			URL:          utils.GetTxURL(message.Group(), message.Name()),
+			Schema:       in,
+			ResultSchema: out,
 		})
 	}
 
 	// Collecting the structure of all queries
-
-	return func(ctx *fiber.Ctx) error {
-		return ctx.JSON(GetWorldResponse{
-			Namespace:  namespace,
-			Components: comps,
-			Messages:   messagesFields,
-			Queries:    world.BuildQueryFields(),
-		})
+	return GetWorldResponse{
+		Namespace:  namespace,
+		Components: comps,
+		Messages:   messagesFields,
+		Queries:    world.BuildQueryFields(),
+		Events:     world.BuildEventFields(),
+		Resources:  world.BuildResourceFields(),
 	}
 }
@@ -0,0 +1,85 @@
+package server_test
+
+import (
+	"encoding/json"
+
+	"github.com/gofiber/fiber/v2"
+
+	"pkg.world.dev/world-engine/cardinal"
+	"pkg.world.dev/world-engine/cardinal/persona/msg"
+	"pkg.world.dev/world-engine/cardinal/server/handler"
+	"pkg.world.dev/world-engine/cardinal/server/utils"
+	"pkg.world.dev/world-engine/sign"
+)
+
+func (s *ServerTestSuite) TestNonceReplayProtection() {
+	s.setupWorld(cardinal.WithNonceReplayProtection())
+	s.fixture.DoTick()
+
+	// System transactions need a nonce too
+	personaTag := "nonce_persona"
+	createPersona := msg.CreatePersona{PersonaTag: personaTag, SignerAddress: s.signerAddr}
+	tx, err := sign.NewSystemTransactionWithNonce(s.privateKey, s.world.Namespace(), 1, createPersona)
+	s.Require().NoError(err)
+	res := s.fixture.Post(utils.GetTxURL("persona", "create-persona"), tx)
+	s.Require().Equal(fiber.StatusOK, res.StatusCode, s.readBody(res.Body))
+	s.fixture.DoTick()
+
+	url := utils.GetTxURL("game", moveMsgName)
+	tx, err = sign.NewTransaction(s.privateKey, personaTag, s.world.Namespace(), MoveMsgInput{Direction: "up"})
+	s.Require().NoError(err)
+	s.Require().Equal(fiber.StatusBadRequest, s.fixture.Post(url, tx).StatusCode)
+
+	up := MoveMsgInput{Direction: "up"}
+	tx, err = sign.NewTransactionWithNonce(s.privateKey, personaTag, s.world.Namespace(), 2, up)
+	s.Require().NoError(err)
+	s.Require().Equal(fiber.StatusOK, s.fixture.Post(url, tx).StatusCode)
+	s.Require().Equal(fiber.StatusForbidden, s.fixture.Post(url, tx).StatusCode)
+
+	// A new transaction can't reuse the nonce either
+	left := MoveMsgInput{Direction: "left"}
+	tx, err = sign.NewTransactionWithNonce(s.privateKey, personaTag, s.world.Namespace(), 2, left)
+	s.Require().NoError(err)
+	s.Require().Equal(fiber.StatusForbidden, s.fixture.Post(url, tx).StatusCode)
+}
+
+func (s *ServerTestSuite) TestNonceReplayProtectionInABatch() {
+	s.setupWorld(cardinal.WithNonceReplayProtection())
+	s.fixture.DoTick()
+	personaTag := "nonce_persona"
+	createPersona := msg.CreatePersona{PersonaTag: personaTag, SignerAddress: s.signerAddr}
+	tx, err := sign.NewSystemTransactionWithNonce(s.privateKey, s.world.Namespace(), 1, createPersona)
+	s.Require().NoError(err)
+	s.Require().Equal(fiber.StatusOK, s.fixture.Post(utils.GetTxURL("persona", "create-persona"), tx).StatusCode)
+	s.fixture.DoTick()
+
+	// The two transactions with nonce 2 are different, but only one of them can use the nonce
+	items := make([]handler.PostTransactionBatchItem, 0, 3)
+	for i, nonce := range []uint64{2, 2, 3} {
+		tx, err := sign.NewTransactionWithNonce(s.privateKey, personaTag, s.world.Namespace(), nonce,
+			MoveMsgInput{Direction: []string{"up", "down", "left"}[i]})
+		s.Require().NoError(err)
+		bz, err := json.Marshal(tx)
+		s.Require().NoError(err)
+		items = append(items, handler.PostTransactionBatchItem{Group: "game", Name: moveMsgName, Transaction: bz})
+	}
+
+	res := s.fixture.Post("/tx/batch", items)
+	s.Require().Equal(fiber.StatusOK, res.StatusCode)
+	var body handler.PostTransactionBatchResponse
+	s.Require().NoError(json.NewDecoder(res.Body).Decode(&body))
+	s.Require().Len(body.Results, 3)
+	s.Require().ElementsMatch(
+		[]int{fiber.StatusOK, fiber.StatusForbidden},
+		[]int{body.Results[0].Status, body.Results[1].Status},
+	)
+	s.Require().Equal(fiber.StatusOK, body.Results[2].Status)
+
+	// Resubmitting the batch uses no nonces
+	res = s.fixture.Post("/tx/batch", items)
+	s.Require().Equal(fiber.StatusOK, res.StatusCode)
+	s.Require().NoError(json.NewDecoder(res.Body).Decode(&body))
+	for _, result := range body.Results {
+		s.Require().Equal(fiber.StatusForbidden, result.Status)
+	}
+}
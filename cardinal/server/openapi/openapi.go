@@ -0,0 +1,251 @@
+// Package openapi generates an OpenAPI document describing the game-specific routes of a Cardinal server.
+// Unlike the static Swagger spec, which describes the generic /tx/{txGroup}/{txName} and /query/{queryGroup}/{queryName}
+// routes, the generated document has a concrete path, with typed request and response bodies, for every registered
+// message and query.
+package openapi
+
+import (
+	"encoding/json"
+
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/server/utils"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+const (
+	// Version is the version of the OpenAPI specification the generated documents follow.
+	Version = "3.1.0"
+
+	// SchemaRefPrefix is the prefix of the references to the schemas in the components of the document.
+	SchemaRefPrefix = "#/components/schemas/"
+
+	defsRefPrefix = "#/$defs/"
+)
+
+// Document is the root of an OpenAPI document.
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Info holds the metadata of the API.
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// PathItem holds the operations available on a path.
+type PathItem struct {
+	Post *Operation `json:"post,omitempty"`
+}
+
+// Operation describes a single operation on a path.
+type Operation struct {
+	OperationID string              `json:"operationId"`
+	Summary     string              `json:"summary,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+	// ReceiptResult is the schema of the result in the receipt of a transaction, as the result of a message is
+	// only available once the transaction is processed in a tick.
+	ReceiptResult any `json:"x-receipt-result,omitempty"`
+}
+
+// RequestBody describes the body of a request.
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response describes a response of an operation.
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType holds the schema of a request or response body.
+type MediaType struct {
+	Schema any `json:"schema"`
+}
+
+// Components holds the schemas referenced by the operations of the document.
+type Components struct {
+	Schemas map[string]any `json:"schemas"`
+}
+
+// Build returns the OpenAPI document describing the routes of the given messages and queries, along with the schemas
+// of the given components.
+func Build(
+	namespace string,
+	components []types.ComponentMetadata,
+	messages []types.Message,
+	queries []types.QuerySchema,
+) ([]byte, error) {
+	b := newBuilder()
+
+	for _, component := range components {
+		if _, err := b.AddNamed(component.Name(), component.GetSchema()); err != nil {
+			return nil, eris.Wrapf(err, "failed to add schema of component %s", component.Name())
+		}
+	}
+
+	for _, msg := range messages {
+		if err := b.addMessage(msg); err != nil {
+			return nil, eris.Wrapf(err, "failed to add message %s", msg.FullName())
+		}
+	}
+
+	for _, q := range queries {
+		if err := b.addQuery(q); err != nil {
+			return nil, eris.Wrapf(err, "failed to add query %s/%s", q.Group, q.Name)
+		}
+	}
+
+	doc := Document{
+		OpenAPI: Version,
+		Info: Info{
+			Title:       namespace,
+			Description: "Messages and queries of the " + namespace + " Cardinal game shard",
+			Version:     "0.0.1",
+		},
+		Paths:      b.paths,
+		Components: Components{Schemas: b.Schemas()},
+	}
+	bz, err := json.Marshal(doc)
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to marshal openapi document")
+	}
+	return bz, nil
+}
+
+type builder struct {
+	*SchemaSet
+	paths map[string]PathItem
+}
+
+func newBuilder() *builder {
+	return &builder{
+		SchemaSet: NewSchemaSet(),
+		paths:     make(map[string]PathItem),
+	}
+}
+
+func (b *builder) addMessage(msg types.Message) error {
+	in, err := msg.GetInSchema()
+	if err != nil {
+		return err
+	}
+	inSchema, err := b.Add(in)
+	if err != nil {
+		return err
+	}
+	out, err := msg.GetOutSchema()
+	if err != nil {
+		return err
+	}
+	outSchema, err := b.Add(out)
+	if err != nil {
+		return err
+	}
+
+	b.paths[utils.GetTxURL(msg.Group(), msg.Name())] = PathItem{
+		Post: &Operation{
+			OperationID: "tx." + msg.FullName(),
+			Summary:     "Submits a " + msg.Name() + " transaction",
+			Tags:        []string{"tx"},
+			RequestBody: jsonRequestBody(transactionSchema(inSchema)),
+			Responses: map[string]Response{
+				"200": jsonResponse("Transaction hash and tick", transactionResponseSchema()),
+				"400": textResponse("Invalid request parameter"),
+				"403": textResponse("Forbidden"),
+				"408": textResponse("Request Timeout - message expired"),
+			},
+			ReceiptResult: outSchema,
+		},
+	}
+	return nil
+}
+
+func (b *builder) addQuery(q types.QuerySchema) error {
+	request, err := b.Add(q.Request)
+	if err != nil {
+		return err
+	}
+	reply, err := b.Add(q.Reply)
+	if err != nil {
+		return err
+	}
+
+	b.paths[utils.GetQueryURL(q.Group, q.Name)] = PathItem{
+		Post: &Operation{
+			OperationID: "query." + q.Group + "." + q.Name,
+			Summary:     "Executes the " + q.Name + " query",
+			Tags:        []string{"query"},
+			RequestBody: jsonRequestBody(request),
+			Responses: map[string]Response{
+				"200": jsonResponse("Results of the executed query", reply),
+				"400": textResponse("Invalid request parameters"),
+			},
+		},
+	}
+	return nil
+}
+
+// transactionSchema returns the schema of a signed transaction carrying a message with the given schema.
+func transactionSchema(body any) map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"personaTag": map[string]any{"type": "string"},
+			"namespace":  map[string]any{"type": "string"},
+			"timestamp":  map[string]any{"type": "integer", "description": "unix millisecond timestamp"},
+			"salt":       map[string]any{"type": "integer"},
+			"signatureType": map[string]any{
+				"type": "string", "enum": []string{"eip712"}, "description": "omitted for legacy signatures",
+			},
+			"scheme": map[string]any{
+				"type": "string", "description": "signature scheme, e.g. ed25519 or webauthn-p256. omitted for secp256k1",
+			},
+			"signature": map[string]any{"type": "string", "description": "hex encoded signature"},
+			"body":      body,
+		},
+		"required": []string{"personaTag", "namespace", "timestamp", "signature", "body"},
+	}
+}
+
+// transactionResponseSchema returns the schema of handler.PostTransactionResponse.
+func transactionResponseSchema() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"TxHash": map[string]any{"type": "string"},
+			"Tick":   map[string]any{"type": "integer"},
+		},
+		"required": []string{"TxHash", "Tick"},
+	}
+}
+
+func jsonRequestBody(schema any) *RequestBody {
+	return &RequestBody{
+		Required: true,
+		Content:  map[string]MediaType{"application/json": {Schema: schema}},
+	}
+}
+
+func jsonResponse(description string, schema any) Response {
+	return Response{
+		Description: description,
+		Content:     map[string]MediaType{"application/json": {Schema: schema}},
+	}
+}
+
+func textResponse(description string) Response {
+	return Response{
+		Description: description,
+		Content:     map[string]MediaType{"text/plain": {Schema: map[string]any{"type": "string"}}},
+	}
+}
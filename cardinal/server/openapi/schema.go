@@ -0,0 +1,138 @@
+package openapi
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rotisserie/eris"
+)
+
+// SchemaSet collects the definitions of JSON schemas generated from Go types under unique names, so that they can be
+// referenced from a single document. References to the schemas of the set start with SchemaRefPrefix.
+type SchemaSet struct {
+	schemas map[string]any
+	// sources holds the original JSON of each schema in schemas, which is used to tell apart different types that
+	// have the same name.
+	sources map[string]string
+	// names holds the name of each schema in schemas by the name and original JSON of its type, so that a type that was
+	// already added under another name, such as a component, is only added once.
+	names map[string]string
+}
+
+func NewSchemaSet() *SchemaSet {
+	return &SchemaSet{
+		schemas: make(map[string]any),
+		sources: make(map[string]string),
+		names:   make(map[string]string),
+	}
+}
+
+// Schemas returns the schemas of the set, keyed by name.
+func (s *SchemaSet) Schemas() map[string]any {
+	return s.schemas
+}
+
+// AddNamed adds a JSON schema generated from a Go type under the given name, rather than the name of the type, and
+// returns a reference to it. This is used for components, which are known by their name.
+func (s *SchemaSet) AddNamed(name string, schema []byte) (any, error) {
+	root, defs, err := parseSchema(schema)
+	if err != nil {
+		return nil, err
+	}
+	if ref, ok := root["$ref"].(string); ok && strings.HasPrefix(ref, defsRefPrefix) {
+		return s.hoist(root, defs, map[string]string{strings.TrimPrefix(ref, defsRefPrefix): name})
+	}
+	// A schema without definitions is added as is.
+	s.schemas[name] = root
+	return map[string]any{"$ref": SchemaRefPrefix + name}, nil
+}
+
+// Add adds the definitions of a JSON schema generated from a Go type to the set, and returns the schema with its
+// references pointing to them.
+func (s *SchemaSet) Add(schema []byte) (any, error) {
+	root, defs, err := parseSchema(schema)
+	if err != nil {
+		return nil, err
+	}
+	return s.hoist(root, defs, map[string]string{})
+}
+
+func (s *SchemaSet) hoist(root map[string]any, defs map[string]any, names map[string]string) (any, error) {
+	// Definitions are visited in order, so that the names given to different types with the same name are stable.
+	defNames := make([]string, 0, len(defs))
+	for name := range defs {
+		defNames = append(defNames, name)
+	}
+	sort.Strings(defNames)
+
+	for _, defName := range defNames {
+		source, err := json.Marshal(defs[defName])
+		if err != nil {
+			return nil, eris.Wrap(err, "failed to marshal schema definition")
+		}
+		key := defName + "\x00" + string(source)
+		if name, ok := s.names[key]; ok {
+			names[defName] = name
+			continue
+		}
+		base, ok := names[defName]
+		if !ok {
+			base = defName
+		}
+		// Different types with the same name are told apart with a numeric suffix.
+		name := base
+		for i := 2; ; i++ {
+			existing, ok := s.sources[name]
+			if !ok || existing == string(source) {
+				break
+			}
+			name = base + strconv.Itoa(i)
+		}
+		names[defName] = name
+		s.sources[name] = string(source)
+		s.names[key] = name
+	}
+
+	for defName, def := range defs {
+		s.schemas[names[defName]] = rewriteRefs(def, names)
+	}
+	return rewriteRefs(root, names), nil
+}
+
+func parseSchema(schema []byte) (map[string]any, map[string]any, error) {
+	var root map[string]any
+	if err := json.Unmarshal(schema, &root); err != nil {
+		return nil, nil, eris.Wrap(err, "failed to unmarshal json schema")
+	}
+	defs, _ := root["$defs"].(map[string]any)
+	delete(root, "$defs")
+	delete(root, "$schema")
+	delete(root, "$id")
+	return root, defs, nil
+}
+
+// rewriteRefs points the references to the definitions of a JSON schema to the schemas of the set.
+func rewriteRefs(schema any, names map[string]string) any {
+	switch v := schema.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, value := range v {
+			if ref, ok := value.(string); ok && key == "$ref" && strings.HasPrefix(ref, defsRefPrefix) {
+				out[key] = SchemaRefPrefix + names[strings.TrimPrefix(ref, defsRefPrefix)]
+				continue
+			}
+			out[key] = rewriteRefs(value, names)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, value := range v {
+			out[i] = rewriteRefs(value, names)
+		}
+		return out
+	default:
+		return schema
+	}
+}
@@ -0,0 +1,66 @@
+package server_test
+
+import (
+	"encoding/json"
+
+	"pkg.world.dev/world-engine/cardinal/server/openapi"
+)
+
+func (s *ServerTestSuite) TestOpenAPIHasAPathForEveryMessageAndQuery() {
+	s.setupWorld()
+	s.fixture.DoTick()
+
+	res := s.fixture.Get("/openapi.json")
+	s.Require().Equal(200, res.StatusCode)
+	var doc openapi.Document
+	s.Require().NoError(json.NewDecoder(res.Body).Decode(&doc))
+	s.Require().Equal(openapi.Version, doc.OpenAPI)
+	s.Require().Equal(s.world.Namespace(), doc.Info.Title)
+
+	move, ok := doc.Paths["/tx/game/"+moveMsgName]
+	s.Require().True(ok)
+	s.Require().NotNil(move.Post)
+	body, ok := move.Post.RequestBody.Content["application/json"].Schema.(map[string]any)
+	s.Require().True(ok)
+	properties, ok := body["properties"].(map[string]any)
+	s.Require().True(ok)
+	s.Require().Equal(
+		map[string]any{"$ref": "#/components/schemas/MoveMsgInput"},
+		properties["body"],
+	)
+	s.Require().Equal(
+		map[string]any{"$ref": "#/components/schemas/MoveMessageOutput"},
+		move.Post.ReceiptResult,
+	)
+
+	location, ok := doc.Paths["/query/game/location"]
+	s.Require().True(ok)
+	s.Require().NotNil(location.Post)
+	s.Require().Equal(
+		map[string]any{"$ref": "#/components/schemas/QueryLocationRequest"},
+		location.Post.RequestBody.Content["application/json"].Schema,
+	)
+	s.Require().Equal(
+		map[string]any{"$ref": "#/components/schemas/QueryLocationResponse"},
+		location.Post.Responses["200"].Content["application/json"].Schema,
+	)
+
+	// Persona messages and queries are registered by cardinal, and are documented as well.
+	_, ok = doc.Paths["/tx/persona/create-persona"]
+	s.Require().True(ok)
+	_, ok = doc.Paths["/query/persona/signer"]
+	s.Require().True(ok)
+
+	// The admin message is not served under /tx.
+	for path := range doc.Paths {
+		s.Require().NotContains(path, "/tx/admin/")
+	}
+
+	// Components are documented under their own name, and every reference points to a documented schema.
+	s.Require().Contains(doc.Components.Schemas, "location")
+	for name, schema := range doc.Components.Schemas {
+		bz, err := json.Marshal(schema)
+		s.Require().NoError(err)
+		s.Require().NotContainsf(string(bz), "#/$defs/", "schema %q has a reference to a missing definition", name)
+	}
+}
@@ -1,5 +1,11 @@
 package server
 
+import (
+	"github.com/rs/zerolog"
+
+	"pkg.world.dev/world-engine/cardinal/server/validator"
+)
+
 type Option func(s *Server)
 
 // WithPort allows the server to run on a specified port.
@@ -37,6 +43,36 @@ func WithMessageExpiration(seconds uint) Option {
 	}
 }
 
+// WithNonceReplayProtection replaces the timestamp and hash cache based replay protection with nonce-based replay
+// protection. Every transaction must carry a nonce that its signer hasn't used before, and nonces are used up in the
+// world's nonce storage, so they stay used across restarts. Timestamps aren't checked, so the clocks of senders don't
+// need to be synchronized, and the WithMessageExpiration and WithHashCacheSize options are ignored.
+// This setting is ignored if the DisableSignatureVerification option is used
+func WithNonceReplayProtection() Option {
+	return func(s *Server) {
+		s.config.replayProtection = validator.ReplayProtectionNonce
+	}
+}
+
+// WithStrictCanonicalBodies rejects transactions whose bodies aren't canonical json (RFC 8785), rather than hashing
+// their canonical encoding. See sign.CanonicalizeJSON.
+// This setting is ignored if the DisableSignatureVerification option is used
+func WithStrictCanonicalBodies() Option {
+	return func(s *Server) {
+		s.config.bodyHashing = validator.BodyHashingStrict
+	}
+}
+
+// WithLegacyBodyHashing also accepts transactions that are signed over the hash of their bodies as they are encoded,
+// rather than over the hash of the canonical encoding of their bodies. Clients that predate canonical body hashing
+// sign these hashes.
+// This setting is ignored if the DisableSignatureVerification option is used
+func WithLegacyBodyHashing() Option {
+	return func(s *Server) {
+		s.config.bodyHashing = validator.BodyHashingLegacy
+	}
+}
+
 // WithHashCacheSize how big the cache of hashes used for replay protection
 // is allowed to be. Default is 1MB.
 // This setting is ignored if the DisableSignatureVerification option is used
@@ -45,3 +81,25 @@ func WithHashCacheSize(sizeKB uint) Option {
 		s.config.messageHashCacheSizeKB = sizeKB
 	}
 }
+
+// WithGRPCPort serves the gRPC API on the given port alongside the HTTP server. The gRPC API is disabled by default.
+func WithGRPCPort(port string) Option {
+	return func(s *Server) {
+		s.config.grpcPort = port
+	}
+}
+
+// WithAdminKey enables the admin API, which edits live entity state. Requests to the admin API must carry the given
+// key in the X-Admin-Key header. The admin API is disabled by default and is meant for development and playtests.
+func WithAdminKey(key string) Option {
+	return func(s *Server) {
+		s.config.adminKey = key
+	}
+}
+
+// WithLogger sets the logger the server logs to. By default, the global logger is used.
+func WithLogger(logger *zerolog.Logger) Option {
+	return func(s *Server) {
+		s.logger = logger
+	}
+}
@@ -0,0 +1,147 @@
+package server_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gofiber/fiber/v2"
+
+	"pkg.world.dev/world-engine/cardinal/persona/msg"
+	"pkg.world.dev/world-engine/cardinal/server/utils"
+	"pkg.world.dev/world-engine/sign"
+)
+
+// TestPersonaSignerKeys tests that a persona's signer keys can sign its transactions, but can't change its signers,
+// and that a rotated signer address can no longer sign from the tick after the rotation.
+func (s *ServerTestSuite) TestPersonaSignerKeys() {
+	s.setupWorld()
+	s.fixture.DoTick()
+	personaTag := "key_persona"
+	s.createPersona(personaTag)
+
+	keyPrivateKey, err := crypto.GenerateKey()
+	s.Require().NoError(err)
+	keyAddr := crypto.PubkeyToAddress(keyPrivateKey.PublicKey).Hex()
+	addKey := msg.AddPersonaSignerKey{Label: "phone", SignerAddress: keyAddr}
+	tx, err := sign.NewTransaction(s.privateKey, personaTag, s.world.Namespace(), addKey)
+	s.Require().NoError(err)
+	res := s.fixture.Post(utils.GetTxURL(msg.MessageGroup, msg.AddPersonaSignerKeyMessageName), tx)
+	s.Require().Equal(fiber.StatusOK, res.StatusCode, s.readBody(res.Body))
+	s.fixture.DoTick()
+
+	moveURL := utils.GetTxURL("game", moveMsgName)
+	tx, err = sign.NewTransaction(keyPrivateKey, personaTag, s.world.Namespace(), MoveMsgInput{Direction: "up"})
+	s.Require().NoError(err)
+	s.Require().Equal(fiber.StatusOK, s.fixture.Post(moveURL, tx).StatusCode)
+
+	// The signer key can't rotate the signer address
+	rotateURL := utils.GetTxURL(msg.MessageGroup, msg.RotatePersonaSignerMessageName)
+	rotate := msg.RotatePersonaSigner{SignerAddress: keyAddr}
+	tx, err = sign.NewTransaction(keyPrivateKey, personaTag, s.world.Namespace(), rotate)
+	s.Require().NoError(err)
+	s.Require().Equal(fiber.StatusUnauthorized, s.fixture.Post(rotateURL, tx).StatusCode)
+
+	tx, err = sign.NewTransaction(s.privateKey, personaTag, s.world.Namespace(), rotate)
+	s.Require().NoError(err)
+	res = s.fixture.Post(rotateURL, tx)
+	s.Require().Equal(fiber.StatusOK, res.StatusCode, s.readBody(res.Body))
+
+	// The old signer address can still sign until the rotation has been applied
+	tx, err = sign.NewTransaction(s.privateKey, personaTag, s.world.Namespace(), MoveMsgInput{Direction: "down"})
+	s.Require().NoError(err)
+	s.Require().Equal(fiber.StatusOK, s.fixture.Post(moveURL, tx).StatusCode)
+	s.fixture.DoTick()
+
+	tx, err = sign.NewTransaction(s.privateKey, personaTag, s.world.Namespace(), MoveMsgInput{Direction: "left"})
+	s.Require().NoError(err)
+	s.Require().Equal(fiber.StatusUnauthorized, s.fixture.Post(moveURL, tx).StatusCode)
+	tx, err = sign.NewTransaction(keyPrivateKey, personaTag, s.world.Namespace(), MoveMsgInput{Direction: "left"})
+	s.Require().NoError(err)
+	s.Require().Equal(fiber.StatusOK, s.fixture.Post(moveURL, tx).StatusCode)
+}
+
+// TestPersonaSessionKeys tests that a session key can sign the transactions of the messages in its scope until it has
+// been used up, but can't manage the persona's keys.
+func (s *ServerTestSuite) TestPersonaSessionKeys() {
+	s.setupWorld()
+	s.fixture.DoTick()
+	personaTag := "session_persona"
+	s.createPersona(personaTag)
+
+	sessionPrivateKey, err := crypto.GenerateKey()
+	s.Require().NoError(err)
+	sessionAddr := crypto.PubkeyToAddress(sessionPrivateKey.PublicKey).Hex()
+	authorize := msg.AuthorizeSessionKey{
+		SessionAddress: sessionAddr,
+		Scope:          []string{"game." + moveMsgName},
+		ExpiresAtTick:  s.world.CurrentTick() + 100,
+		MaxUses:        2,
+	}
+	authorizeURL := utils.GetTxURL(msg.MessageGroup, msg.AuthorizeSessionKeyMessageName)
+	tx, err := sign.NewTransaction(s.privateKey, personaTag, s.world.Namespace(), authorize)
+	s.Require().NoError(err)
+	res := s.fixture.Post(authorizeURL, tx)
+	s.Require().Equal(fiber.StatusOK, res.StatusCode, s.readBody(res.Body))
+	s.fixture.DoTick()
+
+	// The session key can't authorize other session keys
+	tx, err = sign.NewTransaction(sessionPrivateKey, personaTag, s.world.Namespace(), authorize)
+	s.Require().NoError(err)
+	s.Require().Equal(fiber.StatusUnauthorized, s.fixture.Post(authorizeURL, tx).StatusCode)
+
+	moveURL := utils.GetTxURL("game", moveMsgName)
+	for i, want := range []int{fiber.StatusOK, fiber.StatusOK, fiber.StatusUnauthorized} {
+		move := MoveMsgInput{Direction: []string{"up", "down", "left"}[i]}
+		tx, err = sign.NewTransaction(sessionPrivateKey, personaTag, s.world.Namespace(), move)
+		s.Require().NoError(err)
+		s.Require().Equal(want, s.fixture.Post(moveURL, tx).StatusCode)
+	}
+}
+
+// TestEd25519PersonaSigner tests that a persona can be owned by an ed25519 key, which signs its transactions and can
+// add signer keys of other signature schemes.
+func (s *ServerTestSuite) TestEd25519PersonaSigner() {
+	s.setupWorld()
+	s.fixture.DoTick()
+	personaTag := "ed25519_persona"
+	edSigner := sign.NewEd25519Signer(ed25519.NewKeyFromSeed(bytes.Repeat([]byte{1}, ed25519.SeedSize)))
+	createPersona := msg.CreatePersona{PersonaTag: personaTag, SignerAddress: edSigner.Address()}
+	tx, err := sign.NewSystemTransactionWithSigner(edSigner, s.world.Namespace(), 0, createPersona)
+	s.Require().NoError(err)
+	res := s.fixture.Post(utils.GetTxURL(msg.MessageGroup, msg.CreatePersonaMessageName), tx)
+	s.Require().Equal(fiber.StatusOK, res.StatusCode, s.readBody(res.Body))
+	s.fixture.DoTick()
+
+	moveURL := utils.GetTxURL("game", moveMsgName)
+	tx, err = sign.NewTransactionWithSigner(edSigner, personaTag, s.world.Namespace(), 0, MoveMsgInput{Direction: "up"})
+	s.Require().NoError(err)
+	res = s.fixture.Post(moveURL, tx)
+	s.Require().Equal(fiber.StatusOK, res.StatusCode, s.readBody(res.Body))
+
+	// An Ethereum key can't sign for the persona until it is added as a signer key
+	tx, err = sign.NewTransaction(s.privateKey, personaTag, s.world.Namespace(), MoveMsgInput{Direction: "up"})
+	s.Require().NoError(err)
+	s.Require().Equal(fiber.StatusUnauthorized, s.fixture.Post(moveURL, tx).StatusCode)
+
+	addKeyURL := utils.GetTxURL(msg.MessageGroup, msg.AddPersonaSignerKeyMessageName)
+	addKey := msg.AddPersonaSignerKey{Label: "wallet", SignerAddress: s.signerAddr}
+	tx, err = sign.NewTransactionWithSigner(edSigner, personaTag, s.world.Namespace(), 0, addKey)
+	s.Require().NoError(err)
+	res = s.fixture.Post(addKeyURL, tx)
+	s.Require().Equal(fiber.StatusOK, res.StatusCode, s.readBody(res.Body))
+	s.fixture.DoTick()
+
+	tx, err = sign.NewTransaction(s.privateKey, personaTag, s.world.Namespace(), MoveMsgInput{Direction: "down"})
+	s.Require().NoError(err)
+	res = s.fixture.Post(moveURL, tx)
+	s.Require().Equal(fiber.StatusOK, res.StatusCode, s.readBody(res.Body))
+
+	// Signers of unknown schemes, and malformed public keys, are rejected
+	for _, signerAddress := range []string{"unknown:0x1234", "ed25519:0x1234"} {
+		addKey = msg.AddPersonaSignerKey{Label: "bad", SignerAddress: signerAddress}
+		tx, err = sign.NewTransactionWithSigner(edSigner, personaTag, s.world.Namespace(), 0, addKey)
+		s.Require().NoError(err)
+		s.Require().Equal(fiber.StatusBadRequest, s.fixture.Post(addKeyURL, tx).StatusCode)
+	}
+}
@@ -1,12 +1,15 @@
 package server_test
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
 
 	"pkg.world.dev/world-engine/cardinal"
 	"pkg.world.dev/world-engine/cardinal/server/handler"
+	"pkg.world.dev/world-engine/cardinal/server/utils"
+	cardinalv1 "pkg.world.dev/world-engine/rift/cardinal/v1"
 	"pkg.world.dev/world-engine/sign"
 )
 
@@ -77,3 +80,34 @@ func (s *ServerTestSuite) TestReceiptsQuery() {
 	s.Require().Equal(string(expectedJSON1), string(json1))
 	s.Require().Equal(string(expectedJSON2), string(json2))
 }
+
+// TestPrivateReceiptsAreNotListed tests that the receipts of persona transactions can't be listed by anyone when
+// receipts are private.
+func (s *ServerTestSuite) TestPrivateReceiptsAreNotListed() {
+	client := s.setupGRPCWorld(cardinal.WithPrivateReceipts())
+	s.fixture.DoTick()
+	personaTag := s.CreateRandomPersona()
+
+	tx, err := sign.NewTransaction(s.privateKey, personaTag, s.world.Namespace(), MoveMsgInput{Direction: "up"})
+	s.Require().NoError(err)
+	res := s.fixture.Post(utils.GetTxURL("game", moveMsgName), tx)
+	s.Require().Equal(http.StatusOK, res.StatusCode, s.readBody(res.Body))
+	s.fixture.DoTick()
+
+	res = s.fixture.Post("query/receipts/list", handler.ListTxReceiptsRequest{})
+	s.Require().Equal(http.StatusOK, res.StatusCode)
+	var reply handler.ListTxReceiptsResponse
+	s.Require().NoError(json.NewDecoder(res.Body).Decode(&reply))
+	// The receipt of the persona's creation is public, as it is a system transaction
+	s.Require().NotEmpty(reply.Receipts)
+	for _, r := range reply.Receipts {
+		s.Require().NotEqual(tx.HashHex(), r.TxHash)
+	}
+
+	grpcReply, err := client.ListReceipts(context.Background(), &cardinalv1.ListReceiptsRequest{})
+	s.Require().NoError(err)
+	s.Require().NotEmpty(grpcReply.GetReceipts())
+	for _, r := range grpcReply.GetReceipts() {
+		s.Require().NotEqual(tx.HashHex(), r.GetTxHash())
+	}
+}
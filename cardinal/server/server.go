@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"net"
 	"time"
 
 	"github.com/gofiber/contrib/socketio"
@@ -10,9 +11,13 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/swagger"
 	"github.com/rotisserie/eris"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 
+	"pkg.world.dev/world-engine/cardinal/admin"
+	"pkg.world.dev/world-engine/cardinal/receipt"
 	"pkg.world.dev/world-engine/cardinal/server/handler"
+	"pkg.world.dev/world-engine/cardinal/server/openapi"
 	servertypes "pkg.world.dev/world-engine/cardinal/server/types"
 	"pkg.world.dev/world-engine/cardinal/server/validator"
 	"pkg.world.dev/world-engine/cardinal/types"
@@ -33,12 +38,20 @@ type config struct {
 	isSignatureValidationDisabled bool
 	messageExpirationSeconds      uint
 	messageHashCacheSizeKB        uint
+	replayProtection              validator.ReplayProtection
+	bodyHashing                   validator.BodyHashing
+	grpcPort                      string
+	adminKey                      string
 }
 
 type Server struct {
-	app       *fiber.App
-	config    config
-	validator *validator.SignatureValidator
+	app           *fiber.App
+	config        config
+	validator     *validator.SignatureValidator
+	sessions      *handler.PersonaSessions
+	subscriptions *handler.CQLSubscriptions
+	grpc          *grpcServer
+	logger        *zerolog.Logger
 }
 
 // New returns an HTTP server with handlers for all QueryTypes and MessageTypes.
@@ -54,7 +67,10 @@ func New(
 	})
 
 	s := &Server{
-		app: app,
+		app:           app,
+		sessions:      handler.NewPersonaSessions(),
+		subscriptions: handler.NewCQLSubscriptions(world),
+		logger:        &log.Logger,
 		config: config{
 			port:                          defaultPort,
 			isSwaggerDisabled:             false,
@@ -68,19 +84,53 @@ func New(
 	}
 
 	// now that all the options are set, use them to create the Signature validator
-	s.validator = validator.NewSignatureValidator(
-		s.config.isSignatureValidationDisabled,
-		s.config.messageExpirationSeconds,
-		s.config.messageHashCacheSizeKB,
-		world.Namespace(),
-		world, // world is a provider of signature addresses
-	)
+	if s.config.replayProtection == validator.ReplayProtectionNonce {
+		s.validator = validator.NewNonceSignatureValidator(
+			s.config.isSignatureValidationDisabled,
+			world.Namespace(),
+			world, // world is a provider of signature addresses
+			world, // and of nonce storage
+		)
+	} else {
+		s.validator = validator.NewSignatureValidator(
+			s.config.isSignatureValidationDisabled,
+			s.config.messageExpirationSeconds,
+			s.config.messageHashCacheSizeKB,
+			world.Namespace(),
+			world, // world is a provider of signature addresses
+		)
+	}
+
+	s.validator.BodyHashing = s.config.bodyHashing
 
 	// Enable CORS
 	app.Use(cors.New())
 
+	msgIndex := buildMessageIndex(messages)
+	// Admin operations can only be submitted through the admin API, never through /tx.
+	adminMsg := msgIndex[admin.MessageGroup][admin.OperationsMessageName]
+	delete(msgIndex, admin.MessageGroup)
+
+	var openAPIDoc []byte
+	if !s.config.isSwaggerDisabled {
+		queries, err := world.BuildQuerySchemas()
+		if err != nil {
+			return nil, eris.Wrap(err, "failed to build query schemas")
+		}
+		// The admin message is left out, as it is not served under /tx.
+		openAPIDoc, err = openapi.Build(world.Namespace(), components, gameMessages(messages), queries)
+		if err != nil {
+			return nil, eris.Wrap(err, "failed to build openapi document")
+		}
+	}
+
 	// Register routes
-	s.setupRoutes(world, messages, components)
+	s.setupRoutes(world, msgIndex, adminMsg, messages, components, openAPIDoc)
+
+	// The gRPC API is only served when a port is configured for it
+	if s.config.grpcPort != "" {
+		s.grpc = newGRPCServer(world, components, messages, msgIndex, s.validator, s.logger)
+	}
 
 	return s, nil
 }
@@ -92,12 +142,25 @@ func (s *Server) Serve(ctx context.Context) error {
 
 	// Starts the server in a new goroutine
 	go func() {
-		log.Info().Msgf("Starting HTTP server at port %s", s.config.port)
+		s.logger.Info().Msgf("Starting HTTP server at port %s", s.config.port)
 		if err := s.app.Listen(":" + s.config.port); err != nil {
 			serverErr <- eris.Wrap(err, "error starting http server")
 		}
 	}()
 
+	if s.grpc != nil {
+		listener, err := net.Listen("tcp", ":"+s.config.grpcPort)
+		if err != nil {
+			return eris.Wrap(err, "error starting grpc server")
+		}
+		go func() {
+			s.logger.Info().Msgf("Starting gRPC server at port %s", s.config.grpcPort)
+			if err := s.grpc.server.Serve(listener); err != nil {
+				serverErr <- eris.Wrap(err, "error serving grpc server")
+			}
+		}()
+	}
+
 	// This function will block until the server is shutdown or the context is canceled.
 	select {
 	case err := <-serverErr:
@@ -111,29 +174,84 @@ func (s *Server) Serve(ctx context.Context) error {
 	return nil
 }
 
+// BroadcastEvent sends the event to all websocket connections of this server.
 func (s *Server) BroadcastEvent(event any) error {
 	eventBz, err := json.Marshal(event)
 	if err != nil {
 		return err
 	}
-	socketio.Broadcast(eventBz)
+	socketio.EmitToList(s.sessions.Connected(), eventBz)
 	return nil
 }
 
+// EmitToPersona sends the event only to the websocket connections that have authenticated as the given persona.
+// Nothing is sent if the persona has no authenticated connections.
+func (s *Server) EmitToPersona(personaTag string, event any) error {
+	sockets := s.sessions.Sockets(personaTag)
+	if len(sockets) == 0 {
+		return nil
+	}
+	eventBz, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	for _, socketUUID := range sockets {
+		if err := socketio.EmitTo(socketUUID, eventBz); err != nil {
+			// The connection may have been closed since it was looked up; there is nothing left to deliver to.
+			s.logger.Debug().Err(err).Msgf("failed to emit event to persona %q", personaTag)
+		}
+	}
+	return nil
+}
+
+// PublishTickResults sends the public results of a completed tick to the clients streaming tick results over gRPC.
+func (s *Server) PublishTickResults(tick uint64, receipts []receipt.Receipt, events [][]byte) {
+	if s.grpc == nil {
+		return
+	}
+	s.grpc.publishTickResults(tick, receipts, events)
+}
+
+// PublishEntityChanges updates the results of the live CQL queries registered over websocket connections with the
+// entities changed by the given tick, and sends the resulting diffs to the connections.
+func (s *Server) PublishEntityChanges(tick uint64, changed []types.EntityID) {
+	diffs, err := s.subscriptions.Diffs(tick, changed)
+	if err != nil {
+		s.logger.Error().Err(err).Msgf("failed to update cql subscriptions for tick %d", tick)
+		return
+	}
+	for socketUUID, socketDiffs := range diffs {
+		for _, diff := range socketDiffs {
+			diffBz, err := json.Marshal(diff)
+			if err != nil {
+				s.logger.Error().Err(err).Msgf("failed to marshal cql diff for tick %d", tick)
+				continue
+			}
+			if err := socketio.EmitTo(socketUUID, diffBz); err != nil {
+				// The connection may have been closed since the diff was computed; there is nothing left to deliver to.
+				s.logger.Debug().Err(err).Msgf("failed to emit cql diff to subscription %q", diff.SubscriptionID)
+			}
+		}
+	}
+}
+
 // Shutdown gracefully shuts down the server and closes all active websocket connections.
 func (s *Server) shutdown() error {
-	log.Info().Msg("Shutting down server")
+	s.logger.Info().Msg("Shutting down server")
 
-	// Close websocket connections
-	socketio.Broadcast([]byte(""), socketio.CloseMessage)
-	socketio.Fire(socketio.EventClose, nil)
+	// Close the websocket connections of this server. Other servers in the same process keep theirs.
+	socketio.EmitToList(s.sessions.Connected(), []byte(""), socketio.CloseMessage)
 
 	// Gracefully shutdown Fiber server
 	if err := s.app.ShutdownWithTimeout(shutdownTimeout); err != nil {
 		return eris.Wrap(err, "error shutting down server")
 	}
 
-	log.Info().Msg("Successfully shut down server")
+	if s.grpc != nil {
+		s.grpc.stop()
+	}
+
+	s.logger.Info().Msg("Successfully shut down server")
 	return nil
 }
 
@@ -146,30 +264,21 @@ func (s *Server) shutdown() error {
 // @produces		application/json
 func (s *Server) setupRoutes(
 	world servertypes.ProviderWorld,
+	msgIndex map[string]map[string]types.Message,
+	adminMsg types.Message,
 	messages []types.Message,
 	components []types.ComponentMetadata,
+	openAPIDoc []byte,
 ) {
-	// /tx/:group/:txType
-	// maps group -> txType -> tx
-	msgIndex := make(map[string]map[string]types.Message)
-
-	// Create tx index
-	for _, msg := range messages {
-		// Initialize inner map if it doesn't exist
-		if _, ok := msgIndex[msg.Group()]; !ok {
-			msgIndex[msg.Group()] = make(map[string]types.Message)
-		}
-		msgIndex[msg.Group()][msg.Name()] = msg
-	}
-
-	// Route: /swagger/
+	// Route: /swagger/ and /openapi.json
 	if !s.config.isSwaggerDisabled {
 		s.app.Get("/swagger/*", swagger.HandlerDefault)
+		s.app.Get("/openapi.json", handler.GetOpenAPI(openAPIDoc))
 	}
 
 	// Route: /events/
 	s.app.Use("/events", handler.WebSocketUpgrader)
-	s.app.Get("/events", handler.WebSocketEvents())
+	s.app.Get("/events", handler.WebSocketEvents(world, s.sessions, s.subscriptions, s.logger))
 
 	// Route: /world
 	s.app.Get("/world", handler.GetWorld(world, components, messages, world.Namespace()))
@@ -184,11 +293,52 @@ func (s *Server) setupRoutes(
 
 	// Route: /tx/...
 	tx := s.app.Group("/tx")
+	tx.Post("/batch", handler.PostTransactionBatch(world, msgIndex, s.validator))
 	tx.Post("/:group/:name", handler.PostTransaction(world, msgIndex, s.validator))
+	tx.Post("/:group/:name/:version", handler.PostVersionedTransaction(world, msgIndex, s.validator))
 
 	// Route: /cql
 	s.app.Post("/cql", handler.PostCQL(world))
 
-	// Route: /debug/state
-	s.app.Post("/debug/state", handler.GetState(world))
+	// Route: /debug/...
+	debug := s.app.Group("/debug")
+	debug.Post("/state", handler.GetState(world))
+	debug.Get("/state/stream", handler.StreamState(world, s.logger))
+	debug.Get("/entity/:id", handler.GetEntity(world))
+	debug.Get("/archetypes", handler.GetArchetypes(world))
+	debug.Get("/archetypes/:id", handler.GetArchetype(world))
+
+	// Route: /admin/... is only served when an admin key is configured
+	if s.config.adminKey != "" {
+		if adminMsg == nil {
+			s.logger.Warn().Msg("admin key is set, but the admin message is not registered; the admin API is disabled")
+		} else {
+			s.app.Post("/admin/operations", handler.PostAdminOperations(world, adminMsg, s.config.adminKey))
+		}
+	}
+}
+
+// buildMessageIndex maps group -> name -> message, as used by /tx/:group/:name.
+func buildMessageIndex(messages []types.Message) map[string]map[string]types.Message {
+	msgIndex := make(map[string]map[string]types.Message)
+	for _, msg := range messages {
+		// Initialize inner map if it doesn't exist
+		if _, ok := msgIndex[msg.Group()]; !ok {
+			msgIndex[msg.Group()] = make(map[string]types.Message)
+		}
+		msgIndex[msg.Group()][msg.Name()] = msg
+	}
+	return msgIndex
+}
+
+// gameMessages returns the messages that are served under /tx.
+func gameMessages(messages []types.Message) []types.Message {
+	msgs := make([]types.Message, 0, len(messages))
+	for _, msg := range messages {
+		if msg.Group() == admin.MessageGroup {
+			continue
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs
 }
@@ -336,6 +336,106 @@ func (s *ServerTestSuite) TestRejectDuplicateTransactionHash() {
 	s.Require().Equal(fiber.StatusForbidden, res.StatusCode, s.readBody(res.Body))
 }
 
+func (s *ServerTestSuite) TestRejectReencodedDuplicateTransaction() {
+	s.setupWorld()
+	s.fixture.DoTick()
+	personaTag := s.CreateRandomPersona()
+	url := utils.GetTxURL("game", moveMsgName)
+
+	tx, err := sign.NewTransaction(s.privateKey, personaTag, s.world.Namespace(), MoveMsgInput{Direction: "up"})
+	s.Require().NoError(err)
+	res := s.fixture.Post(url, tx)
+	s.Require().Equal(fiber.StatusOK, res.StatusCode, s.readBody(res.Body))
+
+	// The same body in another encoding has the same hash, so it is a duplicate rather than a new transaction
+	tx.Body = json.RawMessage(`{"Direction":"\u0075p"}`)
+	res = s.fixture.Post(url, tx)
+	s.Require().Equal(fiber.StatusForbidden, res.StatusCode, s.readBody(res.Body))
+}
+
+func (s *ServerTestSuite) TestStrictCanonicalBodies() {
+	s.setupWorld(cardinal.WithStrictCanonicalBodies())
+	s.fixture.DoTick()
+	personaTag := s.CreateRandomPersona()
+	url := utils.GetTxURL("game", moveMsgName)
+
+	tx, err := sign.NewTransaction(s.privateKey, personaTag, s.world.Namespace(), MoveMsgInput{Direction: "up"})
+	s.Require().NoError(err)
+	tx.Body = json.RawMessage(`{"Direction":"\u0075p"}`)
+	res := s.fixture.Post(url, tx)
+	s.Require().Equal(fiber.StatusBadRequest, res.StatusCode, s.readBody(res.Body))
+
+	tx.Body = json.RawMessage(`{"Direction":"up"}`)
+	res = s.fixture.Post(url, tx)
+	s.Require().Equal(fiber.StatusOK, res.StatusCode, s.readBody(res.Body))
+}
+
+func (s *ServerTestSuite) TestEIP712Transaction() {
+	s.setupWorld()
+	s.fixture.DoTick()
+	personaTag := s.CreateRandomPersona()
+	url := utils.GetTxURL("game", moveMsgName)
+
+	tx, err := sign.NewEIP712Transaction(s.privateKey, personaTag, s.world.Namespace(), 0, MoveMsgInput{Direction: "up"})
+	s.Require().NoError(err)
+	res := s.fixture.Post(url, tx)
+	s.Require().Equal(fiber.StatusOK, res.StatusCode, s.readBody(res.Body))
+
+	// The typed data covers the body, so the signature doesn't hold for another one
+	tx, err = sign.NewEIP712Transaction(s.privateKey, personaTag, s.world.Namespace(), 0, MoveMsgInput{Direction: "up"})
+	s.Require().NoError(err)
+	tx.Body = json.RawMessage(`{"Direction":"down"}`)
+	res = s.fixture.Post(url, tx)
+	s.Require().Equal(fiber.StatusUnauthorized, res.StatusCode, s.readBody(res.Body))
+}
+
+func (s *ServerTestSuite) TestTransactionBatch() {
+	s.setupWorld()
+	s.fixture.DoTick()
+	personaTag := s.CreateRandomPersona()
+	moveMessage, ok := s.world.GetMessageByFullName("game." + moveMsgName)
+	s.Require().True(ok)
+
+	newItem := func(group, name string, tx *sign.Transaction) handler.PostTransactionBatchItem {
+		bz, err := json.Marshal(tx)
+		s.Require().NoError(err)
+		return handler.PostTransactionBatchItem{Group: group, Name: name, Transaction: bz}
+	}
+	validTx, err := sign.NewTransaction(s.privateKey, personaTag, s.world.Namespace(), MoveMsgInput{Direction: "up"})
+	s.Require().NoError(err)
+	otherKey, err := crypto.GenerateKey()
+	s.Require().NoError(err)
+	badSigTx, err := sign.NewTransaction(otherKey, personaTag, s.world.Namespace(), MoveMsgInput{Direction: "up"})
+	s.Require().NoError(err)
+
+	res := s.fixture.Post("/tx/batch", []handler.PostTransactionBatchItem{
+		newItem(moveMessage.Group(), moveMessage.Name(), validTx),
+		newItem(moveMessage.Group(), moveMessage.Name(), validTx),
+		newItem(moveMessage.Group(), moveMessage.Name(), badSigTx),
+		newItem("game", "does-not-exist", validTx),
+	})
+	s.Require().Equal(fiber.StatusOK, res.StatusCode)
+	var batchRes handler.PostTransactionBatchResponse
+	s.Require().NoError(json.Unmarshal([]byte(s.readBody(res.Body)), &batchRes))
+	s.Require().Len(batchRes.Results, 4)
+	s.Require().Equal(fiber.StatusOK, batchRes.Results[0].Status, batchRes.Results[0].Error)
+	s.Require().Equal(validTx.HashHex(), batchRes.Results[0].TxHash)
+	s.Require().Equal(fiber.StatusForbidden, batchRes.Results[1].Status)
+	s.Require().Equal(fiber.StatusUnauthorized, batchRes.Results[2].Status)
+	s.Require().Equal(fiber.StatusNotFound, batchRes.Results[3].Status)
+	s.fixture.DoTick()
+
+	// Only the valid transaction was executed
+	res = s.fixture.Post("query/game/location", QueryLocationRequest{Persona: personaTag})
+	var loc LocationComponent
+	s.Require().NoError(json.Unmarshal([]byte(s.readBody(res.Body)), &loc))
+	s.Require().Equal(LocationComponent{0, 1}, loc)
+
+	// The transaction can't be replayed through the single transaction endpoint either
+	res = s.fixture.Post(utils.GetTxURL(moveMessage.Group(), moveMessage.Name()), validTx)
+	s.Require().Equal(fiber.StatusForbidden, res.StatusCode, s.readBody(res.Body))
+}
+
 // Creates a transaction with the given message, and runs it in a tick.
 func (s *ServerTestSuite) runTx(personaTag string, msg types.Message, payload any) {
 	tx, err := sign.NewTransaction(s.privateKey, personaTag, s.world.Namespace(), payload)
@@ -493,3 +593,84 @@ func (s *ServerTestSuite) TestCQL_NonExistentComponent() {
 	err = json.Unmarshal([]byte(s.readBody(res.Body)), &result)
 	s.Require().Error(err)
 }
+
+func (s *ServerTestSuite) TestCQL_FieldPredicateAndCursor() {
+	s.setupWorld()
+	s.fixture.DoTick()
+
+	wCtx := cardinal.NewWorldContext(s.world)
+	for i := uint64(0); i < 10; i++ {
+		_, err := cardinal.Create(wCtx, LocationComponent{X: i, Y: i % 2})
+		s.Require().NoError(err)
+	}
+
+	s.fixture.DoTick()
+
+	query := "SELECT location.X WHERE location.X >= 4 & location.Y == 0 ORDER BY location.X DESC LIMIT 2"
+	res := s.fixture.Post("/cql", handler.CQLQueryRequest{CQL: query})
+	s.Require().Equal(fiber.StatusOK, res.StatusCode)
+	var result handler.CQLQueryResponse
+	s.Require().NoError(json.Unmarshal([]byte(s.readBody(res.Body)), &result))
+	s.Require().Len(result.Results, 2)
+	s.Require().JSONEq(`{"X":8}`, string(result.Results[0].Data[0]))
+	s.Require().JSONEq(`{"X":6}`, string(result.Results[1].Data[0]))
+	s.Require().NotEmpty(result.NextCursor)
+
+	res = s.fixture.Post("/cql", handler.CQLQueryRequest{CQL: query, Cursor: result.NextCursor})
+	s.Require().Equal(fiber.StatusOK, res.StatusCode)
+	result = handler.CQLQueryResponse{}
+	s.Require().NoError(json.Unmarshal([]byte(s.readBody(res.Body)), &result))
+	s.Require().Len(result.Results, 1)
+	s.Require().JSONEq(`{"X":4}`, string(result.Results[0].Data[0]))
+	s.Require().Empty(result.NextCursor)
+
+	res = s.fixture.Post("/cql", handler.CQLQueryRequest{CQL: "location.X >"})
+	s.Require().Equal(fiber.StatusBadRequest, res.StatusCode)
+	s.Require().Contains(s.readBody(res.Body), "1:13")
+}
+
+// TestCQL_CursorWithoutOrderBy tests that the pages of a query that isn't ordered by fields follow the entity IDs.
+func (s *ServerTestSuite) TestCQL_CursorWithoutOrderBy() {
+	s.setupWorld()
+	s.fixture.DoTick()
+
+	wCtx := cardinal.NewWorldContext(s.world)
+	for i := uint64(0); i < 10; i++ {
+		_, err := cardinal.Create(wCtx, LocationComponent{X: i, Y: i % 2})
+		s.Require().NoError(err)
+	}
+
+	s.fixture.DoTick()
+
+	pages := func(query string) [][]string {
+		var pages [][]string
+		cursor := ""
+		for {
+			res := s.fixture.Post("/cql", handler.CQLQueryRequest{CQL: query, Cursor: cursor})
+			s.Require().Equal(fiber.StatusOK, res.StatusCode)
+			var result handler.CQLQueryResponse
+			s.Require().NoError(json.Unmarshal([]byte(s.readBody(res.Body)), &result))
+			page := make([]string, 0, len(result.Results))
+			for _, entity := range result.Results {
+				page = append(page, string(entity.Data[0]))
+			}
+			pages = append(pages, page)
+			if result.NextCursor == "" {
+				return pages
+			}
+			cursor = result.NextCursor
+		}
+	}
+
+	s.Require().Equal([][]string{
+		{`{"X":0,"Y":0}`, `{"X":1,"Y":1}`, `{"X":2,"Y":0}`},
+		{`{"X":3,"Y":1}`, `{"X":4,"Y":0}`, `{"X":5,"Y":1}`},
+		{`{"X":6,"Y":0}`, `{"X":7,"Y":1}`, `{"X":8,"Y":0}`},
+		{`{"X":9,"Y":1}`},
+	}, pages("CONTAINS(location) LIMIT 3"))
+	// The offset is skipped at the start of every page
+	s.Require().Equal([][]string{
+		{`{"X":2}`, `{"X":4}`},
+		{`{"X":8}`},
+	}, pages("SELECT location.X WHERE location.Y == 0 LIMIT 2 OFFSET 1"))
+}
@@ -3,7 +3,9 @@ package types
 import (
 	"pkg.world.dev/world-engine/cardinal/gamestate"
 	"pkg.world.dev/world-engine/cardinal/receipt"
+	"pkg.world.dev/world-engine/cardinal/server/handler/cql"
 	"pkg.world.dev/world-engine/cardinal/server/validator"
+	"pkg.world.dev/world-engine/cardinal/txpool"
 	"pkg.world.dev/world-engine/cardinal/types"
 	"pkg.world.dev/world-engine/sign"
 )
@@ -13,6 +15,7 @@ type ProviderWorld interface {
 	UseNonce(signerAddress string, nonce uint64) error
 	GetSignerForPersonaTag(personaTag string, tick uint64) (addr string, err error)
 	AddTransaction(id types.MessageID, v any, sig *sign.Transaction) (uint64, types.TxHash)
+	AddTransactions(txs []txpool.TxData) (uint64, []types.TxHash)
 	Namespace() string
 	GetComponentByName(name string) (types.ComponentMetadata, error)
 	StoreReader() gamestate.Reader
@@ -21,6 +24,13 @@ type ProviderWorld interface {
 	ReceiptHistorySize() uint64
 	GetTransactionReceiptsForTick(tick uint64) ([]receipt.Receipt, error)
 	EvaluateCQL(cql string) ([]types.EntityStateElement, error)
+	EvaluateCQLPage(cql string, cursor string) ([]types.EntityStateElement, string, error)
+	ParseCQL(cqlString string) (*cql.Query, error)
+	EvaluateCQLQuery(query *cql.Query, cursor string) ([]*cql.Entity, error)
+	GetCQLEntity(id types.EntityID) (*cql.Entity, error)
 	GetDebugState() ([]types.DebugStateElement, error)
 	BuildQueryFields() []types.FieldDetail
+	BuildQuerySchemas() ([]types.QuerySchema, error)
+	BuildEventFields() []types.FieldDetail
+	BuildResourceFields() []types.FieldDetail
 }
@@ -0,0 +1,109 @@
+package server_test
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"pkg.world.dev/world-engine/cardinal"
+	"pkg.world.dev/world-engine/cardinal/server/handler"
+	"pkg.world.dev/world-engine/cardinal/server/utils"
+	"pkg.world.dev/world-engine/cardinal/validation"
+	"pkg.world.dev/world-engine/sign"
+)
+
+type AttackMsg struct {
+	Target string `json:"target" cardinal:"len=5"`
+	Damage int    `json:"damage" cardinal:"min=1,max=100"`
+	Weapon string `json:"weapon" cardinal:"enum=sword|bow"`
+}
+
+func (a AttackMsg) Validate() error {
+	if a.Weapon == "bow" && a.Damage > 50 {
+		return errors.New("bows can't deal more than 50 damage")
+	}
+	return nil
+}
+
+func (s *ServerTestSuite) TestInvalidMessagesAreRejectedAtIngress() {
+	s.setupWorld()
+	s.Require().NoError(cardinal.RegisterMessage[AttackMsg, MoveMessageOutput](s.world, "attack"))
+	attacks := 0
+	s.Require().NoError(cardinal.RegisterSystems(s.world, func(wCtx cardinal.WorldContext) error {
+		return cardinal.EachMessage[AttackMsg, MoveMessageOutput](wCtx,
+			func(cardinal.TxData[AttackMsg]) (MoveMessageOutput, error) {
+				attacks++
+				return MoveMessageOutput{}, nil
+			})
+	}))
+	s.fixture.DoTick()
+	personaTag := s.CreateRandomPersona()
+	url := utils.GetTxURL("game", "attack")
+
+	post := func(msg AttackMsg) (int, handler.ValidationErrorResponse) {
+		tx, err := sign.NewTransaction(s.privateKey, personaTag, s.world.Namespace(), msg)
+		s.Require().NoError(err)
+		res := s.fixture.Post(url, tx)
+		var body handler.ValidationErrorResponse
+		if res.StatusCode == fiber.StatusBadRequest {
+			s.Require().NoError(json.NewDecoder(res.Body).Decode(&body))
+		}
+		return res.StatusCode, body
+	}
+
+	// Every broken tag rule is reported.
+	status, body := post(AttackMsg{Target: "abc", Damage: 101, Weapon: "axe"})
+	s.Require().Equal(fiber.StatusBadRequest, status)
+	s.Require().Equal([]validation.FieldError{
+		{Field: "target", Rule: "len", Message: "must have a length of 5"},
+		{Field: "damage", Rule: "max", Message: "must be at most 100"},
+		{Field: "weapon", Rule: "enum", Message: "must be one of sword, bow"},
+	}, body.Fields)
+
+	// The Validate method is checked once the tag rules pass.
+	status, body = post(AttackMsg{Target: "alice", Damage: 60, Weapon: "bow"})
+	s.Require().Equal(fiber.StatusBadRequest, status)
+	s.Require().Equal([]validation.FieldError{
+		{Rule: "validate", Message: "bows can't deal more than 50 damage"},
+	}, body.Fields)
+
+	s.fixture.DoTick()
+	s.Require().Equal(0, attacks)
+
+	status, _ = post(AttackMsg{Target: "alice", Damage: 60, Weapon: "sword"})
+	s.Require().Equal(fiber.StatusOK, status)
+	s.fixture.DoTick()
+	s.Require().Equal(1, attacks)
+}
+
+func (s *ServerTestSuite) TestInvalidMessagesInABatchAreRejectedWithTheirFields() {
+	s.setupWorld()
+	s.Require().NoError(cardinal.RegisterMessage[AttackMsg, MoveMessageOutput](s.world, "attack"))
+	s.fixture.DoTick()
+	personaTag := s.CreateRandomPersona()
+
+	valid, err := sign.NewTransaction(s.privateKey, personaTag, s.world.Namespace(),
+		AttackMsg{Target: "alice", Damage: 1, Weapon: "bow"})
+	s.Require().NoError(err)
+	invalid, err := sign.NewTransaction(s.privateKey, personaTag, s.world.Namespace(),
+		AttackMsg{Target: "alice", Damage: 0, Weapon: "bow"})
+	s.Require().NoError(err)
+	items := make([]handler.PostTransactionBatchItem, 0, 2)
+	for _, tx := range []*sign.Transaction{valid, invalid} {
+		bz, err := json.Marshal(tx)
+		s.Require().NoError(err)
+		items = append(items, handler.PostTransactionBatchItem{Group: "game", Name: "attack", Transaction: bz})
+	}
+
+	res := s.fixture.Post("/tx/batch", items)
+	s.Require().Equal(fiber.StatusOK, res.StatusCode)
+	var body handler.PostTransactionBatchResponse
+	s.Require().NoError(json.NewDecoder(res.Body).Decode(&body))
+	s.Require().Len(body.Results, 2)
+	s.Require().Equal(fiber.StatusOK, body.Results[0].Status)
+	s.Require().Equal(fiber.StatusBadRequest, body.Results[1].Status)
+	s.Require().Equal([]validation.FieldError{
+		{Field: "damage", Rule: "min", Message: "must be at least 1"},
+	}, body.Results[1].Fields)
+}
@@ -4,12 +4,15 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"reflect"
 	"time"
 
 	"github.com/coocood/freecache"
 	"github.com/ethereum/go-ethereum/common" // for hash
 	"github.com/rotisserie/eris"
 
+	"pkg.world.dev/world-engine/cardinal/persona/component"
+	"pkg.world.dev/world-engine/cardinal/storage/redis"
 	"pkg.world.dev/world-engine/sign"
 )
 
@@ -19,8 +22,52 @@ type SignerAddressProvider interface {
 	// tick is used by world provider, but not by the validator package. we include it here
 	// to avoid creating an extra method for a very minor bit of abstraction
 	GetSignerForPersonaTag(personaTag string, tick uint64) (addr string, err error)
+	// GetSignerAddressesForPersonaTag returns the signer address of the persona followed by its signer keys.
+	GetSignerAddressesForPersonaTag(personaTag string, tick uint64) (addrs []string, err error)
+	// GetSessionKeysForPersonaTag returns the session keys of the persona, which can sign transactions of the
+	// messages in their scope.
+	GetSessionKeysForPersonaTag(personaTag string, tick uint64) ([]component.SessionKey, error)
+	// UseSession atomically counts a use of the session. redis.ErrSessionUsedUp is returned once the session has been
+	// used maxUses times.
+	UseSession(sessionID string, maxUses uint64) error
+	// CurrentTick is the tick session keys are checked against
+	CurrentTick() uint64
 }
 
+// NonceStorage atomically uses up the nonces of signers. It is only needed for nonce-based replay protection.
+type NonceStorage interface {
+	UseNonce(signerAddress string, nonce uint64) error
+}
+
+// ReplayProtection is the way a SignatureValidator stops a transaction from being handled more than once.
+type ReplayProtection int
+
+const (
+	// ReplayProtectionTimestamp rejects transactions with expired timestamps, and keeps the hashes of the transactions
+	// that haven't expired in an in-memory cache. The clocks of senders must be synchronized with the server's, and
+	// transactions that haven't expired yet can be replayed after a restart.
+	ReplayProtectionTimestamp ReplayProtection = iota
+	// ReplayProtectionNonce requires each transaction to carry a nonce that its signer hasn't used before. Nonces are
+	// used up in NonceStorage, so they stay used across restarts, and timestamps aren't checked.
+	ReplayProtectionNonce
+)
+
+// BodyHashing is how a SignatureValidator treats the encoding of the bodies of transactions. Transactions are hashed
+// over the canonical encoding of their bodies (see sign.CanonicalizeJSON), so that a body can't be reencoded to pass
+// as a different transaction.
+type BodyHashing int
+
+const (
+	// BodyHashingCanonical accepts bodies in any encoding, and verifies signatures over the hash of their canonical
+	// encoding.
+	BodyHashingCanonical BodyHashing = iota
+	// BodyHashingStrict rejects bodies that aren't in canonical encoding with ErrNonCanonicalBody.
+	BodyHashingStrict
+	// BodyHashingLegacy also accepts signatures over the hash of bodies as they are encoded, which is what clients
+	// that predate canonical body hashing sign. Duplicates are still detected by the hash of the canonical encoding.
+	BodyHashingLegacy
+)
+
 const cacheRetentionExtraSeconds = 10 // this is how many seconds past normal expiration a hash is left in the cache.
 // we want to ensure it's long enough that any message that's not expired but
 // still has its hash in the cache for replay protection. Setting it too long
@@ -43,14 +90,21 @@ var (
 	ErrCacheWriteFailed = eris.New("cache store failed")
 	ErrDuplicateMessage = eris.New("duplicate message")
 	ErrInvalidSignature = eris.New("invalid signature")
+	ErrNoNonce          = eris.New("nonce is required")
+	ErrNonceUseFailed   = eris.New("nonce use failed")
+	ErrSessionUseFailed = eris.New("session use failed")
+	ErrNonCanonicalBody = eris.New("body is not canonically encoded")
 )
 
 type SignatureValidator struct {
 	IsDisabled               bool
+	ReplayProtection         ReplayProtection
+	BodyHashing              BodyHashing
 	MessageExpirationSeconds uint
 	HashCacheSizeKB          uint
 	namespace                string
 	cache                    *freecache.Cache
+	nonces                   NonceStorage
 	signerAddressProvider    SignerAddressProvider
 }
 
@@ -72,12 +126,33 @@ func NewSignatureValidator(disabled bool, msgExpirationSec uint, hashCacheSizeKB
 	return &validator
 }
 
+// NewNonceSignatureValidator returns a SignatureValidator that uses nonce-based replay protection. The nonce of each
+// transaction is used up in the given storage once the transaction's signature has been validated.
+func NewNonceSignatureValidator(disabled bool, namespace string, provider SignerAddressProvider, nonces NonceStorage,
+) *SignatureValidator {
+	return &SignatureValidator{
+		IsDisabled:            disabled,
+		ReplayProtection:      ReplayProtectionNonce,
+		namespace:             namespace,
+		nonces:                nonces,
+		signerAddressProvider: provider,
+	}
+}
+
 // ValidateTransactionTTL checks that the timestamp on the message is valid, the message has not expired,
 // and that the message is not previously handled as indicated by it being in the hash cache.
 // returns an error (ErrMessageExpired, ErrBadTimestamp, ErrDuplicateMessage, or ErrCacheReadFailed) if
 // there was a problem, and nil if everything was ok
+// with nonce-based replay protection, the timestamp isn't checked and only the presence of a nonce is (ErrNoNonce)
 // if signature validation is disabled, no checks are done and nil is always returned
 func (validator *SignatureValidator) ValidateTransactionTTL(tx *sign.Transaction) error {
+	if !validator.IsDisabled && validator.ReplayProtection == ReplayProtectionNonce {
+		// the nonce is only used up once the signature has been validated
+		if tx.Nonce == 0 {
+			return eris.Wrap(ErrNoNonce, fmt.Sprintf("missing nonce for message %s", tx.Hash.String()))
+		}
+		return nil
+	}
 	if !validator.IsDisabled { //nolint:nestif // its fine
 		now := time.Now()
 		if validator.MessageExpirationSeconds > math.MaxInt64 {
@@ -113,11 +188,39 @@ func (validator *SignatureValidator) ValidateTransactionTTL(tx *sign.Transaction
 	return nil
 }
 
-// ValidateTransactionSignature checks that the signature is valid, was signed by the persona (or signer passed in),
-// has the correct namespace, and has not been altered. If all checks pass, it is added to the hash cache as a
-// known message, and nil is returned. Other possible returns are ErrNoPersonaTag, ErrInvalidSignature, and
-// ErrCacheWriteFailed. If signature validation is disabled, we only check for the presence of a persona tag.
+// ValidateTransactionBody checks that the body of the transaction is canonically encoded, when bodies are hashed
+// strictly. ErrNonCanonicalBody is returned otherwise.
+// if signature validation is disabled, no checks are done and nil is always returned
+func (validator *SignatureValidator) ValidateTransactionBody(tx *sign.Transaction) error {
+	if validator.IsDisabled || validator.BodyHashing != BodyHashingStrict || tx.IsBodyCanonical() {
+		return nil
+	}
+	return eris.Wrap(ErrNonCanonicalBody, fmt.Sprintf("message %s must be canonical json (RFC 8785)", tx.Hash.String()))
+}
+
+// ValidateTransactionSignature checks that the signature is valid, was signed by the persona (or signer passed in)
+// with either its signer address or one of its signer keys, has the correct namespace, and has not been altered.
+// Signers may be of any signature scheme registered with sign.RegisterScheme, and the signature must be of the scheme
+// of the signer. If
+// all checks pass, it is added to the hash cache as a known message (or its nonce is used up, with nonce-based replay
+// protection), and nil is returned. Other possible returns are ErrNoPersonaTag, ErrInvalidSignature,
+// ErrCacheWriteFailed, ErrDuplicateMessage, and ErrNonceUseFailed.
+// If signature validation is disabled, we only check for the presence of a persona tag.
 func (validator *SignatureValidator) ValidateTransactionSignature(tx *sign.Transaction, signerAddress string,
+) error {
+	return validator.validateTransactionSignature(tx, signerAddress, "")
+}
+
+// ValidateMessageTransactionSignature is like ValidateTransactionSignature for a transaction of the message with the
+// given full name, that is signed by the persona. It also accepts the transaction if it is signed with one of the
+// persona's session keys that has the message in its scope, and that hasn't expired or been used up. The use of the
+// session key is counted, and ErrSessionUseFailed is returned if that fails.
+func (validator *SignatureValidator) ValidateMessageTransactionSignature(tx *sign.Transaction, fullName string) error {
+	return validator.validateTransactionSignature(tx, "", fullName)
+}
+
+func (validator *SignatureValidator) validateTransactionSignature(
+	tx *sign.Transaction, signerAddress string, fullName string,
 ) error {
 	// this is the only validation we do when signature validation is disabled
 	if tx.PersonaTag == "" {
@@ -128,35 +231,145 @@ func (validator *SignatureValidator) ValidateTransactionSignature(tx *sign.Trans
 		return nil
 	}
 
-	// if they didn't give us a signer address, we will have to look it up with the provider
+	// if they didn't give us a signer address, we will have to look up the persona's addresses with the provider,
+	// and the signature has to match one of them
 	var err error
+	var session *component.SessionKey
 	if signerAddress == "" {
-		signerAddress, err = validator.signerAddressProvider.GetSignerForPersonaTag(tx.PersonaTag, 0)
+		signerAddress, session, err = validator.findSigner(tx, fullName)
 		if err != nil {
-			return eris.Wrap(ErrInvalidSignature,
-				fmt.Sprintf("could not get signer for persona %s: %v", tx.PersonaTag, err))
+			return err
 		}
+	} else if err = validator.validateSignature(tx, signerAddress); err != nil {
+		// check the signature against the address
+		return eris.Wrap(ErrInvalidSignature,
+			fmt.Sprintf("signature validation failed for message %s: %v", tx.Hash.String(), err))
+	}
+
+	if validator.ReplayProtection == ReplayProtectionNonce {
+		err = validator.useNonce(tx, signerAddress)
+	} else {
+		// the message was valid, so add its hash to the cache
+		// we don't do this until we have verified the signature to prevent an attack where someone sends
+		// large numbers of hashes with unsigned/invalid messages and thus blocks legit messages from
+		// being handled
+		err = validator.cache.Set(tx.Hash.Bytes(), nil,
+			int(validator.MessageExpirationSeconds+cacheRetentionExtraSeconds))
+		if err != nil {
+			// if we couldn't store the hash in the cache, don't process the transaction, since that
+			// would open us up to replay attacks
+			err = eris.Wrap(ErrCacheWriteFailed,
+				fmt.Sprintf("unexpected cache store error %v. message %s ignored", err, tx.Hash.String()))
+		}
+	}
+	if err != nil || session == nil {
+		return err
+	}
+	// replays were rejected above, so they don't use up the session
+	return validator.useSession(tx, session)
+}
+
+// PrepareTypedData prepares the EIP-712 typed data of the transaction, given the type of its body, so that its
+// signature can be validated. Transactions with a legacy signature are left as they are. ErrInvalidSignature is
+// returned if the typed data can't be generated for the body.
+func (validator *SignatureValidator) PrepareTypedData(tx *sign.Transaction, bodyType reflect.Type) error {
+	if !tx.IsEIP712() || validator.IsDisabled {
+		return nil
+	}
+	if err := tx.PrepareTypedData(bodyType); err != nil {
+		return eris.Wrap(ErrInvalidSignature,
+			fmt.Sprintf("failed to prepare typed data of message %s: %v", tx.Hash.String(), err))
 	}
+	return nil
+}
 
-	// check the signature against the address
-	if err = validator.validateSignature(tx, signerAddress); err != nil {
+// ValidateTransactionPrimarySignature is like ValidateTransactionSignature, but only accepts transactions signed with
+// the signer address of the persona, and not with its signer keys. It is used for the messages that change the
+// persona's signers.
+func (validator *SignatureValidator) ValidateTransactionPrimarySignature(tx *sign.Transaction) error {
+	if tx.PersonaTag == "" || validator.IsDisabled {
+		return validator.ValidateTransactionSignature(tx, "")
+	}
+	signerAddress, err := validator.signerAddressProvider.GetSignerForPersonaTag(tx.PersonaTag, 0)
+	if err != nil {
 		return eris.Wrap(ErrInvalidSignature,
-			fmt.Sprintf("signature validation failed for message %s: %v", tx.Hash.String(), err))
+			fmt.Sprintf("could not get signer for persona %s: %v", tx.PersonaTag, err))
 	}
+	return validator.ValidateTransactionSignature(tx, signerAddress)
+}
 
-	// the message was valid, so add its hash to the cache
-	// we don't do this until we have verified the signature to prevent an attack where someone sends
-	// large numbers of hashes with unsigned/invalid messages and thus blocks legit messages from
-	// being handled
-	err = validator.cache.Set(tx.Hash.Bytes(), nil,
-		int(validator.MessageExpirationSeconds+cacheRetentionExtraSeconds))
+// findSigner returns the address of the persona that signed the transaction. If the transaction is signed with a
+// session key, which is only accepted when the full name of the message is given, the session key is returned too.
+func (validator *SignatureValidator) findSigner(tx *sign.Transaction, fullName string,
+) (string, *component.SessionKey, error) {
+	addrs, err := validator.signerAddressProvider.GetSignerAddressesForPersonaTag(tx.PersonaTag, 0)
 	if err != nil {
-		// if we couldn't store the hash in the cache, don't process the transaction, since that
-		// would open us up to replay attacks
-		return eris.Wrap(ErrCacheWriteFailed,
-			fmt.Sprintf("unexpected cache store error %v. message %s ignored", err, tx.Hash.String()))
+		return "", nil, eris.Wrap(ErrInvalidSignature,
+			fmt.Sprintf("could not get signer for persona %s: %v", tx.PersonaTag, err))
 	}
-	return nil
+	for _, addr := range addrs {
+		err = validator.validateSignature(tx, addr)
+		if err == nil {
+			return addr, nil, nil
+		}
+		if eris.Is(err, ErrWrongNamespace) {
+			return "", nil, eris.Wrap(ErrInvalidSignature,
+				fmt.Sprintf("signature validation failed for message %s: %v", tx.Hash.String(), err))
+		}
+	}
+
+	if fullName != "" {
+		sessions, sessionsErr := validator.signerAddressProvider.GetSessionKeysForPersonaTag(tx.PersonaTag, 0)
+		if sessionsErr != nil {
+			return "", nil, eris.Wrap(ErrInvalidSignature,
+				fmt.Sprintf("could not get session keys for persona %s: %v", tx.PersonaTag, sessionsErr))
+		}
+		// session keys are checked against the wall clock, rather than the timestamp of the transaction, which is
+		// chosen by the signer
+		tick, now := validator.signerAddressProvider.CurrentTick(), uint64(time.Now().UnixMilli())
+		for i, session := range sessions {
+			if !session.InScope(fullName) || session.IsExpired(tick, now) {
+				continue
+			}
+			if validator.validateSignature(tx, session.Address) == nil {
+				return session.Address, &sessions[i], nil
+			}
+		}
+	}
+	return "", nil, eris.Wrap(ErrInvalidSignature,
+		fmt.Sprintf("signature validation failed for message %s: %v", tx.Hash.String(), err))
+}
+
+// useSession counts a use of the session key that signed the transaction. A session key that has been used up can't
+// sign any more transactions.
+func (validator *SignatureValidator) useSession(tx *sign.Transaction, session *component.SessionKey) error {
+	err := validator.signerAddressProvider.UseSession(session.ID(tx.PersonaTag), session.MaxUses)
+	if err == nil {
+		return nil
+	}
+	if eris.Is(err, redis.ErrSessionUsedUp) {
+		return eris.Wrap(ErrInvalidSignature,
+			fmt.Sprintf("session key %s of persona %s has been used up", session.Address, tx.PersonaTag))
+	}
+	return eris.Wrap(ErrSessionUseFailed,
+		fmt.Sprintf("unexpected session storage error %v. message %s ignored", err, tx.Hash.String()))
+}
+
+// useNonce uses up the nonce of the transaction for the signer. Like adding to the hash cache, this is only done once
+// the signature has been validated, so that nobody but the signer can use up the signer's nonces. A nonce that has
+// already been used, or that is too old to tell whether it has been used, makes the transaction a duplicate.
+func (validator *SignatureValidator) useNonce(tx *sign.Transaction, signerAddress string) error {
+	err := validator.nonces.UseNonce(signerAddress, tx.Nonce)
+	if err == nil {
+		return nil
+	}
+	if eris.Is(err, redis.ErrNonceHasAlreadyBeenUsed) || eris.Is(err, redis.ErrNonceTooOld) {
+		return eris.Wrap(ErrDuplicateMessage,
+			fmt.Sprintf("message %s can't use nonce %d: %v", tx.Hash.String(), tx.Nonce, err))
+	}
+	// if we couldn't use up the nonce, don't process the transaction, since that would open us up to replay attacks
+	return eris.Wrap(ErrNonceUseFailed,
+		fmt.Sprintf("unexpected nonce storage error %v. message %s ignored", err, tx.Hash.String()))
 }
 
 func (validator *SignatureValidator) isHashInCache(hash common.Hash) (bool, error) {
@@ -178,5 +391,9 @@ func (validator *SignatureValidator) validateSignature(tx *sign.Transaction, sig
 	if tx.Namespace != validator.namespace {
 		return eris.Wrap(ErrWrongNamespace, fmt.Sprintf("expected %q got %q", validator.namespace, tx.Namespace))
 	}
-	return tx.Verify(signerAddr)
+	err := tx.Verify(signerAddr)
+	if err != nil && validator.BodyHashing == BodyHashingLegacy && !tx.IsBodyCanonical() {
+		err = tx.VerifyLegacyBody(signerAddr)
+	}
+	return err
 }
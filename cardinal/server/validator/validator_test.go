@@ -1,17 +1,24 @@
 package validator
 
 import (
+	"bytes"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"fmt"
+	"reflect"
+	"strconv"
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/rotisserie/eris"
 	"github.com/stretchr/testify/suite"
 
 	"pkg.world.dev/world-engine/cardinal/persona"
+	"pkg.world.dev/world-engine/cardinal/persona/component"
+	"pkg.world.dev/world-engine/cardinal/storage/redis"
 	"pkg.world.dev/world-engine/sign"
 )
 
@@ -43,6 +50,13 @@ type ValidatorTestSuite struct {
 	signerAddr string
 	namespace  string
 	provider   SignerAddressProvider
+
+	// signer keys of goodPersona, in addition to signerAddr
+	signerKeys []string
+	// session keys of goodPersona, and the number of times each session has been used
+	sessionKeys []component.SessionKey
+	sessionUses map[string]uint64
+	tick        uint64
 }
 
 type ProviderFixture struct {
@@ -57,6 +71,33 @@ func (pf *ProviderFixture) GetSignerForPersonaTag(personaTag string, _ uint64) (
 	return pf.vts.signerAddr, nil
 }
 
+func (pf *ProviderFixture) GetSignerAddressesForPersonaTag(personaTag string, tick uint64) (addrs []string, err error) {
+	signerAddr, err := pf.GetSignerForPersonaTag(personaTag, tick)
+	if err != nil {
+		return nil, err
+	}
+	return append([]string{signerAddr}, pf.vts.signerKeys...), nil
+}
+
+func (pf *ProviderFixture) GetSessionKeysForPersonaTag(personaTag string, tick uint64) ([]component.SessionKey, error) {
+	if _, err := pf.GetSignerForPersonaTag(personaTag, tick); err != nil {
+		return nil, err
+	}
+	return pf.vts.sessionKeys, nil
+}
+
+func (pf *ProviderFixture) UseSession(sessionID string, maxUses uint64) error {
+	if maxUses != 0 && pf.vts.sessionUses[sessionID] >= maxUses {
+		return redis.ErrSessionUsedUp
+	}
+	pf.vts.sessionUses[sessionID]++
+	return nil
+}
+
+func (pf *ProviderFixture) CurrentTick() uint64 {
+	return pf.vts.tick
+}
+
 func TestServerValidator(t *testing.T) {
 	suite.Run(t, new(ValidatorTestSuite))
 }
@@ -68,6 +109,7 @@ func (s *ValidatorTestSuite) SetupTest() {
 	s.Require().NoError(err)
 	s.signerAddr = crypto.PubkeyToAddress(s.privateKey.PublicKey).Hex()
 	s.namespace = goodNamespace
+	s.sessionUses = make(map[string]uint64)
 	s.provider = &ProviderFixture{
 		vts: s,
 	}
@@ -82,6 +124,23 @@ func (s *ValidatorTestSuite) createValidatorWithTTL(ttl uint) *SignatureValidato
 	return NewSignatureValidator(false, ttl, 200, s.namespace, s.provider)
 }
 
+// create an enabled validator with nonce-based replay protection that uses up nonces in the given storage
+func (s *ValidatorTestSuite) createNonceValidator(nonces NonceStorage) *SignatureValidator {
+	return NewNonceSignatureValidator(false, s.namespace, s.provider, nonces)
+}
+
+func (s *ValidatorTestSuite) createNonceStorage() NonceStorage {
+	storage := redis.NewRedisStorage(redis.Options{Addr: miniredis.RunT(s.T()).Addr()}, s.namespace)
+	return &storage
+}
+
+func (s *ValidatorTestSuite) simulateReceivedTransactionWithNonce(nonce uint64) *sign.Transaction {
+	tx, err := sign.NewTransactionWithNonce(s.privateKey, goodPersona, goodNamespace, nonce, goodRequestBody)
+	s.Require().NoError(err)
+	tx.Hash = emptyHash
+	return tx
+}
+
 func (s *ValidatorTestSuite) simulateReceivedTransaction(personaTag, namespace string,
 	data any, //nolint: unparam // future use
 ) (*sign.Transaction, error) {
@@ -379,3 +438,265 @@ func (s *ValidatorTestSuite) TestRejectsDuplicateTx() {
 	s.Require().True(eris.Is(err, ErrDuplicateMessage))
 	s.Require().Contains(err.Error(), fmt.Sprintf("message %s already handled", tx.Hash))
 }
+
+// TestNonceValidatorIgnoresTimestamps tests that with nonce-based replay protection, a transaction is accepted no
+// matter its timestamp, as long as its nonce hasn't been used.
+func (s *ValidatorTestSuite) TestNonceValidatorIgnoresTimestamps() {
+	validator := s.createNonceValidator(s.createNonceStorage())
+	for i, timestamp := range []int64{veryOldTimestamp, futureTimestamp} {
+		tx := &sign.Transaction{
+			PersonaTag: goodPersona,
+			Namespace:  goodNamespace,
+			Timestamp:  timestamp,
+			Nonce:      uint64(i + 1),
+			Body:       []byte(goodRequestBody),
+		}
+		buf, err := crypto.Sign(common.HexToHash(tx.HashHex()).Bytes(), s.privateKey)
+		s.Require().NoError(err)
+		tx.Signature = common.Bytes2Hex(buf)
+
+		s.Require().NoError(validator.ValidateTransactionTTL(tx))
+		s.Require().NoError(validator.ValidateTransactionSignature(tx, lookupSignerAddress))
+	}
+}
+
+// TestNonceValidatorRejectsMissingNonce tests that with nonce-based replay protection, a transaction without a nonce is
+// rejected before its signature is validated.
+func (s *ValidatorTestSuite) TestNonceValidatorRejectsMissingNonce() {
+	validator := s.createNonceValidator(s.createNonceStorage())
+	tx, err := s.simulateReceivedTransaction(goodPersona, goodNamespace, goodRequestBody)
+	s.Require().NoError(err)
+	err = validator.ValidateTransactionTTL(tx)
+	s.Require().True(eris.Is(err, ErrNoNonce))
+}
+
+// TestNonceValidatorRejectsReusedNonce tests that a nonce can only be used once, even across restarts, because it is
+// used up in storage rather than in memory.
+func (s *ValidatorTestSuite) TestNonceValidatorRejectsReusedNonce() {
+	nonces := s.createNonceStorage()
+	validator := s.createNonceValidator(nonces)
+	tx := s.simulateReceivedTransactionWithNonce(7)
+	s.Require().NoError(validator.ValidateTransactionTTL(tx))
+	s.Require().NoError(validator.ValidateTransactionSignature(tx, lookupSignerAddress))
+
+	// a restarted server shares the storage, but not the memory of the last one
+	restarted := s.createNonceValidator(nonces)
+	for _, replay := range []*sign.Transaction{tx, s.simulateReceivedTransactionWithNonce(7)} {
+		s.Require().NoError(restarted.ValidateTransactionTTL(replay))
+		err := restarted.ValidateTransactionSignature(replay, lookupSignerAddress)
+		s.Require().True(eris.Is(err, ErrDuplicateMessage))
+	}
+
+	// nonces that are too far behind the latest nonce of the signer are rejected as well
+	tx = s.simulateReceivedTransactionWithNonce(7 + redis.NonceSlidingWindowSize)
+	s.Require().NoError(restarted.ValidateTransactionSignature(tx, lookupSignerAddress))
+	tx = s.simulateReceivedTransactionWithNonce(6)
+	err := restarted.ValidateTransactionSignature(tx, lookupSignerAddress)
+	s.Require().True(eris.Is(err, ErrDuplicateMessage))
+}
+
+// TestNonceValidatorOnlyUsesNoncesOfValidSignatures tests that a transaction with an invalid signature or an altered
+// nonce doesn't use up the signer's nonce.
+func (s *ValidatorTestSuite) TestNonceValidatorOnlyUsesNoncesOfValidSignatures() {
+	validator := s.createNonceValidator(s.createNonceStorage())
+	tx := s.simulateReceivedTransactionWithNonce(3)
+	tx.Nonce++ // alter the nonce
+	err := validator.ValidateTransactionSignature(tx, lookupSignerAddress)
+	s.Require().True(eris.Is(err, ErrInvalidSignature))
+
+	tx = s.simulateReceivedTransactionWithNonce(4)
+	s.Require().NoError(validator.ValidateTransactionSignature(tx, lookupSignerAddress))
+}
+
+// TestSignerKeys tests that a transaction signed with one of the persona's signer keys is accepted, unless the
+// transaction has to be signed with the persona's signer address.
+func (s *ValidatorTestSuite) TestSignerKeys() {
+	keys := make([]*ecdsa.PrivateKey, 2)
+	for i := range keys {
+		var err error
+		keys[i], err = crypto.GenerateKey()
+		s.Require().NoError(err)
+		s.signerKeys = append(s.signerKeys, crypto.PubkeyToAddress(keys[i].PublicKey).Hex())
+	}
+	validator := s.createValidatorWithTTL(10)
+
+	tx, err := sign.NewTransaction(keys[1], goodPersona, s.namespace, goodRequestBody)
+	s.Require().NoError(err)
+	s.Require().NoError(validator.ValidateTransactionSignature(tx, lookupSignerAddress))
+
+	tx, err = sign.NewTransaction(keys[0], goodPersona, s.namespace, goodRequestBody)
+	s.Require().NoError(err)
+	err = validator.ValidateTransactionPrimarySignature(tx)
+	s.Require().True(eris.Is(err, ErrInvalidSignature))
+
+	tx, err = sign.NewTransaction(s.privateKey, goodPersona, s.namespace, goodRequestBody)
+	s.Require().NoError(err)
+	s.Require().NoError(validator.ValidateTransactionPrimarySignature(tx))
+
+	// a key that isn't one of the persona's keys is still rejected
+	otherKey, err := crypto.GenerateKey()
+	s.Require().NoError(err)
+	tx, err = sign.NewTransaction(otherKey, goodPersona, s.namespace, goodRequestBody)
+	s.Require().NoError(err)
+	err = validator.ValidateTransactionSignature(tx, lookupSignerAddress)
+	s.Require().True(eris.Is(err, ErrInvalidSignature))
+}
+
+// TestSessionKeys tests that a session key can only sign transactions of the messages in its scope, until it expires
+// or has been used up.
+func (s *ValidatorTestSuite) TestSessionKeys() {
+	sessionPrivateKey, err := crypto.GenerateKey()
+	s.Require().NoError(err)
+	s.tick = 10
+	s.sessionKeys = []component.SessionKey{{
+		Address:       crypto.PubkeyToAddress(sessionPrivateKey.PublicKey).Hex(),
+		Scope:         []string{"game.move", "shop"},
+		ExpiresAtTick: 20,
+		MaxUses:       3,
+	}}
+	validator := s.createValidatorWithTTL(10)
+	newTx := func() *sign.Transaction {
+		tx, err := sign.NewTransaction(sessionPrivateKey, goodPersona, s.namespace, goodRequestBody)
+		s.Require().NoError(err)
+		return tx
+	}
+
+	s.Require().NoError(validator.ValidateMessageTransactionSignature(newTx(), "game.move"))
+	s.Require().NoError(validator.ValidateMessageTransactionSignature(newTx(), "shop.buy"))
+
+	// Messages outside the scope, and transactions that don't name their message, aren't accepted
+	err = validator.ValidateMessageTransactionSignature(newTx(), "game.attack")
+	s.Require().True(eris.Is(err, ErrInvalidSignature))
+	err = validator.ValidateTransactionSignature(newTx(), lookupSignerAddress)
+	s.Require().True(eris.Is(err, ErrInvalidSignature))
+
+	// A replay doesn't use up the session
+	tx := newTx()
+	s.Require().NoError(validator.ValidateTransactionTTL(tx))
+	s.Require().NoError(validator.ValidateMessageTransactionSignature(tx, "game.move"))
+	s.Require().True(eris.Is(validator.ValidateTransactionTTL(tx), ErrDuplicateMessage))
+
+	// The session has been used 3 times
+	err = validator.ValidateMessageTransactionSignature(newTx(), "game.move")
+	s.Require().True(eris.Is(err, ErrInvalidSignature))
+
+	s.sessionKeys[0].MaxUses = 0
+	s.Require().NoError(validator.ValidateMessageTransactionSignature(newTx(), "game.move"))
+	s.tick = 20
+	err = validator.ValidateMessageTransactionSignature(newTx(), "game.move")
+	s.Require().True(eris.Is(err, ErrInvalidSignature))
+}
+
+// TestCanValidateEIP712SignedTx tests that a transaction signed over its EIP-712 typed data is accepted once its
+// typed data has been prepared with the type of its body, and rejected otherwise.
+func (s *ValidatorTestSuite) TestCanValidateEIP712SignedTx() {
+	type moveMsg struct {
+		Direction string `json:"direction"`
+		Steps     uint8  `json:"steps"`
+	}
+	validator := s.createValidatorWithTTL(10)
+	newTx := func() *sign.Transaction {
+		tx, err := sign.NewEIP712Transaction(s.privateKey, goodPersona, s.namespace, 0, moveMsg{"north", 3})
+		s.Require().NoError(err)
+		// a newly received transaction has neither a hash nor prepared typed data
+		tx.Hash = emptyHash
+		tx.TypedDataHash = emptyHash
+		return tx
+	}
+
+	tx := newTx()
+	s.Require().NoError(validator.PrepareTypedData(tx, reflect.TypeOf(moveMsg{})))
+	s.Require().NoError(validator.ValidateTransactionTTL(tx))
+	s.Require().NoError(validator.ValidateTransactionSignature(tx, lookupSignerAddress))
+
+	// the signature doesn't hold without the typed data
+	err := validator.ValidateTransactionSignature(newTx(), lookupSignerAddress)
+	s.Require().True(eris.Is(err, ErrInvalidSignature))
+
+	// nor for the typed data of another body type
+	type otherMsg struct {
+		Direction string `json:"direction"`
+		Steps     uint16 `json:"steps"`
+	}
+	tx = newTx()
+	s.Require().NoError(validator.PrepareTypedData(tx, reflect.TypeOf(otherMsg{})))
+	err = validator.ValidateTransactionSignature(tx, lookupSignerAddress)
+	s.Require().True(eris.Is(err, ErrInvalidSignature))
+
+	// bodies whose type can't be represented as typed data are rejected
+	tx = newTx()
+	err = validator.PrepareTypedData(tx, reflect.TypeOf(map[string]any{}))
+	s.Require().True(eris.Is(err, ErrInvalidSignature))
+}
+
+// TestCanValidateSignaturesOfOtherSchemes tests that signer keys of other signature schemes than secp256k1 can sign
+// the transactions of a persona.
+func (s *ValidatorTestSuite) TestCanValidateSignaturesOfOtherSchemes() {
+	edSigner := sign.NewEd25519Signer(ed25519.NewKeyFromSeed(bytes.Repeat([]byte{7}, ed25519.SeedSize)))
+	s.signerKeys = []string{edSigner.Address()}
+	validator := s.createValidatorWithTTL(10)
+
+	tx, err := sign.NewTransactionWithSigner(edSigner, goodPersona, s.namespace, 0, goodRequestBody)
+	s.Require().NoError(err)
+	s.Require().NoError(validator.ValidateTransactionTTL(tx))
+	s.Require().NoError(validator.ValidateTransactionSignature(tx, lookupSignerAddress))
+
+	// the signature is only valid for its own signer
+	tx, err = sign.NewTransactionWithSigner(edSigner, goodPersona, s.namespace, 0, goodRequestBody)
+	s.Require().NoError(err)
+	err = validator.ValidateTransactionSignature(tx, s.signerAddr)
+	s.Require().True(eris.Is(err, ErrInvalidSignature))
+
+	// and it can't pass for another scheme
+	tx.Scheme = sign.SchemeWebAuthnP256
+	tx.Hash = emptyHash
+	err = validator.ValidateTransactionSignature(tx, lookupSignerAddress)
+	s.Require().True(eris.Is(err, ErrInvalidSignature))
+}
+
+// TestBodyHashing tests how transactions whose bodies aren't canonically encoded are treated in each BodyHashing mode.
+func (s *ValidatorTestSuite) TestBodyHashing() {
+	// a client that predates canonical body hashing signs the hash of the body as it encoded it
+	legacyBody := []byte(`{"msg": "this is a request body", "at": 1}`)
+	newLegacyTx := func() *sign.Transaction {
+		tx := &sign.Transaction{
+			PersonaTag: goodPersona,
+			Namespace:  s.namespace,
+			Timestamp:  sign.TimestampNow(),
+			Body:       legacyBody,
+		}
+		hash := crypto.Keccak256Hash([]byte(tx.PersonaTag), []byte(tx.Namespace),
+			[]byte(strconv.FormatInt(tx.Timestamp, 10)), legacyBody)
+		sig, err := crypto.Sign(hash.Bytes(), s.privateKey)
+		s.Require().NoError(err)
+		tx.Signature = common.Bytes2Hex(sig)
+		return tx
+	}
+	// a current client signs the hash of the canonical encoding, but may send the body in any encoding
+	newReencodedTx := func() *sign.Transaction {
+		tx, err := sign.NewTransaction(s.privateKey, goodPersona, s.namespace, legacyBody)
+		s.Require().NoError(err)
+		tx.Body = legacyBody
+		tx.Hash = emptyHash
+		return tx
+	}
+
+	validator := s.createValidatorWithTTL(10)
+	s.Require().NoError(validator.ValidateTransactionBody(newLegacyTx()))
+	err := validator.ValidateTransactionSignature(newLegacyTx(), lookupSignerAddress)
+	s.Require().True(eris.Is(err, ErrInvalidSignature))
+	s.Require().NoError(validator.ValidateTransactionSignature(newReencodedTx(), lookupSignerAddress))
+
+	validator = s.createValidatorWithTTL(10)
+	validator.BodyHashing = BodyHashingLegacy
+	s.Require().NoError(validator.ValidateTransactionSignature(newLegacyTx(), lookupSignerAddress))
+	s.Require().NoError(validator.ValidateTransactionSignature(newReencodedTx(), lookupSignerAddress))
+
+	validator = s.createValidatorWithTTL(10)
+	validator.BodyHashing = BodyHashingStrict
+	s.Require().True(eris.Is(validator.ValidateTransactionBody(newReencodedTx()), ErrNonCanonicalBody))
+	tx, err := s.simulateReceivedTransaction(goodPersona, goodNamespace, goodRequestBody)
+	s.Require().NoError(err)
+	s.Require().NoError(validator.ValidateTransactionBody(tx))
+	s.Require().NoError(validator.ValidateTransactionSignature(tx, lookupSignerAddress))
+}
@@ -0,0 +1,54 @@
+package server_test
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"pkg.world.dev/world-engine/cardinal"
+	"pkg.world.dev/world-engine/sign"
+)
+
+type WalkMsgV1 struct {
+	Direction string
+}
+
+type WalkMsg struct {
+	Direction string
+	Steps     int
+}
+
+func (s *ServerTestSuite) TestOlderMessageVersionsAreUpgradedAtIngress() {
+	s.setupWorld()
+	s.Require().NoError(cardinal.RegisterMessage[WalkMsg, MoveMessageOutput](s.world, "walk",
+		cardinal.WithMessageVersion[WalkMsg, MoveMessageOutput](1, func(old WalkMsgV1) (WalkMsg, error) {
+			return WalkMsg{Direction: old.Direction, Steps: 1}, nil
+		}),
+	))
+	var walks []WalkMsg
+	s.Require().NoError(cardinal.RegisterSystems(s.world, func(wCtx cardinal.WorldContext) error {
+		return cardinal.EachMessage[WalkMsg, MoveMessageOutput](wCtx,
+			func(tx cardinal.TxData[WalkMsg]) (MoveMessageOutput, error) {
+				walks = append(walks, tx.Msg)
+				return MoveMessageOutput{}, nil
+			})
+	}))
+	s.fixture.DoTick()
+	personaTag := s.CreateRandomPersona()
+
+	post := func(url string, msg any) int {
+		tx, err := sign.NewTransaction(s.privateKey, personaTag, s.world.Namespace(), msg)
+		s.Require().NoError(err)
+		return s.fixture.Post(url, tx).StatusCode
+	}
+	s.Require().Equal(fiber.StatusOK, post("/tx/game/walk/v1", WalkMsgV1{Direction: "up"}))
+	s.Require().Equal(fiber.StatusOK, post("/tx/game/walk/v2", WalkMsg{Direction: "left", Steps: 2}))
+	s.Require().Equal(fiber.StatusOK, post("/tx/game/walk", WalkMsg{Direction: "down", Steps: 3}))
+	s.Require().Equal(fiber.StatusNotFound, post("/tx/game/walk/v3", WalkMsg{Direction: "down", Steps: 3}))
+	s.Require().Equal(fiber.StatusNotFound, post("/tx/game/walk/latest", WalkMsg{Direction: "down", Steps: 3}))
+	s.fixture.DoTick()
+
+	s.Require().ElementsMatch([]WalkMsg{
+		{Direction: "up", Steps: 1},
+		{Direction: "left", Steps: 2},
+		{Direction: "down", Steps: 3},
+	}, walks)
+}
@@ -65,9 +65,14 @@ func TestErrorWhenSavedArchetypesDoNotMatchComponentTypes(t *testing.T) {
 	tf1 := cardinal.NewTestFixture(t, nil)
 	world1 := tf1.World
 	assert.NilError(t, cardinal.RegisterComponent[OneAlphaNum](world1))
+	assert.NilError(t, cardinal.RegisterComponent[TwoAlphaNum](world1))
+	assert.NilError(t, cardinal.RegisterComponent[TwoBetaNum](world1))
+	assert.NilError(t, cardinal.RegisterComponent[ThreeAlphaNum](world1))
 	tf1.StartWorld()
 
-	_, err := cardinal.Create(cardinal.NewWorldContext(world1), OneAlphaNum{})
+	// Some internal components are registered after the game's components, and take the IDs that follow them. The
+	// entity is created with the last of several components, so that its ID is beyond those IDs.
+	_, err := cardinal.Create(cardinal.NewWorldContext(world1), ThreeAlphaNum{})
 	assert.NilError(t, err)
 	tf1.DoTick()
 
@@ -79,6 +84,9 @@ func TestErrorWhenSavedArchetypesDoNotMatchComponentTypes(t *testing.T) {
 	// It's ok to register extra components.
 	tf3 := cardinal.NewTestFixture(t, tf1.Redis)
 	world3 := tf3.World
+	assert.NilError(t, cardinal.RegisterComponent[OneAlphaNum](world3))
+	assert.NilError(t, cardinal.RegisterComponent[TwoAlphaNum](world3))
+	assert.NilError(t, cardinal.RegisterComponent[TwoBetaNum](world3))
 	assert.NilError(t, cardinal.RegisterComponent[ThreeAlphaNum](world3))
 	assert.NilError(t, cardinal.RegisterComponent[ThreeBetaNum](world3))
 	tf3.StartWorld()
@@ -86,6 +94,9 @@ func TestErrorWhenSavedArchetypesDoNotMatchComponentTypes(t *testing.T) {
 	// Just the right number of components registered
 	tf4 := cardinal.NewTestFixture(t, tf1.Redis)
 	world4 := tf4.World
+	assert.NilError(t, cardinal.RegisterComponent[OneAlphaNum](world4))
+	assert.NilError(t, cardinal.RegisterComponent[TwoAlphaNum](world4))
+	assert.NilError(t, cardinal.RegisterComponent[TwoBetaNum](world4))
 	assert.NilError(t, cardinal.RegisterComponent[FoundAlphaNum](world4))
 	tf4.StartWorld()
 }
@@ -129,6 +140,38 @@ func TestArchetypeIDIsConsistentAfterSaveAndLoad(t *testing.T) {
 	assert.Equal(t, wantID, gotID)
 }
 
+func TestCanLoadStateSavedBeforeInternalComponentsWereAdded(t *testing.T) {
+	// This is the state of a world that saved an entity with a OneBetaNum component, from before cardinal registered
+	// more internal components than the SignerComponent and the taskMetadata component. These are registered before
+	// the game's components, so OneBetaNum was saved with component ID 3.
+	mr := miniredis.RunT(t)
+	savedState := map[string]string{
+		"ECB:ARCHETYPE-ID-TO-COMPONENT-TYPES":       `{"0":[3]}`,
+		"ECB:ARCHETYPE-ID:ENTITY-ID-0":              "0",
+		"ECB:ACTIVE-ENTITY-IDS:ARCHETYPE-ID-0":      "[0]",
+		"ECB:COMPONENT-VALUE:TYPE-ID-3:ENTITY-ID-0": `{"Num":7}`,
+		"ECB:NEXT-ENTITY-ID":                        "1",
+		"ECB:LAST-FINALIZED-TICK":                   "1",
+	}
+	for key, value := range savedState {
+		assert.NilError(t, mr.Set(key, value))
+	}
+
+	tf := cardinal.NewTestFixture(t, mr)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[OneBetaNum](world))
+	tf.StartWorld()
+
+	oneBetaNum, err := world.GetComponentByName(OneBetaNum{}.Name())
+	assert.NilError(t, err)
+	assert.Equal(t, types.ComponentID(3), oneBetaNum.ID())
+
+	num, err := cardinal.GetComponent[OneBetaNum](cardinal.NewReadOnlyWorldContext(world), 0)
+	assert.NilError(t, err)
+	assert.Equal(t, 7, num.Num)
+	tf.DoTick()
+}
+
 func TestCanRecoverArchetypeInformationAfterLoad(t *testing.T) {
 	mr := miniredis.RunT(t)
 	tf1 := cardinal.NewTestFixture(t, mr)
@@ -136,3 +136,27 @@ func TestUsedNoncesAreRememberedAcrossRestart(t *testing.T) {
 		assert.ErrorIs(t, redis.ErrNonceHasAlreadyBeenUsed, err)
 	}
 }
+
+func TestOldNoncesAreRejectedAfterRestart(t *testing.T) {
+	s := miniredis.RunT(t)
+	opts := redis.Options{
+		Addr:     s.Addr(),
+		Password: "", // no password set
+		DB:       0,  // use default DB
+	}
+	rsOne := redis.NewRedisStorage(opts, Namespace)
+
+	addr := "some-addr"
+	total := 2 * redis.NonceSlidingWindowSize
+	for i := 0; i < total; i++ {
+		assert.NilError(t, rsOne.UseNonce(addr, uint64(i)))
+	}
+
+	// The oldest nonces have been pruned, so a new instance must reject them based on the max nonce in storage
+	rsTwo := redis.NewRedisStorage(opts, Namespace)
+	err := rsTwo.UseNonce(addr, 0)
+	assert.ErrorIs(t, redis.ErrNonceTooOld, err)
+	err = rsTwo.UseNonce(addr, uint64(total-1))
+	assert.ErrorIs(t, redis.ErrNonceHasAlreadyBeenUsed, err)
+	assert.NilError(t, rsTwo.UseNonce(addr, uint64(total)))
+}
@@ -5,6 +5,8 @@ import "fmt"
 /*
 	NONCE STORAGE:      ADDRESS_TO_NONCE -> Nonce used for verifying signatures.
 	Hash set of signature address to uint64 nonce
+
+	SESSION STORAGE:    SESSION_ID -> Number of transactions signed with a session key.
 */
 
 func (r *NonceStorage) nonceSetKey(str string) string {
@@ -14,3 +16,7 @@ func (r *NonceStorage) nonceSetKey(str string) string {
 func (r *SchemaStorage) schemaStorageKey() string {
 	return "COMPONENT_NAME_TO_SCHEMA_DATA"
 }
+
+func (r *SessionStorage) sessionUsesKey(sessionID string) string {
+	return fmt.Sprintf("SESSION_USES_%s", sessionID)
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/redis/go-redis/v9"
 	"github.com/rotisserie/eris"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
 
@@ -32,7 +33,10 @@ const (
 	float64MantissaSize = 52
 )
 
-var ErrNonceHasAlreadyBeenUsed = errors.New("nonce has already been used")
+var (
+	ErrNonceHasAlreadyBeenUsed = errors.New("nonce has already been used")
+	ErrNonceTooOld             = errors.New("nonce is too old")
+)
 
 type NonceStorage struct {
 	Client *redis.Client
@@ -44,6 +48,7 @@ type NonceStorage struct {
 	// countNonce tracks the number of nonces stored in redis for each signer address. This count will increase as
 	// nonces are used and decrease as out-of-window nonces are removed from redis.
 	countNonce map[string]int
+	logger     *zerolog.Logger
 }
 
 func NewNonceStorage(client *redis.Client) NonceStorage {
@@ -52,6 +57,7 @@ func NewNonceStorage(client *redis.Client) NonceStorage {
 		mutex:      &sync.Mutex{},
 		maxNonce:   map[string]uint64{},
 		countNonce: map[string]int{},
+		logger:     &log.Logger,
 	}
 }
 
@@ -76,7 +82,8 @@ func (r *NonceStorage) UseNonce(signerAddress string, nonce uint64) error {
 
 	// Nonces beyond the sliding window are invalid and can be rejected outright.
 	if nonce < maxNonce && maxNonce-nonce >= NonceSlidingWindowSize {
-		return eris.New("nonce is too old")
+		return eris.Wrapf(ErrNonceTooOld, "nonce %d is too far behind nonce %d of signer %q",
+			nonce, maxNonce, signerAddress)
 	}
 
 	zItem := redis.Z{
@@ -112,7 +119,7 @@ func (r *NonceStorage) cleanupOldNonces(ctx context.Context, signerAddressKey st
 	maxScore := strconv.FormatUint(currMax-NonceSlidingWindowSize, 10)
 	removed, err := r.Client.ZRemRangeByScore(ctx, signerAddressKey, minScore, maxScore).Result()
 	if err != nil {
-		log.Err(err).Msg("failed to remove old nonces")
+		r.logger.Err(err).Msg("failed to remove old nonces")
 		return
 	}
 	r.countNonce[signerAddressKey] -= int(removed)
@@ -124,8 +131,8 @@ func (r *NonceStorage) getMaxNonceForKey(ctx context.Context, signerAddressKey s
 	if ok {
 		return maxNonce, nil
 	}
-	// There isn't a max nonce in memory. Fetch it from redis.
-	values, err := r.Client.ZRange(ctx, signerAddressKey, -1, 0).Result()
+	// There isn't a max nonce in memory. Fetch it from redis, where it is the last item of the set.
+	values, err := r.Client.ZRange(ctx, signerAddressKey, -1, -1).Result()
 	if err != nil {
 		return 0, eris.Wrap(err, "failed to get range of nonce values")
 	}
@@ -0,0 +1,52 @@
+package redis
+
+import (
+	"context"
+	"errors"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rotisserie/eris"
+)
+
+var ErrSessionUsedUp = errors.New("session has been used up")
+
+// SessionStorage counts the transactions signed with session keys, so that a session key can't sign more transactions
+// than it is allowed to, even across restarts.
+type SessionStorage struct {
+	Client *redis.Client
+}
+
+func NewSessionStorage(client *redis.Client) SessionStorage {
+	return SessionStorage{
+		Client: client,
+	}
+}
+
+// UseSession atomically counts a use of the given session. If the session already has maxUses uses,
+// ErrSessionUsedUp is returned and the use isn't counted. A maxUses of zero means the session can be used any number
+// of times.
+func (r *SessionStorage) UseSession(sessionID string, maxUses uint64) error {
+	ctx := context.Background()
+	uses, err := r.Client.Incr(ctx, r.sessionUsesKey(sessionID)).Uint64()
+	if err != nil {
+		return eris.Wrap(err, "failed to count session use")
+	}
+	if maxUses != 0 && uses > maxUses {
+		// Uses beyond the limit are taken back, so that they aren't reported
+		if err = r.Client.Decr(ctx, r.sessionUsesKey(sessionID)).Err(); err != nil {
+			return eris.Wrap(err, "failed to take back session use")
+		}
+		return eris.Wrapf(ErrSessionUsedUp, "session %q has been used %d times", sessionID, maxUses)
+	}
+	return nil
+}
+
+// GetSessionUses returns the number of times the given session has been used.
+func (r *SessionStorage) GetSessionUses(sessionID string) (uint64, error) {
+	ctx := context.Background()
+	uses, err := r.Client.Get(ctx, r.sessionUsesKey(sessionID)).Uint64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return uses, eris.Wrap(err, "failed to get session uses")
+}
@@ -1,8 +1,6 @@
 package redis
 
 import (
-	"os"
-
 	"github.com/redis/go-redis/v9"
 	"github.com/rotisserie/eris"
 	"github.com/rs/zerolog"
@@ -12,9 +10,10 @@ import (
 type Storage struct {
 	Namespace string
 	Client    *redis.Client
-	Log       zerolog.Logger
+	logger    *zerolog.Logger
 	NonceStorage
 	SchemaStorage
+	SessionStorage
 }
 
 type Options = redis.Options
@@ -22,22 +21,29 @@ type Options = redis.Options
 func NewRedisStorage(options Options, namespace string) Storage {
 	client := redis.NewClient(&options)
 	return Storage{
-		Namespace:     namespace,
-		Client:        client,
-		Log:           zerolog.New(os.Stdout),
-		NonceStorage:  NewNonceStorage(client),
-		SchemaStorage: NewSchemaStorage(client),
+		Namespace:      namespace,
+		Client:         client,
+		logger:         &log.Logger,
+		NonceStorage:   NewNonceStorage(client),
+		SchemaStorage:  NewSchemaStorage(client),
+		SessionStorage: NewSessionStorage(client),
 	}
 }
 
+// SetLogger replaces the logger of the storage, which defaults to the global logger.
+func (r *Storage) SetLogger(logger *zerolog.Logger) {
+	r.logger = logger
+	r.NonceStorage.logger = logger
+}
+
 func (r *Storage) Close() error {
-	log.Debug().Msg("Closing storage connection")
+	r.logger.Debug().Msg("Closing storage connection")
 
 	err := r.Client.Close()
 	if err != nil {
 		return eris.Wrap(err, "")
 	}
 
-	log.Debug().Msg("Successfully closed storage connection")
+	r.logger.Debug().Msg("Successfully closed storage connection")
 	return nil
 }
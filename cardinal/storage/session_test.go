@@ -0,0 +1,38 @@
+package storage_test
+
+import (
+	"testing"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal/storage/redis"
+)
+
+func TestSessionCanBeUsedUpToMaxUses(t *testing.T) {
+	rs := GetRedisStorage(t)
+	sessionID := "persona:0xabc:1"
+	for i := 0; i < 3; i++ {
+		assert.NilError(t, rs.UseSession(sessionID, 3))
+	}
+	for i := 0; i < 2; i++ {
+		assert.ErrorIs(t, rs.UseSession(sessionID, 3), redis.ErrSessionUsedUp)
+	}
+	uses, err := rs.GetSessionUses(sessionID)
+	assert.NilError(t, err)
+	assert.Equal(t, uint64(3), uses)
+
+	// Other sessions have their own uses
+	uses, err = rs.GetSessionUses("persona:0xabc:2")
+	assert.NilError(t, err)
+	assert.Equal(t, uint64(0), uses)
+}
+
+func TestSessionWithoutMaxUsesCanAlwaysBeUsed(t *testing.T) {
+	rs := GetRedisStorage(t)
+	sessionID := "persona:0xabc:1"
+	for i := 0; i < 100; i++ {
+		assert.NilError(t, rs.UseSession(sessionID, 0))
+	}
+	uses, err := rs.GetSessionUses(sessionID)
+	assert.NilError(t, err)
+	assert.Equal(t, uint64(100), uses)
+}
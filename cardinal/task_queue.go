@@ -0,0 +1,119 @@
+package cardinal
+
+import (
+	"slices"
+
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/filter"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+// The pending tasks of each task type are kept in two schedules of the ECS layer, one ordered by trigger tick and one
+// ordered by trigger timestamp, so that taskSystem only reads the tasks that are due instead of every pending task.
+// The schedules are stored through the entity command buffer, so they are committed and discarded along with the rest
+// of the state changes of a tick, and don't need to be rebuilt when Cardinal starts.
+//
+// The schedules may hold entries of tasks that have been cancelled. Those entries are dropped when they come due,
+// after checking them against the ECS layer.
+
+// taskIndex tracks the task types whose schedules have been checked since Cardinal started. The tasks of a world that
+// were created before tasks were scheduled in the ECS layer are added to the schedules of their task type the first
+// time the type is executed. Each World owns its own index.
+type taskIndex struct {
+	checked map[string]bool
+}
+
+// reset forgets the checked task types, so that their schedules are checked again the next time they are used.
+func (index *taskIndex) reset() {
+	index.checked = nil
+}
+
+// taskSchedules returns the names of the schedules of the tasks with the given name, by tick and by timestamp.
+func taskSchedules(taskName string) (byTick string, byTimestamp string) {
+	return "task:" + taskName + ":tick", "task:" + taskName + ":timestamp"
+}
+
+// taskScheduledResource is the name of the resource that marks the schedules of the tasks with the given name as
+// holding all pending tasks of that name.
+func taskScheduledResource(taskName string) string {
+	return "taskScheduled:" + taskName
+}
+
+// dueTasks returns the IDs of the tasks of type T that are due at the current tick and timestamp, in ascending order.
+func dueTasks[T Task](wCtx WorldContext) ([]types.EntityID, error) {
+	if err := scheduleExistingTasks[T](wCtx); err != nil {
+		return nil, err
+	}
+	var t T
+	byTick, byTimestamp := taskSchedules(t.Name())
+	ids, err := wCtx.storeReader().GetScheduled(byTick, wCtx.CurrentTick())
+	if err != nil {
+		return nil, err
+	}
+	idsByTimestamp, err := wCtx.storeReader().GetScheduled(byTimestamp, wCtx.Timestamp())
+	if err != nil {
+		return nil, err
+	}
+	ids = append(ids, idsByTimestamp...)
+	slices.Sort(ids)
+	return slices.Compact(ids), nil
+}
+
+// scheduleExistingTasks adds the pending tasks of type T to the schedules of their type, unless that has been done
+// before. This only finds tasks in worlds whose tasks were created before they were scheduled in the ECS layer.
+func scheduleExistingTasks[T Task](wCtx WorldContext) error {
+	var t T
+	index := wCtx.taskIndex()
+	if index.checked[t.Name()] {
+		return nil
+	}
+	_, ok, err := wCtx.storeReader().GetResource(taskScheduledResource(t.Name()))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		ids, err := NewSearch().Entity(filter.Contains(filter.Component[T](), filter.Component[taskMetadata]())).
+			Collect(wCtx)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			metadata, err := GetComponent[taskMetadata](wCtx, id)
+			if err != nil {
+				return err
+			}
+			if err = queueTask(wCtx, t, id, *metadata); err != nil {
+				return err
+			}
+		}
+		if err = wCtx.storeManager().SetResource(taskScheduledResource(t.Name()), []byte("true")); err != nil {
+			return eris.Wrap(err, "failed to mark the tasks as scheduled")
+		}
+	}
+	if index.checked == nil {
+		index.checked = map[string]bool{}
+	}
+	index.checked[t.Name()] = true
+	return nil
+}
+
+// queueTask schedules a task by its trigger condition in the schedule of its task type.
+func queueTask(wCtx WorldContext, task Task, id types.EntityID, metadata taskMetadata) error {
+	byTick, byTimestamp := taskSchedules(task.Name())
+	if metadata.TriggerAtTick != nil {
+		return wCtx.storeManager().Schedule(byTick, id, *metadata.TriggerAtTick)
+	} else if metadata.TriggerAtTimestamp != nil {
+		return wCtx.storeManager().Schedule(byTimestamp, id, *metadata.TriggerAtTimestamp)
+	}
+	return nil
+}
+
+// unqueueTask removes a task from the schedules of its task type.
+func unqueueTask(wCtx WorldContext, task Task, id types.EntityID) error {
+	byTick, byTimestamp := taskSchedules(task.Name())
+	if err := wCtx.storeManager().Unschedule(byTick, id); err != nil {
+		return err
+	}
+	return wCtx.storeManager().Unschedule(byTimestamp, id)
+}
@@ -0,0 +1,176 @@
+package cardinal
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal/gamestate"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+type queuedTask struct{}
+
+func (queuedTask) Name() string {
+	return "queuedTask"
+}
+
+func (queuedTask) Handle(WorldContext) error {
+	return nil
+}
+
+// TestTaskSystemOnlyLooksAtDueTasks tests that a tick leaves the tasks that aren't due in the schedule, and that tasks
+// which have been cancelled are dropped from the schedule when they come due.
+func TestTaskSystemOnlyLooksAtDueTasks(t *testing.T) {
+	tf := NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, RegisterTask[queuedTask](world))
+
+	var cancelled types.EntityID
+	err := RegisterInitSystems(world, func(wCtx WorldContext) error {
+		for i := uint64(1); i <= 100; i++ {
+			id, err := wCtx.ScheduleTickTask(i, queuedTask{})
+			assert.NilError(t, err)
+			if i == 2 {
+				cancelled = id
+			}
+		}
+		return nil
+	})
+	assert.NilError(t, err)
+	err = RegisterSystems(world, func(wCtx WorldContext) error {
+		if wCtx.CurrentTick() == 1 {
+			return wCtx.CancelTask(cancelled)
+		}
+		return nil
+	})
+	assert.NilError(t, err)
+
+	byTick, _ := taskSchedules(queuedTask{}.Name())
+	scheduled := func() int {
+		ids, err := world.entityStore.GetScheduled(byTick, math.MaxUint64)
+		assert.NilError(t, err)
+		return len(ids)
+	}
+	tf.DoTick()
+	assert.Equal(t, scheduled(), 100)
+
+	tf.DoTick()
+	tf.DoTick()
+	assert.Equal(t, scheduled(), 98)
+
+	wCtx := NewReadOnlyWorldContext(world)
+	res, err := PendingTasksQuery(wCtx, &PendingTasksQueryRequest{})
+	assert.NilError(t, err)
+	assert.Equal(t, len(res.Tasks), 98)
+}
+
+type retriedTask struct{}
+
+func (retriedTask) Name() string {
+	return "retriedTask"
+}
+
+var retriedTaskRuns int
+
+func (retriedTask) Handle(WorldContext) error {
+	retriedTaskRuns++
+	return nil
+}
+
+// TestTaskIsExecutedAgainAfterFailedTick tests that a task that was executed by a tick that failed, and whose state
+// changes were discarded, is executed again by the next tick.
+func TestTaskIsExecutedAgainAfterFailedTick(t *testing.T) {
+	tf := NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, RegisterTask[retriedTask](world))
+
+	err := RegisterInitSystems(world, func(wCtx WorldContext) error {
+		_, err := wCtx.ScheduleTickTask(1, retriedTask{})
+		return err
+	})
+	assert.NilError(t, err)
+	failTick := false
+	err = RegisterSystems(world, func(WorldContext) error {
+		if failTick {
+			return errors.New("tick failed")
+		}
+		return nil
+	})
+	assert.NilError(t, err)
+	tf.StartWorld()
+	retriedTaskRuns = 0
+
+	ctx := context.Background()
+	assert.NilError(t, world.doTick(ctx, 0))
+
+	// The task is executed, but the tick fails after it and its state changes are discarded
+	failTick = true
+	assert.IsError(t, world.doTick(ctx, 0))
+	assert.Equal(t, retriedTaskRuns, 1)
+	ecb, ok := world.entityStore.(*gamestate.EntityCommandBuffer)
+	assert.True(t, ok)
+	assert.NilError(t, ecb.DiscardPending())
+
+	failTick = false
+	assert.NilError(t, world.doTick(ctx, 0))
+	assert.Equal(t, retriedTaskRuns, 2)
+
+	res, err := PendingTasksQuery(NewReadOnlyWorldContext(world), &PendingTasksQueryRequest{})
+	assert.NilError(t, err)
+	assert.Equal(t, len(res.Tasks), 0)
+}
+
+type persistedTask struct{}
+
+func (persistedTask) Name() string {
+	return "persistedTask"
+}
+
+var persistedTaskRuns int
+
+func (persistedTask) Handle(WorldContext) error {
+	persistedTaskRuns++
+	return nil
+}
+
+// TestTasksAreExecutedAfterRestart tests that the tasks scheduled by a world are executed by the world that recovers
+// its state, both when they were scheduled in the ECS layer and when they were created before tasks were scheduled
+// there.
+func TestTasksAreExecutedAfterRestart(t *testing.T) {
+	for _, name := range []string{"scheduled", "created before scheduling"} {
+		t.Run(name, func(t *testing.T) {
+			redis := miniredis.RunT(t)
+			setup := func() *TestFixture {
+				tf := NewTestFixture(t, redis)
+				assert.NilError(t, RegisterTask[persistedTask](tf.World))
+				return tf
+			}
+
+			tf := setup()
+			assert.NilError(t, RegisterInitSystems(tf.World, func(wCtx WorldContext) error {
+				_, err := wCtx.ScheduleTickTask(3, persistedTask{})
+				return err
+			}))
+			tf.DoTick()
+			tf.DoTick()
+			tf.World.Shutdown()
+			if name != "scheduled" {
+				byTick, _ := taskSchedules(persistedTask{}.Name())
+				redis.Del("ECB:SCHEDULE:" + byTick)
+				redis.Del("ECB:RESOURCE:" + taskScheduledResource(persistedTask{}.Name()))
+			}
+
+			persistedTaskRuns = 0
+			tf = setup()
+			tf.DoTick()
+			assert.Equal(t, persistedTaskRuns, 0)
+			tf.DoTick()
+			assert.Equal(t, persistedTaskRuns, 1)
+		})
+	}
+}
@@ -3,7 +3,7 @@ package telemetry
 import (
 	"context"
 
-	"github.com/rs/zerolog/log"
+	"github.com/rs/zerolog"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
 	"go.opentelemetry.io/otel/sdk/resource"
@@ -15,15 +15,17 @@ type Manager struct {
 	namespace          string
 	tracerShutdownFunc func() error
 	tracerProvider     *trace.TracerProvider
+	logger             *zerolog.Logger
 }
 
-func New(enableTrace bool, namespace string) (*Manager, error) {
+func New(enableTrace bool, namespace string, logger *zerolog.Logger) (*Manager, error) {
 	ctx := context.Background()
 
 	tm := Manager{
 		namespace:          namespace,
 		tracerShutdownFunc: nil,
 		tracerProvider:     nil,
+		logger:             logger,
 	}
 
 	// Set up propagator
@@ -46,14 +48,14 @@ func New(enableTrace bool, namespace string) (*Manager, error) {
 // Shutdown calls cleanup functions registered in the telemetry manager.
 // Each registered cleanup will be invoked once and the errors from the calls are joined.
 func (tm *Manager) Shutdown() error {
-	log.Debug().Msg("Shutting down telemetry")
+	tm.logger.Debug().Msg("Shutting down telemetry")
 
 	if tm.tracerShutdownFunc != nil {
 		err := tm.tracerShutdownFunc()
 		return err
 	}
 
-	log.Debug().Msg("Successfully shutdown telemetry")
+	tm.logger.Debug().Msg("Successfully shutdown telemetry")
 	return nil
 }
 
@@ -2,6 +2,7 @@ package cardinal
 
 import (
 	"encoding/json"
+	"strings"
 
 	"github.com/rotisserie/eris"
 
@@ -12,13 +13,19 @@ type TickResults struct {
 	Tick     uint64
 	Receipts []receipt.Receipt
 	Events   [][]byte
+	// PersonaTag is only set on tick results that are delivered privately to a single persona's sessions.
+	PersonaTag string `json:",omitempty"`
+
+	// personaEvents holds events that must only be delivered to a given persona, keyed by lowercase persona tag.
+	personaEvents map[string][][]byte
 }
 
 func NewTickResults(initialTick uint64) *TickResults {
 	return &TickResults{
-		Tick:     initialTick,
-		Receipts: []receipt.Receipt{},
-		Events:   [][]byte{},
+		Tick:          initialTick,
+		Receipts:      []receipt.Receipt{},
+		Events:        [][]byte{},
+		personaEvents: map[string][][]byte{},
 	}
 }
 
@@ -36,6 +43,45 @@ func (tr *TickResults) AddStringEvent(e string) error {
 	return nil
 }
 
+// AddPersonaEvent adds an event that will only be delivered to websocket sessions authenticated as personaTag.
+func (tr *TickResults) AddPersonaEvent(personaTag string, event any) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return eris.Wrap(err, "must use a json serializable type for emitting events")
+	}
+	if tr.personaEvents == nil {
+		tr.personaEvents = map[string][][]byte{}
+	}
+	personaTag = strings.ToLower(personaTag)
+	tr.personaEvents[personaTag] = append(tr.personaEvents[personaTag], data)
+	return nil
+}
+
+// PersonaTickResults returns the tick results that must be delivered privately, keyed by lowercase persona tag.
+// Each entry contains the events targeted at that persona along with the given receipts that belong to it.
+func (tr *TickResults) PersonaTickResults(receipts map[string][]receipt.Receipt) map[string]*TickResults {
+	results := map[string]*TickResults{}
+	get := func(personaTag string) *TickResults {
+		if _, ok := results[personaTag]; !ok {
+			results[personaTag] = &TickResults{
+				Tick:       tr.Tick,
+				Receipts:   []receipt.Receipt{},
+				Events:     [][]byte{},
+				PersonaTag: personaTag,
+			}
+		}
+		return results[personaTag]
+	}
+	for personaTag, events := range tr.personaEvents {
+		get(personaTag).Events = events
+	}
+	for personaTag, recs := range receipts {
+		personaTag = strings.ToLower(personaTag)
+		get(personaTag).Receipts = append(get(personaTag).Receipts, recs...)
+	}
+	return results
+}
+
 func (tr *TickResults) SetReceipts(newReceipts []receipt.Receipt) {
 	tr.Receipts = newReceipts
 }
@@ -48,4 +94,5 @@ func (tr *TickResults) Clear() {
 	tr.Tick = 0
 	tr.Receipts = nil
 	tr.Events = nil
+	tr.personaEvents = map[string][][]byte{}
 }
@@ -20,6 +20,8 @@ type TxData struct {
 	Tx     *sign.Transaction
 	// EVMSourceTxHash is the tx hash of the EVM tx that triggered this tx.
 	EVMSourceTxHash string
+	// Version is the version of the message that the body of Tx is a payload of. 0 stands for the latest version.
+	Version uint32
 }
 
 type TxPool struct {
@@ -67,6 +69,21 @@ func (t *TxPool) AddEVMTransaction(id types.MessageID, v any, sig *sign.Transact
 	return t.addTransaction(id, v, sig, evmTxHash)
 }
 
+// AddTransactions adds all the given transactions to the pool at once, so they are guaranteed to be executed in the
+// same tick. The hash of each transaction is returned in the same order.
+func (t *TxPool) AddTransactions(txs []TxData) []types.TxHash {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	hashes := make([]types.TxHash, 0, len(txs))
+	for _, tx := range txs {
+		tx.TxHash = types.TxHash(tx.Tx.HashHex())
+		t.m[tx.MsgID] = append(t.m[tx.MsgID], tx)
+		t.txsInPool++
+		hashes = append(hashes, tx.TxHash)
+	}
+	return hashes
+}
+
 func (t *TxPool) addTransaction(id types.MessageID, v any, sig *sign.Transaction, evmTxHash string) types.TxHash {
 	t.mux.Lock()
 	defer t.mux.Unlock()
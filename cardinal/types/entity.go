@@ -2,8 +2,37 @@ package types
 
 import "encoding/json"
 
+// EntityID identifies an entity. The lower 32 bits of an EntityID are the index of the entity, and the 21 bits above
+// them are its generation, so that IDs stay below 2^53 and can be represented exactly in JSON numbers. The generation
+// of an index is increased when an entity is removed and its index is reused for a new entity, which makes IDs of
+// removed entities stale instead of referring to the new entity. Indices are only reused if entity ID recycling is
+// enabled, so the generation of all IDs is 0 otherwise.
 type EntityID uint64
 
+const (
+	entityIndexBits = 32
+
+	// MaxEntityIndex is the largest index of an entity.
+	MaxEntityIndex = 1<<entityIndexBits - 1
+	// MaxEntityGeneration is the largest generation of an entity. Indices that reach it aren't reused anymore.
+	MaxEntityGeneration = 1<<21 - 1
+)
+
+// NewEntityID returns the EntityID of the entity with the given index and generation.
+func NewEntityID(index uint64, generation uint32) EntityID {
+	return EntityID(uint64(generation)<<entityIndexBits | index&MaxEntityIndex)
+}
+
+// Index returns the index of the entity.
+func (id EntityID) Index() uint64 {
+	return uint64(id) & MaxEntityIndex
+}
+
+// Generation returns the generation of the entity.
+func (id EntityID) Generation() uint32 {
+	return uint32(uint64(id) >> entityIndexBits) //nolint:gosec // generations fit in 21 bits
+}
+
 type EntityStateElement struct {
 	ID   EntityID          `json:"id"`
 	Data []json.RawMessage `json:"data" swaggertype:"object"`
@@ -2,4 +2,7 @@ package types
 
 import "github.com/rotisserie/eris"
 
-var ErrQueryNotFound = eris.New("query not found")
+var (
+	ErrQueryNotFound          = eris.New("query not found")
+	ErrMessageVersionNotFound = eris.New("message version not found")
+)
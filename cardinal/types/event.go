@@ -0,0 +1,13 @@
+package types
+
+// Event is the interface that the user needs to implement to create a new typed event.
+type Event interface {
+	// Name returns the name of the event.
+	Name() string
+}
+
+// TypedEvent is the envelope that typed events are emitted in, which lets clients tell the type of an event apart.
+type TypedEvent struct {
+	Event string `json:"event"`
+	Data  any    `json:"data"`
+}
@@ -1,8 +1,22 @@
 package types
 
+import "encoding/json"
+
 // FieldDetail represents a field from a url request.
 type FieldDetail struct {
 	Name   string         `json:"name"`   // name of the message or query
 	Fields map[string]any `json:"fields"` // variable name and type
 	URL    string         `json:"url,omitempty"`
+	// Schema is the JSON schema of the component, event, message input or query request.
+	Schema json.RawMessage `json:"schema,omitempty"`
+	// ResultSchema is the JSON schema of the message output or query reply.
+	ResultSchema json.RawMessage `json:"resultSchema,omitempty"`
+}
+
+// QuerySchema holds the JSON schemas of the request and reply of a registered query.
+type QuerySchema struct {
+	Group   string
+	Name    string
+	Request []byte
+	Reply   []byte
 }
@@ -1,5 +1,7 @@
 package types
 
+import "reflect"
+
 type Message interface {
 	SetID(MessageID) error
 	Name() string
@@ -11,6 +13,16 @@ type Message interface {
 	ID() MessageID
 	Encode(any) ([]byte, error)
 	Decode([]byte) (any, error)
+	// DecodeVersion decodes the payload of the given version of the message, upgrading it to the message's input type.
+	DecodeVersion(uint32, []byte) (any, error)
+	// Version returns the version of the message's input type.
+	Version() uint32
+	// Versions returns every version of the message that can be submitted, in ascending order.
+	Versions() []uint32
+	// PayloadType returns the type of the payload of the given version of the message.
+	PayloadType(uint32) (reflect.Type, error)
+	// Validate checks a decoded message against the validation rules of the message's input type.
+	Validate(any) error
 	// DecodeEVMBytes decodes ABI encoded bytes into the message's input type.
 	DecodeEVMBytes([]byte) (any, error)
 	// ABIEncode encodes the given type in ABI encoding, given that the input is the message type's input or output
@@ -21,6 +33,10 @@ type Message interface {
 
 	// GetInFieldInformation returns a map of the fields of the message's "In" type and it's field types.
 	GetInFieldInformation() map[string]any
+	// GetInSchema returns the JSON schema of the message's "In" type.
+	GetInSchema() ([]byte, error)
+	// GetOutSchema returns the JSON schema of the message's "Out" type.
+	GetOutSchema() ([]byte, error)
 }
 
 // MessageID represents a message's id.
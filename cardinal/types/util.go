@@ -1,6 +1,11 @@
 package types
 
-import "reflect"
+import (
+	"reflect"
+
+	"github.com/invopop/jsonschema"
+	"github.com/rotisserie/eris"
+)
 
 // GetFieldInformation returns a map of the fields of a struct and their types.
 func GetFieldInformation(t reflect.Type) map[string]any {
@@ -28,3 +33,12 @@ func GetFieldInformation(t reflect.Type) map[string]any {
 
 	return fieldMap
 }
+
+// GetJSONSchema returns the JSON schema of the given type.
+func GetJSONSchema(t reflect.Type) ([]byte, error) {
+	schema, err := jsonschema.ReflectFromType(t).MarshalJSON()
+	if err != nil {
+		return nil, eris.Wrapf(err, "failed to generate json schema for %s", t)
+	}
+	return schema, nil
+}
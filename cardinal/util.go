@@ -2,6 +2,7 @@ package cardinal
 
 import (
 	"github.com/rotisserie/eris"
+	"github.com/rs/zerolog"
 
 	"pkg.world.dev/world-engine/cardinal/router"
 	"pkg.world.dev/world-engine/cardinal/server"
@@ -12,28 +13,39 @@ var NonFatalError = []error{
 	ErrComponentNotOnEntity,
 	ErrComponentAlreadyOnEntity,
 	ErrEntityMustHaveAtLeastOneComponent,
+	ErrRelationNotRegistered,
+	ErrRelationCycle,
 }
 
-// separateOptions separates the given options into ecs options, server options, and cardinal (this package) options.
-// The different options are all grouped together to simplify the end user's experience, but under the hood different
-// options are meant for different sub-systems.
+// separateOptions separates the given options into the explicit config, server options, router options, logger
+// options, and cardinal (this package) options. The config is nil if no option sets it; if several options set it, the
+// last one wins. The different options are all grouped together to simplify the end user's experience, but under the
+// hood different options are meant for different sub-systems.
 func separateOptions(opts []WorldOption) (
+	cfg *WorldConfig,
 	serverOptions []server.Option,
 	routerOptions []router.Option,
+	loggerOptions []func(*zerolog.Logger),
 	cardinalOptions []Option,
 ) {
 	for _, opt := range opts {
+		if opt.config != nil {
+			cfg = opt.config
+		}
 		if opt.serverOption != nil {
 			serverOptions = append(serverOptions, opt.serverOption)
 		}
 		if opt.routerOption != nil {
 			routerOptions = append(routerOptions, opt.routerOption)
 		}
+		if opt.loggerOption != nil {
+			loggerOptions = append(loggerOptions, opt.loggerOption)
+		}
 		if opt.cardinalOption != nil {
 			cardinalOptions = append(cardinalOptions, opt.cardinalOption)
 		}
 	}
-	return serverOptions, routerOptions, cardinalOptions
+	return cfg, serverOptions, routerOptions, loggerOptions, cardinalOptions
 }
 
 // panicOnFatalError is a helper function to panic on non-deterministic errors (i.e. Redis error).
@@ -0,0 +1,491 @@
+// Package validation checks messages against the rules declared on their types, so that invalid messages can be
+// rejected before they are sequenced.
+//
+// Rules are declared in the `cardinal` tag of a field, separated by commas. The tag is specific to Cardinal, so that
+// tags written for other validation libraries, such as `validate`, are left alone:
+//
+//	type Attack struct {
+//		Target string `json:"target" cardinal:"len=8"`
+//		Damage int    `json:"damage" cardinal:"min=1,max=100"`
+//		Kind   string `json:"kind" cardinal:"enum=melee|ranged"`
+//		Guild  string `json:"guild" cardinal:"regexp=^[a-z]+(-[a-z]+)*$"`
+//	}
+//
+// min and max bound the value of numbers, and the length of strings, slices, and maps. len requires an exact length.
+// enum lists the allowed values, separated by |. regexp requires strings to match a pattern, and takes the rest of the
+// tag, so it must be the last rule. Nil pointers are not checked.
+//
+// Types may also implement Validator for rules that can't be expressed in tags. Validate is only called once the
+// rules in the tags pass.
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rotisserie/eris"
+)
+
+// Tag is the struct tag that holds the rules of a field.
+const Tag = "cardinal"
+
+// Validator is implemented by types that validate themselves. The error may be an *Error to point at the invalid
+// fields.
+type Validator interface {
+	Validate() error
+}
+
+// FieldError describes a field that broke one of its rules. Field is the path to the field, using the JSON names of
+// the fields, e.g. "items[2].count". It is empty for errors returned by Validate that aren't about a single field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Error is returned when a value is invalid. It lists every rule that was broken.
+type Error struct {
+	Fields []FieldError
+}
+
+func (e *Error) Error() string {
+	msgs := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		if f.Field == "" {
+			msgs = append(msgs, f.Message)
+		} else {
+			msgs = append(msgs, f.Field+" "+f.Message)
+		}
+	}
+	return "validation failed: " + strings.Join(msgs, "; ")
+}
+
+// Rules are the compiled rules of a struct type.
+type Rules struct {
+	fields []fieldRules
+}
+
+type fieldRules struct {
+	index  int
+	name   string
+	checks []check
+	// nested holds the rules of the fields of a struct field, or of the elements of a slice, array, or map of structs.
+	nested *Rules
+}
+
+type check struct {
+	rule string
+	// fails returns a message describing why the value broke the rule, or an empty string if it didn't.
+	fails func(v reflect.Value) string
+}
+
+// Compile reads the rules of a struct type, or pointer to a struct type, from its tags. An error is returned if a rule
+// is unknown, malformed, or doesn't apply to the type of its field.
+func Compile(t reflect.Type) (*Rules, error) {
+	return compile(t, make(map[reflect.Type]*Rules))
+}
+
+func compile(t reflect.Type, seen map[reflect.Type]*Rules) (*Rules, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, eris.Errorf("validation rules can only be declared on structs, got %s", t)
+	}
+	if rules, ok := seen[t]; ok {
+		return rules, nil
+	}
+	rules := &Rules{}
+	seen[t] = rules
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := jsonName(field)
+		if name == "-" {
+			continue
+		}
+
+		f := fieldRules{index: i, name: name}
+		if tag, ok := field.Tag.Lookup(Tag); ok {
+			checks, err := parseTag(tag, field.Type)
+			if err != nil {
+				return nil, eris.Wrapf(err, "invalid validation rules on %s.%s", t.Name(), field.Name)
+			}
+			f.checks = checks
+		}
+		if elem := nestedStruct(field.Type); elem != nil {
+			nested, err := compile(elem, seen)
+			if err != nil {
+				return nil, err
+			}
+			f.nested = nested
+		}
+		if len(f.checks) > 0 || f.nested != nil {
+			rules.fields = append(rules.fields, f)
+		}
+	}
+	return rules, nil
+}
+
+// Validate checks the value against the rules, and then calls its Validate method if it implements Validator. An
+// *Error listing every broken rule is returned if the value is invalid.
+func (r *Rules) Validate(v any) error {
+	value := reflect.ValueOf(v)
+	var errs []FieldError
+	if r != nil {
+		r.validate(value, "", &errs)
+	}
+	if len(errs) == 0 {
+		errs = callValidator(value)
+	}
+	if len(errs) > 0 {
+		return &Error{Fields: errs}
+	}
+	return nil
+}
+
+func (r *Rules) validate(v reflect.Value, path string, errs *[]FieldError) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return
+	}
+	for _, f := range r.fields {
+		fv := v.Field(f.index)
+		fieldPath := joinPath(path, f.name)
+		for _, c := range f.checks {
+			if msg := c.fails(fv); msg != "" {
+				*errs = append(*errs, FieldError{Field: fieldPath, Rule: c.rule, Message: msg})
+			}
+		}
+		if f.nested != nil {
+			f.nested.validateNested(fv, fieldPath, errs)
+		}
+	}
+}
+
+func (r *Rules) validateNested(v reflect.Value, path string, errs *[]FieldError) {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() { //nolint:exhaustive // other kinds hold no structs
+	case reflect.Struct:
+		r.validate(v, path, errs)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			r.validateNested(v.Index(i), fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			r.validateNested(iter.Value(), fmt.Sprintf("%s[%v]", path, iter.Key()), errs)
+		}
+	}
+}
+
+func callValidator(v reflect.Value) []FieldError {
+	if !v.IsValid() || (v.Kind() == reflect.Pointer && v.IsNil()) {
+		return nil
+	}
+	validator, ok := v.Interface().(Validator)
+	if !ok && v.Kind() != reflect.Pointer {
+		// Validate may be declared on the pointer receiver.
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		validator, ok = ptr.Interface().(Validator)
+	}
+	if !ok {
+		return nil
+	}
+	err := validator.Validate()
+	if err == nil {
+		return nil
+	}
+	var validationErr *Error
+	if eris.As(err, &validationErr) {
+		return validationErr.Fields
+	}
+	return []FieldError{{Rule: "validate", Message: err.Error()}}
+}
+
+func parseTag(tag string, t reflect.Type) ([]check, error) {
+	var checks []check
+	for tag != "" {
+		var rule string
+		rule, tag, _ = strings.Cut(tag, ",")
+		name, param, _ := strings.Cut(rule, "=")
+		if name == "regexp" {
+			// Patterns may contain commas, so regexp takes the rest of the tag.
+			if tag != "" {
+				param += "," + tag
+				tag = ""
+			}
+		}
+		c, err := newCheck(name, param, t)
+		if err != nil {
+			return nil, err
+		}
+		checks = append(checks, c)
+	}
+	return checks, nil
+}
+
+func newCheck(rule, param string, t reflect.Type) (check, error) {
+	nilable := t.Kind() == reflect.Pointer
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	var fails func(v reflect.Value) string
+	var err error
+	switch rule {
+	case "min", "max":
+		fails, err = newBound(rule == "min", param, t)
+	case "len":
+		fails, err = newLen(param, t)
+	case "enum":
+		fails, err = newEnum(param, t)
+	case "regexp":
+		fails, err = newRegexp(param, t)
+	default:
+		return check{}, eris.Errorf("unknown rule %q", rule)
+	}
+	if err != nil {
+		return check{}, eris.Wrapf(err, "invalid %s rule", rule)
+	}
+
+	if nilable {
+		inner := fails
+		fails = func(v reflect.Value) string {
+			for v.Kind() == reflect.Pointer {
+				if v.IsNil() {
+					return ""
+				}
+				v = v.Elem()
+			}
+			return inner(v)
+		}
+	}
+	return check{rule: rule, fails: fails}, nil
+}
+
+func newBound(isMin bool, param string, t reflect.Type) (func(reflect.Value) string, error) {
+	verb := "most"
+	if isMin {
+		verb = "least"
+	}
+	outOfBounds := func(cmp int) bool { return (isMin && cmp < 0) || (!isMin && cmp > 0) }
+
+	switch {
+	case isInt(t):
+		bound, err := strconv.ParseInt(param, 10, 64)
+		if err != nil {
+			return nil, eris.Wrapf(err, "bound of %s must be an integer", t)
+		}
+		msg := fmt.Sprintf("must be at %s %d", verb, bound)
+		return func(v reflect.Value) string {
+			return failIf(outOfBounds(compare(v.Int(), bound)), msg)
+		}, nil
+	case isUint(t):
+		bound, err := strconv.ParseUint(param, 10, 64)
+		if err != nil {
+			return nil, eris.Wrapf(err, "bound of %s must be a non negative integer", t)
+		}
+		msg := fmt.Sprintf("must be at %s %d", verb, bound)
+		return func(v reflect.Value) string {
+			return failIf(outOfBounds(compare(v.Uint(), bound)), msg)
+		}, nil
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		bound, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return nil, eris.Wrapf(err, "bound of %s must be a number", t)
+		}
+		msg := fmt.Sprintf("must be at %s %s", verb, param)
+		return func(v reflect.Value) string {
+			return failIf(outOfBounds(compare(v.Float(), bound)), msg)
+		}, nil
+	case hasLen(t):
+		bound, err := parseLen(param)
+		if err != nil {
+			return nil, err
+		}
+		msg := fmt.Sprintf("must have a length of at %s %d", verb, bound)
+		return func(v reflect.Value) string {
+			return failIf(outOfBounds(compare(length(v), bound)), msg)
+		}, nil
+	default:
+		return nil, eris.Errorf("does not apply to %s", t)
+	}
+}
+
+func newLen(param string, t reflect.Type) (func(reflect.Value) string, error) {
+	if !hasLen(t) {
+		return nil, eris.Errorf("does not apply to %s", t)
+	}
+	want, err := parseLen(param)
+	if err != nil {
+		return nil, err
+	}
+	msg := fmt.Sprintf("must have a length of %d", want)
+	return func(v reflect.Value) string {
+		return failIf(length(v) != want, msg)
+	}, nil
+}
+
+func newEnum(param string, t reflect.Type) (func(reflect.Value) string, error) {
+	if t.Kind() != reflect.String && t.Kind() != reflect.Bool && !isInt(t) && !isUint(t) {
+		return nil, eris.Errorf("does not apply to %s", t)
+	}
+	if param == "" {
+		return nil, eris.New("no values are allowed")
+	}
+	values := strings.Split(param, "|")
+	allowed := make(map[string]bool, len(values))
+	for _, value := range values {
+		allowed[value] = true
+	}
+	msg := "must be one of " + strings.Join(values, ", ")
+	return func(v reflect.Value) string {
+		return failIf(!allowed[formatScalar(v)], msg)
+	}, nil
+}
+
+// formatScalar formats the value the way it is written in an enum rule. Unlike fmt, it ignores String methods.
+func formatScalar(v reflect.Value) string {
+	switch {
+	case v.Kind() == reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case isInt(v.Type()):
+		return strconv.FormatInt(v.Int(), 10)
+	case isUint(v.Type()):
+		return strconv.FormatUint(v.Uint(), 10)
+	default:
+		return v.String()
+	}
+}
+
+func newRegexp(param string, t reflect.Type) (func(reflect.Value) string, error) {
+	if t.Kind() != reflect.String {
+		return nil, eris.Errorf("does not apply to %s", t)
+	}
+	re, err := regexp.Compile(param)
+	if err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+	msg := "must match " + param
+	return func(v reflect.Value) string {
+		return failIf(!re.MatchString(v.String()), msg)
+	}, nil
+}
+
+func failIf(failed bool, msg string) string {
+	if failed {
+		return msg
+	}
+	return ""
+}
+
+func compare[T int64 | uint64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func parseLen(param string) (int64, error) {
+	n, err := strconv.ParseInt(param, 10, 64)
+	if err != nil || n < 0 {
+		return 0, eris.Errorf("length %q must be a non negative integer", param)
+	}
+	return n, nil
+}
+
+// length returns the number of characters of a string, or the number of elements of a slice, array, or map.
+func length(v reflect.Value) int64 {
+	if v.Kind() == reflect.String {
+		return int64(utf8.RuneCountInString(v.String()))
+	}
+	return int64(v.Len())
+}
+
+func hasLen(t reflect.Type) bool {
+	switch t.Kind() { //nolint:exhaustive // only these kinds have a length
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return true
+	default:
+		return false
+	}
+}
+
+func isInt(t reflect.Type) bool {
+	switch t.Kind() { //nolint:exhaustive // only these kinds are signed integers
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	default:
+		return false
+	}
+}
+
+func isUint(t reflect.Type) bool {
+	switch t.Kind() { //nolint:exhaustive // only these kinds are unsigned integers
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	default:
+		return false
+	}
+}
+
+// nestedStruct returns the struct type whose fields are checked as part of a field of the given type, if any.
+func nestedStruct(t reflect.Type) reflect.Type {
+	for {
+		switch t.Kind() { //nolint:exhaustive // other kinds hold no structs
+		case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+			t = t.Elem()
+		case reflect.Struct:
+			return t
+		default:
+			return nil
+		}
+	}
+}
+
+// jsonName returns the name of the field in JSON, or an empty string for embedded structs, whose fields are inlined.
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name != "" {
+		return name
+	}
+	if field.Anonymous && nestedStruct(field.Type) == field.Type {
+		return ""
+	}
+	return field.Name
+}
+
+func joinPath(path, name string) string {
+	switch {
+	case path == "":
+		return name
+	case name == "":
+		return path
+	default:
+		return path + "." + name
+	}
+}
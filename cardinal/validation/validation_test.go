@@ -0,0 +1,146 @@
+package validation_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal/validation"
+)
+
+type Item struct {
+	Name  string `json:"name" cardinal:"min=1"`
+	Count uint8  `json:"count" cardinal:"max=10"`
+}
+
+type Order struct {
+	ID       string          `json:"id" cardinal:"regexp=^[a-z]{2,4}-[0-9]+$"`
+	Priority *int            `json:"priority,omitempty" cardinal:"enum=1|2|3"`
+	Price    float64         `cardinal:"min=0.5"`
+	Items    []Item          `json:"items" cardinal:"min=1,max=3"`
+	ByName   map[string]Item `json:"byName"`
+	Note     string          `json:"-" cardinal:"len=100"`
+}
+
+func (o *Order) Validate() error {
+	if len(o.Items) > 0 && o.Items[0].Name == o.ID {
+		return &validation.Error{Fields: []validation.FieldError{
+			{Field: "items[0].name", Rule: "validate", Message: "must differ from the id"},
+		}}
+	}
+	return nil
+}
+
+func compile[T any](t *testing.T) *validation.Rules {
+	rules, err := validation.Compile(reflect.TypeOf(new(T)).Elem())
+	assert.NilError(t, err)
+	return rules
+}
+
+func fieldsOf(t *testing.T, err error) []validation.FieldError {
+	var validationErr *validation.Error
+	assert.Check(t, eris.As(err, &validationErr), "expected a validation error, got %v", err)
+	return validationErr.Fields
+}
+
+func TestValidOrderPasses(t *testing.T) {
+	rules := compile[Order](t)
+	priority := 2
+	order := Order{
+		ID:       "ab-12",
+		Priority: &priority,
+		Price:    0.5,
+		Items:    []Item{{Name: "apple", Count: 10}},
+		ByName:   map[string]Item{"pear": {Name: "pear"}},
+	}
+	assert.NilError(t, rules.Validate(order))
+
+	// Nil pointers are not checked.
+	order.Priority = nil
+	assert.NilError(t, rules.Validate(order))
+}
+
+func TestEveryBrokenRuleIsReported(t *testing.T) {
+	rules := compile[Order](t)
+	priority := 4
+	order := Order{
+		ID:       "abcde-1",
+		Priority: &priority,
+		Price:    0.25,
+		Items:    []Item{{Name: "", Count: 11}},
+		ByName:   map[string]Item{"pear": {Name: ""}},
+	}
+	assert.DeepEqual(t, fieldsOf(t, rules.Validate(order)), []validation.FieldError{
+		{Field: "id", Rule: "regexp", Message: "must match ^[a-z]{2,4}-[0-9]+$"},
+		{Field: "priority", Rule: "enum", Message: "must be one of 1, 2, 3"},
+		{Field: "Price", Rule: "min", Message: "must be at least 0.5"},
+		{Field: "items[0].name", Rule: "min", Message: "must have a length of at least 1"},
+		{Field: "items[0].count", Rule: "max", Message: "must be at most 10"},
+		{Field: "byName[pear].name", Rule: "min", Message: "must have a length of at least 1"},
+	})
+
+	order = Order{ID: "ab-1", Price: 1}
+	assert.DeepEqual(t, fieldsOf(t, rules.Validate(order)), []validation.FieldError{
+		{Field: "items", Rule: "min", Message: "must have a length of at least 1"},
+	})
+}
+
+func TestValidateIsCalledOnceTheTagRulesPass(t *testing.T) {
+	rules := compile[Order](t)
+	order := Order{ID: "ab-1", Price: 1, Items: []Item{{Name: "ab-1"}}}
+	assert.DeepEqual(t, fieldsOf(t, rules.Validate(order)), []validation.FieldError{
+		{Field: "items[0].name", Rule: "validate", Message: "must differ from the id"},
+	})
+
+	order.Price = 0
+	assert.DeepEqual(t, fieldsOf(t, rules.Validate(order)), []validation.FieldError{
+		{Field: "Price", Rule: "min", Message: "must be at least 0.5"},
+	})
+}
+
+func TestInvalidRulesFailToCompile(t *testing.T) {
+	type unknownRule struct {
+		A string `cardinal:"required"`
+	}
+	type badBound struct {
+		A int `cardinal:"min=1.5"`
+	}
+	type regexpOnInt struct {
+		A int `cardinal:"regexp=^1$"`
+	}
+	type lenOnBool struct {
+		A bool `cardinal:"len=1"`
+	}
+	type badNested struct {
+		Items []lenOnBool
+	}
+	for _, typ := range []any{unknownRule{}, badBound{}, regexpOnInt{}, lenOnBool{}, badNested{}} {
+		_, err := validation.Compile(reflect.TypeOf(typ))
+		assert.Check(t, err != nil, "expected %T to fail to compile", typ)
+	}
+}
+
+func TestTagsOfOtherLibrariesAreIgnored(t *testing.T) {
+	type signup struct {
+		Email string `json:"email" validate:"required,email"`
+		Age   int    `json:"age" validate:"gte=13" cardinal:"min=13"`
+	}
+	rules, err := validation.Compile(reflect.TypeOf(signup{}))
+	assert.NilError(t, err)
+	assert.NilError(t, rules.Validate(signup{Age: 13}))
+	assert.Check(t, rules.Validate(signup{Email: "a@b.c", Age: 12}) != nil)
+}
+
+func TestRecursiveTypesCompile(t *testing.T) {
+	type node struct {
+		Name     string  `cardinal:"max=3"`
+		Children []*node `cardinal:"max=2"`
+	}
+	rules := compile[node](t)
+	tree := node{Name: "a", Children: []*node{{Name: "abcd"}}}
+	assert.DeepEqual(t, fieldsOf(t, rules.Validate(tree)), []validation.FieldError{
+		{Field: "Children[0].Name", Rule: "max", Message: "must have a length of at most 3"},
+	})
+}
@@ -6,18 +6,19 @@ import (
 	"errors"
 	"os"
 	"os/signal"
+	"slices"
 	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/rotisserie/eris"
 	"github.com/rs/zerolog"
-	"github.com/rs/zerolog/log"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/errgroup"
 
+	"pkg.world.dev/world-engine/cardinal/admin"
 	"pkg.world.dev/world-engine/cardinal/component"
 	"pkg.world.dev/world-engine/cardinal/filter"
 	"pkg.world.dev/world-engine/cardinal/gamestate"
@@ -25,6 +26,7 @@ import (
 	"pkg.world.dev/world-engine/cardinal/receipt"
 	"pkg.world.dev/world-engine/cardinal/router"
 	"pkg.world.dev/world-engine/cardinal/server"
+	"pkg.world.dev/world-engine/cardinal/server/handler"
 	"pkg.world.dev/world-engine/cardinal/server/handler/cql"
 	servertypes "pkg.world.dev/world-engine/cardinal/server/types"
 	"pkg.world.dev/world-engine/cardinal/storage/redis"
@@ -47,15 +49,45 @@ type World struct {
 	SystemManager
 	MessageManager
 	QueryManager
+	EventManager
 	component.ComponentManager
 
 	namespace     Namespace
 	rollupEnabled bool
 	cancel        context.CancelFunc
 
+	// describeWorldPath is where StartGame writes the description of the world to, instead of starting the game.
+	describeWorldPath string
+
+	// privateReceipts makes receipts of persona transactions visible only to that persona's authenticated sessions.
+	privateReceipts bool
+
+	// logger is the logger of the world, which is used instead of the global logger.
+	logger *zerolog.Logger
+
+	// personaTagIndex is the persona tag index of the world. See buildPersonaIndex.
+	personaTagIndex *personaTagIndex
+
+	// taskIndex tracks the task types whose schedules have been checked. See scheduleExistingTasks.
+	taskIndex *taskIndex
+
+	// hasScheduledSystems is set once a scheduled system is registered. See registerSystemSchedule.
+	hasScheduledSystems bool
+
+	// relations are the policies of the relations between entities, by relation name. See RegisterRelation.
+	relations map[string]RelationPolicy
+
+	// observers are the component lifecycle observers of the world, in registration order. See OnAdd.
+	observers []observer
+
+	// resources are the registered resources of the world, by name. See RegisterResource.
+	resources map[string]resourceType
+
 	// Storage
 	redisStorage *redis.Storage
 	entityStore  gamestate.Manager
+	// entityIDRecycling reuses the indices of removed entities. See WithEntityIDRecycling.
+	entityIDRecycling bool
 
 	// Networking
 	server        *server.Server
@@ -86,25 +118,43 @@ type World struct {
 
 // NewWorld creates a new World object using Redis as the storage layer
 func NewWorld(opts ...WorldOption) (*World, error) {
-	serverOptions, routerOptions, cardinalOptions := separateOptions(opts)
+	cfg, serverOptions, routerOptions, loggerOptions, cardinalOptions := separateOptions(opts)
+
+	// Load config, unless it was passed explicitly. Fallback value is used if it's not set.
+	var err error
+	if cfg == nil {
+		cfg, err = loadWorldConfig()
+		if err != nil {
+			return nil, eris.Wrap(err, "Failed to load config to start world")
+		}
+	} else if err = cfg.Validate(); err != nil {
+		return nil, eris.Wrap(err, "Invalid config")
+	}
 
-	// Load config. Fallback value is used if it's not set.
-	cfg, err := loadWorldConfig()
+	// The logger is shared with the server, router, storage, telemetry, and stage manager of the world. Options that
+	// change the logger replace the value it points to, so they apply to all of them.
+	logger, err := cfg.newLogger()
 	if err != nil {
-		return nil, eris.Wrap(err, "Failed to load config to start world")
+		return nil, eris.Wrap(err, "Failed to create logger")
+	}
+	// Logger options are applied before anything is logged
+	for _, opt := range loggerOptions {
+		opt(&logger)
 	}
+	serverOptions = append([]server.Option{server.WithLogger(&logger)}, serverOptions...)
+	routerOptions = append([]router.Option{router.WithLogger(&logger)}, routerOptions...)
 
 	if cfg.CardinalRollupEnabled {
-		log.Info().Msgf("Creating a new Cardinal world in rollup mode")
+		logger.Info().Msgf("Creating a new Cardinal world in rollup mode")
 	} else {
-		log.Warn().Msg("Cardinal is running in development mode without rollup sequencing. " +
+		logger.Warn().Msg("Cardinal is running in development mode without rollup sequencing. " +
 			"If you intended to run this for production use, set CARDINAL_ROLLUP=true")
 	}
 
 	// Initialize telemetry
 	var tm *telemetry.Manager
 	if cfg.TelemetryTraceEnabled {
-		tm, err = telemetry.New(cfg.TelemetryTraceEnabled, cfg.CardinalNamespace)
+		tm, err = telemetry.New(cfg.TelemetryTraceEnabled, cfg.CardinalNamespace, &logger)
 		if err != nil {
 			return nil, eris.Wrap(err, "failed to create telemetry manager")
 		}
@@ -116,18 +166,29 @@ func NewWorld(opts ...WorldOption) (*World, error) {
 		DB:          0,                              // use default DB
 		DialTimeout: RedisDialTimeOut * time.Second, // Increase startup dial timeout
 	}, cfg.CardinalNamespace)
+	redisMetaStore.SetLogger(&logger)
 
 	redisStore := gamestate.NewRedisPrimitiveStorage(redisMetaStore.Client)
 	entityCommandBuffer, err := gamestate.NewEntityCommandBuffer(&redisStore)
 	if err != nil {
 		return nil, err
 	}
+	entityCommandBuffer.SetLogger(&logger)
 
 	tick := new(atomic.Uint64)
 	world := &World{
-		namespace:     Namespace(cfg.CardinalNamespace),
-		rollupEnabled: cfg.CardinalRollupEnabled,
-		cancel:        nil,
+		namespace:       Namespace(cfg.CardinalNamespace),
+		rollupEnabled:   cfg.CardinalRollupEnabled,
+		cancel:          nil,
+		privateReceipts: false,
+		personaTagIndex: &personaTagIndex{},
+		taskIndex:       &taskIndex{},
+		relations:       map[string]RelationPolicy{ParentRelation: CascadeOnRemove},
+		resources:       map[string]resourceType{},
+
+		describeWorldPath: cfg.CardinalDescribeWorld,
+
+		logger: &logger,
 
 		// Storage
 		redisStorage: &redisMetaStore,
@@ -143,6 +204,7 @@ func NewWorld(opts ...WorldOption) (*World, error) {
 		SystemManager:    newSystemManager(),
 		ComponentManager: component.NewManager(&redisMetaStore),
 		QueryManager:     nil,
+		EventManager:     newEventManager(),
 		router:           nil, // Will be set if run mode is production or its injected via options
 		txPool:           txpool.New(),
 
@@ -163,6 +225,7 @@ func NewWorld(opts ...WorldOption) (*World, error) {
 		addChannelWaitingForNextTick: make(chan chan struct{}),
 	}
 
+	world.worldStage.SetLogger(&logger)
 	world.QueryManager = newQueryManager(world)
 
 	// Initialize shard router if running in rollup mode
@@ -183,6 +246,9 @@ func NewWorld(opts ...WorldOption) (*World, error) {
 	for _, opt := range cardinalOptions {
 		opt(world)
 	}
+	if ecb, ok := world.entityStore.(*gamestate.EntityCommandBuffer); ok {
+		ecb.SetEntityIDRecycling(world.entityIDRecycling)
+	}
 
 	// Register internal plugins
 	world.RegisterPlugin(newPersonaPlugin())
@@ -220,6 +286,14 @@ func (w *World) doTick(ctx context.Context, timestamp uint64) (err error) {
 	// current system that is running.
 	defer w.handleTickPanic()
 
+	// The tasks that are added to the task schedules by a tick that fails are discarded along with its other state
+	// changes, so the schedules are checked again by the next tick.
+	defer func() {
+		if err != nil {
+			w.taskIndex.reset()
+		}
+	}()
+
 	// Copy the transactions from the pool so that we can safely modify the pool while the tick is running.
 	txPool := w.txPool.CopyTransactions(ctx)
 
@@ -237,6 +311,21 @@ func (w *World) doTick(ctx context.Context, timestamp uint64) (err error) {
 		return err
 	}
 
+	// Run the observers of the component changes made by the systems
+	if err := w.runObservers(wCtx); err != nil {
+		span.SetStatus(codes.Error, eris.ToString(err, true))
+		span.RecordError(err)
+		return err
+	}
+
+	// The entities changed by this tick must be collected before the tick is finalized, which discards them.
+	changedEntities, err := w.entityStore.PendingEntityChanges()
+	if err != nil {
+		span.SetStatus(codes.Error, eris.ToString(err, true))
+		span.RecordError(err)
+		return err
+	}
+
 	if err := w.entityStore.FinalizeTick(ctx); err != nil {
 		span.SetStatus(codes.Error, eris.ToString(err, true))
 		span.RecordError(err)
@@ -244,6 +333,9 @@ func (w *World) doTick(ctx context.Context, timestamp uint64) (err error) {
 	}
 
 	w.setEvmResults(txPool.GetEVMTxs())
+	if w.privateReceipts {
+		w.setReceiptPersonaTags(txPool)
+	}
 
 	// Handle tx data blob submission
 	// Only submit transactions when the following criteria is satisfied:
@@ -264,10 +356,13 @@ func (w *World) doTick(ctx context.Context, timestamp uint64) (err error) {
 
 	if w.worldStage.Current() != worldstage.Recovering {
 		// Populate world.TickResults for the current tick and emit it as an Event
-		w.broadcastTickResults(ctx)
+		w.broadcastTickResults(ctx, txPool)
+
+		// Send the changes to the results of live CQL subscriptions
+		w.server.PublishEntityChanges(w.CurrentTick()-1, changedEntities)
 	}
 
-	log.Info().
+	w.logger.Info().
 		Int64("tick", int64(w.CurrentTick()-1)).
 		Str("duration", time.Since(startTime).String()).
 		Int("tx_count", txPool.GetAmountOfTxs()).
@@ -295,6 +390,28 @@ func (w *World) StartGame() error {
 		w.Shutdown()
 	}()
 
+	// The admin and persona keys plugins and the task recurrence and system schedule components are registered after
+	// the game registered its components and messages, so that they don't shift the IDs of the game's components, which
+	// are stored with the archetypes, or of the game's messages, which are recorded to the base shard.
+	if w.worldStage.Current() == worldstage.Init {
+		if err := newAdminPlugin().Register(w); err != nil {
+			return eris.Wrap(err, "failed to register admin plugin")
+		}
+		if err := newPersonaKeysPlugin().Register(w); err != nil {
+			return eris.Wrap(err, "failed to register persona keys plugin")
+		}
+		if err := registerTaskRecurrence(w); err != nil {
+			return eris.Wrap(err, "failed to register task recurrence component")
+		}
+		if err := registerSystemSchedule(w); err != nil {
+			return eris.Wrap(err, "failed to register system schedule component")
+		}
+	}
+
+	if w.describeWorldPath != "" {
+		return w.describeWorld(w.describeWorldPath)
+	}
+
 	// World stage: Init -> Starting
 	ok := w.worldStage.CompareAndSwap(worldstage.Init, worldstage.Starting)
 	if !ok {
@@ -308,7 +425,7 @@ func (w *World) StartGame() error {
 	}
 
 	// Log world info
-	ecslog.World(&log.Logger, w, zerolog.InfoLevel)
+	ecslog.World(w.logger, w, zerolog.InfoLevel)
 
 	// Start router if it is set
 	if w.router != nil {
@@ -361,14 +478,14 @@ func (w *World) StartGame() error {
 }
 
 func (w *World) startGameLoop(ctx context.Context, tickStart <-chan time.Time, tickDone chan<- uint64) error {
-	log.Info().Msg("Game loop started")
+	w.logger.Info().Msg("Game loop started")
 	var waitingChs []chan struct{}
 
 loop:
 	for {
 		select {
 		case <-ctx.Done():
-			log.Info().Msg("Shutting down game loop")
+			w.logger.Info().Msg("Shutting down game loop")
 			w.drainChannelsWaitingForNextTick()
 			closeAllChannels(waitingChs)
 			if tickDone != nil {
@@ -390,7 +507,7 @@ loop:
 		}
 	}
 
-	log.Info().Msg("Successfully shut down game loop")
+	w.logger.Info().Msg("Successfully shut down game loop")
 	return nil
 }
 
@@ -419,28 +536,28 @@ func (w *World) IsGameRunning() bool {
 // Shutdown will trigger a graceful shutdown of the World.
 func (w *World) Shutdown() {
 	if w.worldStage.Current() == worldstage.ShutDown || w.worldStage.Current() == worldstage.ShuttingDown {
-		log.Warn().Msgf("Cardinal is already %s, ignoring shutdown request", w.worldStage.Current())
+		w.logger.Warn().Msgf("Cardinal is already %s, ignoring shutdown request", w.worldStage.Current())
 		return
 	}
 
-	log.Info().Msg("Shutting down cardinal")
+	w.logger.Info().Msg("Shutting down cardinal")
 	w.worldStage.Store(worldstage.ShuttingDown)
 
 	// Cancel the context used for server and game loop, therefore triggering their shutdown.
 	w.cancel()
 	<-w.worldStage.NotifyOnStage(worldstage.ShutDown)
 
-	log.Info().Msg("Successfully shut down cardinal")
+	w.logger.Info().Msg("Successfully shut down cardinal")
 }
 
 // cleanup is called after StartGame terminates. It does the housekeeping required to cleanly shutdown World.
 func (w *World) cleanup() {
 	if err := w.redisStorage.Close(); err != nil {
-		log.Error().Err(err).Msg("Failed to close storage connection")
+		w.logger.Error().Err(err).Msg("Failed to close storage connection")
 	}
 	if w.telemetry != nil {
 		if err := w.telemetry.Shutdown(); err != nil {
-			log.Error().Err(err).Msg("Failed to shut down telemetry")
+			w.logger.Error().Err(err).Msg("Failed to shut down telemetry")
 		}
 	}
 	w.worldStage.Store(worldstage.ShutDown)
@@ -448,7 +565,7 @@ func (w *World) cleanup() {
 
 func (w *World) handleTickPanic() {
 	if r := recover(); r != nil {
-		log.Error().Msgf(
+		w.logger.Error().Msgf(
 			"Tick: %d, Current running system: %s",
 			w.CurrentTick(),
 			w.SystemManager.GetCurrentSystem(),
@@ -459,7 +576,7 @@ func (w *World) handleTickPanic() {
 
 func (w *World) RegisterPlugin(plugin Plugin) {
 	if err := plugin.Register(w); err != nil {
-		log.Fatal().Err(err).Msgf("failed to register plugin: %v", err)
+		w.logger.Fatal().Err(err).Msgf("failed to register plugin: %v", err)
 	}
 }
 
@@ -493,6 +610,14 @@ func (w *World) AddTransaction(id types.MessageID, v any, sig *sign.Transaction)
 	return tick, txHash
 }
 
+// AddTransactions adds a batch of transactions to the transaction pool. All transactions in the batch will be executed
+// in the same tick. Returns that tick and the hash of each transaction.
+func (w *World) AddTransactions(txs []txpool.TxData) (tick uint64, txHashes []types.TxHash) {
+	tick = w.CurrentTick()
+	txHashes = w.txPool.AddTransactions(txs)
+	return tick, txHashes
+}
+
 func (w *World) AddEVMTransaction(
 	id types.MessageID,
 	v any,
@@ -510,6 +635,10 @@ func (w *World) UseNonce(signerAddress string, nonce uint64) error {
 	return w.redisStorage.UseNonce(signerAddress, nonce)
 }
 
+func (w *World) UseSession(sessionID string, maxUses uint64) error {
+	return w.redisStorage.UseSession(sessionID, maxUses)
+}
+
 func (w *World) GetDebugState() ([]types.DebugStateElement, error) {
 	result := make([]types.DebugStateElement, 0)
 	s := w.Search(filter.All())
@@ -586,7 +715,7 @@ func (w *World) GetMessageByID(id types.MessageID) (types.Message, bool) {
 	return msg, msg != nil
 }
 
-func (w *World) broadcastTickResults(ctx context.Context) {
+func (w *World) broadcastTickResults(ctx context.Context, txPool *txpool.TxPool) {
 	_, span := w.tracer.Start(ctx, "world.tick.broadcast_tick_results")
 	defer span.End()
 
@@ -594,7 +723,11 @@ func (w *World) broadcastTickResults(ctx context.Context) {
 	//  current tick. We should fix this.
 	receipts, err := w.receiptHistory.GetReceiptsForTick(w.CurrentTick() - 1)
 	if err != nil {
-		log.Error().Err(err).Msgf("failed to get receipts for tick %d", w.CurrentTick()-1)
+		w.logger.Error().Err(err).Msgf("failed to get receipts for tick %d", w.CurrentTick()-1)
+	}
+	personaReceipts := map[string][]receipt.Receipt{}
+	if w.privateReceipts {
+		receipts, personaReceipts = splitReceiptsByPersona(receipts)
 	}
 	w.tickResults.SetReceipts(receipts)
 	w.tickResults.SetTick(w.CurrentTick() - 1)
@@ -603,18 +736,90 @@ func (w *World) broadcastTickResults(ctx context.Context) {
 	if err := w.server.BroadcastEvent(w.tickResults); err != nil {
 		span.SetStatus(codes.Error, eris.ToString(err, true))
 		span.RecordError(err)
-		log.Err(err).Msgf("failed to broadcast tick results")
+		w.logger.Err(err).Msgf("failed to broadcast tick results")
+	}
+	w.server.PublishTickResults(w.tickResults.Tick, w.tickResults.Receipts, w.tickResults.Events)
+
+	// Deliver the events and receipts that are private to a persona to that persona's authenticated clients
+	for personaTag, results := range w.tickResults.PersonaTickResults(personaReceipts) {
+		if err := w.server.EmitToPersona(personaTag, results); err != nil {
+			span.SetStatus(codes.Error, eris.ToString(err, true))
+			span.RecordError(err)
+			w.logger.Err(err).Msgf("failed to emit tick results to persona %q", personaTag)
+		}
 	}
 
 	// Clear the TickResults for this tick in preparation for the next tick
 	w.tickResults.Clear()
 }
 
+// setReceiptPersonaTags makes the receipts of transactions signed by a persona private to that persona. System
+// transactions stay public.
+func (w *World) setReceiptPersonaTags(txPool *txpool.TxPool) {
+	for _, txs := range txPool.Transactions() {
+		for _, tx := range txs {
+			// System and admin transactions don't belong to a persona
+			if tx.Tx == nil || tx.Tx.PersonaTag == "" || tx.Tx.IsSystemTransaction() ||
+				tx.Tx.PersonaTag == admin.PersonaTag {
+				continue
+			}
+			w.receiptHistory.SetPersonaTag(tx.TxHash, tx.Tx.PersonaTag)
+		}
+	}
+}
+
+// splitReceiptsByPersona separates the receipts that are private to a persona from the remaining (public) receipts.
+func splitReceiptsByPersona(receipts []receipt.Receipt) ([]receipt.Receipt, map[string][]receipt.Receipt) {
+	public := make([]receipt.Receipt, 0, len(receipts))
+	private := map[string][]receipt.Receipt{}
+	for _, rec := range receipts {
+		if rec.PersonaTag == "" {
+			public = append(public, rec)
+			continue
+		}
+		private[rec.PersonaTag] = append(private[rec.PersonaTag], rec)
+	}
+	return public, private
+}
+
 func (w *World) ReceiptHistorySize() uint64 {
 	return w.receiptHistory.Size()
 }
 
 func (w *World) EvaluateCQL(cqlString string) ([]types.EntityStateElement, error) {
+	result, _, err := w.EvaluateCQLPage(cqlString, "")
+	return result, err
+}
+
+// EvaluateCQLPage evaluates a CQL query and returns the requested page of matching entities, together with a cursor
+// for the next page. The cursor is empty when there are no more matching entities.
+func (w *World) EvaluateCQLPage(cqlString string, cursor string) ([]types.EntityStateElement, string, error) {
+	query, err := w.ParseCQL(cqlString)
+	if err != nil {
+		return nil, "", err
+	}
+	matches, err := w.EvaluateCQLQuery(query, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	page, nextCursor, err := query.Paginate(matches, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	result := make([]types.EntityStateElement, 0, len(page))
+	for _, entity := range page {
+		data, err := query.Project(entity)
+		if err != nil {
+			return nil, "", err
+		}
+		result = append(result, types.EntityStateElement{ID: entity.ID, Data: data})
+	}
+	return result, nextCursor, nil
+}
+
+// ParseCQL parses a CQL string into a query over the components registered to this world.
+func (w *World) ParseCQL(cqlString string) (*cql.Query, error) {
 	// getComponentByName is a wrapper function that casts component.ComponentMetadata from ctx.getComponentByName
 	// to types.Component
 	getComponentByName := func(name string) (types.Component, error) {
@@ -625,42 +830,88 @@ func (w *World) EvaluateCQL(cqlString string) ([]types.EntityStateElement, error
 		return comp, nil
 	}
 
-	// Parse the CQL string into a filter
-	cqlFilter, err := cql.Parse(cqlString, getComponentByName)
+	query, err := cql.ParseQuery(cqlString, getComponentByName)
 	if err != nil {
-		return nil, eris.Errorf("failed to parse cql string: %s", cqlString)
+		return nil, eris.Wrapf(err, "failed to parse cql string: %s", cqlString)
 	}
-	result := make([]types.EntityStateElement, 0)
-	var eachError error
-	wCtx := NewReadOnlyWorldContext(w)
-	searchErr := w.Search(cqlFilter).Each(wCtx,
-		func(id types.EntityID) bool {
-			components, err := w.StoreReader().GetComponentTypesForEntity(id)
-			if err != nil {
-				eachError = err
-				return false
-			}
-			resultElement := types.EntityStateElement{
-				ID:   id,
-				Data: make([]json.RawMessage, 0),
-			}
+	return query, nil
+}
 
-			for _, c := range components {
-				data, err := w.StoreReader().GetComponentForEntityInRawJSON(c, id)
-				if err != nil {
-					eachError = err
-					return false
-				}
-				resultElement.Data = append(resultElement.Data, data)
-			}
-			result = append(result, resultElement)
-			return true
-		},
-	)
-	if eachError != nil {
-		return nil, eachError
-	} else if searchErr != nil {
-		return nil, searchErr
+// EvaluateCQLQuery returns the entities that match the given query and that Paginate needs to find the page after the
+// given cursor. Only entities of the archetypes selected by the query's filter are loaded, in ascending ID order, and
+// the scan stops as soon as the page is known, unless the query is ordered by component fields.
+//
+// Cardinal doesn't index component fields, so field comparisons are checked against every entity that is loaded.
+// Looking up matches in field indexes is left for when the ECS layer has them.
+func (w *World) EvaluateCQLQuery(query *cql.Query, cursor string) ([]*cql.Entity, error) {
+	scan, err := query.PageScan(cursor)
+	if err != nil {
+		return nil, err
 	}
-	return result, nil
+	ids, err := w.Search(query.Filter).Collect(NewReadOnlyWorldContext(w))
+	if err != nil {
+		return nil, err
+	}
+	if scan.HasAfter {
+		start, found := slices.BinarySearch(ids, scan.After)
+		if found {
+			start++
+		}
+		ids = ids[start:]
+	}
+
+	matches := make([]*cql.Entity, 0)
+	for _, id := range ids {
+		if scan.MaxMatches > 0 && len(matches) == scan.MaxMatches {
+			break
+		}
+		entity, err := w.GetCQLEntity(id)
+		if err != nil {
+			return nil, err
+		}
+		ok, err := query.Matches(entity)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matches = append(matches, entity)
+		}
+	}
+	return matches, nil
+}
+
+// GetCQLEntity loads all components of the given entity so that it can be evaluated against a CQL query.
+func (w *World) GetCQLEntity(id types.EntityID) (*cql.Entity, error) {
+	components, err := w.StoreReader().GetComponentTypesForEntity(id)
+	if err != nil {
+		return nil, err
+	}
+	entity := &cql.Entity{
+		ID:         id,
+		Components: make([]types.Component, 0, len(components)),
+		Data:       make([]json.RawMessage, 0, len(components)),
+	}
+	for _, c := range components {
+		data, err := w.StoreReader().GetComponentForEntityInRawJSON(c, id)
+		if err != nil {
+			return nil, err
+		}
+		entity.Components = append(entity.Components, c)
+		entity.Data = append(entity.Data, data)
+	}
+	return entity, nil
+}
+
+// describeWorld writes the description of the world that is served at /world to the given path.
+func (w *World) describeWorld(path string) error {
+	description := handler.BuildWorldResponse(w, w.GetRegisteredComponents(), w.GetRegisteredMessages(), w.Namespace())
+	bz, err := json.MarshalIndent(description, "", "  ")
+	if err != nil {
+		return eris.Wrap(err, "failed to marshal world description")
+	}
+	if err := os.WriteFile(path, bz, 0o600); err != nil {
+		return eris.Wrap(err, "failed to write world description")
+	}
+	w.logger.Info().Msgf("Wrote world description to %s", path)
+	return nil
 }
@@ -7,7 +7,6 @@ import (
 
 	"github.com/rotisserie/eris"
 	"github.com/rs/zerolog"
-	"github.com/rs/zerolog/log"
 
 	"pkg.world.dev/world-engine/cardinal/gamestate"
 	"pkg.world.dev/world-engine/cardinal/receipt"
@@ -35,6 +34,10 @@ type WorldContext interface {
 	// EmitEvent emits an event that will be broadcast to all websocket subscribers.
 	EmitEvent(map[string]any) error
 
+	// EmitEventTo emits an event that will only be delivered to websocket subscribers that have authenticated as the
+	// given persona. Use this for events that must stay private to a player, e.g. a hidden card draw.
+	EmitEventTo(personaTag string, event map[string]any) error
+
 	// EmitStringEvent emits a string event that will be broadcast to all websocket subscribers.
 	// This method is provided for backwards compatability. EmitEvent should be used for most cases.
 	EmitStringEvent(string) error
@@ -46,12 +49,24 @@ type WorldContext interface {
 	Rand() *rand.Rand
 
 	// ScheduleTickTask schedules a task to be executed after the specified tickDelay.
-	// The given Task must have been registered using RegisterTask.
-	ScheduleTickTask(uint64, Task) error
+	// The given Task must have been registered using RegisterTask. The returned ID can be used to cancel the task.
+	ScheduleTickTask(uint64, Task) (types.EntityID, error)
 
 	// ScheduleTimeTask schedules a task to be executed after the specified duration (in wall clock time).
-	// The given Task must have been registered using RegisterTask.
-	ScheduleTimeTask(time.Duration, Task) error
+	// The given Task must have been registered using RegisterTask. The returned ID can be used to cancel the task.
+	ScheduleTimeTask(time.Duration, Task) (types.EntityID, error)
+
+	// ScheduleRecurringTickTask schedules a task to be executed every interval ticks, starting interval ticks from now.
+	// The task is executed at most maxRuns times, or until it is cancelled if maxRuns is 0.
+	ScheduleRecurringTickTask(interval uint64, maxRuns uint64, task Task) (types.EntityID, error)
+
+	// ScheduleRecurringTimeTask schedules a task to be executed every interval (in wall clock time), starting one
+	// interval from now. The task is executed at most maxRuns times, or until it is cancelled if maxRuns is 0.
+	ScheduleRecurringTimeTask(interval time.Duration, maxRuns uint64, task Task) (types.EntityID, error)
+
+	// CancelTask cancels a scheduled task, so that it is not executed (again). ErrTaskNotFound is returned if there is
+	// no pending task with the given ID.
+	CancelTask(types.EntityID) error
 
 	// Private methods for internal use.
 	setLogger(logger zerolog.Logger)
@@ -59,8 +74,16 @@ type WorldContext interface {
 	setMessageResult(id types.TxHash, a any)
 	getComponentByName(name string) (types.ComponentMetadata, error)
 	getMessageByType(mType reflect.Type) (types.Message, bool)
+	getEventType(name string) (reflect.Type, bool)
+	emitEvent(event any) error
+	emitEventTo(personaTag string, event any) error
 	getTransactionReceipt(id types.TxHash) (any, []error, bool)
 	getSignerForPersonaTag(personaTag string, tick uint64) (addr string, err error)
+	getSessionUses(sessionID string) (uint64, error)
+	personaTagIndex() *personaTagIndex
+	taskIndex() *taskIndex
+	getRelations() map[string]RelationPolicy
+	getResources() map[string]resourceType
 	getTransactionReceiptsForTick(tick uint64) ([]receipt.Receipt, error)
 	receiptHistorySize() uint64
 	addTransaction(id types.MessageID, v any, sig *sign.Transaction) (uint64, types.TxHash)
@@ -83,7 +106,7 @@ func newWorldContextForTick(world *World, txPool *txpool.TxPool) WorldContext {
 	return &worldContext{
 		world:    world,
 		txPool:   txPool,
-		logger:   &log.Logger,
+		logger:   world.logger,
 		readOnly: false,
 		//nolint:gosec // we require manual in the rng which crypto/rand doesn't have, but math/rand does.
 		rand: rand.New(rand.NewSource(int64(world.timestamp.Load()))),
@@ -94,7 +117,7 @@ func NewWorldContext(world *World) WorldContext {
 	return &worldContext{
 		world:    world,
 		txPool:   nil,
-		logger:   &log.Logger,
+		logger:   world.logger,
 		readOnly: false,
 		rand:     nil,
 	}
@@ -104,7 +127,7 @@ func NewReadOnlyWorldContext(world *World) WorldContext {
 	return &worldContext{
 		world:    world,
 		txPool:   nil,
-		logger:   &log.Logger,
+		logger:   world.logger,
 		readOnly: true,
 		rand:     nil,
 	}
@@ -114,22 +137,55 @@ func NewReadOnlyWorldContext(world *World) WorldContext {
 // Public methods
 // -----------------------------------------------------------------------------
 
-func (ctx *worldContext) ScheduleTickTask(tickDelay uint64, task Task) error {
+func (ctx *worldContext) ScheduleTickTask(tickDelay uint64, task Task) (types.EntityID, error) {
 	triggerAtTick := ctx.CurrentTick() + tickDelay
-	return createTickTask(ctx, triggerAtTick, task)
+	return createTickTask(ctx, triggerAtTick, 0, 0, task)
 }
 
-func (ctx *worldContext) ScheduleTimeTask(duration time.Duration, task Task) error {
-	if duration.Milliseconds() < 0 {
-		return eris.New("duration value must be positive")
+func (ctx *worldContext) ScheduleTimeTask(duration time.Duration, task Task) (types.EntityID, error) {
+	delay, err := durationToMillis(duration)
+	if err != nil {
+		return 0, err
 	}
+	triggerAtTimestamp := ctx.Timestamp() + delay
+	return createTimestampTask(ctx, triggerAtTimestamp, 0, 0, task)
+}
 
-	triggerAtTimestamp := ctx.Timestamp() + uint64(duration.Milliseconds())
-	return createTimestampTask(ctx, triggerAtTimestamp, task)
+func (ctx *worldContext) ScheduleRecurringTickTask(interval uint64, maxRuns uint64, task Task) (types.EntityID, error) {
+	if interval == 0 {
+		return 0, eris.New("interval of a recurring task must be at least one tick")
+	}
+	triggerAtTick := ctx.CurrentTick() + interval
+	return createTickTask(ctx, triggerAtTick, interval, maxRuns, task)
+}
+
+func (ctx *worldContext) ScheduleRecurringTimeTask(
+	interval time.Duration, maxRuns uint64, task Task,
+) (types.EntityID, error) {
+	intervalMillis, err := durationToMillis(interval)
+	if err != nil {
+		return 0, err
+	}
+	if intervalMillis == 0 {
+		return 0, eris.New("interval of a recurring task must be at least one millisecond")
+	}
+	triggerAtTimestamp := ctx.Timestamp() + intervalMillis
+	return createTimestampTask(ctx, triggerAtTimestamp, intervalMillis, maxRuns, task)
+}
+
+func (ctx *worldContext) CancelTask(id types.EntityID) error {
+	return cancelTask(ctx, id)
 }
 
 func (ctx *worldContext) EmitEvent(event map[string]any) error {
-	return ctx.world.tickResults.AddEvent(event)
+	return ctx.emitEvent(event)
+}
+
+func (ctx *worldContext) EmitEventTo(personaTag string, event map[string]any) error {
+	if personaTag == "" {
+		return eris.New("persona tag is required to emit a targeted event")
+	}
+	return ctx.emitEventTo(personaTag, event)
 }
 
 func (ctx *worldContext) EmitStringEvent(e string) error {
@@ -169,6 +225,18 @@ func (ctx *worldContext) getMessageByType(mType reflect.Type) (types.Message, bo
 	return ctx.world.GetMessageByType(mType)
 }
 
+func (ctx *worldContext) getEventType(name string) (reflect.Type, bool) {
+	return ctx.world.getEventType(name)
+}
+
+func (ctx *worldContext) emitEvent(event any) error {
+	return ctx.world.tickResults.AddEvent(event)
+}
+
+func (ctx *worldContext) emitEventTo(personaTag string, event any) error {
+	return ctx.world.tickResults.AddPersonaEvent(personaTag, event)
+}
+
 func (ctx *worldContext) setLogger(logger zerolog.Logger) {
 	ctx.logger = &logger
 }
@@ -199,6 +267,26 @@ func (ctx *worldContext) getSignerForPersonaTag(personaTag string, tick uint64)
 	return ctx.world.GetSignerForPersonaTag(personaTag, tick)
 }
 
+func (ctx *worldContext) getSessionUses(sessionID string) (uint64, error) {
+	return ctx.world.redisStorage.GetSessionUses(sessionID)
+}
+
+func (ctx *worldContext) personaTagIndex() *personaTagIndex {
+	return ctx.world.personaTagIndex
+}
+
+func (ctx *worldContext) taskIndex() *taskIndex {
+	return ctx.world.taskIndex
+}
+
+func (ctx *worldContext) getRelations() map[string]RelationPolicy {
+	return ctx.world.relations
+}
+
+func (ctx *worldContext) getResources() map[string]resourceType {
+	return ctx.world.resources
+}
+
 func (ctx *worldContext) getTransactionReceiptsForTick(tick uint64) ([]receipt.Receipt, error) {
 	return ctx.world.GetTransactionReceiptsForTick(tick)
 }
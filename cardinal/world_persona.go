@@ -21,7 +21,7 @@ func (w *World) GetSignerForPersonaTag(personaTag string, tick uint64) (addr str
 	}
 	var errs []error
 	wCtx := NewReadOnlyWorldContext(w)
-	s := NewSearch().Entity(filter.Exact(filter.Component[component.SignerComponent]()))
+	s := NewSearch().Entity(filter.Contains(filter.Component[component.SignerComponent]()))
 	err = s.Each(wCtx,
 		func(id types.EntityID) bool {
 			sc, err := GetComponent[component.SignerComponent](wCtx, id)
@@ -42,10 +42,93 @@ func (w *World) GetSignerForPersonaTag(personaTag string, tick uint64) (addr str
 	return addr, errors.Join(errs...)
 }
 
+// GetSignerAddressesForPersonaTag returns all the addresses that can sign transactions for the given persona tag after
+// the given tick: the persona's signer address, followed by the addresses of its signer keys. The same errors as
+// GetSignerForPersonaTag are returned.
+// implements the validator.SignerAddressProvider interface
+func (w *World) GetSignerAddressesForPersonaTag(personaTag string, tick uint64) (addrs []string, err error) {
+	if tick >= w.CurrentTick() {
+		return nil, persona.ErrCreatePersonaTxsNotProcessed
+	}
+	wCtx := NewReadOnlyWorldContext(w)
+	id, sc, err := findPersona(wCtx, personaTag)
+	if err != nil {
+		return nil, err
+	}
+	addrs = []string{sc.SignerAddress}
+
+	keys, err := GetComponent[component.SignerKeysComponent](wCtx, id)
+	if eris.Is(err, ErrComponentNotOnEntity) {
+		return addrs, nil
+	} else if err != nil {
+		return nil, err
+	}
+	for _, key := range keys.Keys {
+		addrs = append(addrs, key.Address)
+	}
+	return addrs, nil
+}
+
+// GetSessionKeysForPersonaTag returns the session keys of the given persona tag after the given tick, including the
+// ones that have expired or have been used up. The same errors as GetSignerForPersonaTag are returned.
+// implements the validator.SignerAddressProvider interface
+func (w *World) GetSessionKeysForPersonaTag(personaTag string, tick uint64) ([]component.SessionKey, error) {
+	if tick >= w.CurrentTick() {
+		return nil, persona.ErrCreatePersonaTxsNotProcessed
+	}
+	wCtx := NewReadOnlyWorldContext(w)
+	id, _, err := findPersona(wCtx, personaTag)
+	if err != nil {
+		return nil, err
+	}
+	return getSessionKeys(wCtx, id)
+}
+
+// findPersona returns the entity and signer component of the given persona tag. If the persona tag has no signer
+// address, ErrPersonaTagHasNoSigner is returned.
+func findPersona(wCtx WorldContext, personaTag string) (types.EntityID, *component.SignerComponent, error) {
+	var id types.EntityID
+	var sc *component.SignerComponent
+	s := NewSearch().Entity(filter.Contains(filter.Component[component.SignerComponent]()))
+	var getComponentErr error
+	err := s.Each(wCtx,
+		func(entityID types.EntityID) bool {
+			var signerComp *component.SignerComponent
+			signerComp, getComponentErr = GetComponent[component.SignerComponent](wCtx, entityID)
+			if getComponentErr != nil {
+				return false
+			}
+			if signerComp.PersonaTag == personaTag {
+				id, sc = entityID, signerComp
+				return false
+			}
+			return true
+		},
+	)
+	if err = errors.Join(err, getComponentErr); err != nil {
+		return 0, nil, err
+	}
+	if sc == nil || sc.SignerAddress == "" {
+		return 0, nil, persona.ErrPersonaTagHasNoSigner
+	}
+	return id, sc, nil
+}
+
+// getSessionKeys returns the session keys of the persona entity.
+func getSessionKeys(wCtx WorldContext, id types.EntityID) ([]component.SessionKey, error) {
+	sessions, err := GetComponent[component.SessionKeysComponent](wCtx, id)
+	if eris.Is(err, ErrComponentNotOnEntity) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return sessions.Sessions, nil
+}
+
 func (w *World) GetSignerComponentForPersona(personaTag string) (*component.SignerComponent, error) {
 	var sc *component.SignerComponent
 	wCtx := NewReadOnlyWorldContext(w)
-	q := NewSearch().Entity(filter.Exact(filter.Component[component.SignerComponent]()))
+	q := NewSearch().Entity(filter.Contains(filter.Component[component.SignerComponent]()))
 	var getComponentErr error
 	searchIterationErr := eris.Wrap(
 		q.Each(wCtx,
@@ -1,6 +1,7 @@
 package cardinal_test
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"testing"
@@ -160,3 +161,197 @@ func TestCreatePersonaSystem_WhenCardinalIsRestarted_PersonaTagsAreStillRegister
 	assert.Len(t, errs, 1)
 	assert.ErrorContains(t, errs[0], "persona tag pt5 has already been registered")
 }
+
+const (
+	signerAddrA = "0x1111111111111111111111111111111111111111"
+	signerAddrB = "0x2222222222222222222222222222222222222222"
+	signerAddrC = "0x3333333333333333333333333333333333333333"
+)
+
+// addPersonaTx adds a transaction of the persona with the given message, runs a tick and returns the errors of the
+// transaction's receipt. It must be the only transaction of the tick.
+func addPersonaTx(t *testing.T, tf *cardinal.TestFixture, personaTag, fullName string, m any) []error {
+	msgType, exists := tf.World.GetMessageByFullName(fullName)
+	assert.True(t, exists)
+	tf.AddTransaction(msgType.ID(), m, &sign.Transaction{PersonaTag: personaTag})
+	tf.DoTick()
+
+	receipts, err := tf.World.GetTransactionReceiptsForTick(tf.World.CurrentTick() - 1)
+	assert.NilError(t, err)
+	assert.Len(t, receipts, 1)
+	return receipts[0].Errs
+}
+
+func TestRotatePersonaSigner(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	personaTag := "rotator"
+	tf.CreatePersona(personaTag, signerAddrA)
+
+	errs := addPersonaTx(t, tf, personaTag, "persona."+msg.RotatePersonaSignerMessageName,
+		msg.RotatePersonaSigner{SignerAddress: signerAddrB})
+	assert.Len(t, errs, 0)
+
+	addr, err := world.GetSignerForPersonaTag(personaTag, world.CurrentTick()-1)
+	assert.NilError(t, err)
+	assert.Equal(t, addr, signerAddrB)
+
+	// The persona index is updated as well, so the old signer can't be used to create the persona again
+	sc, err := world.GetSignerComponentForPersona(personaTag)
+	assert.NilError(t, err)
+	assert.Equal(t, sc.SignerAddress, signerAddrB)
+
+	errs = addPersonaTx(t, tf, "nobody", "persona."+msg.RotatePersonaSignerMessageName,
+		msg.RotatePersonaSigner{SignerAddress: signerAddrB})
+	assert.Len(t, errs, 1)
+}
+
+func TestPersonaSignerKeys(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	personaTag := "keyholder"
+	tf.CreatePersona(personaTag, signerAddrA)
+
+	addKey := "persona." + msg.AddPersonaSignerKeyMessageName
+	removeKey := "persona." + msg.RemovePersonaSignerKeyMessageName
+	errs := addPersonaTx(t, tf, personaTag, addKey, msg.AddPersonaSignerKey{Label: "phone", SignerAddress: signerAddrB})
+	assert.Len(t, errs, 0)
+	errs = addPersonaTx(t, tf, personaTag, addKey, msg.AddPersonaSignerKey{Label: "laptop", SignerAddress: signerAddrC})
+	assert.Len(t, errs, 0)
+
+	addrs, err := world.GetSignerAddressesForPersonaTag(personaTag, world.CurrentTick()-1)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, addrs, []string{signerAddrA, signerAddrB, signerAddrC})
+
+	// Labels and addresses can't be registered twice, and the signer address can't be a key
+	for _, m := range []msg.AddPersonaSignerKey{
+		{Label: "phone", SignerAddress: "0x4444444444444444444444444444444444444444"},
+		{Label: "tablet", SignerAddress: signerAddrB},
+		{Label: "tablet", SignerAddress: signerAddrA},
+	} {
+		errs = addPersonaTx(t, tf, personaTag, addKey, m)
+		assert.Len(t, errs, 1)
+	}
+
+	errs = addPersonaTx(t, tf, personaTag, removeKey, msg.RemovePersonaSignerKey{Label: "phone"})
+	assert.Len(t, errs, 0)
+	errs = addPersonaTx(t, tf, personaTag, removeKey, msg.RemovePersonaSignerKey{Label: "phone"})
+	assert.Len(t, errs, 1)
+
+	addrs, err = world.GetSignerAddressesForPersonaTag(personaTag, world.CurrentTick()-1)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, addrs, []string{signerAddrA, signerAddrC})
+
+	// Rotating the signer to one of the keys promotes the key
+	errs = addPersonaTx(t, tf, personaTag, "persona."+msg.RotatePersonaSignerMessageName,
+		msg.RotatePersonaSigner{SignerAddress: signerAddrC})
+	assert.Len(t, errs, 0)
+	addrs, err = world.GetSignerAddressesForPersonaTag(personaTag, world.CurrentTick()-1)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, addrs, []string{signerAddrC})
+
+	// The persona can still be found once it has no keys left
+	addr, err := world.GetSignerForPersonaTag(personaTag, world.CurrentTick()-1)
+	assert.NilError(t, err)
+	assert.Equal(t, addr, signerAddrC)
+}
+
+func TestRevokePersonaAddress(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	personaTag := "revoker"
+	tf.CreatePersona(personaTag, signerAddrA)
+
+	authorizedAddr := "0xd5e099c71b797516c10ed0f0d895f429c2781142"
+	errs := addPersonaTx(t, tf, personaTag, "game."+msg.AuthorizePersonaAddressMessageName,
+		msg.AuthorizePersonaAddress{Address: authorizedAddr})
+	assert.Len(t, errs, 0)
+
+	revoke := "persona." + msg.RevokePersonaAddressMessageName
+	errs = addPersonaTx(t, tf, personaTag, revoke, msg.RevokePersonaAddress{Address: authorizedAddr})
+	assert.Len(t, errs, 0)
+	sc, err := world.GetSignerComponentForPersona(personaTag)
+	assert.NilError(t, err)
+	assert.Len(t, sc.AuthorizedAddresses, 0)
+
+	errs = addPersonaTx(t, tf, personaTag, revoke, msg.RevokePersonaAddress{Address: authorizedAddr})
+	assert.Len(t, errs, 1)
+}
+
+func TestPersonaSessionKeys(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	personaTag := "sessioner"
+	tf.CreatePersona(personaTag, signerAddrA)
+
+	authorize := "persona." + msg.AuthorizeSessionKeyMessageName
+	errs := addPersonaTx(t, tf, personaTag, authorize, msg.AuthorizeSessionKey{
+		SessionAddress: signerAddrB,
+		Scope:          []string{"game"},
+		ExpiresAtTick:  world.CurrentTick() + 100,
+		MaxUses:        10,
+	})
+	assert.Len(t, errs, 0)
+	// This session key expires after the next tick
+	errs = addPersonaTx(t, tf, personaTag, authorize, msg.AuthorizeSessionKey{
+		SessionAddress: signerAddrC,
+		Scope:          []string{"game.move"},
+		ExpiresAtTick:  world.CurrentTick() + 2,
+	})
+	assert.Len(t, errs, 0)
+	// Session keys that have already expired can't be authorized
+	errs = addPersonaTx(t, tf, personaTag, authorize, msg.AuthorizeSessionKey{
+		SessionAddress: signerAddrC,
+		Scope:          []string{"game.move"},
+		ExpiresAt:      uint64(time.Now().Add(-time.Minute).UnixMilli()),
+	})
+	assert.Len(t, errs, 1)
+
+	sessions, err := world.GetSessionKeysForPersonaTag(personaTag, world.CurrentTick()-1)
+	assert.NilError(t, err)
+	assert.Len(t, sessions, 2)
+	assert.Equal(t, sessions[0].Address, signerAddrB)
+	assert.Equal(t, sessions[1].Address, signerAddrC)
+
+	// The expired session key isn't listed as an active session
+	tf.DoTick()
+	bz, err := world.HandleQuery("persona", "sessions", []byte(`{"personaTag":"`+personaTag+`"}`))
+	assert.NilError(t, err)
+	var res cardinal.PersonaSessionsQueryResponse
+	assert.NilError(t, json.Unmarshal(bz, &res))
+	assert.Len(t, res.Sessions, 1)
+	assert.Equal(t, res.Sessions[0].SessionAddress, signerAddrB)
+	assert.Equal(t, res.Sessions[0].MaxUses, uint64(10))
+
+	errs = addPersonaTx(t, tf, personaTag, "persona."+msg.RevokeSessionKeyMessageName,
+		msg.RevokeSessionKey{SessionAddress: signerAddrB})
+	assert.Len(t, errs, 0)
+	bz, err = world.HandleQuery("persona", "sessions", []byte(`{"personaTag":"`+personaTag+`"}`))
+	assert.NilError(t, err)
+	assert.NilError(t, json.Unmarshal(bz, &res))
+	assert.Len(t, res.Sessions, 0)
+}
+
+func TestRotatePersonaSignerRevokesSessionKeys(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	personaTag := "rotator"
+	tf.CreatePersona(personaTag, signerAddrA)
+
+	errs := addPersonaTx(t, tf, personaTag, "persona."+msg.AuthorizeSessionKeyMessageName, msg.AuthorizeSessionKey{
+		SessionAddress: signerAddrB,
+		Scope:          []string{"game"},
+		ExpiresAtTick:  world.CurrentTick() + 100,
+	})
+	assert.Len(t, errs, 0)
+	sessions, err := world.GetSessionKeysForPersonaTag(personaTag, world.CurrentTick()-1)
+	assert.NilError(t, err)
+	assert.Len(t, sessions, 1)
+
+	errs = addPersonaTx(t, tf, personaTag, "persona."+msg.RotatePersonaSignerMessageName,
+		msg.RotatePersonaSigner{SignerAddress: signerAddrC})
+	assert.Len(t, errs, 0)
+	sessions, err = world.GetSessionKeysForPersonaTag(personaTag, world.CurrentTick()-1)
+	assert.NilError(t, err)
+	assert.Len(t, sessions, 0)
+}
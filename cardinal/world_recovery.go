@@ -4,7 +4,6 @@ import (
 	"context"
 
 	"github.com/rotisserie/eris"
-	"github.com/rs/zerolog/log"
 
 	"pkg.world.dev/world-engine/cardinal/router/iterator"
 )
@@ -20,7 +19,7 @@ func (w *World) recoverFromChain(ctx context.Context) error {
 		)
 	}
 
-	log.Info().Msgf("Synchronizing state from base shard starting from tick %d", w.CurrentTick())
+	w.logger.Info().Msgf("Synchronizing state from base shard starting from tick %d", w.CurrentTick())
 
 	start := w.CurrentTick()
 	err := w.router.TransactionIterator().Each(func(batches []*iterator.TxBatch, tick, timestamp uint64) error {
@@ -29,23 +28,23 @@ func (w *World) recoverFromChain(ctx context.Context) error {
 			return eris.New("context cancelled, terminating recovery")
 
 		default:
-			log.Info().Msgf("Found transactions for tick %d", tick)
+			w.logger.Info().Msgf("Found transactions for tick %d", tick)
 
 			if w.CurrentTick() != tick {
-				log.Info().Msgf("Fast forwarding to tick %d from %d", tick, w.CurrentTick())
+				w.logger.Info().Msgf("Fast forwarding to tick %d from %d", tick, w.CurrentTick())
 			}
 			for w.CurrentTick() != tick {
 				if err := w.doTick(context.Background(), timestamp); err != nil {
 					return eris.Wrap(err, "failed to tick world")
 				}
 			}
-			log.Info().Msgf("Successfully fast forwarded to tick %d", tick)
+			w.logger.Info().Msgf("Successfully fast forwarded to tick %d", tick)
 
 			for _, batch := range batches {
 				w.AddTransaction(batch.MsgID, batch.MsgValue, batch.Tx)
 			}
 
-			log.Info().Msgf("Executing tick %d in recovery mode", tick)
+			w.logger.Info().Msgf("Executing tick %d in recovery mode", tick)
 			if err := w.doTick(context.Background(), timestamp); err != nil {
 				return eris.Wrap(err, "failed to tick world")
 			}
@@ -56,6 +55,6 @@ func (w *World) recoverFromChain(ctx context.Context) error {
 		return eris.Wrap(err, "encountered an error while recovering from chain")
 	}
 
-	log.Info().Msgf("Successfully synchronized state from base shard")
+	w.logger.Info().Msgf("Successfully synchronized state from base shard")
 	return nil
 }
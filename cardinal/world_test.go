@@ -2,16 +2,22 @@ package cardinal
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net"
+	"os"
+	"path/filepath"
+	"slices"
 	"testing"
 
 	"github.com/alicebob/miniredis/v2"
+	"github.com/gorilla/websocket"
 	"github.com/rotisserie/eris"
 
 	"pkg.world.dev/world-engine/assert"
 	"pkg.world.dev/world-engine/cardinal/filter"
+	"pkg.world.dev/world-engine/cardinal/server/handler"
 	"pkg.world.dev/world-engine/cardinal/types"
 	"pkg.world.dev/world-engine/cardinal/worldstage"
 )
@@ -383,3 +389,76 @@ func getOpenPort(t testing.TB) string {
 	assert.NilError(t, err)
 	return fmt.Sprintf("%d", tcpAddr.Port)
 }
+
+func TestDescribeWorldWritesTheWorldDescriptionInsteadOfStarting(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "world.json")
+	t.Setenv("CARDINAL_DESCRIBE_WORLD", path)
+	tf := NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, RegisterComponent[ScalarComponentStatic](world))
+	assert.NilError(t, RegisterMessage[ScalarComponentStatic, ScalarComponentToggle](world, "set-static"))
+
+	assert.NilError(t, world.StartGame())
+	assert.Equal(t, world.worldStage.Current(), worldstage.ShutDown)
+
+	bz, err := os.ReadFile(path)
+	assert.NilError(t, err)
+	var description handler.GetWorldResponse
+	assert.NilError(t, json.Unmarshal(bz, &description))
+	assert.Equal(t, description.Namespace, world.Namespace())
+	// The description lists every registered component, which includes Cardinal's own components, such as the
+	// SignerComponent registered by the persona plugin, so only the game's component is looked up.
+	assert.Check(t, slices.ContainsFunc(description.Components, func(field types.FieldDetail) bool {
+		return field.Name == "static" && len(field.Schema) > 0
+	}))
+	assert.Check(t, slices.ContainsFunc(description.Messages, func(field types.FieldDetail) bool {
+		return field.Name == "set-static" && len(field.Schema) > 0 && len(field.ResultSchema) > 0
+	}))
+}
+
+func TestWorldsCanRunSideBySide(t *testing.T) {
+	newFixture := func(namespace string) *TestFixture {
+		redis := miniredis.RunT(t)
+		cfg := defaultConfig
+		cfg.CardinalNamespace = namespace
+		cfg.RedisAddress = redis.Addr()
+		tf := NewTestFixture(t, redis, WithConfig(cfg))
+		assert.NilError(t, RegisterSystems(tf.World, func(wCtx WorldContext) error {
+			return wCtx.EmitEvent(map[string]any{"namespace": wCtx.Namespace()})
+		}))
+		tf.StartWorld()
+		return tf
+	}
+	tf1, tf2 := newFixture("world-one"), newFixture("world-two")
+
+	// Each world keeps its own persona tag index, so the same persona tag can be claimed in both worlds.
+	tf1.CreatePersona("alpha", "0x1111111111111111111111111111111111111111")
+	tf2.CreatePersona("alpha", "0x2222222222222222222222222222222222222222")
+	addr, err := tf1.World.GetSignerForPersonaTag("alpha", 0)
+	assert.NilError(t, err)
+	assert.Equal(t, addr, "0x1111111111111111111111111111111111111111")
+	addr, err = tf2.World.GetSignerForPersonaTag("alpha", 0)
+	assert.NilError(t, err)
+	assert.Equal(t, addr, "0x2222222222222222222222222222222222222222")
+
+	// Events are only broadcast to the websocket connections of the world that emitted them.
+	conn1, _, err := websocket.DefaultDialer.Dial("ws://"+tf1.BaseURL+"/events", nil)
+	assert.NilError(t, err)
+	defer conn1.Close()
+	conn2, _, err := websocket.DefaultDialer.Dial("ws://"+tf2.BaseURL+"/events", nil)
+	assert.NilError(t, err)
+	defer conn2.Close()
+
+	tf2.DoTick()
+	tf1.DoTick()
+	for conn, namespace := range map[*websocket.Conn]string{conn1: "world-one", conn2: "world-two"} {
+		_, bz, err := conn.ReadMessage()
+		assert.NilError(t, err)
+		var results TickResults
+		assert.NilError(t, json.Unmarshal(bz, &results))
+		assert.Equal(t, len(results.Events), 1)
+		var event map[string]any
+		assert.NilError(t, json.Unmarshal(results.Events[0], &event))
+		assert.Equal(t, event["namespace"], namespace)
+	}
+}
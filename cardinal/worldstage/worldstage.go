@@ -3,6 +3,7 @@ package worldstage
 import (
 	"sync/atomic"
 
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
 
@@ -25,12 +26,14 @@ type Manager struct {
 	// atStage contains a channel for each stage that will be closed when the stage is reached.
 	// This will allow goroutines to block until a specified stage has been reached.
 	atStage map[Stage]chan struct{}
+	logger  *zerolog.Logger
 }
 
 func NewManager() *Manager {
 	m := &Manager{
 		current: &atomic.Value{},
 		atStage: map[Stage]chan struct{}{},
+		logger:  &log.Logger,
 	}
 	m.current.Store(Init)
 
@@ -41,12 +44,17 @@ func NewManager() *Manager {
 	return m
 }
 
+// SetLogger replaces the logger stage changes are logged to, which defaults to the global logger.
+func (m *Manager) SetLogger(logger *zerolog.Logger) {
+	m.logger = logger
+}
+
 func (m *Manager) CompareAndSwap(oldStage, newStage Stage) (swapped bool) {
 	ok := m.current.CompareAndSwap(oldStage, newStage)
 	if ok {
 		close(m.atStage[newStage])
 	}
-	log.Info().Msgf("New world stage: %q → %q", oldStage, newStage)
+	m.logger.Info().Msgf("New world stage: %q → %q", oldStage, newStage)
 	return ok
 }
 
@@ -58,7 +66,7 @@ func (m *Manager) Store(newStage Stage) {
 	oldStage := m.current.Load()
 	m.current.Store(newStage)
 	close(m.atStage[newStage])
-	log.Info().Msgf("New world stage: %q → %q", oldStage, newStage)
+	m.logger.Info().Msgf("New world stage: %q → %q", oldStage, newStage)
 }
 
 // NotifyOnStage returns a channel that will be closed when the specified stage has been reached.
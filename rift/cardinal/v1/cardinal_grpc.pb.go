@@ -0,0 +1,325 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: cardinal/v1/cardinal.proto
+
+package cardinalv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// CardinalClient is the client API for Cardinal service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CardinalClient interface {
+	// SubmitTransaction submits a signed transaction for the message registered under group and name.
+	SubmitTransaction(ctx context.Context, in *SubmitTransactionRequest, opts ...grpc.CallOption) (*SubmitTransactionResponse, error)
+	// Query executes the query registered under group and name.
+	Query(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (*QueryResponse, error)
+	// ListReceipts lists the transaction receipts that are still held in the receipt history.
+	ListReceipts(ctx context.Context, in *ListReceiptsRequest, opts ...grpc.CallOption) (*ListReceiptsResponse, error)
+	// GetWorld returns the registered components, messages, and queries of the world.
+	GetWorld(ctx context.Context, in *GetWorldRequest, opts ...grpc.CallOption) (*GetWorldResponse, error)
+	// EvaluateCQL evaluates a CQL (Cardinal Query Language) query against the current game state.
+	EvaluateCQL(ctx context.Context, in *EvaluateCQLRequest, opts ...grpc.CallOption) (*EvaluateCQLResponse, error)
+	// TickResults streams the results of every completed tick until the client disconnects.
+	TickResults(ctx context.Context, in *TickResultsRequest, opts ...grpc.CallOption) (Cardinal_TickResultsClient, error)
+}
+
+type cardinalClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCardinalClient(cc grpc.ClientConnInterface) CardinalClient {
+	return &cardinalClient{cc}
+}
+
+func (c *cardinalClient) SubmitTransaction(ctx context.Context, in *SubmitTransactionRequest, opts ...grpc.CallOption) (*SubmitTransactionResponse, error) {
+	out := new(SubmitTransactionResponse)
+	err := c.cc.Invoke(ctx, "/world.engine.cardinal.v1.Cardinal/SubmitTransaction", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cardinalClient) Query(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (*QueryResponse, error) {
+	out := new(QueryResponse)
+	err := c.cc.Invoke(ctx, "/world.engine.cardinal.v1.Cardinal/Query", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cardinalClient) ListReceipts(ctx context.Context, in *ListReceiptsRequest, opts ...grpc.CallOption) (*ListReceiptsResponse, error) {
+	out := new(ListReceiptsResponse)
+	err := c.cc.Invoke(ctx, "/world.engine.cardinal.v1.Cardinal/ListReceipts", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cardinalClient) GetWorld(ctx context.Context, in *GetWorldRequest, opts ...grpc.CallOption) (*GetWorldResponse, error) {
+	out := new(GetWorldResponse)
+	err := c.cc.Invoke(ctx, "/world.engine.cardinal.v1.Cardinal/GetWorld", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cardinalClient) EvaluateCQL(ctx context.Context, in *EvaluateCQLRequest, opts ...grpc.CallOption) (*EvaluateCQLResponse, error) {
+	out := new(EvaluateCQLResponse)
+	err := c.cc.Invoke(ctx, "/world.engine.cardinal.v1.Cardinal/EvaluateCQL", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cardinalClient) TickResults(ctx context.Context, in *TickResultsRequest, opts ...grpc.CallOption) (Cardinal_TickResultsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Cardinal_ServiceDesc.Streams[0], "/world.engine.cardinal.v1.Cardinal/TickResults", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &cardinalTickResultsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Cardinal_TickResultsClient interface {
+	Recv() (*TickResultsResponse, error)
+	grpc.ClientStream
+}
+
+type cardinalTickResultsClient struct {
+	grpc.ClientStream
+}
+
+func (x *cardinalTickResultsClient) Recv() (*TickResultsResponse, error) {
+	m := new(TickResultsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// CardinalServer is the server API for Cardinal service.
+// All implementations must embed UnimplementedCardinalServer
+// for forward compatibility
+type CardinalServer interface {
+	// SubmitTransaction submits a signed transaction for the message registered under group and name.
+	SubmitTransaction(context.Context, *SubmitTransactionRequest) (*SubmitTransactionResponse, error)
+	// Query executes the query registered under group and name.
+	Query(context.Context, *QueryRequest) (*QueryResponse, error)
+	// ListReceipts lists the transaction receipts that are still held in the receipt history.
+	ListReceipts(context.Context, *ListReceiptsRequest) (*ListReceiptsResponse, error)
+	// GetWorld returns the registered components, messages, and queries of the world.
+	GetWorld(context.Context, *GetWorldRequest) (*GetWorldResponse, error)
+	// EvaluateCQL evaluates a CQL (Cardinal Query Language) query against the current game state.
+	EvaluateCQL(context.Context, *EvaluateCQLRequest) (*EvaluateCQLResponse, error)
+	// TickResults streams the results of every completed tick until the client disconnects.
+	TickResults(*TickResultsRequest, Cardinal_TickResultsServer) error
+	mustEmbedUnimplementedCardinalServer()
+}
+
+// UnimplementedCardinalServer must be embedded to have forward compatible implementations.
+type UnimplementedCardinalServer struct {
+}
+
+func (UnimplementedCardinalServer) SubmitTransaction(context.Context, *SubmitTransactionRequest) (*SubmitTransactionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitTransaction not implemented")
+}
+func (UnimplementedCardinalServer) Query(context.Context, *QueryRequest) (*QueryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Query not implemented")
+}
+func (UnimplementedCardinalServer) ListReceipts(context.Context, *ListReceiptsRequest) (*ListReceiptsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListReceipts not implemented")
+}
+func (UnimplementedCardinalServer) GetWorld(context.Context, *GetWorldRequest) (*GetWorldResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWorld not implemented")
+}
+func (UnimplementedCardinalServer) EvaluateCQL(context.Context, *EvaluateCQLRequest) (*EvaluateCQLResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EvaluateCQL not implemented")
+}
+func (UnimplementedCardinalServer) TickResults(*TickResultsRequest, Cardinal_TickResultsServer) error {
+	return status.Errorf(codes.Unimplemented, "method TickResults not implemented")
+}
+func (UnimplementedCardinalServer) mustEmbedUnimplementedCardinalServer() {}
+
+// UnsafeCardinalServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CardinalServer will
+// result in compilation errors.
+type UnsafeCardinalServer interface {
+	mustEmbedUnimplementedCardinalServer()
+}
+
+func RegisterCardinalServer(s grpc.ServiceRegistrar, srv CardinalServer) {
+	s.RegisterService(&Cardinal_ServiceDesc, srv)
+}
+
+func _Cardinal_SubmitTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CardinalServer).SubmitTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/world.engine.cardinal.v1.Cardinal/SubmitTransaction",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CardinalServer).SubmitTransaction(ctx, req.(*SubmitTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cardinal_Query_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CardinalServer).Query(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/world.engine.cardinal.v1.Cardinal/Query",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CardinalServer).Query(ctx, req.(*QueryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cardinal_ListReceipts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListReceiptsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CardinalServer).ListReceipts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/world.engine.cardinal.v1.Cardinal/ListReceipts",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CardinalServer).ListReceipts(ctx, req.(*ListReceiptsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cardinal_GetWorld_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetWorldRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CardinalServer).GetWorld(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/world.engine.cardinal.v1.Cardinal/GetWorld",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CardinalServer).GetWorld(ctx, req.(*GetWorldRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cardinal_EvaluateCQL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EvaluateCQLRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CardinalServer).EvaluateCQL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/world.engine.cardinal.v1.Cardinal/EvaluateCQL",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CardinalServer).EvaluateCQL(ctx, req.(*EvaluateCQLRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cardinal_TickResults_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TickResultsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CardinalServer).TickResults(m, &cardinalTickResultsServer{stream})
+}
+
+type Cardinal_TickResultsServer interface {
+	Send(*TickResultsResponse) error
+	grpc.ServerStream
+}
+
+type cardinalTickResultsServer struct {
+	grpc.ServerStream
+}
+
+func (x *cardinalTickResultsServer) Send(m *TickResultsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Cardinal_ServiceDesc is the grpc.ServiceDesc for Cardinal service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Cardinal_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "world.engine.cardinal.v1.Cardinal",
+	HandlerType: (*CardinalServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SubmitTransaction",
+			Handler:    _Cardinal_SubmitTransaction_Handler,
+		},
+		{
+			MethodName: "Query",
+			Handler:    _Cardinal_Query_Handler,
+		},
+		{
+			MethodName: "ListReceipts",
+			Handler:    _Cardinal_ListReceipts_Handler,
+		},
+		{
+			MethodName: "GetWorld",
+			Handler:    _Cardinal_GetWorld_Handler,
+		},
+		{
+			MethodName: "EvaluateCQL",
+			Handler:    _Cardinal_EvaluateCQL_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "TickResults",
+			Handler:       _Cardinal_TickResults_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "cardinal/v1/cardinal.proto",
+}
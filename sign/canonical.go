@@ -0,0 +1,168 @@
+package sign
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/rotisserie/eris"
+)
+
+// maxSafeInteger is the largest integer that is exactly representable as a double, 2^53.
+const maxSafeInteger = 1 << 53
+
+var ErrInvalidJSON = errors.New("invalid json")
+
+// CanonicalizeJSON returns the canonical encoding of the given JSON value, as specified by the JSON Canonicalization
+// Scheme (RFC 8785): object members are sorted by the UTF-16 code units of their names, there is no whitespace, and
+// strings and numbers have a single encoding. Values that are encoded differently, but are logically the same, have
+// the same canonical encoding, and so the same hash.
+//
+// RFC 8785 encodes every number as a double, which would give integers beyond 2^53 the same encoding as their
+// neighbours. Integer literals are kept as they are instead, so that they can't be swapped for a different integer
+// without changing the hash. This only differs from RFC 8785 for integers that doubles can't represent exactly.
+func CanonicalizeJSON(bz []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(bz))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, eris.Wrap(ErrInvalidJSON, err.Error())
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, eris.Wrap(ErrInvalidJSON, "unexpected data after the top-level value")
+	}
+	var buf bytes.Buffer
+	if err := writeCanonicalJSON(&buf, value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// IsCanonicalJSON reports whether the given JSON value is in canonical encoding.
+func IsCanonicalJSON(bz []byte) bool {
+	canonical, err := CanonicalizeJSON(bz)
+	return err == nil && bytes.Equal(bz, canonical)
+}
+
+func writeCanonicalJSON(buf *bytes.Buffer, value any) error {
+	switch v := value.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case string:
+		writeCanonicalString(buf, v)
+	case json.Number:
+		number, err := canonicalNumber(v)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(number)
+	case []any:
+		buf.WriteByte('[')
+		for i, elem := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonicalJSON(buf, elem); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]any:
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		slices.SortFunc(names, compareUTF16)
+		buf.WriteByte('{')
+		for i, name := range names {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeCanonicalString(buf, name)
+			buf.WriteByte(':')
+			if err := writeCanonicalJSON(buf, v[name]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return eris.Wrapf(ErrInvalidJSON, "unexpected value of type %T", value)
+	}
+	return nil
+}
+
+// writeCanonicalString writes the string with only the escapes that RFC 8785 requires: quotation marks, backslashes,
+// and control characters.
+func writeCanonicalString(buf *bytes.Buffer, s string) {
+	const hexDigits = "0123456789abcdef"
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 { //nolint:mnd // control characters
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hexDigits[r>>4])
+				buf.WriteByte(hexDigits[r&0xf])
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+}
+
+// canonicalNumber returns the ECMAScript encoding of the number as a double, which is what RFC 8785 requires, except
+// for integer literals that can't be represented exactly as a double.
+func canonicalNumber(n json.Number) (string, error) {
+	literal := n.String()
+	if !strings.ContainsAny(literal, ".eE") && !isSafeInteger(literal) {
+		// JSON doesn't allow leading zeros, so an integer literal is the only encoding of its integer
+		return literal, nil
+	}
+	f, err := strconv.ParseFloat(literal, 64)
+	if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+		return "", eris.Wrapf(ErrInvalidJSON, "number %s can't be represented", literal)
+	}
+	if f == 0 {
+		// also covers negative zero
+		return "0", nil
+	}
+	if abs := math.Abs(f); abs < 1e-6 || abs >= 1e21 {
+		// ECMAScript uses exponents for these magnitudes, and writes them without leading zeros
+		mantissa, exponent, _ := strings.Cut(strconv.FormatFloat(f, 'e', -1, 64), "e")
+		return mantissa + "e" + exponent[:1] + strings.TrimLeft(exponent[1:], "0"), nil
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64), nil
+}
+
+func isSafeInteger(literal string) bool {
+	i, err := strconv.ParseInt(literal, 10, 64)
+	return err == nil && i <= maxSafeInteger && i >= -maxSafeInteger
+}
+
+// compareUTF16 orders strings by their UTF-16 code units, as RFC 8785 requires for the names of object members.
+func compareUTF16(a, b string) int {
+	return slices.Compare(utf16.Encode([]rune(a)), utf16.Encode([]rune(b)))
+}
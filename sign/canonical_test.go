@@ -0,0 +1,112 @@
+package sign
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/rotisserie/eris"
+	"gotest.tools/v3/assert"
+)
+
+func TestCanonicalizeJSON(t *testing.T) {
+	testCases := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			// the example of section 3.2.2 of RFC 8785
+			name: "rfc 8785 example",
+			in: `{"numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+				"string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+				"literals": [null, true, false]}`,
+			want: `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],` +
+				`"string":"€$\u000f\nA'B\"\\\\\"/"}`,
+		},
+		{
+			// the sorting example of section 3.2.3 of RFC 8785
+			name: "members are sorted by utf-16 code units",
+			in:   `{"\u20ac":1,"\r":2,"\ufb33":3,"1":4,"\ud83d\ude00":5,"\u0080":6,"\u00f6":7}`,
+			want: "{\"\\r\":2,\"1\":4,\"\u0080\":6,\"ö\":7,\"€\":1,\"😀\":5,\"\ufb33\":3}",
+		},
+		{
+			name: "nested values and whitespace",
+			in:   " { \"b\" : [ 1 , { \"d\" : 1.0 , \"c\" : -0 } ] , \"a\" : \"<&>\" } ",
+			want: `{"a":"<&>","b":[1,{"c":0,"d":1}]}`,
+		},
+		{
+			name: "integers beyond 2^53 are kept exactly",
+			in:   `[9007199254740993, -12345678901234567890, 9007199254740992, 1e2]`,
+			want: `[9007199254740993,-12345678901234567890,9007199254740992,100]`,
+		},
+		{
+			name: "small and large magnitudes",
+			in:   `[0.000001, 0.0000001, 1e20, 1e21, -1.5e-7]`,
+			want: `[0.000001,1e-7,100000000000000000000,1e+21,-1.5e-7]`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := CanonicalizeJSON([]byte(tc.in))
+			assert.NilError(t, err)
+			assert.Equal(t, tc.want, string(got))
+			assert.Check(t, IsCanonicalJSON(got))
+			assert.Check(t, !IsCanonicalJSON([]byte(tc.in)))
+		})
+	}
+
+	for _, invalid := range []string{``, `{"a":}`, `{"a":1} {"b":2}`, `1e400`} {
+		_, err := CanonicalizeJSON([]byte(invalid))
+		assert.ErrorIs(t, eris.Unwrap(err), ErrInvalidJSON, invalid)
+	}
+}
+
+func TestTransactionHashCoversCanonicalBody(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	addr := crypto.PubkeyToAddress(key.PublicKey).Hex()
+	tx, err := NewTransaction(key, "my-tag", "my-namespace", map[string]any{"b": 2, "a": "<1>"})
+	assert.NilError(t, err)
+	assert.Equal(t, `{"a":"<1>","b":2}`, string(tx.Body))
+	assert.Check(t, tx.IsBodyCanonical())
+
+	// Reencoding the body gives the same hash, and keeps the signature valid
+	reencoded := *tx
+	reencoded.Body = json.RawMessage(`{ "b": 2.0, "a": "\u003c1\u003e" }`)
+	reencoded.Hash = common.Hash{}
+	assert.Equal(t, tx.HashHex(), reencoded.HashHex())
+	assert.Check(t, !reencoded.IsBodyCanonical())
+	assert.NilError(t, reencoded.Verify(addr))
+	// but a different body doesn't
+	reencoded.Body = json.RawMessage(`{"a":"<1>","b":3}`)
+	reencoded.Hash = common.Hash{}
+	assert.ErrorIs(t, eris.Unwrap(reencoded.Verify(addr)), ErrSignatureValidationFailed)
+}
+
+func TestCanVerifyLegacyBodySignatures(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	addr := crypto.PubkeyToAddress(key.PublicKey).Hex()
+
+	// Older clients signed the hash of the body as they encoded it
+	tx := &Transaction{
+		PersonaTag: "my-tag",
+		Namespace:  "my-namespace",
+		Timestamp:  TimestampNow(),
+		Body:       json.RawMessage(`{"b":2,"a":"\u003c1\u003e"}`),
+	}
+	sig, err := crypto.Sign(tx.hashBody(tx.Body).Bytes(), key)
+	assert.NilError(t, err)
+	tx.Signature = common.Bytes2Hex(sig)
+
+	assert.ErrorIs(t, eris.Unwrap(tx.Verify(addr)), ErrSignatureValidationFailed)
+	assert.NilError(t, tx.VerifyLegacyBody(addr))
+
+	// Transactions of current clients verify either way, as their bodies are canonical
+	tx, err = NewTransaction(key, "my-tag", "my-namespace", `{"b":2,"a":"<1>"}`)
+	assert.NilError(t, err)
+	assert.NilError(t, tx.Verify(addr))
+	assert.NilError(t, tx.VerifyLegacyBody(addr))
+}
@@ -0,0 +1,64 @@
+package sign
+
+import (
+	"crypto/ecdsa"
+	"encoding/binary"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/rotisserie/eris"
+)
+
+// challengeDomain separates challenge signatures from transaction signatures so that a signed challenge can never be
+// replayed as a transaction (and vice versa).
+const challengeDomain = "world-engine-challenge"
+
+var ErrEmptyChallenge = errors.New("challenge must not be empty")
+
+// ChallengeHash returns the hash that must be signed to answer the given challenge. The namespace is included so a
+// challenge answered for one shard cannot be reused on another. The namespace and challenge are prefixed with their
+// lengths, so that the boundary between them can't be moved to answer a different challenge.
+func ChallengeHash(namespace, challenge string) common.Hash {
+	return crypto.Keccak256Hash(
+		[]byte(challengeDomain),
+		lengthPrefixed(namespace),
+		lengthPrefixed(challenge),
+	)
+}
+
+// lengthPrefixed returns the given string prefixed with its length as a big-endian uint64.
+func lengthPrefixed(s string) []byte {
+	return append(binary.BigEndian.AppendUint64(nil, uint64(len(s))), s...)
+}
+
+// SignChallenge signs the given challenge with the given private key and returns the hex encoded signature.
+// This is used by clients to prove control of a persona's signer key, e.g. when authenticating a websocket session.
+func SignChallenge(pk *ecdsa.PrivateKey, namespace, challenge string) (string, error) {
+	return SignChallengeWithSigner(NewSecp256k1Signer(pk), namespace, challenge)
+}
+
+// SignChallengeWithSigner is like SignChallenge for signers of any signature scheme.
+func SignChallengeWithSigner(signer Signer, namespace, challenge string) (string, error) {
+	if challenge == "" {
+		return "", ErrEmptyChallenge
+	}
+	if len(namespace) == 0 {
+		return "", ErrInvalidNamespace
+	}
+	return signer.Sign(ChallengeHash(namespace, challenge))
+}
+
+// VerifyChallenge verifies that the signature answers the given challenge and was produced by the given signer, which
+// is in the format of FormatSigner. If nil is returned, the signature is valid.
+func VerifyChallenge(signer, namespace, challenge, signature string) error {
+	if challenge == "" {
+		return ErrEmptyChallenge
+	}
+	schemeName, publicKey := ParseSigner(signer)
+	scheme, err := LookupScheme(schemeName)
+	if err != nil {
+		return eris.Wrap(ErrSignatureValidationFailed, err.Error())
+	}
+	return scheme.Verify(publicKey, ChallengeHash(namespace, challenge), signature)
+}
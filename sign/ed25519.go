@@ -0,0 +1,70 @@
+package sign
+
+import (
+	"crypto/ed25519"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rotisserie/eris"
+)
+
+// NewEd25519Signer returns a Signer of ed25519 signatures.
+func NewEd25519Signer(key ed25519.PrivateKey) Signer {
+	return ed25519Signer{key: key}
+}
+
+type ed25519Signer struct {
+	key ed25519.PrivateKey
+}
+
+func (ed25519Signer) Scheme() string {
+	return SchemeEd25519
+}
+
+func (s ed25519Signer) Address() string {
+	publicKey, _ := s.key.Public().(ed25519.PublicKey)
+	return FormatSigner(SchemeEd25519, "0x"+common.Bytes2Hex(publicKey))
+}
+
+func (s ed25519Signer) Sign(hash common.Hash) (string, error) {
+	if len(s.key) != ed25519.PrivateKeySize {
+		return "", eris.New("invalid ed25519 private key")
+	}
+	return common.Bytes2Hex(ed25519.Sign(s.key, hash.Bytes())), nil
+}
+
+type ed25519Scheme struct{}
+
+func (ed25519Scheme) Name() string {
+	return SchemeEd25519
+}
+
+func (ed25519Scheme) NormalizePublicKey(publicKey string) (string, error) {
+	key, err := parseEd25519PublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+	return "0x" + common.Bytes2Hex(key), nil
+}
+
+func (ed25519Scheme) Verify(publicKey string, hash common.Hash, signature string) error {
+	key, err := parseEd25519PublicKey(publicKey)
+	if err != nil {
+		return eris.Wrap(ErrSignatureValidationFailed, err.Error())
+	}
+	sig, err := decodeHex(signature)
+	if err != nil {
+		return eris.Wrap(ErrSignatureValidationFailed, "hex to bytes failed")
+	}
+	if !ed25519.Verify(key, hash.Bytes(), sig) {
+		return eris.Wrap(ErrSignatureValidationFailed, "")
+	}
+	return nil
+}
+
+func parseEd25519PublicKey(publicKey string) (ed25519.PublicKey, error) {
+	bz, err := decodeHex(publicKey)
+	if err != nil || len(bz) != ed25519.PublicKeySize {
+		return nil, eris.Wrapf(ErrInvalidPublicKey, "%q is not a hex encoded ed25519 public key", publicKey)
+	}
+	return bz, nil
+}
@@ -0,0 +1,449 @@
+package sign
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/rotisserie/eris"
+)
+
+const (
+	// SignatureTypeEIP712 marks a transaction that is signed over its EIP-712 typed data, instead of the hash of the
+	// transaction. Wallets show the fields of typed data to their users, rather than an opaque hash.
+	SignatureTypeEIP712 = "eip712"
+
+	// TypedDataDomainVersion is the version of the EIP-712 domain of transactions.
+	TypedDataDomainVersion = "1"
+
+	typedDataDomainType      = "EIP712Domain"
+	typedDataTransactionType = "Transaction"
+)
+
+var (
+	ErrUnsupportedTypedDataType = errors.New("type can't be used in EIP-712 typed data")
+	ErrTypedDataNotPrepared     = errors.New("typed data of the transaction has not been prepared")
+	ErrInvalidTypedDataValue    = errors.New("invalid typed data value")
+
+	addressType = reflect.TypeOf(common.Address{})
+	bigIntType  = reflect.TypeOf(big.Int{})
+)
+
+// TypedDataField is a field of an EIP-712 struct type.
+type TypedDataField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// TypedDataTypes maps the names of EIP-712 struct types to their fields.
+type TypedDataTypes map[string][]TypedDataField
+
+// TypedData is the EIP-712 typed data of a transaction, in the format accepted by eth_signTypedData_v4.
+type TypedData struct {
+	Types       TypedDataTypes `json:"types"`
+	PrimaryType string         `json:"primaryType"`
+	Domain      map[string]any `json:"domain"`
+	Message     map[string]any `json:"message"`
+}
+
+// BodyTypes generates the EIP-712 types of a transaction body from its Go type, which must be a named struct. The
+// fields of the struct are named like their JSON encoding. The name of the struct type of the body is returned, along
+// with the types of the body and of the structs it contains.
+func BodyTypes(bodyType reflect.Type) (string, TypedDataTypes, error) {
+	for bodyType.Kind() == reflect.Pointer {
+		bodyType = bodyType.Elem()
+	}
+	if bodyType.Kind() != reflect.Struct {
+		return "", nil, eris.Wrapf(ErrUnsupportedTypedDataType, "body must be a struct, got %s", bodyType)
+	}
+	g := &typeGenerator{types: TypedDataTypes{}, goTypes: map[string]reflect.Type{}}
+	name, err := g.typeName(bodyType)
+	if err != nil {
+		return "", nil, err
+	}
+	return name, g.types, nil
+}
+
+// typeGenerator generates the EIP-712 types of Go types.
+type typeGenerator struct {
+	types TypedDataTypes
+	// goTypes keeps track of the Go types of the generated struct types, so that different Go types with the same name
+	// are caught.
+	goTypes map[string]reflect.Type
+}
+
+func (g *typeGenerator) typeName(t reflect.Type) (string, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == addressType:
+		return "address", nil
+	case t == bigIntType:
+		return "int256", nil
+	}
+
+	switch t.Kind() { //nolint:exhaustive // the other kinds are not supported
+	case reflect.String:
+		return "string", nil
+	case reflect.Bool:
+		return "bool", nil
+	case reflect.Int:
+		return "int64", nil
+	case reflect.Uint:
+		return "uint64", nil
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "int" + strconv.Itoa(t.Bits()), nil
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "uint" + strconv.Itoa(t.Bits()), nil
+	case reflect.Slice, reflect.Array:
+		// byte slices and arrays are JSON encoded as base64 strings and arrays of numbers respectively, neither of
+		// which wallets would show as bytes
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "", eris.Wrapf(ErrUnsupportedTypedDataType, "%s", t)
+		}
+		elem, err := g.typeName(t.Elem())
+		if err != nil {
+			return "", err
+		}
+		if t.Kind() == reflect.Array {
+			return fmt.Sprintf("%s[%d]", elem, t.Len()), nil
+		}
+		return elem + "[]", nil
+	case reflect.Struct:
+		return g.structName(t)
+	default:
+		return "", eris.Wrapf(ErrUnsupportedTypedDataType, "%s", t)
+	}
+}
+
+func (g *typeGenerator) structName(t reflect.Type) (string, error) {
+	name := t.Name()
+	if name == "" {
+		return "", eris.Wrapf(ErrUnsupportedTypedDataType, "anonymous struct %s", t)
+	}
+	if other, ok := g.goTypes[name]; ok {
+		if other != t {
+			return "", eris.Wrapf(ErrUnsupportedTypedDataType, "%s and %s have the same name", t, other)
+		}
+		return name, nil
+	}
+	g.goTypes[name] = t
+	fields, err := g.structFields(t)
+	if err != nil {
+		return "", err
+	}
+	g.types[name] = fields
+	return name, nil
+}
+
+// structFields returns the fields of the struct the way they are JSON encoded. The fields of embedded structs without
+// a JSON name are promoted.
+func (g *typeGenerator) structFields(t reflect.Type) ([]TypedDataField, error) {
+	fields := make([]TypedDataField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			promoted, err := g.structFields(fieldType)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, promoted...)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		typ, err := g.typeName(field.Type)
+		if err != nil {
+			return nil, eris.Wrapf(err, "field %s of %s", field.Name, t)
+		}
+		fields = append(fields, TypedDataField{Name: name, Type: typ})
+	}
+	return fields, nil
+}
+
+// TypedData returns the EIP-712 typed data of the transaction, given the Go type of its body. The domain is derived
+// from the namespace, which binds the signature to the shard.
+func (s *Transaction) TypedData(bodyType reflect.Type) (*TypedData, error) {
+	bodyName, types, err := BodyTypes(bodyType)
+	if err != nil {
+		return nil, err
+	}
+	for _, reserved := range []string{typedDataDomainType, typedDataTransactionType} {
+		if _, ok := types[reserved]; ok {
+			return nil, eris.Wrapf(ErrUnsupportedTypedDataType, "%s is a reserved type name", reserved)
+		}
+	}
+	types[typedDataDomainType] = []TypedDataField{
+		{Name: "name", Type: "string"},
+		{Name: "version", Type: "string"},
+	}
+	types[typedDataTransactionType] = []TypedDataField{
+		{Name: "personaTag", Type: "string"},
+		{Name: "timestamp", Type: "int64"},
+		{Name: "salt", Type: "uint16"},
+		{Name: "nonce", Type: "uint64"},
+		{Name: "body", Type: bodyName},
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(s.Body))
+	dec.UseNumber()
+	var body map[string]any
+	if err := dec.Decode(&body); err != nil {
+		return nil, eris.Wrap(err, "body of the transaction is not a JSON object")
+	}
+	return &TypedData{
+		Types:       types,
+		PrimaryType: typedDataTransactionType,
+		Domain: map[string]any{
+			"name":    s.Namespace,
+			"version": TypedDataDomainVersion,
+		},
+		Message: map[string]any{
+			"personaTag": s.PersonaTag,
+			"timestamp":  json.Number(strconv.FormatInt(s.Timestamp, 10)),
+			"salt":       json.Number(strconv.FormatUint(uint64(s.Salt), 10)),
+			"nonce":      json.Number(strconv.FormatUint(s.Nonce, 10)),
+			"body":       body,
+		},
+	}, nil
+}
+
+// PrepareTypedData computes the hash of the EIP-712 typed data of the transaction, given the Go type of its body. The
+// signature of an EIP-712 transaction is over this hash, so it must be prepared before the transaction is verified.
+func (s *Transaction) PrepareTypedData(bodyType reflect.Type) error {
+	typedData, err := s.TypedData(bodyType)
+	if err != nil {
+		return err
+	}
+	s.TypedDataHash, err = typedData.Hash()
+	return err
+}
+
+// IsEIP712 reports if the transaction is signed over its EIP-712 typed data.
+func (s *Transaction) IsEIP712() bool {
+	return s.SignatureType == SignatureTypeEIP712
+}
+
+// Hash returns the hash of the typed data that is signed, as defined by EIP-712:
+// keccak256("\x19\x01" ‖ hashStruct(domain) ‖ hashStruct(message)).
+func (d *TypedData) Hash() (common.Hash, error) {
+	domainHash, err := d.hashStruct(typedDataDomainType, d.Domain)
+	if err != nil {
+		return common.Hash{}, eris.Wrap(err, "failed to hash domain")
+	}
+	messageHash, err := d.hashStruct(d.PrimaryType, d.Message)
+	if err != nil {
+		return common.Hash{}, eris.Wrap(err, "failed to hash message")
+	}
+	return crypto.Keccak256Hash([]byte{0x19, 0x01}, domainHash, messageHash), nil
+}
+
+// encodeType returns the encoding of the struct type and the struct types it references, e.g.
+// "Mail(Person from,Person to,string contents)Person(string name,address wallet)".
+func (d *TypedData) encodeType(name string) string {
+	deps := d.dependencies(name, map[string]bool{})
+	slices.Sort(deps)
+	var sb strings.Builder
+	for _, typ := range append([]string{name}, deps...) {
+		sb.WriteString(typ)
+		sb.WriteString("(")
+		for i, field := range d.Types[typ] {
+			if i > 0 {
+				sb.WriteString(",")
+			}
+			sb.WriteString(field.Type + " " + field.Name)
+		}
+		sb.WriteString(")")
+	}
+	return sb.String()
+}
+
+// dependencies returns the struct types that are referenced by the given struct type, directly or indirectly.
+func (d *TypedData) dependencies(name string, seen map[string]bool) []string {
+	seen[name] = true
+	var deps []string
+	for _, field := range d.Types[name] {
+		typ := baseType(field.Type)
+		if _, ok := d.Types[typ]; !ok || seen[typ] {
+			continue
+		}
+		deps = append(deps, typ)
+		deps = append(deps, d.dependencies(typ, seen)...)
+	}
+	return deps
+}
+
+func (d *TypedData) hashStruct(name string, value any) ([]byte, error) {
+	fields, ok := d.Types[name]
+	if !ok {
+		return nil, eris.Errorf("type %s is not defined", name)
+	}
+	values, ok := value.(map[string]any)
+	if value != nil && !ok {
+		return nil, eris.Wrapf(ErrInvalidTypedDataValue, "%s must be an object", name)
+	}
+	buf := crypto.Keccak256([]byte(d.encodeType(name)))
+	for _, field := range fields {
+		enc, err := d.encodeValue(field.Type, values[field.Name])
+		if err != nil {
+			return nil, eris.Wrapf(err, "field %s of %s", field.Name, name)
+		}
+		buf = append(buf, enc...)
+	}
+	return crypto.Keccak256(buf), nil
+}
+
+// encodeValue returns the 32 byte encoding of the value of the given type. Missing values are encoded as the zero
+// value of the type, as they are left out of the JSON encoding of zero values.
+func (d *TypedData) encodeValue(typ string, value any) ([]byte, error) {
+	if _, ok := d.Types[typ]; ok {
+		return d.hashStruct(typ, value)
+	}
+	if strings.HasSuffix(typ, "]") {
+		elemType := typ[:strings.LastIndex(typ, "[")]
+		elems, ok := value.([]any)
+		if value != nil && !ok {
+			return nil, eris.Wrapf(ErrInvalidTypedDataValue, "%s must be an array", typ)
+		}
+		var buf []byte
+		for _, elem := range elems {
+			enc, err := d.encodeValue(elemType, elem)
+			if err != nil {
+				return nil, err
+			}
+			buf = append(buf, enc...)
+		}
+		return crypto.Keccak256(buf), nil
+	}
+
+	switch {
+	case typ == "string":
+		str, ok := value.(string)
+		if value != nil && !ok {
+			return nil, eris.Wrapf(ErrInvalidTypedDataValue, "%v is not a string", value)
+		}
+		return crypto.Keccak256([]byte(str)), nil
+	case typ == "bool":
+		b, ok := value.(bool)
+		if value != nil && !ok {
+			return nil, eris.Wrapf(ErrInvalidTypedDataValue, "%v is not a bool", value)
+		}
+		if b {
+			return math.U256Bytes(big.NewInt(1)), nil
+		}
+		return math.U256Bytes(big.NewInt(0)), nil
+	case typ == "address":
+		str, ok := value.(string)
+		if value != nil && (!ok || !common.IsHexAddress(str)) {
+			return nil, eris.Wrapf(ErrInvalidTypedDataValue, "%v is not an address", value)
+		}
+		return common.LeftPadBytes(common.HexToAddress(str).Bytes(), 32), nil //nolint:mnd // word size
+	case strings.HasPrefix(typ, "int"), strings.HasPrefix(typ, "uint"):
+		return encodeInteger(typ, value)
+	default:
+		return nil, eris.Wrapf(ErrUnsupportedTypedDataType, "%s", typ)
+	}
+}
+
+// encodeInteger returns the 32 byte two's complement encoding of an intN or uintN value.
+func encodeInteger(typ string, value any) ([]byte, error) {
+	signed := strings.HasPrefix(typ, "int")
+	bits, err := strconv.Atoi(strings.TrimPrefix(strings.TrimPrefix(typ, "u"), "int"))
+	if err != nil || bits <= 0 || bits > 256 || bits%8 != 0 {
+		return nil, eris.Wrapf(ErrUnsupportedTypedDataType, "%s", typ)
+	}
+
+	n := new(big.Int)
+	switch v := value.(type) {
+	case nil:
+	case json.Number:
+		if _, ok := n.SetString(v.String(), 10); !ok {
+			return nil, eris.Wrapf(ErrInvalidTypedDataValue, "%s is not an integer", v)
+		}
+	case string:
+		if _, ok := n.SetString(v, 0); !ok {
+			return nil, eris.Wrapf(ErrInvalidTypedDataValue, "%s is not an integer", v)
+		}
+	case float64:
+		if v != float64(int64(v)) {
+			return nil, eris.Wrapf(ErrInvalidTypedDataValue, "%v is not an integer", v)
+		}
+		n.SetInt64(int64(v))
+	default:
+		return nil, eris.Wrapf(ErrInvalidTypedDataValue, "%v is not an integer", value)
+	}
+
+	minValue, maxValue := big.NewInt(0), new(big.Int).Lsh(big.NewInt(1), uint(bits))
+	if signed {
+		maxValue.Rsh(maxValue, 1)
+		minValue.Neg(maxValue)
+	}
+	if n.Cmp(minValue) < 0 || n.Cmp(maxValue) >= 0 {
+		return nil, eris.Wrapf(ErrInvalidTypedDataValue, "%s is out of range for %s", n, typ)
+	}
+	return math.U256Bytes(n), nil
+}
+
+// baseType returns the type of the elements of an array type, or the type itself if it is not an array.
+func baseType(typ string) string {
+	if i := strings.Index(typ, "["); i >= 0 {
+		return typ[:i]
+	}
+	return typ
+}
+
+// NewEIP712Transaction signs the EIP-712 typed data of the given body, tag, and nonce with the given private key. The
+// body must be a struct, from which the EIP-712 types are generated. A nonce of 0 can be used when the server doesn't
+// use nonce-based replay protection.
+func NewEIP712Transaction(
+	pk *ecdsa.PrivateKey,
+	personaTag,
+	namespace string,
+	nonce uint64,
+	data any,
+) (*Transaction, error) {
+	if len(personaTag) == 0 || personaTag == SystemPersonaTag {
+		return nil, ErrInvalidPersonaTag
+	}
+	if data == nil {
+		return nil, ErrCannotSignEmptyBody
+	}
+	sp, err := newUnsignedTransaction(personaTag, namespace, nonce, data)
+	if err != nil {
+		return nil, err
+	}
+	sp.SignatureType = SignatureTypeEIP712
+	sp.populateHash()
+	if err = sp.PrepareTypedData(reflect.TypeOf(data)); err != nil {
+		return nil, err
+	}
+	buf, err := crypto.Sign(sp.TypedDataHash.Bytes(), pk)
+	if err != nil {
+		return nil, eris.Wrap(err, "error signing typed data hash")
+	}
+	sp.Signature = common.Bytes2Hex(buf)
+	return sp, nil
+}
@@ -0,0 +1,134 @@
+package sign
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/rotisserie/eris"
+	"gotest.tools/v3/assert"
+)
+
+type eip712Item struct {
+	Name     string `json:"name"`
+	Quantity uint32 `json:"quantity"`
+}
+
+type eip712Trade struct {
+	To       common.Address `json:"to"`
+	Items    []eip712Item   `json:"items"`
+	Offset   int16          `json:"offset,omitempty"`
+	Accepted bool
+	Ignored  string `json:"-"`
+}
+
+func TestBodyTypesAreGeneratedFromTheGoType(t *testing.T) {
+	name, types, err := BodyTypes(reflect.TypeOf(&eip712Trade{}))
+	assert.NilError(t, err)
+	assert.Equal(t, name, "eip712Trade")
+	assert.DeepEqual(t, types, TypedDataTypes{
+		"eip712Trade": {
+			{Name: "to", Type: "address"},
+			{Name: "items", Type: "eip712Item[]"},
+			{Name: "offset", Type: "int16"},
+			{Name: "Accepted", Type: "bool"},
+		},
+		"eip712Item": {
+			{Name: "name", Type: "string"},
+			{Name: "quantity", Type: "uint32"},
+		},
+	})
+
+	_, _, err = BodyTypes(reflect.TypeOf(struct{ Amount float64 }{}))
+	assert.ErrorIs(t, eris.Cause(err), ErrUnsupportedTypedDataType)
+	_, _, err = BodyTypes(reflect.TypeOf(eip712Item{}).Field(0).Type)
+	assert.ErrorIs(t, eris.Cause(err), ErrUnsupportedTypedDataType)
+}
+
+// TestTypedDataHashMatchesTheSpecification uses the example of https://eips.ethereum.org/EIPS/eip-712.
+func TestTypedDataHashMatchesTheSpecification(t *testing.T) {
+	var typedData TypedData
+	assert.NilError(t, json.Unmarshal([]byte(`{
+		"types": {
+			"EIP712Domain": [
+				{"name": "name", "type": "string"},
+				{"name": "version", "type": "string"},
+				{"name": "chainId", "type": "uint256"},
+				{"name": "verifyingContract", "type": "address"}
+			],
+			"Person": [
+				{"name": "name", "type": "string"},
+				{"name": "wallet", "type": "address"}
+			],
+			"Mail": [
+				{"name": "from", "type": "Person"},
+				{"name": "to", "type": "Person"},
+				{"name": "contents", "type": "string"}
+			]
+		},
+		"primaryType": "Mail",
+		"domain": {
+			"name": "Ether Mail",
+			"version": "1",
+			"chainId": 1,
+			"verifyingContract": "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+		},
+		"message": {
+			"from": {"name": "Cow", "wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+			"to": {"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+			"contents": "Hello, Bob!"
+		}
+	}`), &typedData))
+
+	assert.Equal(t, typedData.encodeType("Mail"),
+		"Mail(Person from,Person to,string contents)Person(string name,address wallet)")
+	hash, err := typedData.Hash()
+	assert.NilError(t, err)
+	assert.Equal(t, hash.Hex(), "0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2")
+}
+
+func TestCanSignAndVerifyEIP712Transaction(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	addressHex := crypto.PubkeyToAddress(key.PublicKey).Hex()
+	trade := eip712Trade{
+		To:       common.HexToAddress("0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"),
+		Items:    []eip712Item{{Name: "sword", Quantity: 1}},
+		Accepted: true,
+	}
+
+	tx, err := NewEIP712Transaction(key, "my-tag", "my-namespace", 7, trade)
+	assert.NilError(t, err)
+	buf, err := tx.Marshal()
+	assert.NilError(t, err)
+	toBeVerified, err := UnmarshalTransaction(buf)
+	assert.NilError(t, err)
+	assert.Assert(t, toBeVerified.IsEIP712())
+	assert.Equal(t, toBeVerified.Hash, tx.Hash)
+
+	// The typed data must be prepared before the signature can be verified
+	assert.ErrorIs(t, eris.Unwrap(toBeVerified.Verify(addressHex)), ErrSignatureValidationFailed)
+	assert.NilError(t, toBeVerified.PrepareTypedData(reflect.TypeOf(trade)))
+	assert.NilError(t, toBeVerified.Verify(addressHex))
+
+	// The signature is bound to the namespace through the domain
+	toBeVerified.Namespace = "other-namespace"
+	assert.NilError(t, toBeVerified.PrepareTypedData(reflect.TypeOf(trade)))
+	assert.ErrorIs(t, eris.Unwrap(toBeVerified.Verify(addressHex)), ErrSignatureValidationFailed)
+	toBeVerified.Namespace = tx.Namespace
+
+	// The body can't be altered without invalidating the signature
+	toBeVerified.Body = json.RawMessage(`{"to":"0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB",` +
+		`"items":[{"name":"sword","quantity":2}],"Accepted":true}`)
+	assert.NilError(t, toBeVerified.PrepareTypedData(reflect.TypeOf(trade)))
+	assert.ErrorIs(t, eris.Unwrap(toBeVerified.Verify(addressHex)), ErrSignatureValidationFailed)
+
+	// A legacy signature isn't accepted for an EIP-712 transaction
+	legacy, err := NewTransaction(key, "my-tag", "my-namespace", trade)
+	assert.NilError(t, err)
+	legacy.SignatureType = SignatureTypeEIP712
+	assert.NilError(t, legacy.PrepareTypedData(reflect.TypeOf(trade)))
+	assert.ErrorIs(t, eris.Unwrap(legacy.Verify(addressHex)), ErrSignatureValidationFailed)
+}
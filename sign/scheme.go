@@ -0,0 +1,231 @@
+package sign
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/rotisserie/eris"
+)
+
+const (
+	// SchemeSecp256k1 is the scheme of Ethereum signatures. Its public keys are the hex encoded addresses of the
+	// signers. Transactions and signers without a scheme use it.
+	SchemeSecp256k1 = "secp256k1"
+	// SchemeEd25519 is the scheme of ed25519 signatures, which are used by wallets of chains like Solana. Its public
+	// keys are hex encoded, and so are its signatures of the transaction hash.
+	SchemeEd25519 = "ed25519"
+	// SchemeWebAuthnP256 is the scheme of passkeys. Its public keys are hex encoded uncompressed P-256 points, and its
+	// signatures are WebAuthn assertions whose challenge is the transaction hash. See WebAuthnAssertion.
+	SchemeWebAuthnP256 = "webauthn-p256"
+
+	// signerSchemeSeparator separates the scheme of a signer from its public key.
+	signerSchemeSeparator = ":"
+)
+
+var (
+	ErrUnknownScheme           = errors.New("unknown signature scheme")
+	ErrInvalidSchemeName       = errors.New("invalid signature scheme name")
+	ErrSchemeAlreadyRegistered = errors.New("signature scheme has already been registered")
+	ErrInvalidPublicKey        = errors.New("invalid public key")
+
+	schemes = struct {
+		mux    sync.RWMutex
+		byName map[string]Scheme
+	}{
+		byName: map[string]Scheme{
+			SchemeSecp256k1:    secp256k1Scheme{},
+			SchemeEd25519:      ed25519Scheme{},
+			SchemeWebAuthnP256: webAuthnP256Scheme{},
+		},
+	}
+)
+
+// Scheme verifies the signatures of a signature scheme. The scheme of a transaction is picked by its Scheme field, and
+// the scheme of a persona signer by the prefix of the signer (see FormatSigner).
+type Scheme interface {
+	// Name tags the transactions and signers of the scheme. It must not contain a colon.
+	Name() string
+	// NormalizePublicKey checks the public key, and returns it in the form it is stored in signers.
+	NormalizePublicKey(publicKey string) (string, error)
+	// Verify checks that the signature over the hash was produced by the owner of the public key. If nil is returned,
+	// the signature is valid.
+	Verify(publicKey string, hash common.Hash, signature string) error
+}
+
+// Signer signs the hashes of transactions with the private key of a signature scheme.
+type Signer interface {
+	// Scheme is the name of the signature scheme of the signer.
+	Scheme() string
+	// Address is the signer as it is registered for a persona, in the format of FormatSigner.
+	Address() string
+	// Sign returns the signature of the hash, in the encoding of the scheme.
+	Sign(hash common.Hash) (string, error)
+}
+
+// RegisterScheme makes a signature scheme available for transactions and signers, in addition to the built-in
+// secp256k1, ed25519, and webauthn-p256 schemes.
+func RegisterScheme(scheme Scheme) error {
+	name := scheme.Name()
+	if name == "" || strings.Contains(name, signerSchemeSeparator) {
+		return eris.Wrapf(ErrInvalidSchemeName, "%q", name)
+	}
+	schemes.mux.Lock()
+	defer schemes.mux.Unlock()
+	if _, ok := schemes.byName[name]; ok {
+		return eris.Wrapf(ErrSchemeAlreadyRegistered, "%q", name)
+	}
+	schemes.byName[name] = scheme
+	return nil
+}
+
+// LookupScheme returns the registered signature scheme with the given name. The empty name is the secp256k1 scheme.
+func LookupScheme(name string) (Scheme, error) {
+	if name == "" {
+		name = SchemeSecp256k1
+	}
+	schemes.mux.RLock()
+	defer schemes.mux.RUnlock()
+	scheme, ok := schemes.byName[name]
+	if !ok {
+		return nil, eris.Wrapf(ErrUnknownScheme, "%q", name)
+	}
+	return scheme, nil
+}
+
+// FormatSigner returns the signer of a persona with the given public key of the given scheme. Signers of other schemes
+// than secp256k1 are prefixed with the scheme and a colon, e.g. "ed25519:0x...". Secp256k1 signers are plain Ethereum
+// addresses, so that the signers of existing personas keep their meaning.
+func FormatSigner(scheme, publicKey string) string {
+	if scheme == "" || scheme == SchemeSecp256k1 {
+		return publicKey
+	}
+	return scheme + signerSchemeSeparator + publicKey
+}
+
+// ParseSigner splits a signer in the format of FormatSigner into its scheme and public key.
+func ParseSigner(signer string) (scheme string, publicKey string) {
+	scheme, publicKey, ok := strings.Cut(signer, signerSchemeSeparator)
+	if !ok {
+		return SchemeSecp256k1, signer
+	}
+	return scheme, publicKey
+}
+
+// NormalizeSigner checks that the signer has a registered scheme and a valid public key, and returns it in normalized
+// form, so that signers can be compared.
+func NormalizeSigner(signer string) (string, error) {
+	schemeName, publicKey := ParseSigner(signer)
+	scheme, err := LookupScheme(schemeName)
+	if err != nil {
+		return "", err
+	}
+	publicKey, err = scheme.NormalizePublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+	return FormatSigner(schemeName, publicKey), nil
+}
+
+// SameSigner reports whether the two signers are the same, regardless of how they are encoded. Signers that can't be
+// normalized are compared regardless of their case.
+func SameSigner(a, b string) bool {
+	normalizedA, errA := NormalizeSigner(a)
+	normalizedB, errB := NormalizeSigner(b)
+	if errA != nil || errB != nil {
+		return strings.EqualFold(a, b)
+	}
+	return normalizedA == normalizedB
+}
+
+// NewTransactionWithSigner signs a given body, tag, and nonce with the given signer. The scheme of the signer is
+// recorded in the transaction, unless it is secp256k1.
+func NewTransactionWithSigner(
+	signer Signer,
+	personaTag,
+	namespace string,
+	nonce uint64,
+	data any,
+) (*Transaction, error) {
+	if len(personaTag) == 0 || personaTag == SystemPersonaTag {
+		return nil, ErrInvalidPersonaTag
+	}
+	return signWithSigner(signer, personaTag, namespace, nonce, data)
+}
+
+// NewSystemTransactionWithSigner signs a given body and nonce with the given signer using the SystemPersonaTag.
+func NewSystemTransactionWithSigner(signer Signer, namespace string, nonce uint64, data any) (*Transaction, error) {
+	return signWithSigner(signer, SystemPersonaTag, namespace, nonce, data)
+}
+
+// signWithSigner is like sign for signers of any signature scheme.
+func signWithSigner(signer Signer, personaTag, namespace string, nonce uint64, data any) (*Transaction, error) {
+	sp, err := newUnsignedTransaction(personaTag, namespace, nonce, data)
+	if err != nil {
+		return nil, err
+	}
+	if signer.Scheme() != SchemeSecp256k1 {
+		sp.Scheme = signer.Scheme()
+	}
+	sp.populateHash()
+	sp.Signature, err = signer.Sign(sp.Hash)
+	if err != nil {
+		return nil, err
+	}
+	return sp, nil
+}
+
+// NewSecp256k1Signer returns a Signer of Ethereum signatures.
+func NewSecp256k1Signer(pk *ecdsa.PrivateKey) Signer {
+	return secp256k1Signer{pk: pk}
+}
+
+type secp256k1Signer struct {
+	pk *ecdsa.PrivateKey
+}
+
+func (secp256k1Signer) Scheme() string {
+	return SchemeSecp256k1
+}
+
+func (s secp256k1Signer) Address() string {
+	return crypto.PubkeyToAddress(s.pk.PublicKey).Hex()
+}
+
+func (s secp256k1Signer) Sign(hash common.Hash) (string, error) {
+	buf, err := crypto.Sign(hash.Bytes(), s.pk)
+	if err != nil {
+		return "", eris.Wrap(err, "error signing hash")
+	}
+	return common.Bytes2Hex(buf), nil
+}
+
+type secp256k1Scheme struct{}
+
+func (secp256k1Scheme) Name() string {
+	return SchemeSecp256k1
+}
+
+func (secp256k1Scheme) NormalizePublicKey(publicKey string) (string, error) {
+	if !strings.HasPrefix(publicKey, "0x") || !common.IsHexAddress(publicKey) {
+		return "", eris.Wrapf(ErrInvalidPublicKey, "%q is not a hex encoded address", publicKey)
+	}
+	return common.HexToAddress(publicKey).Hex(), nil
+}
+
+func (secp256k1Scheme) Verify(publicKey string, hash common.Hash, signature string) error {
+	return verifyHash(hash, signature, publicKey)
+}
+
+// decodeHex decodes a hex encoded string, with or without a 0x prefix.
+func decodeHex(s string) ([]byte, error) {
+	bz, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return nil, eris.Wrap(err, "invalid hex")
+	}
+	return bz, nil
+}
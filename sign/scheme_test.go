@@ -0,0 +1,224 @@
+package sign
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/rotisserie/eris"
+	"gotest.tools/v3/assert"
+)
+
+// webAuthnTestSigner emulates a passkey that signs the hashes of transactions.
+type webAuthnTestSigner struct {
+	key         *ecdsa.PrivateKey
+	flags       byte
+	clientType  string
+	tamperedSig bool
+}
+
+func newWebAuthnTestSigner(t *testing.T) *webAuthnTestSigner {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+	return &webAuthnTestSigner{key: key, flags: webAuthnFlagUserPresent, clientType: webAuthnAssertionType}
+}
+
+func (*webAuthnTestSigner) Scheme() string {
+	return SchemeWebAuthnP256
+}
+
+func (w *webAuthnTestSigner) Address() string {
+	publicKey, _ := w.key.PublicKey.ECDH()
+	return FormatSigner(SchemeWebAuthnP256, "0x"+common.Bytes2Hex(publicKey.Bytes()))
+}
+
+func (w *webAuthnTestSigner) Sign(hash common.Hash) (string, error) {
+	clientData, err := json.Marshal(map[string]any{
+		"type":      w.clientType,
+		"challenge": WebAuthnChallenge(hash),
+		"origin":    "https://game.example",
+	})
+	if err != nil {
+		return "", err
+	}
+	rpIDHash := sha256.Sum256([]byte("game.example"))
+	authData := append(rpIDHash[:], w.flags, 0, 0, 0, 1)
+	clientDataHash := sha256.Sum256(clientData)
+	signed := sha256.Sum256(bytes.Join([][]byte{authData, clientDataHash[:]}, nil))
+	sig, err := ecdsa.SignASN1(rand.Reader, w.key, signed[:])
+	if err != nil {
+		return "", err
+	}
+	if w.tamperedSig {
+		authData[len(authData)-1]++
+	}
+	return WebAuthnAssertion{AuthenticatorData: authData, ClientDataJSON: clientData, Signature: sig}.Encode()
+}
+
+func TestCanSignAndVerifyWithEachScheme(t *testing.T) {
+	ethKey, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NilError(t, err)
+	_, otherEdKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NilError(t, err)
+
+	signers := []Signer{NewSecp256k1Signer(ethKey), NewEd25519Signer(edKey), newWebAuthnTestSigner(t)}
+	for _, signer := range signers {
+		t.Run(signer.Scheme(), func(t *testing.T) {
+			tx, err := NewTransactionWithSigner(signer, "my-tag", "my-namespace", 0, `{"msg": "hello"}`)
+			assert.NilError(t, err)
+			buf, err := tx.Marshal()
+			assert.NilError(t, err)
+			gotTx, err := UnmarshalTransaction(buf)
+			assert.NilError(t, err)
+			assert.Equal(t, signer.Scheme(), gotTx.SchemeName())
+			assert.NilError(t, gotTx.Verify(signer.Address()))
+
+			// The scheme is part of the hash, so it can't be changed
+			gotTx.Scheme = "other"
+			gotTx.populateHash()
+			assert.ErrorIs(t, eris.Unwrap(gotTx.Verify(signer.Address())), ErrSignatureValidationFailed)
+		})
+	}
+
+	// Signatures are only valid for signers of their own scheme
+	tx, err := NewTransactionWithSigner(NewEd25519Signer(edKey), "my-tag", "my-namespace", 0, `{"msg": "hello"}`)
+	assert.NilError(t, err)
+	assert.ErrorIs(t, eris.Unwrap(tx.Verify(crypto.PubkeyToAddress(ethKey.PublicKey).Hex())),
+		ErrSignatureValidationFailed)
+	assert.ErrorIs(t, eris.Unwrap(tx.Verify(NewEd25519Signer(otherEdKey).Address())), ErrSignatureValidationFailed)
+	legacyTx, err := NewTransaction(ethKey, "my-tag", "my-namespace", `{"msg": "hello"}`)
+	assert.NilError(t, err)
+	assert.ErrorIs(t, eris.Unwrap(legacyTx.Verify(NewEd25519Signer(edKey).Address())), ErrSignatureValidationFailed)
+}
+
+func TestSecp256k1SignerSignsLegacyTransactions(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	tx, err := NewTransactionWithSigner(NewSecp256k1Signer(key), "my-tag", "my-namespace", 0, `{"msg": "hello"}`)
+	assert.NilError(t, err)
+	assert.Equal(t, "", tx.Scheme)
+	assert.NilError(t, tx.Verify(crypto.PubkeyToAddress(key.PublicKey).Hex()))
+}
+
+func TestRejectInvalidWebAuthnAssertions(t *testing.T) {
+	testCases := []struct {
+		name   string
+		modify func(*webAuthnTestSigner)
+	}{
+		{name: "user not present", modify: func(w *webAuthnTestSigner) { w.flags = 0 }},
+		{name: "wrong client data type", modify: func(w *webAuthnTestSigner) { w.clientType = "webauthn.create" }},
+		{name: "tampered authenticator data", modify: func(w *webAuthnTestSigner) { w.tamperedSig = true }},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			signer := newWebAuthnTestSigner(t)
+			tc.modify(signer)
+			tx, err := NewTransactionWithSigner(signer, "my-tag", "my-namespace", 0, `{"msg": "hello"}`)
+			assert.NilError(t, err)
+			assert.ErrorIs(t, eris.Unwrap(tx.Verify(signer.Address())), ErrSignatureValidationFailed)
+		})
+	}
+
+	// The challenge must be the hash of the transaction
+	signer := newWebAuthnTestSigner(t)
+	tx, err := NewTransactionWithSigner(signer, "my-tag", "my-namespace", 0, `{"msg": "hello"}`)
+	assert.NilError(t, err)
+	tx.Signature, err = signer.Sign(crypto.Keccak256Hash([]byte("something else")))
+	assert.NilError(t, err)
+	assert.ErrorIs(t, eris.Unwrap(tx.Verify(signer.Address())), ErrSignatureValidationFailed)
+}
+
+func TestSignersCanBeNormalized(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	addr := crypto.PubkeyToAddress(key.PublicKey).Hex()
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NilError(t, err)
+	edSigner := NewEd25519Signer(edKey).Address()
+	passkey := newWebAuthnTestSigner(t).Address()
+
+	for _, signer := range []string{addr, edSigner, passkey} {
+		normalized, err := NormalizeSigner(signer)
+		assert.NilError(t, err)
+		assert.Equal(t, signer, normalized)
+	}
+	normalized, err := NormalizeSigner(strings.ToLower(addr))
+	assert.NilError(t, err)
+	assert.Equal(t, addr, normalized)
+	normalized, err = NormalizeSigner("ed25519:" + strings.ToUpper(strings.TrimPrefix(edSigner, "ed25519:0x")))
+	assert.NilError(t, err)
+	assert.Equal(t, edSigner, normalized)
+
+	assert.Assert(t, SameSigner(addr, strings.ToLower(addr)))
+	assert.Assert(t, SameSigner(addr, "secp256k1:"+strings.ToLower(addr)))
+	assert.Assert(t, !SameSigner(addr, edSigner))
+
+	for _, signer := range []string{
+		"0x1234",
+		"ed25519:0x1234",
+		"webauthn-p256:" + strings.TrimPrefix(edSigner, "ed25519:"),
+		"unknown:" + addr,
+	} {
+		_, err = NormalizeSigner(signer)
+		assert.Check(t, err != nil, signer)
+	}
+	_, err = NormalizeSigner("unknown:" + addr)
+	assert.ErrorIs(t, eris.Unwrap(err), ErrUnknownScheme)
+}
+
+// prefixScheme is a toy signature scheme whose signatures are the public key followed by the hash.
+type prefixScheme struct{}
+
+func (prefixScheme) Name() string {
+	return "prefix-test"
+}
+
+func (prefixScheme) NormalizePublicKey(publicKey string) (string, error) {
+	return publicKey, nil
+}
+
+func (prefixScheme) Verify(publicKey string, hash common.Hash, signature string) error {
+	if signature != publicKey+hash.Hex() {
+		return eris.Wrap(ErrSignatureValidationFailed, "")
+	}
+	return nil
+}
+
+type prefixSigner struct{}
+
+func (prefixSigner) Scheme() string {
+	return "prefix-test"
+}
+
+func (prefixSigner) Address() string {
+	return FormatSigner("prefix-test", "me")
+}
+
+func (prefixSigner) Sign(hash common.Hash) (string, error) {
+	return "me" + hash.Hex(), nil
+}
+
+func TestCanRegisterSchemes(t *testing.T) {
+	assert.NilError(t, RegisterScheme(prefixScheme{}))
+	assert.ErrorIs(t, eris.Unwrap(RegisterScheme(prefixScheme{})), ErrSchemeAlreadyRegistered)
+	assert.ErrorIs(t, eris.Unwrap(RegisterScheme(secp256k1Scheme{})), ErrSchemeAlreadyRegistered)
+
+	tx, err := NewTransactionWithSigner(prefixSigner{}, "my-tag", "my-namespace", 0, `{"msg": "hello"}`)
+	assert.NilError(t, err)
+	assert.NilError(t, tx.Verify("prefix-test:me"))
+	assert.ErrorIs(t, eris.Unwrap(tx.Verify("prefix-test:you")), ErrSignatureValidationFailed)
+
+	sig, err := SignChallengeWithSigner(prefixSigner{}, "my-namespace", "challenge")
+	assert.NilError(t, err)
+	assert.NilError(t, VerifyChallenge("prefix-test:me", "my-namespace", "challenge", sig))
+}
@@ -42,9 +42,18 @@ type Transaction struct {
 	Namespace  string          `json:"namespace"`
 	Timestamp  int64           `json:"timestamp"`                 // unix millisecond timestamp
 	Salt       uint16          `json:"salt,omitempty"`            // an optional field for additional hash uniqueness
+	Nonce      uint64          `json:"nonce,omitempty"`           // the signer's nonce, for nonce-based replay protection
 	Signature  string          `json:"signature"`                 // hex encoded string
 	Hash       common.Hash     `json:"-"`                         // don't marshal or unmarshal for json
 	Body       json.RawMessage `json:"body" swaggertype:"object"` // json string
+	// SignatureType is SignatureTypeEIP712 if the signature is over the EIP-712 typed data of the transaction. It is
+	// empty if the signature is over Hash.
+	SignatureType string `json:"signatureType,omitempty"`
+	// Scheme is the signature scheme of the signature, see RegisterScheme. It is empty for secp256k1 signatures.
+	Scheme string `json:"scheme,omitempty"`
+
+	// TypedDataHash is the hash of the EIP-712 typed data of the transaction, which is set by PrepareTypedData.
+	TypedDataHash common.Hash `json:"-"`
 }
 
 // returns a sign compatible timestamp for the current wall time
@@ -106,13 +115,16 @@ func (s *Transaction) checkRequiredFields() error {
 func MappedTransaction(tx map[string]interface{}) (*Transaction, error) {
 	s := new(Transaction)
 	transactionKeys := map[string]bool{
-		"personaTag": true,
-		"namespace":  true,
-		"signature":  true,
-		"timestamp":  true,
-		"salt":       true,
-		"body":       true,
-		"hash":       true,
+		"personaTag":    true,
+		"namespace":     true,
+		"signature":     true,
+		"timestamp":     true,
+		"salt":          true,
+		"nonce":         true,
+		"body":          true,
+		"signatureType": true,
+		"scheme":        true,
+		"hash":          true,
 	}
 	for key := range tx {
 		if !transactionKeys[key] {
@@ -141,8 +153,9 @@ func MappedTransaction(tx map[string]interface{}) (*Transaction, error) {
 	return s, nil
 }
 
-// normalizeJSON marshals the given data object. If data is a string or bytes, the json format is verified
-// and any extraneous spaces are removed. Otherwise, the given data is run through json.Marshal.
+// normalizeJSON marshals the given data object into its canonical encoding (see CanonicalizeJSON). If data is a string
+// or bytes, the json format is verified and it is canonicalized. Otherwise, the given data is run through json.Marshal
+// first.
 func normalizeJSON(data any) ([]byte, error) {
 	var asBuf []byte
 	if v, ok := data.(string); ok {
@@ -153,30 +166,31 @@ func normalizeJSON(data any) ([]byte, error) {
 	if asBuf == nil {
 		// The given data was neither a string nor a []byte. Just json.Marshal it.
 		res, err := json.Marshal(data)
-		return res, eris.Wrap(err, "")
-	}
-
-	asMap := map[string]any{}
-
-	// The swagger endpoints end up processing the transaction body as a map[string]any{}. When this map is
-	// marshalled, the resulting JSON blob has keys in sorted order. If the original JSON blob did NOT have
-	// sorted keys, the resulting hashes will be different and the signature will fail.
-	// For this reason, we must Unmarshal/Marshal any pre-built JSON bodies to ensure the resulting hashes during
-	// signing match the hash during verification
-	if err := json.Unmarshal(asBuf, &asMap); err != nil {
-		return nil, eris.Errorf("data %q is not valid json", string(asBuf))
+		if err != nil {
+			return nil, eris.Wrap(err, "")
+		}
+		asBuf = res
 	}
 
-	normalizedBz, err := json.Marshal(asMap)
+	// The swagger endpoints end up processing the transaction body as a map[string]any{}, and clients may encode the
+	// same body differently. Transactions are hashed over the canonical encoding of their bodies, so that the hashes
+	// during signing match the hash during verification. The body is canonicalized too, so that it is accepted by
+	// servers that require canonical bodies.
+	normalizedBz, err := CanonicalizeJSON(asBuf)
 	if err != nil {
-		return nil, eris.Wrapf(err, "failed to generate compact json")
+		return nil, eris.Errorf("data %q is not valid json", string(asBuf))
 	}
 	return normalizedBz, nil
 }
 
-// sign uses the given private key to sign the personaTag, namespace, timestamp, and data. The timestamp is set
-// automatically to the wall time by the sign function just before signing.
-func sign(pk *ecdsa.PrivateKey, personaTag, namespace string, data any) (*Transaction, error) {
+// sign uses the given private key to sign the personaTag, namespace, timestamp, nonce, and data. The timestamp is set
+// automatically to the wall time by the sign function just before signing. A nonce of 0 is left out of the signature.
+func sign(pk *ecdsa.PrivateKey, personaTag, namespace string, nonce uint64, data any) (*Transaction, error) {
+	return signWithSigner(NewSecp256k1Signer(pk), personaTag, namespace, nonce, data)
+}
+
+// newUnsignedTransaction returns a transaction of the given data, with the timestamp set to the wall time.
+func newUnsignedTransaction(personaTag, namespace string, nonce uint64, data any) (*Transaction, error) {
 	if data == nil || reflect.ValueOf(data).IsZero() {
 		return nil, ErrCannotSignEmptyBody
 	}
@@ -190,38 +204,54 @@ func sign(pk *ecdsa.PrivateKey, personaTag, namespace string, data any) (*Transa
 	if len(bz) == 0 {
 		return nil, ErrCannotSignEmptyBody
 	}
-	sp := &Transaction{
+	return &Transaction{
 		PersonaTag: personaTag,
 		Namespace:  namespace,
 		Timestamp:  TimestampNow(),
 		Salt:       uint16(rand.Intn(math.MaxUint16)), //nolint: gosec // additional uniqueness for each hash and sign
+		Nonce:      nonce,
 		Body:       bz,
-	}
-	sp.populateHash()
-	buf, err := crypto.Sign(sp.Hash.Bytes(), pk)
-	if err != nil {
-		return nil, eris.Wrap(err, "error signing hash")
-	}
-	sp.Signature = common.Bytes2Hex(buf)
-	return sp, nil
+	}, nil
 }
 
 // NewSystemTransaction signs a given body with the given private key using the SystemPersonaTag.
 func NewSystemTransaction(pk *ecdsa.PrivateKey, namespace string, data any) (*Transaction, error) {
-	return sign(pk, SystemPersonaTag, namespace, data)
+	return sign(pk, SystemPersonaTag, namespace, 0, data)
+}
+
+// NewSystemTransactionWithNonce signs a given body and nonce with the given private key using the SystemPersonaTag.
+func NewSystemTransactionWithNonce(
+	pk *ecdsa.PrivateKey,
+	namespace string,
+	nonce uint64,
+	data any,
+) (*Transaction, error) {
+	return sign(pk, SystemPersonaTag, namespace, nonce, data)
 }
 
-// NewTransaction signs a given body, tag, and nonce with the given private key.
+// NewTransaction signs a given body and tag with the given private key.
 func NewTransaction(
 	pk *ecdsa.PrivateKey,
 	personaTag,
 	namespace string,
 	data any,
+) (*Transaction, error) {
+	return NewTransactionWithNonce(pk, personaTag, namespace, 0, data)
+}
+
+// NewTransactionWithNonce signs a given body, tag, and nonce with the given private key. Nonces are required by
+// servers that use nonce-based replay protection, and must be unique for each of the signer's transactions.
+func NewTransactionWithNonce(
+	pk *ecdsa.PrivateKey,
+	personaTag,
+	namespace string,
+	nonce uint64,
+	data any,
 ) (*Transaction, error) {
 	if len(personaTag) == 0 || personaTag == SystemPersonaTag {
 		return nil, ErrInvalidPersonaTag
 	}
-	return sign(pk, personaTag, namespace, data)
+	return sign(pk, personaTag, namespace, nonce, data)
 }
 
 func (s *Transaction) IsSystemTransaction() bool {
@@ -248,26 +278,58 @@ func (s *Transaction) HashHex() string {
 	return s.Hash.Hex()
 }
 
-// Verify verifies this Transaction has a valid signature. If nil is returned, the signature is valid.
-// Signature verification follows the pattern in crypto.TestSign:
+// Verify verifies this Transaction has a valid signature of the given signer, which is in the format of FormatSigner.
+// If nil is returned, the signature is valid. The signature must be of the scheme of the signer.
+// Secp256k1 signature verification follows the pattern in crypto.TestSign:
 // https://github.com/ethereum/go-ethereum/blob/master/crypto/crypto_test.go#L94
+// The typed data of EIP-712 transactions must be prepared with PrepareTypedData before they can be verified.
 // TODO: Review this signature verification, and compare it to geth's sig verification
-func (s *Transaction) Verify(hexAddress string) error {
-	addr := common.HexToAddress(hexAddress)
-
+func (s *Transaction) Verify(signer string) error {
 	if IsZeroHash(s.Hash) {
 		s.populateHash()
 	}
+	schemeName, publicKey := ParseSigner(signer)
+	if schemeName != s.SchemeName() {
+		return eris.Wrapf(ErrSignatureValidationFailed, "signature is of scheme %s, not %s", s.SchemeName(), schemeName)
+	}
+	scheme, err := LookupScheme(schemeName)
+	if err != nil {
+		return eris.Wrap(ErrSignatureValidationFailed, err.Error())
+	}
+	hash := s.Hash
+	if s.IsEIP712() {
+		if schemeName != SchemeSecp256k1 {
+			return eris.Wrapf(ErrSignatureValidationFailed, "EIP-712 signatures must be of scheme %s", SchemeSecp256k1)
+		}
+		if IsZeroHash(s.TypedDataHash) {
+			return eris.Wrap(ErrSignatureValidationFailed, ErrTypedDataNotPrepared.Error())
+		}
+		hash = s.TypedDataHash
+	}
+	return scheme.Verify(publicKey, hash, s.Signature)
+}
+
+// SchemeName returns the name of the signature scheme of the transaction.
+func (s *Transaction) SchemeName() string {
+	if s.Scheme == "" {
+		return SchemeSecp256k1
+	}
+	return s.Scheme
+}
+
+// verifyHash checks that the hex encoded signature over hash was produced by the key that owns hexAddress.
+func verifyHash(hash common.Hash, signature string, hexAddress string) error {
+	addr := common.HexToAddress(hexAddress)
 
-	sig := common.Hex2Bytes(s.Signature)
-	if len(sig) < crypto.RecoveryIDOffset {
-		return eris.Wrap(ErrSignatureValidationFailed, "hex to bytes failed")
+	sig := common.Hex2Bytes(signature)
+	if len(sig) != crypto.SignatureLength {
+		return eris.Wrapf(ErrSignatureValidationFailed, "signature must be %d bytes", crypto.SignatureLength)
 	}
 	if sig[crypto.RecoveryIDOffset] == 27 || sig[crypto.RecoveryIDOffset] == 28 {
 		sig[crypto.RecoveryIDOffset] -= 27 // Transform yellow paper V from 27/28 to 0/1
 	}
 
-	signerPubKey, err := crypto.SigToPub(s.Hash.Bytes(), sig)
+	signerPubKey, err := crypto.SigToPub(hash.Bytes(), sig)
 	err = eris.Wrap(err, "")
 	if err != nil {
 		return err
@@ -279,23 +341,54 @@ func (s *Transaction) Verify(hexAddress string) error {
 	return nil
 }
 
+// IsBodyCanonical reports whether the body of the transaction is in canonical encoding (see CanonicalizeJSON).
+func (s *Transaction) IsBodyCanonical() bool {
+	return IsCanonicalJSON(s.Body)
+}
+
+// VerifyLegacyBody is like Verify, but checks the signature over the hash of the body as it is encoded, rather than
+// the hash of its canonical encoding. Clients that predate canonical body hashing sign this hash.
+func (s *Transaction) VerifyLegacyBody(signer string) error {
+	legacy := *s
+	legacy.Hash = s.hashBody(s.Body)
+	return legacy.Verify(signer)
+}
+
+// populateHash sets the hash of the transaction, which covers the canonical encoding of the body, so that bodies that
+// are encoded differently but are logically the same have the same hash. Bodies that aren't valid json are hashed as
+// they are.
 func (s *Transaction) populateHash() {
+	body, err := CanonicalizeJSON(s.Body)
+	if err != nil {
+		body = s.Body
+	}
+	s.Hash = s.hashBody(body)
+}
+
+func (s *Transaction) hashBody(body []byte) common.Hash {
+	parts := [][]byte{
+		[]byte(s.PersonaTag),
+		[]byte(s.Namespace),
+		[]byte(strconv.FormatInt(s.Timestamp, 10)),
+	}
+	// salt not set, don't include it in the hash
+	// this is needed for kms test with precomputed signature
 	if s.Salt != 0 {
-		s.Hash = crypto.Keccak256Hash(
-			[]byte(s.PersonaTag),
-			[]byte(s.Namespace),
-			[]byte(strconv.FormatInt(s.Timestamp, 10)),
-			[]byte(strconv.FormatInt(int64(s.Salt), 10)),
-			s.Body,
-		)
-	} else {
-		// salt not set, don't include it in the hash
-		// this is needed for kms test with precomputed signature
-		s.Hash = crypto.Keccak256Hash(
-			[]byte(s.PersonaTag),
-			[]byte(s.Namespace),
-			[]byte(strconv.FormatInt(s.Timestamp, 10)),
-			s.Body,
-		)
+		parts = append(parts, []byte(strconv.FormatInt(int64(s.Salt), 10)))
+	}
+	// the nonce is left out when it's not set, so that the hashes of transactions without a nonce don't change. It is
+	// prefixed so that its digits can't be confused with the digits of the salt
+	if s.Nonce != 0 {
+		parts = append(parts, []byte("nonce:"+strconv.FormatUint(s.Nonce, 10)))
+	}
+	// likewise, the signature type is only included for EIP-712 transactions
+	if s.SignatureType != "" {
+		parts = append(parts, []byte("signatureType:"+s.SignatureType))
+	}
+	// and the scheme for transactions that aren't signed with secp256k1
+	if s.Scheme != "" {
+		parts = append(parts, []byte("scheme:"+s.Scheme))
 	}
+	parts = append(parts, body)
+	return crypto.Keccak256Hash(parts...)
 }
@@ -2,6 +2,7 @@ package sign
 
 import (
 	"encoding/json"
+	"strconv"
 	"testing"
 	"time"
 
@@ -49,6 +50,39 @@ func TestCanSignAndVerifyPayload(t *testing.T) {
 	assert.ErrorIs(t, err, ErrSignatureValidationFailed)
 }
 
+func TestNonceIsSigned(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	addressHex := crypto.PubkeyToAddress(key.PublicKey).Hex()
+
+	tx, err := NewTransactionWithNonce(key, "my-tag", "my-namespace", 42, `{"msg": "hello"}`)
+	assert.NilError(t, err)
+	buf, err := tx.Marshal()
+	assert.NilError(t, err)
+	toBeVerified, err := UnmarshalTransaction(buf)
+	assert.NilError(t, err)
+	assert.Equal(t, toBeVerified.Nonce, uint64(42))
+	assert.NilError(t, toBeVerified.Verify(addressHex))
+
+	// The nonce can't be altered without invalidating the signature
+	toBeVerified.Nonce++
+	toBeVerified.Hash = common.Hash{}
+	assert.ErrorIs(t, eris.Unwrap(toBeVerified.Verify(addressHex)), ErrSignatureValidationFailed)
+
+	// Transactions without a nonce are hashed the way they were before nonces were added
+	toBeVerified.Nonce = 0
+	toBeVerified.Salt = 1
+	toBeVerified.Hash = common.Hash{}
+	want := crypto.Keccak256Hash(
+		[]byte(toBeVerified.PersonaTag),
+		[]byte(toBeVerified.Namespace),
+		[]byte(strconv.FormatInt(toBeVerified.Timestamp, 10)),
+		[]byte(strconv.FormatInt(int64(toBeVerified.Salt), 10)),
+		toBeVerified.Body,
+	)
+	assert.Equal(t, toBeVerified.HashHex(), want.Hex())
+}
+
 func TestCanParseAMappedTransaction(t *testing.T) {
 	goodKey, err := crypto.GenerateKey()
 	assert.NilError(t, err)
@@ -353,3 +387,51 @@ func TestUnsortedJSONBlobsCanBeSignedAndVerified(t *testing.T) {
 
 	assert.NilError(t, gotTx.Verify(addr))
 }
+
+func TestCanSignAndVerifyChallenge(t *testing.T) {
+	goodKey, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	badKey, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	goodAddressHex := crypto.PubkeyToAddress(goodKey.PublicKey).Hex()
+	badAddressHex := crypto.PubkeyToAddress(badKey.PublicKey).Hex()
+	namespace := "my-namespace"
+	challenge := "some-random-challenge"
+
+	sig, err := SignChallenge(goodKey, namespace, challenge)
+	assert.NilError(t, err)
+	assert.NilError(t, VerifyChallenge(goodAddressHex, namespace, challenge, sig))
+
+	// The wrong signer, namespace, or challenge must all fail verification
+	assert.ErrorIs(t, eris.Unwrap(VerifyChallenge(badAddressHex, namespace, challenge, sig)),
+		ErrSignatureValidationFailed)
+	assert.Check(t, VerifyChallenge(goodAddressHex, "other-namespace", challenge, sig) != nil)
+	assert.Check(t, VerifyChallenge(goodAddressHex, namespace, "other-challenge", sig) != nil)
+
+	_, err = SignChallenge(goodKey, namespace, "")
+	assert.ErrorIs(t, err, ErrEmptyChallenge)
+}
+
+func TestChallengeHashSeparatesNamespaceAndChallenge(t *testing.T) {
+	// Moving the boundary between the namespace and the challenge must change the hash
+	assert.Check(t, ChallengeHash("my-namespace", "challenge") != ChallengeHash("my-namespace-chal", "lenge"))
+	assert.Check(t, ChallengeHash("my-namespace", "challenge") != ChallengeHash("my-", "namespacechallenge"))
+}
+
+func TestVerifyRejectsSignatureOfWrongLength(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	addressHex := crypto.PubkeyToAddress(key.PublicKey).Hex()
+	namespace := "my-namespace"
+	challenge := "some-random-challenge"
+
+	sig, err := SignChallenge(key, namespace, challenge)
+	assert.NilError(t, err)
+	sigBytes := common.FromHex(sig)
+
+	// A signature without its recovery ID, or with extra bytes, must fail verification without panicking
+	for _, bz := range [][]byte{sigBytes[:crypto.SignatureLength-1], append(sigBytes, 0), nil} {
+		err = VerifyChallenge(addressHex, namespace, challenge, common.Bytes2Hex(bz))
+		assert.ErrorIs(t, eris.Unwrap(err), ErrSignatureValidationFailed)
+	}
+}
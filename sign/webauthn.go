@@ -0,0 +1,135 @@
+package sign
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rotisserie/eris"
+)
+
+const (
+	// webAuthnAssertionType is the type of the client data of WebAuthn assertions.
+	webAuthnAssertionType = "webauthn.get"
+	// webAuthnMinAuthenticatorDataLength is the length of the relying party ID hash, the flags, and the signature
+	// counter at the start of the authenticator data.
+	webAuthnMinAuthenticatorDataLength = 37
+	webAuthnFlagsOffset                = 32
+	webAuthnFlagUserPresent            = 0x01
+
+	// p256CoordinateSize is the size of the coordinates of uncompressed P-256 points, which follow a format byte.
+	p256CoordinateSize = 32
+)
+
+// WebAuthnAssertion is a signature made with a passkey. Passkeys sign a challenge along with the data of the
+// authenticator and the client, so the challenge of the assertion must be WebAuthnChallenge of the signed hash.
+// Signatures of the webauthn-p256 scheme are the hex encoded JSON of the assertion, see Encode.
+//
+// The origin of the client and the relying party of the authenticator are not checked. The namespace that is part of
+// the signed hash already binds the signature to a shard.
+type WebAuthnAssertion struct {
+	AuthenticatorData []byte `json:"authenticatorData"`
+	ClientDataJSON    []byte `json:"clientDataJSON"`
+	// Signature is the ASN.1 DER encoded ECDSA signature of the assertion.
+	Signature []byte `json:"signature"`
+}
+
+// webAuthnClientData holds the fields of the client data of an assertion that are checked.
+type webAuthnClientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+}
+
+// WebAuthnChallenge returns the challenge a passkey must sign to sign the given hash.
+func WebAuthnChallenge(hash common.Hash) string {
+	return base64.RawURLEncoding.EncodeToString(hash.Bytes())
+}
+
+// Encode returns the assertion as the signature of a transaction.
+func (a WebAuthnAssertion) Encode() (string, error) {
+	bz, err := json.Marshal(a)
+	if err != nil {
+		return "", eris.Wrap(err, "error encoding WebAuthn assertion")
+	}
+	return common.Bytes2Hex(bz), nil
+}
+
+// verify checks that the assertion is a signature of the hash by the public key.
+func (a WebAuthnAssertion) verify(key *ecdsa.PublicKey, hash common.Hash) error {
+	var clientData webAuthnClientData
+	if err := json.Unmarshal(a.ClientDataJSON, &clientData); err != nil {
+		return eris.Wrap(ErrSignatureValidationFailed, "invalid client data")
+	}
+	if clientData.Type != webAuthnAssertionType {
+		return eris.Wrapf(ErrSignatureValidationFailed, "client data type must be %q", webAuthnAssertionType)
+	}
+	if clientData.Challenge != WebAuthnChallenge(hash) {
+		return eris.Wrap(ErrSignatureValidationFailed, "challenge does not match the hash")
+	}
+	if len(a.AuthenticatorData) < webAuthnMinAuthenticatorDataLength {
+		return eris.Wrap(ErrSignatureValidationFailed, "authenticator data is too short")
+	}
+	if a.AuthenticatorData[webAuthnFlagsOffset]&webAuthnFlagUserPresent == 0 {
+		return eris.Wrap(ErrSignatureValidationFailed, "user was not present")
+	}
+	clientDataHash := sha256.Sum256(a.ClientDataJSON)
+	signed := sha256.Sum256(bytes.Join([][]byte{a.AuthenticatorData, clientDataHash[:]}, nil))
+	if !ecdsa.VerifyASN1(key, signed[:], a.Signature) {
+		return eris.Wrap(ErrSignatureValidationFailed, "")
+	}
+	return nil
+}
+
+type webAuthnP256Scheme struct{}
+
+func (webAuthnP256Scheme) Name() string {
+	return SchemeWebAuthnP256
+}
+
+func (webAuthnP256Scheme) NormalizePublicKey(publicKey string) (string, error) {
+	key, err := parseP256PublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+	return "0x" + common.Bytes2Hex(key.Bytes()), nil
+}
+
+func (webAuthnP256Scheme) Verify(publicKey string, hash common.Hash, signature string) error {
+	key, err := parseP256PublicKey(publicKey)
+	if err != nil {
+		return eris.Wrap(ErrSignatureValidationFailed, err.Error())
+	}
+	bz, err := decodeHex(signature)
+	if err != nil {
+		return eris.Wrap(ErrSignatureValidationFailed, "hex to bytes failed")
+	}
+	var assertion WebAuthnAssertion
+	if err = json.Unmarshal(bz, &assertion); err != nil {
+		return eris.Wrap(ErrSignatureValidationFailed, "invalid WebAuthn assertion")
+	}
+	point := key.Bytes()
+	return assertion.verify(&ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(point[1 : 1+p256CoordinateSize]),
+		Y:     new(big.Int).SetBytes(point[1+p256CoordinateSize:]),
+	}, hash)
+}
+
+// parseP256PublicKey parses a hex encoded uncompressed P-256 point, and checks that it is on the curve.
+func parseP256PublicKey(publicKey string) (*ecdh.PublicKey, error) {
+	bz, err := decodeHex(publicKey)
+	if err != nil {
+		return nil, eris.Wrapf(ErrInvalidPublicKey, "%q is not hex encoded", publicKey)
+	}
+	key, err := ecdh.P256().NewPublicKey(bz)
+	if err != nil {
+		return nil, eris.Wrapf(ErrInvalidPublicKey, "%q is not an uncompressed P-256 point", publicKey)
+	}
+	return key, nil
+}